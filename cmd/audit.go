@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+var auditCMD = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit log related commands",
+}
+
+var verifyAuditCMD = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that the hash chain of the audit log is intact",
+	Run: func(cmd *cobra.Command, args []string) {
+		verifyAuditLog()
+	},
+}
+
+func init() {
+	auditCMD.AddCommand(verifyAuditCMD)
+}
+
+func verifyAuditLog() {
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
+	if err != nil {
+		log.Fatalf("Cannot open database: %s", err)
+	}
+
+	auditService := service.NewAuditService(repository.NewAuditRepository(db))
+	report, err := auditService.Verify(context.Background())
+	if err != nil {
+		log.Fatal("audit log verification failed: ", err)
+	}
+
+	if !report.OK() {
+		log.Fatalf("audit log is broken at event %d, it %s", report.Break.EventID, report.Break.Reason)
+	}
+
+	log.Infof("audit log verification was successful, %d events are intact", report.Events)
+}
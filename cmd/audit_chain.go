@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+	"github.com/jmehdipour/gift-card/internal/worker"
+)
+
+var auditChainOnce bool
+
+var auditChainWorkerCMD = &cobra.Command{
+	Use:   "audit",
+	Short: "Chain the queued audit events, the failed logins, into the audit log",
+	Run: func(cmd *cobra.Command, args []string) {
+		chainAuditEvents()
+	},
+}
+
+func init() {
+	auditChainWorkerCMD.Flags().BoolVar(&auditChainOnce, "once", false, "chain the queued audit events once and exit")
+}
+
+func chainAuditEvents() {
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
+	if err != nil {
+		log.Fatalf("Cannot open database: %s", err)
+	}
+
+	auditService := service.NewAuditService(repository.NewAuditRepository(db))
+	auditChainWorker := worker.NewAuditChainWorker(auditService, config.C.Audit.Chain.Interval, config.C.Audit.Chain.BatchSize)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	if auditChainOnce {
+		chained, err := auditChainWorker.RunOnce(ctx)
+		if err != nil {
+			log.Fatal("audit chain failed: ", err)
+		}
+
+		log.Infof("audit chain was successful, %d audit events chained", chained)
+
+		return
+	}
+
+	auditChainWorker.Run(ctx)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+	"github.com/jmehdipour/gift-card/internal/worker"
+)
+
+var expireOnce bool
+
+var expireWorkerCMD = &cobra.Command{
+	Use:   "expire",
+	Short: "Expire pending gift cards that are past their expiry date",
+	Run: func(cmd *cobra.Command, args []string) {
+		expireGiftCards()
+	},
+}
+
+func init() {
+	expireWorkerCMD.Flags().BoolVar(&expireOnce, "once", false, "expire the overdue gift cards once and exit")
+}
+
+func expireGiftCards() {
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
+	if err != nil {
+		log.Fatalf("Cannot open database: %s", err)
+	}
+
+	giftCardService := service.NewGiftCardService(repository.NewGiftCardRepository(db), repository.NewUnitOfWork(db), config.C.GiftCard.DefaultTTL)
+	expiryWorker := worker.NewExpiryWorker(giftCardService, config.C.GiftCard.Expiry.Interval, config.C.GiftCard.Expiry.BatchSize)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	if expireOnce {
+		expired, err := expiryWorker.RunOnce(ctx)
+		if err != nil {
+			log.Fatal("gift card expiry failed: ", err)
+		}
+
+		log.Infof("gift card expiry was successful, %d gift cards expired", expired)
+
+		return
+	}
+
+	expiryWorker.Run(ctx)
+}
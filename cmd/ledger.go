@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var ledgerCMD = &cobra.Command{
+	Use:   "ledger",
+	Short: "Ledger related commands",
+}
+
+func init() {
+	ledgerCMD.AddCommand(reconcileLedgerCMD)
+}
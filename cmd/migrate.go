@@ -1,59 +1,131 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/jmehdipour/gift-card/internal/config"
 	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/migration"
 )
 
 var migrateDatabaseCMD = &cobra.Command{
 	Use:   "migrate",
 	Short: "Run database migrations",
+}
+
+var migrateUpCMD = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		logMigrations("applied", func(m *migration.Migrator) ([]migration.Migration, error) {
+			return m.Up()
+		})
+	},
+}
+
+var migrateDownCMD = &cobra.Command{
+	Use:   "down [N]",
+	Short: "Roll back the last N applied migrations, one by default",
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		migrateGiftCardDB()
+		n := 1
+		if len(args) == 1 {
+			var err error
+			n, err = strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				log.Fatalf("invalid number of migrations: %s", args[0])
+			}
+		}
+
+		logMigrations("rolled back", func(m *migration.Migrator) ([]migration.Migration, error) {
+			return m.Down(n)
+		})
 	},
 }
 
-func migrateGiftCardDB() {
-	db, err := database.CreateDatabase(config.C.Database.String())
+var migrateToCMD = &cobra.Command{
+	Use:   "to VERSION",
+	Short: "Apply or roll back migrations until VERSION is the latest applied one, 0 rolls back all of them",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		version, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			log.Fatalf("invalid migration version: %s", args[0])
+		}
+
+		logMigrations("migrated", func(m *migration.Migrator) ([]migration.Migration, error) {
+			return m.To(uint(version))
+		})
+	},
+}
+
+var migrateStatusCMD = &cobra.Command{
+	Use:   "status",
+	Short: "Show which migrations are applied",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		statuses, err := newMigrator().Status()
+		if err != nil {
+			log.Fatal("database migration status failed: ", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			state, appliedAt := "pending", ""
+			if s.Applied {
+				state = "applied"
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+
+			if s.Dirty {
+				state = "dirty"
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+
+		_ = w.Flush()
+	},
+}
+
+func init() {
+	migrateDatabaseCMD.AddCommand(migrateUpCMD)
+	migrateDatabaseCMD.AddCommand(migrateDownCMD)
+	migrateDatabaseCMD.AddCommand(migrateToCMD)
+	migrateDatabaseCMD.AddCommand(migrateStatusCMD)
+}
+
+func newMigrator() *migration.Migrator {
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
 	if err != nil {
 		log.Fatalf("Cannot open database: %s", err)
 	}
 
-	drop := `DROP TABLE IF EXISTS gift_cards;
-DROP TABLE IF EXISTS users;`
-
-	_, err = db.Exec(drop)
+	migrations, err := migration.Load(database.DialectOf(db))
 	if err != nil {
-		log.Fatal("database migration (drop tables) failed: ", err)
+		log.Fatal("loading database migrations failed: ", err)
+	}
+
+	return migration.NewMigrator(db, migrations)
+}
+
+func logMigrations(action string, run func(m *migration.Migrator) ([]migration.Migration, error)) {
+	done, err := run(newMigrator())
+	for _, m := range done {
+		log.Infof("%s migration %d_%s", action, m.Version, m.Name)
 	}
 
-	schema := `CREATE TABLE gift_cards (
-    id INT AUTO_INCREMENT,
-    amount DECIMAL(10, 2),
-    sender_id INT,
-    receiver_id INT,
-    created_at DATETIME,
-    updated_at DATETIME,
-    status TINYINT,
-    PRIMARY KEY (id)
-);
-CREATE TABLE users (
-    id INT AUTO_INCREMENT,
-    username VARCHAR(255),
-    email VARCHAR(255),
-    password VARCHAR(255),
-    created_at DATETIME,
-    updated_at DATETIME,
-    PRIMARY KEY (id),
-    UNIQUE (username),
-    UNIQUE (email)
-);
-`
-	_, err = db.Exec(schema)
 	if err != nil {
-		log.Fatal("database migration (create tables) failed: ", err)
+		log.Fatal("database migration failed: ", err)
 	}
+
+	log.Infof("database migration was successful, %d migrations %s", len(done), action)
 }
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+var reconcileLedgerCMD = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Recompute wallet balances from the ledger and report any drift",
+	Run: func(cmd *cobra.Command, args []string) {
+		reconcileLedger()
+	},
+}
+
+func reconcileLedger() {
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
+	if err != nil {
+		log.Fatalf("Cannot open database: %s", err)
+	}
+
+	ledgerService := service.NewLedgerService(repository.NewLedgerRepository(db))
+	report, err := ledgerService.Reconcile(context.Background())
+	if err != nil {
+		log.Fatal("ledger reconciliation failed: ", err)
+	}
+
+	for _, id := range report.UnbalancedTransactionIDs {
+		log.Errorf("ledger transaction %d is not balanced", id)
+	}
+
+	for _, d := range report.Drifts {
+		log.Errorf("account %s drifted: ledger %s, cached %s", d.Account, d.Ledger, d.Cached)
+	}
+
+	if !report.OK() {
+		log.Fatalf("ledger reconciliation found %d drifted accounts and %d unbalanced transactions",
+			len(report.Drifts), len(report.UnbalancedTransactionIDs))
+	}
+
+	log.Infof("ledger reconciliation was successful, %d accounts match", report.Accounts)
+}
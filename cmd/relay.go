@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/messaging"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+	"github.com/jmehdipour/gift-card/internal/worker"
+)
+
+var (
+	relayOnce   bool
+	relayOutput string
+)
+
+var relayWorkerCMD = &cobra.Command{
+	Use:   "relay",
+	Short: "Publish the domain events recorded in the outbox",
+	Run: func(cmd *cobra.Command, args []string) {
+		relayEvents()
+	},
+}
+
+func init() {
+	relayWorkerCMD.Flags().BoolVar(&relayOnce, "once", false, "publish the unpublished events once and exit")
+	relayWorkerCMD.Flags().StringVar(&relayOutput, "output", "", `file to append the events to, "-" for stdout (defaults to outbox.output)`)
+}
+
+func relayEvents() {
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
+	if err != nil {
+		log.Fatalf("Cannot open database: %s", err)
+	}
+
+	output := relayOutput
+	if output == "" {
+		output = config.C.Outbox.Output
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "-" {
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Fatalf("Cannot open outbox output [%s]: %s", output, err)
+		}
+
+		defer f.Close()
+		w = f
+	}
+
+	outboxService := service.NewOutboxService(repository.NewOutboxRepository(db), messaging.NewWriterPublisher(w))
+	relayWorker := worker.NewRelayWorker(outboxService, config.C.Outbox.Interval, config.C.Outbox.BatchSize)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	if relayOnce {
+		published, err := relayWorker.RunOnce(ctx)
+		if err != nil {
+			log.Fatal("outbox relay failed: ", err)
+		}
+
+		log.Infof("outbox relay was successful, %d events published", published)
+
+		return
+	}
+
+	relayWorker.Run(ctx)
+}
@@ -22,6 +22,10 @@ func init() {
 
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(databaseCMD)
+	rootCmd.AddCommand(ledgerCMD)
+	rootCmd.AddCommand(workerCMD)
+	rootCmd.AddCommand(usersCMD)
+	rootCmd.AddCommand(auditCMD)
 }
 
 func preRun(_ *cobra.Command, _ []string) {
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+var usersCMD = &cobra.Command{
+	Use:   "users",
+	Short: "User related commands",
+}
+
+var userRolesCMD = &cobra.Command{
+	Use:   "roles <email> [role...]",
+	Short: "Replace the roles of a user, no roles takes them all away",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setUserRoles(args[0], args[1:])
+	},
+}
+
+var userUnlockCMD = &cobra.Command{
+	Use:   "unlock <email>",
+	Short: "Forget the failed logins of a user, which lifts a lockout",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		unlockUser(args[0])
+	},
+}
+
+func init() {
+	usersCMD.AddCommand(userRolesCMD)
+	usersCMD.AddCommand(userUnlockCMD)
+}
+
+func setUserRoles(email string, names []string) {
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
+	if err != nil {
+		log.Fatalf("Cannot open database: %s", err)
+	}
+
+	roles := make([]domain.Role, len(names))
+	for i, name := range names {
+		roles[i] = domain.Role(name)
+	}
+
+	userService := service.NewUserService(repository.NewUserRepository(db), repository.NewLoginFailureRepository(db))
+	user, err := userService.SetRoles(context.Background(), email, roles)
+	if err != nil {
+		log.Fatal("setting the roles failed: ", err)
+	}
+
+	log.Infof("user %s now has the roles %v and the permissions %v", user.Email, user.Roles, user.Permissions())
+}
+
+func unlockUser(email string) {
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
+	if err != nil {
+		log.Fatalf("Cannot open database: %s", err)
+	}
+
+	userService := service.NewUserService(repository.NewUserRepository(db), repository.NewLoginFailureRepository(db))
+	user, err := userService.FindUserByEmail(context.Background(), email)
+	if err != nil {
+		log.Fatal("finding the user failed: ", err)
+	}
+
+	if _, err := userService.UnlockUser(context.Background(), user.ID, nil); err != nil {
+		log.Fatal("unlocking the user failed: ", err)
+	}
+
+	log.Infof("user %s is unlocked", user.Email)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/messaging"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+	"github.com/jmehdipour/gift-card/internal/worker"
+)
+
+var webhooksOnce bool
+
+var webhooksWorkerCMD = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Send the due webhook deliveries",
+	Run: func(cmd *cobra.Command, args []string) {
+		deliverWebhooks()
+	},
+}
+
+func init() {
+	webhooksWorkerCMD.Flags().BoolVar(&webhooksOnce, "once", false, "send the due webhook deliveries once and exit")
+}
+
+func deliverWebhooks() {
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
+	if err != nil {
+		log.Fatalf("Cannot open database: %s", err)
+	}
+
+	deliveryConfig := config.C.Webhook.Delivery
+	sender := messaging.NewHTTPWebhookSender(messaging.NewWebhookHTTPClient(deliveryConfig.Timeout))
+	webhookService := service.NewWebhookService(repository.NewWebhookRepository(db), sender, deliveryConfig.Lease)
+	webhookWorker := worker.NewWebhookWorker(webhookService, deliveryConfig.Interval, deliveryConfig.BatchSize)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	if webhooksOnce {
+		delivered, err := webhookWorker.RunOnce(ctx)
+		if err != nil {
+			log.Fatal("webhook delivery failed: ", err)
+		}
+
+		log.Infof("webhook delivery was successful, %d deliveries attempted", delivered)
+
+		return
+	}
+
+	webhookWorker.Run(ctx)
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var workerCMD = &cobra.Command{
+	Use:   "worker",
+	Short: "Background worker related commands",
+}
+
+func init() {
+	workerCMD.AddCommand(auditChainWorkerCMD)
+	workerCMD.AddCommand(expireWorkerCMD)
+	workerCMD.AddCommand(relayWorkerCMD)
+	workerCMD.AddCommand(webhooksWorkerCMD)
+}
@@ -4,6 +4,10 @@ const envPrefix = "gift_card"
 
 var builtinConfig = []byte(`http_server:
   address: 0.0.0.0:8080
+  request_timeout: 10s
+  idempotency:
+    lease: 1m
+    ttl: 24h
 database:
   driver: mysql
   host: localhost
@@ -12,4 +16,47 @@ database:
   user: gift-card-app
   password: password
 user:
-  secret: example-secret`)
+  ephemeral_signing_key: false
+  issuer: gift-card
+  audience: gift-card
+  access_token_ttl: 15m
+  refresh_token_ttl: 720h
+  login:
+    account:
+      window: 15m
+      free_failures: 3
+      base_delay: 1s
+      max_delay: 30s
+      lockout_after: 10
+      lockout_duration: 15m
+    ip:
+      window: 15m
+      free_failures: 20
+      base_delay: 1s
+      max_delay: 30s
+      lockout_after: 100
+      lockout_duration: 15m
+gift_card:
+  default_ttl: 720h
+  expiry:
+    enabled: true
+    interval: 1m
+    batch_size: 100
+outbox:
+  interval: 5s
+  batch_size: 100
+  output: "-"
+webhook:
+  delivery:
+    enabled: true
+    interval: 10s
+    batch_size: 20
+    timeout: 10s
+    lease: 10m
+audit:
+  chain:
+    enabled: true
+    interval: 1s
+    batch_size: 500
+seed:
+  admin_password: ""`)
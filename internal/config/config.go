@@ -3,7 +3,11 @@ package config
 import (
 	"bytes"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	log "github.com/sirupsen/logrus"
@@ -16,12 +20,38 @@ type Config struct {
 	HTTPServer HTTPServer  `yaml:"http_server"`
 	Database   SQLDatabase `yaml:"database"`
 	User       User        `yaml:"user"`
+	GiftCard   GiftCard    `yaml:"gift_card"`
+	Outbox     Outbox      `yaml:"outbox"`
+	Webhook    Webhook     `yaml:"webhook"`
+	Audit      Audit       `yaml:"audit"`
+	Seed       Seed        `yaml:"seed"`
 }
 
+// HTTPServer configures the API. RequestTimeout bounds how long the queries
+// of a request may run, zero means they are only cancelled when the client
+// goes away. TrustedProxies are the CIDRs of the proxies whose
+// X-Forwarded-For header names the client IP, without any the IP of the
+// connection is the client IP and the header is ignored.
 type HTTPServer struct {
-	Address string `yaml:"address"`
+	Address        string        `yaml:"address"`
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	TrustedProxies []string      `yaml:"trusted_proxies"`
+	Idempotency    Idempotency   `yaml:"idempotency"`
 }
 
+// Idempotency configures the Idempotency-Key header. A request holds its key
+// for Lease, after which a retry may take over a key whose request never
+// completed, so it should exceed RequestTimeout. Keys and their responses are
+// kept for TTL.
+type Idempotency struct {
+	Lease time.Duration `yaml:"lease"`
+	TTL   time.Duration `yaml:"ttl"`
+}
+
+// SQLDatabase configures the database. Driver is mysql, postgres, sqlite or
+// memory, for sqlite DB is the path of the database file and the other fields
+// are not used. memory keeps the data in the process of the server and needs
+// no database at all.
 type SQLDatabase struct {
 	Driver   string `yaml:"driver"`
 	Host     string `yaml:"host"`
@@ -29,6 +59,8 @@ type SQLDatabase struct {
 	DB       string `yaml:"db"`
 	User     string `yaml:"user"`
 	Password string `yaml:"password"`
+	// SSLMode is only used by postgres, it defaults to disable.
+	SSLMode string `yaml:"ssl_mode"`
 }
 
 // String returns SQLDatabase formatted DSN
@@ -36,17 +68,152 @@ func (d *SQLDatabase) String() string {
 	switch d.Driver {
 	case "mysql":
 		return d.mysqlDSN()
+	case "postgres":
+		return d.postgresDSN()
+	case "sqlite":
+		return d.sqliteDSN()
+	case "memory":
+		return ""
 	}
 
 	panic("SQLDatabase driver is not supported")
 }
 
 func (d *SQLDatabase) mysqlDSN() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&multiStatements=true&interpolateParams=true&collation=utf8mb4_general_ci&clientFoundRows=true", d.User, d.Password, d.Host, d.Port, d.DB)
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&interpolateParams=true&collation=utf8mb4_general_ci&clientFoundRows=true", d.User, d.Password, d.Host, d.Port, d.DB)
 }
 
+func (d *SQLDatabase) postgresDSN() string {
+	sslMode := d.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+		Path:     d.DB,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+
+	return dsn.String()
+}
+
+// sqliteDSN waits for locks instead of failing on them and begins every
+// transaction with the write lock, SQLite has no row locks to take instead.
+func (d *SQLDatabase) sqliteDSN() string {
+	params := url.Values{
+		"_pragma":      {"busy_timeout(5000)", "journal_mode(WAL)"},
+		"_txlock":      {"immediate"},
+		"_time_format": {"sqlite"},
+	}
+
+	return "file:" + d.DB + "?" + params.Encode()
+}
+
+// User configures the tokens of the users. Access tokens are signed with the
+// signing key ActiveKeyID, name Issuer and Audience, which they are checked
+// against, and are good for AccessTokenTTL. A session lasts while it is
+// refreshed within RefreshTokenTTL of the last refresh. The server does not
+// start without signing keys, unless EphemeralSigningKey lets it sign with a
+// key it generates on start, which is only for development: tokens do not
+// survive a restart and other instances cannot verify them.
 type User struct {
-	Secret string `yaml:"secret"`
+	SigningKeys         []SigningKey  `yaml:"signing_keys"`
+	ActiveKeyID         string        `yaml:"active_key_id"`
+	EphemeralSigningKey bool          `yaml:"ephemeral_signing_key"`
+	Issuer              string        `yaml:"issuer"`
+	Audience            string        `yaml:"audience"`
+	AccessTokenTTL      time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL     time.Duration `yaml:"refresh_token_ttl"`
+	Login               Login         `yaml:"login"`
+}
+
+// Login throttles failed logins. Account counts the failures of an email,
+// whether a user has it or not, IP the ones of a client IP.
+type Login struct {
+	Account LoginPolicy `yaml:"account"`
+	IP      LoginPolicy `yaml:"ip"`
+}
+
+// LoginPolicy is a domain.LoginPolicy. Failures are forgotten Window after
+// the last one. After FreeFailures of them logins are delayed by BaseDelay,
+// doubled with every further failure up to MaxDelay. LockoutAfter failures
+// lock logins for LockoutDuration, zero never locks.
+type LoginPolicy struct {
+	Window          time.Duration `yaml:"window"`
+	FreeFailures    int           `yaml:"free_failures"`
+	BaseDelay       time.Duration `yaml:"base_delay"`
+	MaxDelay        time.Duration `yaml:"max_delay"`
+	LockoutAfter    int           `yaml:"lockout_after"`
+	LockoutDuration time.Duration `yaml:"lockout_duration"`
+}
+
+// SigningKey is a private key in a PEM file, ID is its kid. Algorithm is
+// RS256 or EdDSA and defaults to the one of the key. The keys that are not
+// the active one only verify the tokens they signed, so rotating adds a new
+// key, makes it the active one and drops the old one once its tokens expired.
+type SigningKey struct {
+	ID             string `yaml:"id"`
+	Algorithm      string `yaml:"algorithm"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+}
+
+type GiftCard struct {
+	// DefaultTTL is how long gift cards created without an expiry date stay
+	// pending, zero means they never expire.
+	DefaultTTL time.Duration `yaml:"default_ttl"`
+	Expiry     Expiry        `yaml:"expiry"`
+}
+
+// Expiry configures the worker that expires overdue gift cards.
+type Expiry struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+}
+
+// Outbox configures the worker that relays outbox events. Output is the file
+// the events are written to, "-" writes them to stdout.
+type Outbox struct {
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+	Output    string        `yaml:"output"`
+}
+
+type Webhook struct {
+	Delivery WebhookDelivery `yaml:"delivery"`
+}
+
+// WebhookDelivery configures the worker that sends webhook deliveries.
+// Timeout bounds a single request and Lease is how long a claimed batch is
+// hidden from other workers, so it should exceed BatchSize times Timeout.
+type WebhookDelivery struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+	Timeout   time.Duration `yaml:"timeout"`
+	Lease     time.Duration `yaml:"lease"`
+}
+
+type Audit struct {
+	Chain AuditChain `yaml:"chain"`
+}
+
+// AuditChain configures the worker that chains the queued audit events, the
+// failed logins, into the audit log. Until it runs they are not listed.
+type AuditChain struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+}
+
+// Seed configures the demo data of the seed command and the memory driver.
+// The admin, admin@example.com, is only seeded with AdminPassword, without
+// it there is no admin to log in as.
+type Seed struct {
+	AdminPassword string `yaml:"admin_password"`
 }
 
 func Init(filename string) {
@@ -82,5 +249,64 @@ func Init(filename string) {
 		log.Fatalf("failed on config unmarshal: %s: %v", filename, err)
 	}
 
+	if err := c.Validate(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+
 	C = c
 }
+
+// Validate checks the settings a zero or negative value breaks: the
+// intervals and batch sizes of the workers and the durations they and the
+// idempotency keys are held for, and the issuer and audience of the access
+// tokens, an empty one would not be checked.
+func (c *Config) Validate() error {
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"http_server.idempotency.lease", c.HTTPServer.Idempotency.Lease},
+		{"http_server.idempotency.ttl", c.HTTPServer.Idempotency.TTL},
+		{"gift_card.expiry.interval", c.GiftCard.Expiry.Interval},
+		{"outbox.interval", c.Outbox.Interval},
+		{"webhook.delivery.interval", c.Webhook.Delivery.Interval},
+		{"webhook.delivery.timeout", c.Webhook.Delivery.Timeout},
+		{"webhook.delivery.lease", c.Webhook.Delivery.Lease},
+		{"audit.chain.interval", c.Audit.Chain.Interval},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %s", d.name, d.value)
+		}
+	}
+
+	names := []struct {
+		name  string
+		value string
+	}{
+		{"user.issuer", c.User.Issuer},
+		{"user.audience", c.User.Audience},
+	}
+	for _, n := range names {
+		if n.value == "" {
+			return fmt.Errorf("%s must be set", n.name)
+		}
+	}
+
+	batchSizes := []struct {
+		name  string
+		value int
+	}{
+		{"gift_card.expiry.batch_size", c.GiftCard.Expiry.BatchSize},
+		{"outbox.batch_size", c.Outbox.BatchSize},
+		{"webhook.delivery.batch_size", c.Webhook.Delivery.BatchSize},
+		{"audit.chain.batch_size", c.Audit.Chain.BatchSize},
+	}
+	for _, b := range batchSizes {
+		if b.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %d", b.name, b.value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ConfigTestSuite) SetupTest() {
+	Init("")
+}
+
+func (suite *ConfigTestSuite) TestValidate_Builtin_Success() {
+	require := suite.Require()
+
+	require.NoError(C.Validate())
+}
+
+func (suite *ConfigTestSuite) TestInit_SeedAdminPassword_Success() {
+	require := suite.Require()
+
+	require.Empty(C.Seed.AdminPassword)
+
+	suite.T().Setenv("GIFT_CARD_SEED_ADMIN_PASSWORD", "secret")
+	Init("")
+
+	require.Equal("secret", C.Seed.AdminPassword)
+}
+
+func (suite *ConfigTestSuite) TestValidate_Failure() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		change        func(c *Config)
+		expectedError string
+	}{
+		{func(c *Config) { c.GiftCard.Expiry.Interval = 0 }, "gift_card.expiry.interval must be positive, got 0s"},
+		{func(c *Config) { c.Outbox.Interval = -time.Second }, "outbox.interval must be positive, got -1s"},
+		{func(c *Config) { c.HTTPServer.Idempotency.Lease = 0 }, "http_server.idempotency.lease must be positive, got 0s"},
+		{func(c *Config) { c.Webhook.Delivery.BatchSize = 0 }, "webhook.delivery.batch_size must be positive, got 0"},
+		{func(c *Config) { c.Audit.Chain.Interval = 0 }, "audit.chain.interval must be positive, got 0s"},
+		{func(c *Config) { c.Audit.Chain.BatchSize = 0 }, "audit.chain.batch_size must be positive, got 0"},
+		{func(c *Config) { c.GiftCard.Expiry.BatchSize = -1 }, "gift_card.expiry.batch_size must be positive, got -1"},
+		{func(c *Config) { c.User.Issuer = "" }, "user.issuer must be set"},
+		{func(c *Config) { c.User.Audience = "" }, "user.audience must be set"},
+	} {
+		c := *C
+		tc.change(&c)
+
+		require.EqualError(c.Validate(), tc.expectedError)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
@@ -0,0 +1,261 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditUserRegistered        AuditAction = "user.registered"
+	AuditUserLoggedIn          AuditAction = "user.logged_in"
+	AuditUserLoginFailed       AuditAction = "user.login_failed"
+	AuditUserRolesChanged      AuditAction = "user.roles_changed"
+	AuditGiftCardCreated       AuditAction = "gift_card.created"
+	AuditGiftCardStatusChanged AuditAction = "gift_card.status_changed"
+	AuditGiftCardRedeemed      AuditAction = "gift_card.redeemed"
+	AuditWalletDeposited       AuditAction = "wallet.deposited"
+	AuditLoginLocked           AuditAction = "login.locked"
+	AuditLoginUnlocked         AuditAction = "login.unlocked"
+	AuditAdminRequest          AuditAction = "admin.request"
+)
+
+const (
+	AuditTargetUser     = "user"
+	AuditTargetGiftCard = "gift_card"
+	AuditTargetRequest  = "request"
+	AuditTargetLogin    = "login"
+)
+
+// AuditEvent records who changed what. Before and After are JSON snapshots
+// of the target around the change, nil where there is nothing to show.
+//
+// Events are append only and chained: Hash covers the event and PrevHash, the
+// Hash of the event recorded before it, so an event that is changed, removed
+// or slipped in breaks the chain from there on, see VerifyAuditChain.
+type AuditEvent struct {
+	ID         uint
+	ActorID    *uint
+	Action     AuditAction
+	TargetType string
+	TargetID   *uint
+	Before     []byte
+	After      []byte
+	RequestID  string
+	IP         string
+	UserAgent  string
+	PrevHash   string
+	Hash       string
+	CreatedAt  time.Time
+}
+
+// auditHashInput is what the hash of an event covers. CreatedAt is in
+// microseconds, the precision every database keeps.
+type auditHashInput struct {
+	PrevHash   string      `json:"prev_hash"`
+	ActorID    *uint       `json:"actor_id"`
+	Action     AuditAction `json:"action"`
+	TargetType string      `json:"target_type"`
+	TargetID   *uint       `json:"target_id"`
+	Before     string      `json:"before"`
+	After      string      `json:"after"`
+	RequestID  string      `json:"request_id"`
+	IP         string      `json:"ip"`
+	UserAgent  string      `json:"user_agent"`
+	CreatedAt  int64       `json:"created_at"`
+}
+
+// Chain links the event to the event before it, whose hash is prevHash, and
+// sets its time and hash. prevHash is empty for the first event.
+func (e *AuditEvent) Chain(prevHash string, now time.Time) {
+	e.PrevHash = prevHash
+	e.CreatedAt = now.UTC().Truncate(time.Microsecond)
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash returns the hex SHA-256 of the event and its PrevHash.
+func (e *AuditEvent) ComputeHash() string {
+	b, _ := json.Marshal(auditHashInput{
+		PrevHash:   e.PrevHash,
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Before:     string(e.Before),
+		After:      string(e.After),
+		RequestID:  e.RequestID,
+		IP:         e.IP,
+		UserAgent:  e.UserAgent,
+		CreatedAt:  e.CreatedAt.UnixMicro(),
+	})
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditChainBreak is the first event of a chain that does not add up.
+type AuditChainBreak struct {
+	EventID uint
+	Reason  string
+}
+
+// VerifyAuditChain checks events, which follow the event whose hash is
+// prevHash in the order they were recorded. It returns the hash of the last
+// event, to carry on with the next events, and the first break if there is
+// one.
+func VerifyAuditChain(prevHash string, events []AuditEvent) (string, *AuditChainBreak) {
+	for _, event := range events {
+		if event.PrevHash != prevHash {
+			return prevHash, &AuditChainBreak{EventID: event.ID, Reason: "does not follow the event before it"}
+		}
+
+		if event.ComputeHash() != event.Hash {
+			return prevHash, &AuditChainBreak{EventID: event.ID, Reason: "does not match its hash"}
+		}
+
+		prevHash = event.Hash
+	}
+
+	return prevHash, nil
+}
+
+// AuditChainReport is the outcome of checking the whole audit log.
+type AuditChainReport struct {
+	Events int
+	Break  *AuditChainBreak
+}
+
+func (r AuditChainReport) OK() bool {
+	return r.Break == nil
+}
+
+// The snapshots are what the audit log shows of a target. They leave the
+// secrets out, the password of a user and the code of a gift card.
+type (
+	AuditUser struct {
+		ID    uint   `json:"id"`
+		Email string `json:"email"`
+		Roles []Role `json:"roles"`
+	}
+
+	AuditGiftCard struct {
+		ID              uint       `json:"id"`
+		Amount          string     `json:"amount"`
+		RemainingAmount string     `json:"remaining_amount"`
+		Currency        string     `json:"currency"`
+		Status          string     `json:"status"`
+		GifterID        uint       `json:"gifter_id"`
+		GifteeID        uint       `json:"giftee_id"`
+		ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+		Version         uint       `json:"version"`
+	}
+
+	AuditLoginFailures struct {
+		Key         string     `json:"key"`
+		Failures    int        `json:"failures"`
+		LockedUntil *time.Time `json:"locked_until,omitempty"`
+	}
+
+	AuditDeposit struct {
+		UserID   uint   `json:"user_id"`
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}
+)
+
+func NewAuditUser(u User) AuditUser {
+	roles := u.Roles
+	if roles == nil {
+		roles = []Role{}
+	}
+
+	return AuditUser{ID: u.ID, Email: u.Email, Roles: roles}
+}
+
+func NewAuditGiftCard(g GiftCard) AuditGiftCard {
+	return AuditGiftCard{
+		ID:              g.ID,
+		Amount:          g.Amount.Decimal(),
+		RemainingAmount: g.RemainingAmount.Decimal(),
+		Currency:        g.Amount.Currency,
+		Status:          g.Status.String(),
+		GifterID:        g.GifterID,
+		GifteeID:        g.GifteeID,
+		ExpiresAt:       g.ExpiresAt,
+		Version:         g.Version,
+	}
+}
+
+func NewAuditLoginFailures(f LoginFailures) AuditLoginFailures {
+	return AuditLoginFailures{Key: f.Key, Failures: f.Count, LockedUntil: f.LockedUntil}
+}
+
+func NewAuditDeposit(userID uint, amount Money) AuditDeposit {
+	return AuditDeposit{UserID: userID, Amount: amount.Decimal(), Currency: amount.Currency}
+}
+
+// NewAuditEvent returns the event of the action of the actor on the target,
+// with the request of ctx. before and after are encoded as JSON unless they
+// are nil.
+func NewAuditEvent(ctx context.Context, action AuditAction, actorID *uint, targetType string, targetID *uint, before, after any) (AuditEvent, error) {
+	request := RequestInfoFrom(ctx)
+	event := AuditEvent{
+		ActorID:    copyUint(actorID),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   copyUint(targetID),
+		RequestID:  request.ID,
+		IP:         request.IP,
+		UserAgent:  request.UserAgent,
+	}
+
+	var err error
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			return AuditEvent{}, err
+		}
+	}
+
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			return AuditEvent{}, err
+		}
+	}
+
+	return event, nil
+}
+
+// RequestInfo describes the request an operation serves, for the audit log.
+type RequestInfo struct {
+	ID        string
+	IP        string
+	UserAgent string
+}
+
+type requestInfoKey struct{}
+
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFrom returns the request of ctx, which is empty outside of a
+// request, for the workers and the commands.
+func RequestInfoFrom(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+
+	return info
+}
+
+func copyUint(u *uint) *uint {
+	if u == nil {
+		return nil
+	}
+
+	c := *u
+
+	return &c
+}
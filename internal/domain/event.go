@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventGiftCardCreated       EventType = "gift_card.created"
+	EventGiftCardStatusChanged EventType = "gift_card.status_changed"
+	EventGiftCardRedeemed      EventType = "gift_card.redeemed"
+)
+
+// Event is a domain event waiting in the outbox to be published. Payload is
+// the JSON encoding of one of the event payloads below.
+type Event struct {
+	ID          uint
+	Type        EventType
+	AggregateID uint
+	Payload     []byte
+	CreatedAt   time.Time
+}
+
+// The event payloads are published as is, so their JSON encoding is part of
+// the contract with the consumers.
+type (
+	GiftCardCreated struct {
+		GiftCardID uint       `json:"gift_card_id"`
+		GifterID   uint       `json:"gifter_id"`
+		GifteeID   uint       `json:"giftee_id"`
+		Amount     string     `json:"amount"`
+		Currency   string     `json:"currency"`
+		ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	}
+
+	GiftCardStatusChanged struct {
+		GiftCardID uint   `json:"gift_card_id"`
+		GifterID   uint   `json:"gifter_id"`
+		GifteeID   uint   `json:"giftee_id"`
+		From       string `json:"from"`
+		To         string `json:"to"`
+		ActorID    *uint  `json:"actor_id"`
+	}
+
+	GiftCardRedeemed struct {
+		GiftCardID      uint   `json:"gift_card_id"`
+		RedemptionID    uint   `json:"redemption_id"`
+		RedeemerID      uint   `json:"redeemer_id"`
+		Amount          string `json:"amount"`
+		RemainingAmount string `json:"remaining_amount"`
+		Currency        string `json:"currency"`
+	}
+)
+
+func NewGiftCardCreatedEvent(g GiftCard) (Event, error) {
+	return newEvent(EventGiftCardCreated, g.ID, GiftCardCreated{
+		GiftCardID: g.ID,
+		GifterID:   g.GifterID,
+		GifteeID:   g.GifteeID,
+		Amount:     g.Amount.Decimal(),
+		Currency:   g.Amount.Currency,
+		ExpiresAt:  g.ExpiresAt,
+	})
+}
+
+func NewGiftCardStatusChangedEvent(g GiftCard, from GiftCardStatus, actorID *uint) (Event, error) {
+	return newEvent(EventGiftCardStatusChanged, g.ID, GiftCardStatusChanged{
+		GiftCardID: g.ID,
+		GifterID:   g.GifterID,
+		GifteeID:   g.GifteeID,
+		From:       from.String(),
+		To:         g.Status.String(),
+		ActorID:    actorID,
+	})
+}
+
+func NewGiftCardRedeemedEvent(r GiftCardRedemption) (Event, error) {
+	return newEvent(EventGiftCardRedeemed, r.GiftCardID, GiftCardRedeemed{
+		GiftCardID:      r.GiftCardID,
+		RedemptionID:    r.ID,
+		RedeemerID:      r.RedeemerID,
+		Amount:          r.Amount.Decimal(),
+		RemainingAmount: r.RemainingAmount.Decimal(),
+		Currency:        r.Amount.Currency,
+	})
+}
+
+func newEvent(t EventType, aggregateID uint, payload any) (Event, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{Type: t, AggregateID: aggregateID, Payload: b}, nil
+}
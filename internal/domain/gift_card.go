@@ -1,15 +1,35 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
+var (
+	ErrGiftCardNotFound   = errors.New("gift card not found")
+	ErrGiftCardExpired    = errors.New("gift card has expired")
+	ErrGiftCardNotOverdue = errors.New("gift card is not overdue")
+	ErrNotGiftCardGiftee  = errors.New("user is not the receiver of the gift card")
+	ErrNotGiftCardGifter  = errors.New("user is not the sender of the gift card")
+	ErrGiftCardToSelf     = errors.New("gift card cannot be sent to its sender")
+	ErrGifteeNotFound     = errors.New("giftee not found")
+
+	ErrGiftCardVersionMismatch = errors.New("gift card has been changed since it was read")
+
+	ErrGiftCardNotRedeemable    = errors.New("gift card is not accepted")
+	ErrRedemptionExceedsBalance = errors.New("redemption exceeds the remaining amount of the gift card")
+)
+
 type GiftCardStatus int
 
 var validGiftCard = map[GiftCardStatus]struct{}{
-	GCSAccepted: {},
-	GCSRejected: {},
-	GCSPending:  {},
+	GCSAccepted:  {},
+	GCSRejected:  {},
+	GCSPending:   {},
+	GCSCancelled: {},
+	GCSExpired:   {},
+	GCSVoided:    {},
 }
 
 func (s GiftCardStatus) IsValid() bool {
@@ -22,21 +42,199 @@ const (
 	GCSAccepted GiftCardStatus = iota
 	GCSRejected
 	GCSPending
+	GCSCancelled
+	GCSExpired
+	// GCSVoided is a gift card the staff took back from the gifter and
+	// giftee. The amount a pending card holds goes back to the gifter, and so
+	// does the remaining amount of an accepted one, its redemptions stand.
+	GCSVoided
 )
 
+var giftCardStatusNames = map[GiftCardStatus]string{
+	GCSAccepted:  "accepted",
+	GCSRejected:  "rejected",
+	GCSPending:   "pending",
+	GCSCancelled: "cancelled",
+	GCSExpired:   "expired",
+	GCSVoided:    "voided",
+}
+
+func (s GiftCardStatus) String() string {
+	if name, ok := giftCardStatusNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// giftCardTransitions lists the statuses a gift card can move to from each
+// status. Statuses without an entry are final.
+var giftCardTransitions = map[GiftCardStatus][]GiftCardStatus{
+	GCSPending:  {GCSAccepted, GCSRejected, GCSCancelled, GCSExpired, GCSVoided},
+	GCSAccepted: {GCSVoided},
+}
+
+func (s GiftCardStatus) CanTransitionTo(to GiftCardStatus) bool {
+	for _, allowed := range giftCardTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// InvalidTransitionError is returned when a gift card is asked to move to a
+// status that is not reachable from its current one.
+type InvalidTransitionError struct {
+	From GiftCardStatus
+	To   GiftCardStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("gift card cannot move from %s to %s", e.From, e.To)
+}
+
 type GiftCard struct {
-	ID           uint
-	Amount       float64
-	Status       GiftCardStatus
-	GifterID     uint
-	GifteeID     uint
-	CreationDate time.Time
+	ID     uint
+	Code   string
+	Amount Money
+	// RemainingAmount is the part of the amount that has not been redeemed yet.
+	RemainingAmount Money
+	Status          GiftCardStatus
+	GifterID        uint
+	GifteeID        uint
+	CreationDate    time.Time
+	UpdatedAt       time.Time
+	// ExpiresAt is the time after which a pending gift card can no longer be
+	// decided on, nil means it never expires.
+	ExpiresAt *time.Time
+	// Version starts at 1 and is bumped by every change of the gift card.
+	Version uint
+}
+
+// IsOverdue reports whether the gift card is pending past its expiry date.
+func (c *GiftCard) IsOverdue(now time.Time) bool {
+	return c.Status == GCSPending && c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
 }
 
+// CanUpdateStatus reports whether the gift card is not in a final status.
 func (c *GiftCard) CanUpdateStatus() bool {
-	if c.Status == GCSPending {
-		return true
+	return len(giftCardTransitions[c.Status]) > 0
+}
+
+// IsParticipant reports whether the user is the gifter or the giftee of the
+// gift card. Nobody else is told that the card exists.
+func (c *GiftCard) IsParticipant(userID uint) bool {
+	return c.GifterID == userID || c.GifteeID == userID
+}
+
+// CheckActor returns an error if the user may not move the gift card to
+// status: the gifter can only cancel it and any other decision is up to the
+// giftee.
+func (c *GiftCard) CheckActor(status GiftCardStatus, userID uint) error {
+	if status == GCSCancelled {
+		if c.GifterID != userID {
+			return ErrNotGiftCardGifter
+		}
+
+		return nil
 	}
 
-	return false
+	if c.GifteeID != userID {
+		return ErrNotGiftCardGiftee
+	}
+
+	return nil
+}
+
+// TransitionTo moves the gift card to the given status if the state machine
+// allows it. Once a gift card is overdue it can only expire.
+func (c *GiftCard) TransitionTo(status GiftCardStatus, now time.Time) error {
+	if !c.Status.CanTransitionTo(status) {
+		return &InvalidTransitionError{From: c.Status, To: status}
+	}
+
+	overdue := c.IsOverdue(now)
+	if status == GCSExpired && !overdue {
+		return ErrGiftCardNotOverdue
+	}
+
+	if status != GCSExpired && overdue {
+		return ErrGiftCardExpired
+	}
+
+	c.moveTo(status)
+
+	return nil
+}
+
+// ForceTransitionTo moves the gift card to the given status if the state
+// machine allows it, whatever its expiry date. It is how the staff expire a
+// card early or void an overdue one.
+func (c *GiftCard) ForceTransitionTo(status GiftCardStatus) error {
+	if !c.Status.CanTransitionTo(status) {
+		return &InvalidTransitionError{From: c.Status, To: status}
+	}
+
+	c.moveTo(status)
+
+	return nil
+}
+
+// moveTo sets the status. An accepted card only moves when it is voided, its
+// remaining amount goes back to the gifter then and nothing is left on it.
+func (c *GiftCard) moveTo(status GiftCardStatus) {
+	if c.Status == GCSAccepted {
+		c.RemainingAmount = NewMoney(0, c.RemainingAmount.Currency)
+	}
+
+	c.Status = status
+}
+
+// Redeem spends amount of the remaining amount of an accepted gift card.
+func (c *GiftCard) Redeem(amount Money) error {
+	if c.Status != GCSAccepted {
+		return ErrGiftCardNotRedeemable
+	}
+
+	if !amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+
+	cmp, err := amount.Cmp(c.RemainingAmount)
+	if err != nil {
+		return err
+	}
+
+	if cmp > 0 {
+		return ErrRedemptionExceedsBalance
+	}
+
+	c.RemainingAmount, err = c.RemainingAmount.Sub(amount)
+
+	return err
+}
+
+// GiftCardRedemption records a spend of a gift card by a redeemer.
+// RemainingAmount is what was left on the card after the spend.
+type GiftCardRedemption struct {
+	ID              uint
+	GiftCardID      uint
+	RedeemerID      uint
+	Amount          Money
+	RemainingAmount Money
+	CreatedAt       time.Time
+}
+
+// GiftCardStatusChange is an entry of the status history of a gift card. From
+// is nil for the entry that records the creation of the card and ActorID is
+// nil when the change was not made by a user.
+type GiftCardStatusChange struct {
+	ID         uint
+	GiftCardID uint
+	From       *GiftCardStatus
+	To         GiftCardStatus
+	ActorID    *uint
+	CreatedAt  time.Time
 }
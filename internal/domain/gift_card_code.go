@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidGiftCardCode = errors.New("invalid gift card code")
+
+// giftCardCodeAlphabet is Crockford's base32 alphabet, it leaves out I, L, O
+// and U so codes can be read out and typed without confusion.
+const giftCardCodeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// giftCardCodeLength is the length of a code including its check character.
+// The other 15 characters are random and carry 75 bits of entropy.
+const giftCardCodeLength = 16
+
+// NewGiftCardCode generates a random gift card code whose last character is
+// a Luhn mod 32 check character of the others.
+func NewGiftCardCode() (string, error) {
+	random := make([]byte, giftCardCodeLength-1)
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", err
+	}
+
+	code := make([]byte, 0, giftCardCodeLength)
+	for _, b := range random {
+		code = append(code, giftCardCodeAlphabet[b%32])
+	}
+
+	return string(append(code, giftCardCodeCheck(string(code)))), nil
+}
+
+// ParseGiftCardCode normalizes a code the way a person may type it, e.g.
+// "abcd-efgh-jkmn-pqr7", and validates its check character.
+func ParseGiftCardCode(code string) (string, error) {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	code = strings.NewReplacer("I", "1", "L", "1", "O", "0").Replace(code)
+	if len(code) != giftCardCodeLength {
+		return "", ErrInvalidGiftCardCode
+	}
+
+	for _, c := range code {
+		if !strings.ContainsRune(giftCardCodeAlphabet, c) {
+			return "", ErrInvalidGiftCardCode
+		}
+	}
+
+	if giftCardCodeCheck(code[:giftCardCodeLength-1]) != code[giftCardCodeLength-1] {
+		return "", ErrInvalidGiftCardCode
+	}
+
+	return code, nil
+}
+
+// FormatGiftCardCode splits a code into groups of four characters.
+func FormatGiftCardCode(code string) string {
+	var groups []string
+	for len(code) > 4 {
+		groups = append(groups, code[:4])
+		code = code[4:]
+	}
+
+	return strings.Join(append(groups, code), "-")
+}
+
+// giftCardCodeCheck computes the Luhn mod 32 check character of code.
+func giftCardCodeCheck(code string) byte {
+	factor, sum := 2, 0
+	for i := len(code) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(giftCardCodeAlphabet, code[i])
+		sum += addend/32 + addend%32
+		factor = 3 - factor
+	}
+
+	return giftCardCodeAlphabet[(32-sum%32)%32]
+}
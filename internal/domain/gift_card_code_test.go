@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GiftCardCodeTestSuite struct {
+	suite.Suite
+}
+
+func (suite *GiftCardCodeTestSuite) newCode() string {
+	code, err := NewGiftCardCode()
+	suite.Require().NoError(err)
+
+	return code
+}
+
+func (suite *GiftCardCodeTestSuite) TestNewGiftCardCode_Success() {
+	require := suite.Require()
+
+	for i := 0; i < 100; i++ {
+		code := suite.newCode()
+
+		require.Len(code, giftCardCodeLength)
+		parsed, err := ParseGiftCardCode(code)
+		require.NoError(err)
+		require.Equal(code, parsed)
+	}
+}
+
+// TestParseGiftCardCode_Success checks that a code is accepted the way a
+// person may type it, with the characters Crockford's alphabet leaves out
+// read as the ones they look like.
+func (suite *GiftCardCodeTestSuite) TestParseGiftCardCode_Success() {
+	require := suite.Require()
+	code := "0123456789ABCDE" + string(giftCardCodeCheck("0123456789ABCDE"))
+
+	for _, typed := range []string{
+		code,
+		FormatGiftCardCode(code),
+		strings.ToLower(FormatGiftCardCode(code)),
+		"0123 4567 89ab cde" + code[15:],
+		"OL23456789ABCDE" + code[15:],
+		"oI23456789ABCDE" + code[15:],
+	} {
+		parsed, err := ParseGiftCardCode(typed)
+
+		require.NoError(err, typed)
+		require.Equal(code, parsed, typed)
+	}
+}
+
+func (suite *GiftCardCodeTestSuite) TestParseGiftCardCode_Failure() {
+	require := suite.Require()
+	code := suite.newCode()
+
+	for _, typed := range []string{
+		"",
+		code[:15],
+		code + "0",
+		code[:15] + "U",
+		code[:14] + "!" + code[15:],
+	} {
+		_, err := ParseGiftCardCode(typed)
+
+		require.ErrorIs(err, ErrInvalidGiftCardCode, typed)
+	}
+}
+
+// TestParseGiftCardCode_Typo_Failure checks that the check character catches
+// every mistyped character and every swap of two neighbouring characters,
+// except a swap of 0 and Z, whose contributions to the Luhn sum are equal.
+func (suite *GiftCardCodeTestSuite) TestParseGiftCardCode_Typo_Failure() {
+	require := suite.Require()
+
+	for n := 0; n < 20; n++ {
+		code := suite.newCode()
+
+		for i := 0; i < len(code); i++ {
+			for _, c := range []byte(giftCardCodeAlphabet) {
+				if c == code[i] {
+					continue
+				}
+
+				typo := code[:i] + string(c) + code[i+1:]
+				_, err := ParseGiftCardCode(typo)
+				require.ErrorIs(err, ErrInvalidGiftCardCode, "%s typed as %s", code, typo)
+			}
+		}
+
+		for i := 0; i+1 < len(code); i++ {
+			pair := code[i : i+2]
+			if pair[0] == pair[1] || pair == "0Z" || pair == "Z0" {
+				continue
+			}
+
+			swap := code[:i] + string(pair[1]) + string(pair[0]) + code[i+2:]
+			_, err := ParseGiftCardCode(swap)
+			require.ErrorIs(err, ErrInvalidGiftCardCode, "%s typed as %s", code, swap)
+		}
+	}
+}
+
+func (suite *GiftCardCodeTestSuite) TestFormatGiftCardCode() {
+	require := suite.Require()
+
+	require.Equal("ABCD-EFGH-JKMN-PQRS", FormatGiftCardCode("ABCDEFGHJKMNPQRS"))
+	require.Equal("ABCD-EF", FormatGiftCardCode("ABCDEF"))
+	require.Equal("ABCD", FormatGiftCardCode("ABCD"))
+}
+
+func TestGiftCardCode(t *testing.T) {
+	suite.Run(t, new(GiftCardCodeTestSuite))
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GiftCardTestSuite struct {
+	suite.Suite
+}
+
+var allGiftCardStatuses = []GiftCardStatus{GCSAccepted, GCSRejected, GCSPending, GCSCancelled, GCSExpired, GCSVoided}
+
+// TestCanTransitionTo checks every pair of statuses against the state
+// machine: a pending gift card moves to any other status and an accepted one
+// can only be voided.
+func (suite *GiftCardTestSuite) TestCanTransitionTo() {
+	require := suite.Require()
+	allowed := map[GiftCardStatus][]GiftCardStatus{
+		GCSPending:  {GCSAccepted, GCSRejected, GCSCancelled, GCSExpired, GCSVoided},
+		GCSAccepted: {GCSVoided},
+	}
+
+	for _, from := range allGiftCardStatuses {
+		for _, to := range allGiftCardStatuses {
+			expected := false
+			for _, status := range allowed[from] {
+				expected = expected || status == to
+			}
+
+			require.Equal(expected, from.CanTransitionTo(to), "%s to %s", from, to)
+		}
+
+		require.Equal(len(allowed[from]) > 0, (&GiftCard{Status: from}).CanUpdateStatus(), from.String())
+		require.False(from.CanTransitionTo(GiftCardStatus(99)), from.String())
+	}
+}
+
+func (suite *GiftCardTestSuite) TestTransitionTo() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	for _, tc := range []struct {
+		from          GiftCardStatus
+		to            GiftCardStatus
+		expiresAt     *time.Time
+		expectedError error
+	}{
+		{from: GCSPending, to: GCSAccepted},
+		{from: GCSPending, to: GCSRejected, expiresAt: &future},
+		{from: GCSPending, to: GCSCancelled},
+		{from: GCSPending, to: GCSVoided},
+		{from: GCSPending, to: GCSExpired, expiresAt: &past},
+		{from: GCSPending, to: GCSExpired, expiresAt: &now},
+		{from: GCSPending, to: GCSExpired, expiresAt: &future, expectedError: ErrGiftCardNotOverdue},
+		{from: GCSPending, to: GCSExpired, expectedError: ErrGiftCardNotOverdue},
+		{from: GCSPending, to: GCSAccepted, expiresAt: &past, expectedError: ErrGiftCardExpired},
+		{from: GCSPending, to: GCSCancelled, expiresAt: &past, expectedError: ErrGiftCardExpired},
+		{from: GCSPending, to: GCSVoided, expiresAt: &past, expectedError: ErrGiftCardExpired},
+		{from: GCSPending, to: GCSPending, expectedError: &InvalidTransitionError{From: GCSPending, To: GCSPending}},
+		{from: GCSAccepted, to: GCSVoided, expiresAt: &past},
+		{from: GCSAccepted, to: GCSRejected, expectedError: &InvalidTransitionError{From: GCSAccepted, To: GCSRejected}},
+		{from: GCSAccepted, to: GCSExpired, expiresAt: &past, expectedError: &InvalidTransitionError{From: GCSAccepted, To: GCSExpired}},
+		{from: GCSRejected, to: GCSAccepted, expectedError: &InvalidTransitionError{From: GCSRejected, To: GCSAccepted}},
+		{from: GCSExpired, to: GCSExpired, expiresAt: &past, expectedError: &InvalidTransitionError{From: GCSExpired, To: GCSExpired}},
+		{from: GCSVoided, to: GCSPending, expectedError: &InvalidTransitionError{From: GCSVoided, To: GCSPending}},
+	} {
+		giftCard := &GiftCard{Status: tc.from, ExpiresAt: tc.expiresAt}
+
+		err := giftCard.TransitionTo(tc.to, now)
+
+		if tc.expectedError != nil {
+			require.Equal(tc.expectedError, err, "%s to %s", tc.from, tc.to)
+			require.Equal(tc.from, giftCard.Status)
+			continue
+		}
+
+		require.NoError(err, "%s to %s", tc.from, tc.to)
+		require.Equal(tc.to, giftCard.Status)
+	}
+}
+
+func (suite *GiftCardTestSuite) TestForceTransitionTo() {
+	require := suite.Require()
+	past := time.Now().Add(-time.Minute)
+
+	giftCard := &GiftCard{Status: GCSPending, RemainingAmount: NewMoney(1000, "USD"), ExpiresAt: &past}
+	require.NoError(giftCard.ForceTransitionTo(GCSVoided))
+	require.Equal(GCSVoided, giftCard.Status)
+	require.Equal(NewMoney(1000, "USD"), giftCard.RemainingAmount)
+
+	accepted := &GiftCard{Status: GCSAccepted, RemainingAmount: NewMoney(400, "USD"), ExpiresAt: &past}
+	require.NoError(accepted.ForceTransitionTo(GCSVoided))
+	require.Equal(GCSVoided, accepted.Status)
+	require.Equal(NewMoney(0, "USD"), accepted.RemainingAmount)
+
+	err := giftCard.ForceTransitionTo(GCSExpired)
+	require.Equal(&InvalidTransitionError{From: GCSVoided, To: GCSExpired}, err)
+	require.EqualError(err, "gift card cannot move from voided to expired")
+}
+
+func (suite *GiftCardTestSuite) TestCheckActor() {
+	require := suite.Require()
+	giftCard := &GiftCard{GifterID: 1, GifteeID: 2}
+
+	for _, tc := range []struct {
+		status        GiftCardStatus
+		userID        uint
+		expectedError error
+	}{
+		{GCSCancelled, 1, nil},
+		{GCSCancelled, 2, ErrNotGiftCardGifter},
+		{GCSAccepted, 2, nil},
+		{GCSRejected, 2, nil},
+		{GCSAccepted, 1, ErrNotGiftCardGiftee},
+		{GCSRejected, 3, ErrNotGiftCardGiftee},
+	} {
+		require.Equal(tc.expectedError, giftCard.CheckActor(tc.status, tc.userID), "%s by %d", tc.status, tc.userID)
+	}
+}
+
+func (suite *GiftCardTestSuite) TestRedeem() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		status            GiftCardStatus
+		amount            Money
+		expectedRemaining Money
+		expectedError     error
+	}{
+		{status: GCSAccepted, amount: NewMoney(300, "USD"), expectedRemaining: NewMoney(700, "USD")},
+		{status: GCSAccepted, amount: NewMoney(1000, "USD"), expectedRemaining: NewMoney(0, "USD")},
+		{status: GCSAccepted, amount: NewMoney(1001, "USD"), expectedError: ErrRedemptionExceedsBalance},
+		{status: GCSAccepted, amount: NewMoney(0, "USD"), expectedError: ErrInvalidAmount},
+		{status: GCSAccepted, amount: NewMoney(-1, "USD"), expectedError: ErrInvalidAmount},
+		{status: GCSAccepted, amount: NewMoney(300, "EUR"), expectedError: ErrCurrencyMismatch},
+		{status: GCSPending, amount: NewMoney(300, "USD"), expectedError: ErrGiftCardNotRedeemable},
+		{status: GCSVoided, amount: NewMoney(300, "USD"), expectedError: ErrGiftCardNotRedeemable},
+	} {
+		giftCard := &GiftCard{Status: tc.status, RemainingAmount: NewMoney(1000, "USD")}
+
+		err := giftCard.Redeem(tc.amount)
+
+		if tc.expectedError != nil {
+			require.ErrorIs(err, tc.expectedError, tc.amount.String())
+			require.Equal(NewMoney(1000, "USD"), giftCard.RemainingAmount)
+			continue
+		}
+
+		require.NoError(err)
+		require.Equal(tc.expectedRemaining, giftCard.RemainingAmount)
+	}
+}
+
+func TestGiftCard(t *testing.T) {
+	suite.Run(t, new(GiftCardTestSuite))
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// IdempotencyKey is a key a user sent with a mutating request. StatusCode,
+// ResponseHeaders and ResponseBody hold the response of the first request
+// once it completed and StatusCode is zero while it is in progress.
+//
+// Token identifies the request that reserved the key, only that request
+// completes or releases it. A request in progress holds the key for a lease
+// from LockedAt, after which another request may take the key over, so that a
+// request that never completed does not block the key. The key and its
+// response are kept until ExpiresAt.
+type IdempotencyKey struct {
+	Key             string
+	UserID          uint
+	RequestHash     string
+	Token           string
+	StatusCode      int
+	ResponseHeaders map[string][]string
+	ResponseBody    []byte
+	LockedAt        time.Time
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+}
+
+// NewIdempotencyKey reserves the key for a new request at now, it is kept for
+// ttl.
+func NewIdempotencyKey(userID uint, key, requestHash string, now time.Time, ttl time.Duration) (IdempotencyKey, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return IdempotencyKey{}, err
+	}
+
+	return IdempotencyKey{
+		Key:         key,
+		UserID:      userID,
+		RequestHash: requestHash,
+		Token:       hex.EncodeToString(b),
+		LockedAt:    now,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+	}, nil
+}
+
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.StatusCode != 0
+}
+
+// CanBeTakenOver reports whether a new request may reserve the key at now,
+// either because it expired or because the request in progress did not
+// complete within lease.
+func (k *IdempotencyKey) CanBeTakenOver(now time.Time, lease time.Duration) bool {
+	if !now.Before(k.ExpiresAt) {
+		return true
+	}
+
+	return !k.IsCompleted() && !now.Before(k.LockedAt.Add(lease))
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExternalDepositsAccount is the counterpart of every deposit into a wallet of
+// the given currency, its balance is the negative of all the money that
+// entered the system.
+func ExternalDepositsAccount(currency string) string {
+	return fmt.Sprintf("external:%s:deposits", currency)
+}
+
+// WalletAvailableAccount is the ledger account of the available balance of a
+// user's wallet.
+func WalletAvailableAccount(userID uint, currency string) string {
+	return fmt.Sprintf("wallet:%d:%s:available", userID, currency)
+}
+
+// WalletHeldAccount is the ledger account of the held balance of a user's
+// wallet.
+func WalletHeldAccount(userID uint, currency string) string {
+	return fmt.Sprintf("wallet:%d:%s:held", userID, currency)
+}
+
+// GiftCardAccount is the ledger account of the money an accepted gift card
+// still holds. Accepting the card moves its amount there from the gifter's
+// held balance and redemptions are paid out of it, so its balance is the
+// remaining amount of the card. Voiding the card refunds the rest to the
+// gifter.
+func GiftCardAccount(giftCardID uint, currency string) string {
+	return fmt.Sprintf("gift_card:%d:%s", giftCardID, currency)
+}
+
+type LedgerTransactionType int
+
+const (
+	LTTDeposit LedgerTransactionType = iota
+	LTTHold
+	LTTRelease
+	LTTTransfer
+	LTTRedemption
+	LTTRefund
+)
+
+type LedgerEntryDirection int
+
+const (
+	LEDDebit LedgerEntryDirection = iota
+	LEDCredit
+)
+
+// LedgerEntry is one side of a ledger transaction. A debit takes the amount
+// out of the account and a credit puts it in.
+type LedgerEntry struct {
+	ID            uint
+	TransactionID uint
+	Account       string
+	Direction     LedgerEntryDirection
+	Amount        Money
+}
+
+// LedgerTransaction groups the entries of a single movement of funds. Its
+// debits and credits always add up to the same amount of a single currency.
+type LedgerTransaction struct {
+	ID         uint
+	GiftCardID *uint
+	Type       LedgerTransactionType
+	Entries    []LedgerEntry
+	CreatedAt  time.Time
+}
+
+// NewLedgerTransfer creates a transaction that moves amount from one account
+// to another.
+func NewLedgerTransfer(t LedgerTransactionType, giftCardID *uint, from, to string, amount Money) LedgerTransaction {
+	return LedgerTransaction{
+		GiftCardID: giftCardID,
+		Type:       t,
+		Entries: []LedgerEntry{
+			{Account: from, Direction: LEDDebit, Amount: amount},
+			{Account: to, Direction: LEDCredit, Amount: amount},
+		},
+	}
+}
+
+func (t LedgerTransaction) IsBalanced() bool {
+	if len(t.Entries) == 0 {
+		return false
+	}
+
+	currency := t.Entries[0].Amount.Currency
+	sum := NewMoney(0, currency)
+	for _, e := range t.Entries {
+		amount := e.Amount
+		if e.Direction == LEDDebit {
+			amount.Amount = -amount.Amount
+		}
+
+		var err error
+		sum, err = sum.Add(amount)
+		if err != nil {
+			return false
+		}
+	}
+
+	return sum.Amount == 0
+}
+
+// LedgerSnapshot is a consistent view of the ledger, of the cached wallet
+// balances and of the remaining amounts of the accepted gift cards that is
+// used to reconcile them.
+type LedgerSnapshot struct {
+	AccountBalances          map[string]Money
+	Wallets                  []Wallet
+	GiftCards                []GiftCard
+	UnbalancedTransactionIDs []uint
+}
+
+// LedgerDrift is an account whose cached balance does not match the balance
+// recomputed from the ledger.
+type LedgerDrift struct {
+	Account string
+	Ledger  Money
+	Cached  Money
+}
+
+type ReconciliationReport struct {
+	Accounts                 int
+	Drifts                   []LedgerDrift
+	UnbalancedTransactionIDs []uint
+}
+
+func (r ReconciliationReport) OK() bool {
+	return len(r.Drifts) == 0 && len(r.UnbalancedTransactionIDs) == 0
+}
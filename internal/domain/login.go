@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LoginPolicy throttles the failed logins of an account or an IP. Failures
+// are forgotten Window after the last one. After FreeFailures of them every
+// further login has to wait, BaseDelay after the first extra failure and
+// twice as long after every next one, up to MaxDelay. LockoutAfter failures
+// block logins for LockoutDuration, zero never locks.
+type LoginPolicy struct {
+	Window          time.Duration
+	FreeFailures    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutAfter    int
+	LockoutDuration time.Duration
+}
+
+// delay returns how long a login has to wait after count failures.
+func (p LoginPolicy) delay(count int) time.Duration {
+	n := count - p.FreeFailures
+	if n <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < n && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return delay
+}
+
+// LoginFailures counts the failed logins of a key, see AccountLoginKey and
+// IPLoginKey. The count is forgotten at ExpiresAt.
+type LoginFailures struct {
+	Key          string
+	Count        int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+	ExpiresAt    time.Time
+}
+
+// AccountLoginKey is the key of the failed logins of an email, whether a
+// user has the email or not, so throttling does not tell which emails are
+// registered.
+func AccountLoginKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func IPLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+// LoginBlockedError is returned for a login before RetryAt, Locked tells a
+// lockout from a delay.
+type LoginBlockedError struct {
+	RetryAt time.Time
+	Locked  bool
+}
+
+func (e *LoginBlockedError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed logins, locked until %s", e.RetryAt.UTC().Format(time.RFC3339))
+	}
+
+	return fmt.Sprintf("too many failed logins, retry at %s", e.RetryAt.UTC().Format(time.RFC3339))
+}
+
+// Check returns a *LoginBlockedError if a login has to wait at now.
+func (f *LoginFailures) Check(now time.Time, policy LoginPolicy) error {
+	if !now.Before(f.ExpiresAt) {
+		return nil
+	}
+
+	if f.LockedUntil != nil && now.Before(*f.LockedUntil) {
+		return &LoginBlockedError{RetryAt: *f.LockedUntil, Locked: true}
+	}
+
+	retryAt := f.LastFailedAt.Add(policy.delay(f.Count))
+	if now.Before(retryAt) {
+		return &LoginBlockedError{RetryAt: retryAt}
+	}
+
+	return nil
+}
+
+// Attempt counts a login at now as failed before its password is checked, so
+// that concurrent logins of the key are throttled by each other. A login that
+// has to wait fails with a *LoginBlockedError and is not counted. It reports
+// whether the attempt locked the key, a login that succeeds takes its attempt
+// back with Release.
+func (f *LoginFailures) Attempt(now time.Time, policy LoginPolicy) (bool, error) {
+	if err := f.Check(now, policy); err != nil {
+		return false, err
+	}
+
+	return f.Fail(now, policy), nil
+}
+
+// Release takes back the attempt of a login that succeeded, along with the
+// lock it took.
+func (f *LoginFailures) Release(policy LoginPolicy) {
+	if f.Count > 0 {
+		f.Count--
+	}
+
+	if policy.LockoutAfter <= 0 || f.Count < policy.LockoutAfter {
+		f.LockedUntil = nil
+	}
+}
+
+// Fail counts a failed login at now. It reports whether the failure locked
+// the key.
+func (f *LoginFailures) Fail(now time.Time, policy LoginPolicy) bool {
+	if !now.Before(f.ExpiresAt) {
+		f.Count = 0
+		f.LockedUntil = nil
+	}
+
+	f.Count++
+	f.LastFailedAt = now
+	f.ExpiresAt = now.Add(policy.Window)
+
+	locked := policy.LockoutAfter > 0 && f.Count >= policy.LockoutAfter
+	if locked {
+		lockedUntil := now.Add(policy.LockoutDuration)
+		f.LockedUntil = &lockedUntil
+		if lockedUntil.After(f.ExpiresAt) {
+			f.ExpiresAt = lockedUntil
+		}
+	}
+
+	return locked
+}
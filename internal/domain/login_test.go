@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LoginTestSuite struct {
+	suite.Suite
+}
+
+var testLoginPolicy = LoginPolicy{
+	Window:          15 * time.Minute,
+	FreeFailures:    3,
+	BaseDelay:       time.Second,
+	MaxDelay:        30 * time.Second,
+	LockoutAfter:    10,
+	LockoutDuration: 15 * time.Minute,
+}
+
+func (suite *LoginTestSuite) TestDelay() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		count    int
+		expected time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{6, 4 * time.Second},
+		{8, 16 * time.Second},
+		{9, 30 * time.Second},
+		{1000, 30 * time.Second},
+	} {
+		require.Equal(tc.expected, testLoginPolicy.delay(tc.count), "%d failures", tc.count)
+	}
+
+	require.Zero(LoginPolicy{FreeFailures: 3}.delay(10))
+}
+
+// TestAttempt checks the thresholds of the policy: the free failures, the
+// delays after them and the lockout, one login right after the other.
+func (suite *LoginTestSuite) TestAttempt() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	failures := &LoginFailures{Key: IPLoginKey("192.0.2.1")}
+
+	var lockedUntil time.Time
+	for count := 1; count <= testLoginPolicy.LockoutAfter; count++ {
+		locked, err := failures.Attempt(now, testLoginPolicy)
+
+		require.NoError(err, "attempt %d", count)
+		require.Equal(count == testLoginPolicy.LockoutAfter, locked, "attempt %d", count)
+		require.Equal(count, failures.Count)
+		require.Equal(now, failures.LastFailedAt)
+
+		if count == testLoginPolicy.LockoutAfter {
+			lockedUntil = now.Add(testLoginPolicy.LockoutDuration)
+			break
+		}
+
+		require.Nil(failures.LockedUntil)
+		require.Equal(now.Add(testLoginPolicy.Window), failures.ExpiresAt)
+
+		delay := testLoginPolicy.delay(count)
+		if delay > 0 {
+			_, err := failures.Attempt(now.Add(delay-time.Millisecond), testLoginPolicy)
+			require.Equal(&LoginBlockedError{RetryAt: now.Add(delay)}, err, "attempt %d", count)
+			require.Equal(count, failures.Count, "a blocked attempt is not counted")
+		}
+
+		now = now.Add(delay)
+	}
+
+	require.Equal(&lockedUntil, failures.LockedUntil)
+	require.Equal(lockedUntil, failures.ExpiresAt)
+
+	_, err := failures.Attempt(lockedUntil.Add(-time.Second), testLoginPolicy)
+	require.Equal(&LoginBlockedError{RetryAt: lockedUntil, Locked: true}, err)
+
+	locked, err := failures.Attempt(lockedUntil, testLoginPolicy)
+	require.NoError(err)
+	require.False(locked)
+	require.Equal(1, failures.Count)
+	require.Nil(failures.LockedUntil)
+}
+
+func (suite *LoginTestSuite) TestAttempt_Expired() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	failures := &LoginFailures{Count: 5, LastFailedAt: now.Add(-testLoginPolicy.Window), ExpiresAt: now}
+
+	locked, err := failures.Attempt(now, testLoginPolicy)
+
+	require.NoError(err)
+	require.False(locked)
+	require.Equal(1, failures.Count)
+	require.Equal(now.Add(testLoginPolicy.Window), failures.ExpiresAt)
+}
+
+func (suite *LoginTestSuite) TestAttempt_NoLockout() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := LoginPolicy{Window: time.Hour}
+	failures := &LoginFailures{}
+
+	for i := 0; i < 100; i++ {
+		locked, err := failures.Attempt(now, policy)
+
+		require.NoError(err)
+		require.False(locked)
+	}
+
+	require.Equal(100, failures.Count)
+	require.Nil(failures.LockedUntil)
+}
+
+func (suite *LoginTestSuite) TestRelease() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lockedUntil := now.Add(testLoginPolicy.LockoutDuration)
+
+	for _, tc := range []struct {
+		failures            LoginFailures
+		expectedCount       int
+		expectedLockedUntil *time.Time
+	}{
+		{LoginFailures{Count: 1}, 0, nil},
+		{LoginFailures{}, 0, nil},
+		{LoginFailures{Count: 10, LockedUntil: &lockedUntil}, 9, nil},
+		{LoginFailures{Count: 11, LockedUntil: &lockedUntil}, 10, &lockedUntil},
+	} {
+		tc.failures.Release(testLoginPolicy)
+
+		require.Equal(tc.expectedCount, tc.failures.Count)
+		require.Equal(tc.expectedLockedUntil, tc.failures.LockedUntil)
+	}
+}
+
+func (suite *LoginTestSuite) TestLoginKeys() {
+	require := suite.Require()
+
+	require.Equal("account:foo@example.com", AccountLoginKey("  Foo@Example.COM "))
+	require.Equal("ip:192.0.2.1", IPLoginKey("192.0.2.1"))
+}
+
+func TestLogin(t *testing.T) {
+	suite.Run(t, new(LoginTestSuite))
+}
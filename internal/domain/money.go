@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency = "USD"
+
+var (
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrInvalidCurrency  = errors.New("invalid currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// currencyExponents holds the number of minor unit digits of the supported
+// ISO-4217 currencies.
+var currencyExponents = map[string]int{
+	"AUD": 2,
+	"BHD": 3,
+	"CAD": 2,
+	"CHF": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"SEK": 2,
+	"USD": 2,
+}
+
+func IsValidCurrency(currency string) bool {
+	_, ok := currencyExponents[currency]
+
+	return ok
+}
+
+// Money is an exact amount in the minor units of its currency, e.g. cents for
+// USD.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal amount in major units like "12.34" without
+// going through floating point.
+func ParseMoney(amount, currency string) (Money, error) {
+	exponent, ok := currencyExponents[currency]
+	if !ok {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(amount, "-")
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if whole == "" || len(fraction) > exponent || strings.Contains(amount, ".") && fraction == "" {
+		return Money{}, ErrInvalidAmount
+	}
+
+	digits := whole + fraction + strings.Repeat("0", exponent-len(fraction))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Money{}, ErrInvalidAmount
+		}
+	}
+
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	if (o.Amount > 0 && m.Amount > math.MaxInt64-o.Amount) || (o.Amount < 0 && m.Amount < math.MinInt64-o.Amount) {
+		return Money{}, ErrInvalidAmount
+	}
+
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub checks for overflow like Add instead of adding the negated amount, the
+// negation of math.MinInt64 overflows itself.
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	if (o.Amount < 0 && m.Amount > math.MaxInt64+o.Amount) || (o.Amount > 0 && m.Amount < math.MinInt64+o.Amount) {
+		return Money{}, ErrInvalidAmount
+	}
+
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+// Cmp compares two amounts of the same currency and returns -1, 0 or 1.
+func (m Money) Cmp(o Money) (int, error) {
+	if m.Currency != o.Currency {
+		return 0, ErrCurrencyMismatch
+	}
+
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// Decimal formats the amount in major units, e.g. "12.34".
+func (m Money) Decimal() string {
+	exponent := currencyExponents[m.Currency]
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+
+	digits := strings.TrimPrefix(strconv.FormatInt(m.Amount, 10), "-")
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Decimal(), m.Currency)
+}
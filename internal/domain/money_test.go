@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MoneyTestSuite struct {
+	suite.Suite
+}
+
+func (suite *MoneyTestSuite) TestParseMoney_Success() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		amount   string
+		currency string
+		expected Money
+	}{
+		{"12.34", "USD", NewMoney(1234, "USD")},
+		{"12.3", "USD", NewMoney(1230, "USD")},
+		{"12", "USD", NewMoney(1200, "USD")},
+		{"0.01", "USD", NewMoney(1, "USD")},
+		{"-5.5", "EUR", NewMoney(-550, "EUR")},
+		{"007", "USD", NewMoney(700, "USD")},
+		{"1500", "JPY", NewMoney(1500, "JPY")},
+		{"1.234", "KWD", NewMoney(1234, "KWD")},
+		{"92233720368547758.07", "USD", NewMoney(math.MaxInt64, "USD")},
+	} {
+		money, err := ParseMoney(tc.amount, tc.currency)
+
+		require.NoError(err, tc.amount)
+		require.Equal(tc.expected, money, tc.amount)
+	}
+}
+
+// TestParseMoney_Failure checks that amounts finer than the minor unit are
+// rejected rather than rounded, and that amounts beyond int64 are rejected
+// rather than wrapped.
+func (suite *MoneyTestSuite) TestParseMoney_Failure() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		amount   string
+		currency string
+		expected error
+	}{
+		{"1.005", "USD", ErrInvalidAmount},
+		{"0.001", "USD", ErrInvalidAmount},
+		{"1.5", "JPY", ErrInvalidAmount},
+		{"1.2345", "KWD", ErrInvalidAmount},
+		{"92233720368547758.08", "USD", ErrInvalidAmount},
+		{"9223372036854775808", "JPY", ErrInvalidAmount},
+		{"", "USD", ErrInvalidAmount},
+		{".5", "USD", ErrInvalidAmount},
+		{"1.", "USD", ErrInvalidAmount},
+		{"1e3", "USD", ErrInvalidAmount},
+		{"+1", "USD", ErrInvalidAmount},
+		{"--1", "USD", ErrInvalidAmount},
+		{"1,00", "USD", ErrInvalidAmount},
+		{" 1", "USD", ErrInvalidAmount},
+		{"1", "usd", ErrInvalidCurrency},
+		{"1", "XXX", ErrInvalidCurrency},
+	} {
+		_, err := ParseMoney(tc.amount, tc.currency)
+
+		require.ErrorIs(err, tc.expected, tc.amount)
+	}
+}
+
+func (suite *MoneyTestSuite) TestDecimal_Success() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		money    Money
+		expected string
+	}{
+		{NewMoney(1234, "USD"), "12.34"},
+		{NewMoney(5, "USD"), "0.05"},
+		{NewMoney(0, "USD"), "0.00"},
+		{NewMoney(-5, "USD"), "-0.05"},
+		{NewMoney(-1234, "EUR"), "-12.34"},
+		{NewMoney(1500, "JPY"), "1500"},
+		{NewMoney(-1500, "JPY"), "-1500"},
+		{NewMoney(7, "BHD"), "0.007"},
+		{NewMoney(math.MaxInt64, "USD"), "92233720368547758.07"},
+		{NewMoney(math.MinInt64, "USD"), "-92233720368547758.08"},
+	} {
+		require.Equal(tc.expected, tc.money.Decimal())
+
+		if tc.money.Amount != math.MinInt64 {
+			parsed, err := ParseMoney(tc.money.Decimal(), tc.money.Currency)
+			require.NoError(err)
+			require.Equal(tc.money, parsed)
+		}
+	}
+
+	require.Equal("12.34 USD", NewMoney(1234, "USD").String())
+}
+
+func (suite *MoneyTestSuite) TestAdd() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		a, b          Money
+		expected      Money
+		expectedError error
+	}{
+		{a: NewMoney(100, "USD"), b: NewMoney(23, "USD"), expected: NewMoney(123, "USD")},
+		{a: NewMoney(100, "USD"), b: NewMoney(-150, "USD"), expected: NewMoney(-50, "USD")},
+		{a: NewMoney(math.MaxInt64-1, "USD"), b: NewMoney(1, "USD"), expected: NewMoney(math.MaxInt64, "USD")},
+		{a: NewMoney(math.MaxInt64, "USD"), b: NewMoney(1, "USD"), expectedError: ErrInvalidAmount},
+		{a: NewMoney(math.MinInt64, "USD"), b: NewMoney(-1, "USD"), expectedError: ErrInvalidAmount},
+		{a: NewMoney(100, "USD"), b: NewMoney(100, "EUR"), expectedError: ErrCurrencyMismatch},
+	} {
+		sum, err := tc.a.Add(tc.b)
+
+		if tc.expectedError != nil {
+			require.ErrorIs(err, tc.expectedError)
+			continue
+		}
+
+		require.NoError(err)
+		require.Equal(tc.expected, sum)
+	}
+}
+
+func (suite *MoneyTestSuite) TestSub() {
+	require := suite.Require()
+
+	difference, err := NewMoney(100, "USD").Sub(NewMoney(30, "USD"))
+	require.NoError(err)
+	require.Equal(NewMoney(70, "USD"), difference)
+
+	_, err = NewMoney(math.MinInt64, "USD").Sub(NewMoney(1, "USD"))
+	require.ErrorIs(err, ErrInvalidAmount)
+
+	_, err = NewMoney(0, "USD").Sub(NewMoney(math.MinInt64, "USD"))
+	require.ErrorIs(err, ErrInvalidAmount)
+
+	difference, err = NewMoney(-1, "USD").Sub(NewMoney(math.MinInt64, "USD"))
+	require.NoError(err)
+	require.Equal(NewMoney(math.MaxInt64, "USD"), difference)
+
+	_, err = NewMoney(100, "USD").Sub(NewMoney(30, "EUR"))
+	require.ErrorIs(err, ErrCurrencyMismatch)
+}
+
+func (suite *MoneyTestSuite) TestCmp() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		a, b     Money
+		expected int
+	}{
+		{NewMoney(1, "USD"), NewMoney(2, "USD"), -1},
+		{NewMoney(2, "USD"), NewMoney(2, "USD"), 0},
+		{NewMoney(3, "USD"), NewMoney(2, "USD"), 1},
+	} {
+		cmp, err := tc.a.Cmp(tc.b)
+
+		require.NoError(err)
+		require.Equal(tc.expected, cmp)
+	}
+
+	_, err := NewMoney(1, "USD").Cmp(NewMoney(1, "EUR"))
+	require.ErrorIs(err, ErrCurrencyMismatch)
+}
+
+func TestMoney(t *testing.T) {
+	suite.Run(t, new(MoneyTestSuite))
+}
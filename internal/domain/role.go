@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrInvalidRole  = errors.New("invalid role")
+)
+
+// Role is a role of the staff. Users without a role can only act on their
+// own wallet and gift cards.
+type Role string
+
+const (
+	RoleSupport Role = "support"
+	RoleAdmin   Role = "admin"
+)
+
+// Permission allows an action on the data of any user.
+type Permission string
+
+const (
+	PermissionReadUsers      Permission = "users:read"
+	PermissionUnlockUsers    Permission = "users:unlock"
+	PermissionReadGiftCards  Permission = "gift_cards:read"
+	PermissionExpireGiftCard Permission = "gift_cards:expire"
+	PermissionVoidGiftCard   Permission = "gift_cards:void"
+	PermissionDepositFunds   Permission = "wallets:deposit"
+	PermissionReadAuditLog   Permission = "audit_log:read"
+)
+
+// rolePermissions grants the permissions of each role:
+//
+//	permission          support  admin
+//	users:read          yes      yes
+//	users:unlock        yes      yes
+//	gift_cards:read     yes      yes
+//	gift_cards:expire   yes      yes
+//	gift_cards:void     yes      yes
+//	wallets:deposit     no       yes
+//	audit_log:read      no       yes
+//
+// Support looks into the accounts and cards of users, unlocks their logins
+// and force-expires or voids their cards, only admins fund wallets and read
+// the audit log.
+var rolePermissions = map[Role][]Permission{
+	RoleSupport: {PermissionReadUsers, PermissionUnlockUsers, PermissionReadGiftCards, PermissionExpireGiftCard, PermissionVoidGiftCard},
+	RoleAdmin:   {PermissionReadUsers, PermissionUnlockUsers, PermissionReadGiftCards, PermissionExpireGiftCard, PermissionVoidGiftCard, PermissionDepositFunds, PermissionReadAuditLog},
+}
+
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+
+	return ok
+}
+
+// PermissionsOf returns the permissions the roles grant, sorted and without
+// duplicates.
+func PermissionsOf(roles []Role) []Permission {
+	seen := make(map[Permission]bool)
+	var permissions []Permission
+	for _, role := range roles {
+		for _, permission := range rolePermissions[role] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i] < permissions[j] })
+
+	return permissions
+}
+
+// HasPermission reports whether permission is one of the permissions.
+func HasPermission(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RoleTestSuite struct {
+	suite.Suite
+}
+
+func (suite *RoleTestSuite) TestPermissionsOf() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		roles    []Role
+		expected []Permission
+	}{
+		{nil, nil},
+		{[]Role{RoleSupport}, []Permission{PermissionExpireGiftCard, PermissionReadGiftCards, PermissionVoidGiftCard, PermissionReadUsers, PermissionUnlockUsers}},
+		{[]Role{RoleAdmin}, []Permission{PermissionReadAuditLog, PermissionExpireGiftCard, PermissionReadGiftCards, PermissionVoidGiftCard, PermissionReadUsers, PermissionUnlockUsers, PermissionDepositFunds}},
+		{[]Role{RoleSupport, RoleAdmin}, []Permission{PermissionReadAuditLog, PermissionExpireGiftCard, PermissionReadGiftCards, PermissionVoidGiftCard, PermissionReadUsers, PermissionUnlockUsers, PermissionDepositFunds}},
+		{[]Role{"unknown"}, nil},
+	} {
+		require.Equal(tc.expected, PermissionsOf(tc.roles), tc.roles)
+	}
+}
+
+func (suite *RoleTestSuite) TestHasPermission() {
+	require := suite.Require()
+	permissions := PermissionsOf([]Role{RoleSupport})
+
+	require.True(HasPermission(permissions, PermissionUnlockUsers))
+	require.True(HasPermission(permissions, PermissionVoidGiftCard))
+	require.True(HasPermission(permissions, PermissionExpireGiftCard))
+	require.False(HasPermission(permissions, PermissionDepositFunds))
+	require.False(HasPermission(permissions, PermissionReadAuditLog))
+}
+
+func TestRole(t *testing.T) {
+	suite.Run(t, new(RoleTestSuite))
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidAccessToken  = errors.New("invalid token")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session is revoked")
+)
+
+// RefreshToken is a refresh token of a session, only the hash of the token is
+// kept. A refresh token is used once, refreshing rotates it into a new token
+// of the same family. Every token rotated from one login shares its family,
+// so a used token showing up again means the session leaked and the whole
+// family is revoked.
+type RefreshToken struct {
+	ID        uint
+	UserID    uint
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+func (t *RefreshToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// IsActive reports whether the token can still be exchanged at now.
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// NewTokenID returns a random id for the jti of an access token or for a
+// family of refresh tokens.
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// NewRefreshTokenSecret returns a random refresh token for the client and
+// the hash it is stored by.
+func NewRefreshTokenSecret() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := hex.EncodeToString(b)
+
+	return token, HashRefreshToken(token), nil
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -1,18 +1,29 @@
 package domain
 
 import (
+	"errors"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrEmailTaken = errors.New("email is already registered")
+
 type User struct {
-	ID        uint
-	Email     string
-	Password  string
+	ID       uint
+	Email    string
+	Password string
+	// Roles are the staff roles of the user, see Role.
+	Roles     []Role
 	CreatedAt time.Time
 }
 
+// Permissions returns the permissions the roles of the user grant.
+func (u *User) Permissions() []Permission {
+	return PermissionsOf(u.Roles)
+}
+
 func (u *User) SetPassword(password string) error {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -32,3 +43,19 @@ func (u *User) CheckPassword(password string) bool {
 
 	return true
 }
+
+// dummyPassword is a hash to check passwords against when there is no user,
+// so that a login takes as long whether its email is registered or not.
+var dummyPassword = sync.OnceValue(func() []byte {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+	return hashed
+})
+
+// CheckDummyPassword takes as long as User.CheckPassword and fails, it stands
+// in for it when there is no user.
+func CheckDummyPassword(password string) bool {
+	_ = bcrypt.CompareHashAndPassword(dummyPassword(), []byte(password))
+
+	return false
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Wallet is the balance a user can spend on gift cards in one currency. Held
+// is the part of the balance that is reserved by pending gift cards the user
+// has sent.
+type Wallet struct {
+	ID        uint
+	UserID    uint
+	Balance   Money
+	Held      Money
+	UpdatedAt time.Time
+}
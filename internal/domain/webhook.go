@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrWebhookNotFound     = errors.New("webhook not found")
+	ErrInvalidWebhookURL   = errors.New("webhook url must be an absolute http or https url")
+	ErrWebhookURLNotPublic = errors.New("webhook url must point to a public address")
+)
+
+const (
+	// MaxWebhookAttempts is how many times a delivery is tried before it is
+	// dead-lettered.
+	MaxWebhookAttempts = 8
+
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = 6 * time.Hour
+)
+
+// Webhook is a subscription of a user to the status changes of the gift cards
+// they sent or received. Deliveries are signed with Secret.
+type Webhook struct {
+	ID        uint
+	UserID    uint
+	URL       string
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+}
+
+func NewWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// ValidateWebhookURL checks that the url is an absolute http or https url
+// whose host is not obviously internal. A host name may still resolve to an
+// internal address, the sender checks every address it dials with
+// IsPublicWebhookAddr as well.
+func ValidateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrWebhookURLNotPublic
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicWebhookAddr(addr) {
+		return ErrWebhookURLNotPublic
+	}
+
+	return nil
+}
+
+// nonPublicPrefixes are the special purpose ranges that the net/netip
+// predicates do not cover.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// IsPublicWebhookAddr reports whether a webhook may be delivered to addr. It
+// rules out loopback, private, link-local, multicast and the other special
+// purpose ranges, so that webhooks cannot reach the internal network of the
+// service.
+func IsPublicWebhookAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type WebhookDeliveryStatus int
+
+const (
+	WDSPending WebhookDeliveryStatus = iota
+	WDSSucceeded
+	WDSDead
+)
+
+var webhookDeliveryStatusNames = map[WebhookDeliveryStatus]string{
+	WDSPending:   "pending",
+	WDSSucceeded: "succeeded",
+	WDSDead:      "dead",
+}
+
+func (s WebhookDeliveryStatus) String() string {
+	if name, ok := webhookDeliveryStatusNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// WebhookDelivery is an event to be sent to a webhook. It stays pending with
+// NextAttemptAt pushed back after every failed attempt until it succeeds or
+// runs out of attempts and is dead-lettered.
+type WebhookDelivery struct {
+	ID             uint
+	WebhookID      uint
+	EventID        uint
+	EventType      EventType
+	AggregateID    uint
+	Payload        []byte
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// RecordAttempt updates the delivery with the outcome of an attempt made at
+// now. statusCode is zero when no response was received.
+func (d *WebhookDelivery) RecordAttempt(now time.Time, statusCode int, err error) {
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	if err != nil {
+		d.LastError = err.Error()
+	}
+
+	switch {
+	case err == nil && statusCode >= 200 && statusCode < 300:
+		d.Status = WDSSucceeded
+	case d.Attempts >= MaxWebhookAttempts:
+		d.Status = WDSDead
+	default:
+		d.NextAttemptAt = now.Add(WebhookRetryDelay(d.Attempts))
+	}
+}
+
+// DeadLetter gives up on the delivery without another attempt.
+func (d *WebhookDelivery) DeadLetter(reason error) {
+	d.Status = WDSDead
+	d.LastError = reason.Error()
+}
+
+// WebhookRetryDelay is how long to wait after the given failed attempt, it
+// doubles with every attempt up to a maximum.
+func WebhookRetryDelay(attempt int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= webhookRetryMaxDelay {
+			return webhookRetryMaxDelay
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,28 @@
+package messaging
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type EventPublisherMock struct {
+	mock.Mock
+}
+
+func (p *EventPublisherMock) Publish(event domain.Event) error {
+	args := p.Called(event)
+
+	return args.Error(0)
+}
+
+type WebhookSenderMock struct {
+	mock.Mock
+}
+
+func (s *WebhookSenderMock) Send(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	args := s.Called(ctx, webhook, delivery)
+
+	return args.Int(0), args.Error(1)
+}
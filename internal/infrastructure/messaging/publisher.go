@@ -0,0 +1,49 @@
+package messaging
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+// EventPublisher delivers domain events relayed from the outbox. Events are
+// delivered at least once, so consumers should deduplicate them by ID.
+type EventPublisher interface {
+	Publish(event domain.Event) error
+}
+
+// message is the wire format of a published event.
+type message struct {
+	ID          uint             `json:"id"`
+	Type        domain.EventType `json:"type"`
+	AggregateID uint             `json:"aggregate_id"`
+	Payload     json.RawMessage  `json:"payload"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+type writerPublisher struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewWriterPublisher returns a publisher that writes every event to w as a
+// line of JSON, which is enough to use a file or stdout as the broker.
+func NewWriterPublisher(w io.Writer) EventPublisher {
+	return &writerPublisher{encoder: json.NewEncoder(w)}
+}
+
+func (p *writerPublisher) Publish(event domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.encoder.Encode(message{
+		ID:          event.ID,
+		Type:        event.Type,
+		AggregateID: event.AggregateID,
+		Payload:     event.Payload,
+		CreatedAt:   event.CreatedAt,
+	})
+}
@@ -0,0 +1,155 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+const (
+	HeaderWebhookID        = "X-Webhook-Id"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// The errors of a delivery are stored in its last error and shown to the
+// owner of the webhook, so Send never returns the error of the transport,
+// which could tell about the network of the service, but one of these.
+var (
+	ErrWebhookAddressNotAllowed = errors.New("webhook address is not public")
+	ErrWebhookTimeout           = errors.New("webhook request timed out")
+	ErrWebhookRequestFailed     = errors.New("webhook request failed")
+)
+
+// errAddressNotAllowed is returned by the dialer of the client of
+// NewWebhookHTTPClient for the addresses it refuses to connect to.
+var errAddressNotAllowed = errors.New("address not allowed")
+
+// NewWebhookHTTPClient returns the client to send webhook deliveries with. It
+// only connects to public addresses, see domain.IsPublicWebhookAddr, and the
+// check runs on the address that is dialed, after the host name is resolved,
+// so that a name cannot point it to the internal network. It does not follow
+// redirects, a redirect is an unsuccessful response like any other, and it
+// ignores the proxy of the environment.
+func NewWebhookHTTPClient(timeout time.Duration) *http.Client {
+	return newWebhookHTTPClient(timeout, domain.IsPublicWebhookAddr)
+}
+
+func newWebhookHTTPClient(timeout time.Duration, allow func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !allow(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errAddressNotAllowed, address)
+			}
+
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// WebhookSender posts a delivery to a webhook and returns the status code of
+// the response. A response that is not 2xx is not an error, it is up to the
+// caller to decide whether to retry.
+type WebhookSender interface {
+	Send(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error)
+}
+
+type httpWebhookSender struct {
+	client *http.Client
+}
+
+func NewHTTPWebhookSender(client *http.Client) WebhookSender {
+	return &httpWebhookSender{client: client}
+}
+
+// Send posts the event of the delivery in the same format the outbox relay
+// publishes it. The request is signed with the secret of the webhook, see
+// SignWebhookPayload.
+func (s *httpWebhookSender) Send(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(message{
+		ID:          delivery.EventID,
+		Type:        delivery.EventType,
+		AggregateID: delivery.AggregateID,
+		Payload:     delivery.Payload,
+		CreatedAt:   delivery.CreatedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderWebhookID, strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set(HeaderWebhookEvent, string(delivery.EventType))
+	request.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderWebhookSignature, "sha256="+SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, sendError(err)
+	}
+
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	return response.StatusCode, nil
+}
+
+// sendError maps an error of the client to one of the errors of Send.
+func sendError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errAddressNotAllowed):
+		return ErrWebhookAddressNotAllowed
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrWebhookTimeout
+	default:
+		return ErrWebhookRequestFailed
+	}
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 of the timestamp and
+// the body joined by a dot. Receivers compute the same signature from the
+// X-Webhook-Timestamp header and the raw body to verify a delivery, and reject
+// old timestamps to prevent replays.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
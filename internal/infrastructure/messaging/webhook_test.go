@@ -0,0 +1,130 @@
+package messaging
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type WebhookSenderTestSuite struct {
+	suite.Suite
+}
+
+func (suite *WebhookSenderTestSuite) TestSend_Success() {
+	require := suite.Require()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	webhook := domain.Webhook{ID: 3, Secret: "secret"}
+	delivery := domain.WebhookDelivery{
+		ID:          7,
+		EventID:     5,
+		EventType:   domain.EventGiftCardStatusChanged,
+		AggregateID: 101,
+		Payload:     []byte(`{"gift_card_id":101}`),
+		CreatedAt:   createdAt,
+	}
+	expectedBody := `{"id":5,"type":"gift_card.status_changed","aggregate_id":101,"payload":{"gift_card_id":101},"created_at":"2024-01-01T00:00:00Z"}`
+
+	var request *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	webhook.URL = server.URL
+
+	statusCode, err := NewHTTPWebhookSender(server.Client()).Send(context.Background(), webhook, delivery)
+
+	require.NoError(err)
+	require.Equal(http.StatusAccepted, statusCode)
+	require.JSONEq(expectedBody, string(body))
+	require.Equal("7", request.Header.Get(HeaderWebhookID))
+	require.Equal(string(domain.EventGiftCardStatusChanged), request.Header.Get(HeaderWebhookEvent))
+
+	timestamp, err := strconv.ParseInt(request.Header.Get(HeaderWebhookTimestamp), 10, 64)
+	require.NoError(err)
+	require.Equal("sha256="+SignWebhookPayload("secret", timestamp, body), request.Header.Get(HeaderWebhookSignature))
+}
+
+func (suite *WebhookSenderTestSuite) TestSend_ConnectionError_Failure() {
+	require := suite.Require()
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	statusCode, err := NewHTTPWebhookSender(http.DefaultClient).Send(context.Background(), domain.Webhook{URL: server.URL}, domain.WebhookDelivery{Payload: []byte(`{}`)})
+
+	require.ErrorIs(err, ErrWebhookRequestFailed)
+	require.Zero(statusCode)
+}
+
+func (suite *WebhookSenderTestSuite) TestSend_InternalAddress_Failure() {
+	require := suite.Require()
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	statusCode, err := NewHTTPWebhookSender(NewWebhookHTTPClient(time.Second)).Send(context.Background(), domain.Webhook{URL: server.URL}, domain.WebhookDelivery{Payload: []byte(`{}`)})
+
+	require.ErrorIs(err, ErrWebhookAddressNotAllowed)
+	require.NotContains(err.Error(), server.Listener.Addr().String())
+	require.Zero(statusCode)
+	require.False(called)
+}
+
+func (suite *WebhookSenderTestSuite) TestSend_Redirect_Failure() {
+	require := suite.Require()
+	var redirected bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer server.Close()
+	client := newWebhookHTTPClient(time.Second, func(netip.Addr) bool { return true })
+
+	statusCode, err := NewHTTPWebhookSender(client).Send(context.Background(), domain.Webhook{URL: server.URL}, domain.WebhookDelivery{Payload: []byte(`{}`)})
+
+	require.NoError(err)
+	require.Equal(http.StatusTemporaryRedirect, statusCode)
+	require.False(redirected)
+}
+
+func (suite *WebhookSenderTestSuite) TestSend_Timeout_Failure() {
+	require := suite.Require()
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+	client := newWebhookHTTPClient(50*time.Millisecond, func(netip.Addr) bool { return true })
+
+	statusCode, err := NewHTTPWebhookSender(client).Send(context.Background(), domain.Webhook{URL: server.URL}, domain.WebhookDelivery{Payload: []byte(`{}`)})
+
+	require.ErrorIs(err, ErrWebhookTimeout)
+	require.Zero(statusCode)
+}
+
+func (suite *WebhookSenderTestSuite) TestSignWebhookPayload() {
+	require := suite.Require()
+
+	signature := SignWebhookPayload("secret", 1700000000, []byte(`{}`))
+
+	require.Equal("b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163", signature)
+}
+
+func TestWebhookSender(t *testing.T) {
+	suite.Run(t, new(WebhookSenderTestSuite))
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+)
+
+// Dialect is the SQL flavour of a database. Queries are written for MySQL
+// and rebound for the dialect they run on.
+type Dialect int
+
+const (
+	MySQL Dialect = iota
+	Postgres
+	SQLite
+)
+
+// sqliteNow and sqliteTimeFormat write times as the same fixed width text,
+// so times written by queries and by the application compare as text the
+// way they compare as times. SQLite only has the milliseconds of now, the
+// rest of the nanoseconds are padded with zeros.
+const (
+	sqliteNow        = "strftime('%Y-%m-%d %H:%M:%f000000+00:00', 'now')"
+	sqliteTimeFormat = "2006-01-02 15:04:05.000000000-07:00"
+)
+
+// SQLite has no row locks, transactions take the write lock of the whole
+// database when they begin instead, see the _txlock parameter of the DSN.
+var sqliteReplacer = strings.NewReplacer(
+	"NOW()", sqliteNow,
+	" FOR UPDATE SKIP LOCKED", "",
+	" FOR UPDATE", "",
+)
+
+// DialectOf returns the dialect of the driver db was opened with. Drivers
+// other than lib/pq and modernc.org/sqlite are treated as MySQL.
+func DialectOf(db *sql.DB) Dialect {
+	switch db.Driver().(type) {
+	case *pq.Driver:
+		return Postgres
+	case *sqlite.Driver:
+		return SQLite
+	}
+
+	return MySQL
+}
+
+func (d Dialect) String() string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	case SQLite:
+		return "sqlite"
+	}
+
+	return "mysql"
+}
+
+// Rebind adapts query to the dialect. Postgres gets the numbered $n
+// placeholders instead of ?, question marks inside quoted strings are left
+// alone. SQLite gets its own NOW() and loses the row locking clauses.
+func (d Dialect) Rebind(query string) string {
+	if d == SQLite {
+		return sqliteReplacer.Replace(query)
+	}
+
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// BindArgs adapts the arguments of a query to the dialect. SQLite stores
+// times as text, so they are written in UTC in the format of sqliteNow to
+// keep them comparable. The driver would trim the trailing zeros of the
+// fraction, which breaks both equality and ordering as text.
+func (d Dialect) BindArgs(args []any) []any {
+	if d != SQLite {
+		return args
+	}
+
+	bound := make([]any, len(args))
+	for i, arg := range args {
+		switch t := arg.(type) {
+		case time.Time:
+			arg = t.UTC().Format(sqliteTimeFormat)
+		case *time.Time:
+			if t != nil {
+				arg = t.UTC().Format(sqliteTimeFormat)
+			}
+		}
+
+		bound[i] = arg
+	}
+
+	return bound
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DialectTestSuite struct {
+	suite.Suite
+}
+
+func (suite *DialectTestSuite) TestRebind_MySQL() {
+	require := suite.Require()
+
+	query := MySQL.Rebind("SELECT id FROM users WHERE email = ? AND id > ?")
+
+	require.Equal("SELECT id FROM users WHERE email = ? AND id > ?", query)
+}
+
+func (suite *DialectTestSuite) TestRebind_Postgres() {
+	require := suite.Require()
+
+	query := Postgres.Rebind("SELECT id FROM users WHERE email = ? AND name <> 'who?' AND id > ?")
+
+	require.Equal("SELECT id FROM users WHERE email = $1 AND name <> 'who?' AND id > $2", query)
+}
+
+func (suite *DialectTestSuite) TestRebind_SQLite() {
+	require := suite.Require()
+
+	query := SQLite.Rebind("SELECT id FROM gift_cards WHERE id = ? FOR UPDATE")
+	update := SQLite.Rebind("UPDATE outbox SET published_at = NOW() WHERE id = ?")
+
+	require.Equal("SELECT id FROM gift_cards WHERE id = ?", query)
+	require.Equal("UPDATE outbox SET published_at = "+sqliteNow+" WHERE id = ?", update)
+}
+
+func (suite *DialectTestSuite) TestBindArgs_SQLite() {
+	require := suite.Require()
+	t := time.Date(2024, 1, 1, 12, 0, 0, 120000000, time.FixedZone("UTC+2", 2*60*60))
+	args := []any{1, t, &t, (*time.Time)(nil)}
+
+	bound := SQLite.BindArgs(args)
+
+	require.Equal([]any{1, "2024-01-01 10:00:00.120000000+00:00", "2024-01-01 10:00:00.120000000+00:00", (*time.Time)(nil)}, bound)
+	require.Equal(t, args[1], "the arguments of the caller are not changed")
+}
+
+func TestDialect(t *testing.T) {
+	suite.Run(t, new(DialectTestSuite))
+}
@@ -0,0 +1,201 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+// The MySQL deployments were set up by an old migrate command that created the
+// users and gift_cards tables without recording a version. Its users table is
+// the one of the baseline. Its gift_cards table stores amounts as DECIMAL
+// dollars and has no codes, currencies, remaining amounts or expiry, and its
+// pending cards hold no money since there were no wallets yet.
+//
+// legacyGiftCardsConversion brings that gift_cards table to the baseline
+// schema. The codes are generated in between, see adoptLegacySchema.
+var legacyGiftCardsConversion = []string{
+	`ALTER TABLE gift_cards
+    MODIFY amount DECIMAL(12, 2),
+    ADD code CHAR(16) NULL AFTER id,
+    ADD remaining_amount BIGINT NOT NULL DEFAULT 0 AFTER amount,
+    ADD currency CHAR(3) AFTER remaining_amount,
+    ADD expires_at DATETIME NULL`,
+	fmt.Sprintf("UPDATE gift_cards SET remaining_amount = IF(status = %d, COALESCE(amount, 0) * 100, 0), amount = amount * 100, currency = '%s'",
+		domain.GCSPending, domain.DefaultCurrency),
+	"ALTER TABLE gift_cards MODIFY amount BIGINT, ALTER remaining_amount DROP DEFAULT",
+}
+
+var legacyGiftCardsIndexes = "ALTER TABLE gift_cards MODIFY code CHAR(16) NOT NULL, ADD UNIQUE (code), ADD INDEX (status, expires_at)"
+
+// hasLegacySchema reports whether a MySQL database without applied migrations
+// has the tables of the old migrate command. The other dialects never had
+// that command.
+func (m *Migrator) hasLegacySchema(conn *sql.Conn) (bool, error) {
+	if m.dialect != database.MySQL {
+		return false, nil
+	}
+
+	var tables int
+	query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name IN ('gift_cards', 'users')"
+	if err := conn.QueryRowContext(context.Background(), query).Scan(&tables); err != nil {
+		return false, err
+	}
+
+	return tables > 0, nil
+}
+
+// adoptLegacySchema applies the baseline migration to a database that has the
+// tables of the old migrate command. It converts the gift_cards table, lets
+// the baseline create the missing tables and then funds the wallets of the
+// gifters with the amounts of their pending cards, so that declining or
+// accepting those cards finds the money held like for any other card.
+//
+// Like any migration it is marked dirty until it is done.
+func (m *Migrator) adoptLegacySchema(conn *sql.Conn, migration Migration) error {
+	ctx := context.Background()
+	_, err := conn.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, TRUE, NOW())", migration.Version, migration.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := m.convertLegacyGiftCards(conn); err != nil {
+		return fmt.Errorf("migration %d_%s legacy schema: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := execStatements(conn, migration.Up); err != nil {
+		return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := m.holdLegacyPendingGiftCards(conn); err != nil {
+		return fmt.Errorf("migration %d_%s legacy schema: %w", migration.Version, migration.Name, err)
+	}
+
+	_, err = conn.ExecContext(ctx, "UPDATE schema_migrations SET dirty = FALSE WHERE version = ?", migration.Version)
+
+	return err
+}
+
+func (m *Migrator) convertLegacyGiftCards(conn *sql.Conn) error {
+	ctx := context.Background()
+	for _, query := range legacyGiftCardsConversion {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	ids, err := queryIDs(conn, "SELECT id FROM gift_cards WHERE code IS NULL")
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		code, err := domain.NewGiftCardCode()
+		if err != nil {
+			return err
+		}
+
+		if _, err := conn.ExecContext(ctx, "UPDATE gift_cards SET code = ? WHERE id = ?", code, id); err != nil {
+			return err
+		}
+	}
+
+	_, err = conn.ExecContext(ctx, legacyGiftCardsIndexes)
+
+	return err
+}
+
+// holdLegacyPendingGiftCards deposits the remaining amount of every pending
+// gift card into the wallet of its gifter and holds it there.
+func (m *Migrator) holdLegacyPendingGiftCards(conn *sql.Conn) error {
+	ctx := context.Background()
+	rows, err := conn.QueryContext(ctx, "SELECT id, sender_id, remaining_amount, currency FROM gift_cards WHERE status = ? AND sender_id IS NOT NULL AND remaining_amount > 0 ORDER BY id", int(domain.GCSPending))
+	if err != nil {
+		return err
+	}
+
+	var cards []domain.GiftCard
+	for rows.Next() {
+		var card domain.GiftCard
+		if err := rows.Scan(&card.ID, &card.GifterID, &card.RemainingAmount.Amount, &card.RemainingAmount.Currency); err != nil {
+			rows.Close()
+
+			return err
+		}
+
+		cards = append(cards, card)
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, card := range cards {
+		amount := card.RemainingAmount
+		query := `INSERT INTO wallets (user_id, currency, balance, held, created_at, updated_at) VALUES (?, ?, 0, ?, NOW(), NOW())
+ON DUPLICATE KEY UPDATE held = held + VALUES(held), updated_at = NOW()`
+		if _, err := conn.ExecContext(ctx, query, card.GifterID, amount.Currency, amount.Amount); err != nil {
+			return err
+		}
+
+		giftCardID := card.ID
+		available := domain.WalletAvailableAccount(card.GifterID, amount.Currency)
+		for _, t := range []domain.LedgerTransaction{
+			domain.NewLedgerTransfer(domain.LTTDeposit, nil, domain.ExternalDepositsAccount(amount.Currency), available, amount),
+			domain.NewLedgerTransfer(domain.LTTHold, &giftCardID, available, domain.WalletHeldAccount(card.GifterID, amount.Currency), amount),
+		} {
+			if err := recordLedgerTransaction(conn, t); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func recordLedgerTransaction(conn *sql.Conn, t domain.LedgerTransaction) error {
+	ctx := context.Background()
+	res, err := conn.ExecContext(ctx, "INSERT INTO ledger_transactions (gift_card_id, type, created_at) VALUES (?, ?, NOW())", t.GiftCardID, int(t.Type))
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO ledger_entries (transaction_id, account, direction, amount, currency, created_at) VALUES (?, ?, ?, ?, ?, NOW())"
+	for _, e := range t.Entries {
+		_, err = conn.ExecContext(ctx, query, id, e.Account, int(e.Direction), e.Amount.Amount, e.Amount.Currency)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func queryIDs(conn *sql.Conn, query string) ([]uint, error) {
+	rows, err := conn.QueryContext(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,511 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+// Migrations are numbered pairs of files in the directory of their dialect,
+// named <version>_<name>.up.sql and <version>_<name>.down.sql. A new migration
+// gets the next version in every dialect, applied migrations must never be
+// edited.
+//
+//go:embed migrations/mysql/*.sql migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrationsFS embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+const (
+	lockName    = "gift-card:schema_migrations"
+	lockTimeout = 30 * time.Second
+
+	lockPollInterval = time.Second
+)
+
+var (
+	ErrLocked         = errors.New("another migration is running")
+	ErrUnknownVersion = errors.New("unknown migration version")
+	ErrDirtyMigration = errors.New("a migration failed halfway, fix the schema by hand and remove its row from schema_migrations")
+)
+
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+// Load reads the embedded migrations of the dialect ordered by version.
+func Load(dialect database.Dialect) ([]Migration, error) {
+	return load(migrationsFS, path.Join("migrations", dialect.String()))
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files with different names", version)
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d needs both an up and a down file", m.Version)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Migrator applies and rolls back migrations and keeps track of them in the
+// schema_migrations table. MySQL commits DDL statements implicitly, so a
+// migration is marked dirty while it runs and a failed one has to be cleaned
+// up by hand before migrating again.
+type Migrator struct {
+	db         *sql.DB
+	dialect    database.Dialect
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, dialect: database.DialectOf(db), migrations: migrations}
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up() ([]Migration, error) {
+	if len(m.migrations) == 0 {
+		return nil, nil
+	}
+
+	return m.To(m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down rolls back the last n applied migrations.
+func (m *Migrator) Down(n int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(applied) - 1; i >= 0 && len(done) < n; i-- {
+			migration, err := m.find(applied[i])
+			if err != nil {
+				return err
+			}
+
+			if err := m.down(conn, migration); err != nil {
+				return err
+			}
+
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// To applies or rolls back migrations until version is the latest applied
+// one, zero rolls back all of them.
+func (m *Migrator) To(version uint) ([]Migration, error) {
+	if version != 0 {
+		if _, err := m.find(version); err != nil {
+			return nil, err
+		}
+	}
+
+	var done []Migration
+	err := m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		legacy := false
+		if len(applied) == 0 && version != 0 {
+			legacy, err = m.hasLegacySchema(conn)
+			if err != nil {
+				return err
+			}
+		}
+
+		isApplied := make(map[uint]bool, len(applied))
+		for _, v := range applied {
+			isApplied[v] = true
+		}
+
+		for i := len(applied) - 1; i >= 0 && applied[i] > version; i-- {
+			migration, err := m.find(applied[i])
+			if err != nil {
+				return err
+			}
+
+			if err := m.down(conn, migration); err != nil {
+				return err
+			}
+
+			done = append(done, migration)
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version > version || isApplied[migration.Version] {
+				continue
+			}
+
+			up := m.up
+			if legacy && migration.Version == m.migrations[0].Version {
+				up = m.adoptLegacySchema
+			}
+
+			if err := up(conn, migration); err != nil {
+				return err
+			}
+
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Status returns every known migration and whether it is applied.
+func (m *Migrator) Status() ([]Status, error) {
+	var statuses []Status
+	err := m.withConn(func(conn *sql.Conn) error {
+		if err := m.ensureTable(conn); err != nil {
+			return err
+		}
+
+		rows, err := conn.QueryContext(context.Background(), "SELECT version, dirty, applied_at FROM schema_migrations")
+		if err != nil {
+			return err
+		}
+
+		defer rows.Close()
+		type row struct {
+			dirty     bool
+			appliedAt time.Time
+		}
+
+		applied := make(map[uint]row)
+		for rows.Next() {
+			var version uint
+			var r row
+			if err := rows.Scan(&version, &r.dirty, &r.appliedAt); err != nil {
+				return err
+			}
+
+			applied[version] = r
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := Status{Migration: migration}
+			if r, ok := applied[migration.Version]; ok {
+				appliedAt := r.appliedAt
+				status.Applied = true
+				status.Dirty = r.dirty
+				status.AppliedAt = &appliedAt
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+func (m *Migrator) up(conn *sql.Conn, migration Migration) error {
+	ctx := context.Background()
+	_, err := conn.ExecContext(ctx, m.dialect.Rebind("INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, TRUE, NOW())"), migration.Version, migration.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := execStatements(conn, migration.Up); err != nil {
+		return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+	}
+
+	_, err = conn.ExecContext(ctx, m.dialect.Rebind("UPDATE schema_migrations SET dirty = FALSE WHERE version = ?"), migration.Version)
+
+	return err
+}
+
+func (m *Migrator) down(conn *sql.Conn, migration Migration) error {
+	ctx := context.Background()
+	_, err := conn.ExecContext(ctx, m.dialect.Rebind("UPDATE schema_migrations SET dirty = TRUE WHERE version = ?"), migration.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := execStatements(conn, migration.Down); err != nil {
+		return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+	}
+
+	_, err = conn.ExecContext(ctx, m.dialect.Rebind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+
+	return err
+}
+
+// execStatements runs the statements of a migration one at a time, so that the
+// MySQL connections do not need multiStatements.
+func execStatements(conn *sql.Conn, query string) error {
+	for _, statement := range splitStatements(query) {
+		if _, err := conn.ExecContext(context.Background(), statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitStatements splits the SQL of a migration into its statements without
+// their semicolons. A statement ends with a semicolon at the end of a line,
+// comments stay with the statement that follows them and trailing comments
+// are dropped.
+func splitStatements(query string) []string {
+	var statements []string
+	var statement strings.Builder
+	hasSQL := false
+	for _, line := range strings.Split(query, "\n") {
+		trimmed := strings.TrimSpace(line)
+		isComment := strings.HasPrefix(trimmed, "--")
+		if trimmed != "" && !isComment {
+			hasSQL = true
+		}
+
+		if isComment || !strings.HasSuffix(trimmed, ";") {
+			statement.WriteString(line)
+			statement.WriteString("\n")
+
+			continue
+		}
+
+		statement.WriteString(strings.TrimSuffix(strings.TrimRight(line, " \t\r"), ";"))
+		statements = append(statements, strings.TrimSpace(statement.String()))
+		statement.Reset()
+		hasSQL = false
+	}
+
+	if hasSQL {
+		statements = append(statements, strings.TrimSpace(statement.String()))
+	}
+
+	return statements
+}
+
+// applied returns the versions of the applied migrations in ascending order.
+// It fails if one of them is dirty.
+func (m *Migrator) applied(conn *sql.Conn) ([]uint, error) {
+	if err := m.ensureTable(conn); err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(context.Background(), "SELECT version, dirty FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	var versions []uint
+	for rows.Next() {
+		var version uint
+		var dirty bool
+		if err := rows.Scan(&version, &dirty); err != nil {
+			return nil, err
+		}
+
+		if dirty {
+			return nil, fmt.Errorf("migration %d: %w", version, ErrDirtyMigration)
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+func (m *Migrator) ensureTable(conn *sql.Conn) error {
+	appliedAtType := "DATETIME"
+	if m.dialect == database.Postgres {
+		appliedAtType = "TIMESTAMPTZ"
+	}
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    dirty BOOLEAN NOT NULL,
+    applied_at ` + appliedAtType + ` NOT NULL,
+    PRIMARY KEY (version)
+)`
+	_, err := conn.ExecContext(context.Background(), query)
+
+	return err
+}
+
+func (m *Migrator) find(version uint) (Migration, error) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, nil
+		}
+	}
+
+	return Migration{}, fmt.Errorf("migration %d: %w", version, ErrUnknownVersion)
+}
+
+// withLock runs fn holding an advisory lock, so that replicas starting at the
+// same time do not migrate concurrently. The lock belongs to the session, so
+// fn gets the connection that holds it.
+func (m *Migrator) withLock(fn func(conn *sql.Conn) error) error {
+	return m.withConn(func(conn *sql.Conn) error {
+		lock, unlock := m.lock, m.unlock
+		switch m.dialect {
+		case database.Postgres:
+			lock, unlock = m.pgLock, m.pgUnlock
+		case database.SQLite:
+			// An SQLite database is a local file that only one process
+			// migrates.
+			return fn(conn)
+		}
+
+		if err := lock(conn); err != nil {
+			return err
+		}
+
+		defer unlock(conn)
+
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) lock(conn *sql.Conn) error {
+	var locked sql.NullInt64
+	err := conn.QueryRowContext(context.Background(), "SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&locked)
+	if err != nil {
+		return err
+	}
+
+	if !locked.Valid || locked.Int64 != 1 {
+		return ErrLocked
+	}
+
+	return nil
+}
+
+func (m *Migrator) unlock(conn *sql.Conn) {
+	_, _ = conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+}
+
+// pgLock polls for the lock, pg_advisory_lock would wait for it without a
+// timeout.
+func (m *Migrator) pgLock(conn *sql.Conn) error {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		var locked bool
+		err := conn.QueryRowContext(context.Background(), "SELECT pg_try_advisory_lock($1)", pgLockKey()).Scan(&locked)
+		if err != nil {
+			return err
+		}
+
+		if locked {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return ErrLocked
+		}
+
+		time.Sleep(lockPollInterval)
+	}
+}
+
+func (m *Migrator) pgUnlock(conn *sql.Conn) {
+	_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", pgLockKey())
+}
+
+// pgLockKey derives the numeric key Postgres advisory locks need from
+// lockName.
+func pgLockKey() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(lockName))
+
+	return int64(h.Sum64())
+}
+
+func (m *Migrator) withConn(fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	return fn(conn)
+}
@@ -0,0 +1,417 @@
+package migration
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+type MigratorTestSuite struct {
+	suite.Suite
+	db         *sql.DB
+	mock       sqlmock.Sqlmock
+	migrations []Migration
+	migrator   *Migrator
+}
+
+func (suite *MigratorTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.migrations = []Migration{
+		{Version: 1, Name: "create_users", Up: "CREATE TABLE users (id INT);", Down: "DROP TABLE users;"},
+		{Version: 2, Name: "create_wallets", Up: "CREATE TABLE wallets (id INT);", Down: "DROP TABLE wallets;"},
+	}
+	suite.migrator = NewMigrator(suite.db, suite.migrations)
+}
+
+func (suite *MigratorTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *MigratorTestSuite) expectLock(result any) {
+	suite.mock.ExpectQuery("^SELECT GET_LOCK\\(\\?, \\?\\)$").
+		WithArgs(lockName, 30).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(result))
+}
+
+func (suite *MigratorTestSuite) expectApplied(rows *sqlmock.Rows) {
+	suite.mock.ExpectExec("^CREATE TABLE IF NOT EXISTS schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery("^SELECT version, dirty FROM schema_migrations ORDER BY version$").
+		WillReturnRows(rows)
+}
+
+func (suite *MigratorTestSuite) expectLegacyTables(count int) {
+	suite.mock.ExpectQuery("^SELECT COUNT\\(\\*\\) FROM information_schema.tables WHERE table_schema = DATABASE\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+func (suite *MigratorTestSuite) expectUp(m Migration) {
+	suite.mock.ExpectExec("^INSERT INTO schema_migrations").
+		WithArgs(m.Version, m.Name).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(strings.TrimSuffix(m.Up, ";"))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^UPDATE schema_migrations SET dirty = FALSE WHERE version = \\?$").
+		WithArgs(m.Version).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func (suite *MigratorTestSuite) expectDown(m Migration) {
+	suite.mock.ExpectExec("^UPDATE schema_migrations SET dirty = TRUE WHERE version = \\?$").
+		WithArgs(m.Version).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(strings.TrimSuffix(m.Down, ";"))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^DELETE FROM schema_migrations WHERE version = \\?$").
+		WithArgs(m.Version).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// expectLedgerTransfer expects a ledger transaction that moves amount from one
+// account to another.
+func (suite *MigratorTestSuite) expectLedgerTransfer(giftCardID any, t domain.LedgerTransactionType, from, to string, amount domain.Money) {
+	suite.mock.ExpectExec("^INSERT INTO ledger_transactions").
+		WithArgs(giftCardID, int(t)).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	suite.mock.ExpectExec("^INSERT INTO ledger_entries").
+		WithArgs(int64(7), from, int(domain.LEDDebit), amount.Amount, amount.Currency).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectExec("^INSERT INTO ledger_entries").
+		WithArgs(int64(7), to, int(domain.LEDCredit), amount.Amount, amount.Currency).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+}
+
+func (suite *MigratorTestSuite) expectUnlock() {
+	suite.mock.ExpectExec("^SELECT RELEASE_LOCK\\(\\?\\)$").
+		WithArgs(lockName).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (suite *MigratorTestSuite) TestUp_Success() {
+	require := suite.Require()
+
+	suite.expectLock(1)
+	suite.expectApplied(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false))
+	suite.expectUp(suite.migrations[1])
+	suite.expectUnlock()
+
+	done, err := suite.migrator.Up()
+
+	require.NoError(err)
+	require.Equal([]Migration{suite.migrations[1]}, done)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigratorTestSuite) TestUp_Empty_Success() {
+	require := suite.Require()
+
+	suite.expectLock(1)
+	suite.expectApplied(sqlmock.NewRows([]string{"version", "dirty"}))
+	suite.expectLegacyTables(0)
+	suite.expectUp(suite.migrations[0])
+	suite.expectUp(suite.migrations[1])
+	suite.expectUnlock()
+
+	done, err := suite.migrator.Up()
+
+	require.NoError(err)
+	require.Equal(suite.migrations, done)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigratorTestSuite) TestUp_LegacySchema_Success() {
+	require := suite.Require()
+	amount := domain.NewMoney(1050, "USD")
+	giftCardID := uint(3)
+
+	suite.expectLock(1)
+	suite.expectApplied(sqlmock.NewRows([]string{"version", "dirty"}))
+	suite.expectLegacyTables(2)
+	suite.mock.ExpectExec("^INSERT INTO schema_migrations").
+		WithArgs(uint(1), "create_users").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec("^ALTER TABLE gift_cards\\s+MODIFY amount DECIMAL\\(12, 2\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^UPDATE gift_cards SET remaining_amount = IF\\(status = 2, COALESCE\\(amount, 0\\) \\* 100, 0\\), amount = amount \\* 100, currency = 'USD'$").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectExec("^ALTER TABLE gift_cards MODIFY amount BIGINT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery("^SELECT id FROM gift_cards WHERE code IS NULL$").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(3))
+	suite.mock.ExpectExec("^UPDATE gift_cards SET code = \\? WHERE id = \\?$").
+		WithArgs(sqlmock.AnyArg(), uint(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec("^UPDATE gift_cards SET code = \\? WHERE id = \\?$").
+		WithArgs(sqlmock.AnyArg(), uint(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec("^ALTER TABLE gift_cards MODIFY code CHAR\\(16\\) NOT NULL").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec(regexp.QuoteMeta(strings.TrimSuffix(suite.migrations[0].Up, ";"))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery("^SELECT id, sender_id, remaining_amount, currency FROM gift_cards WHERE status = \\?").
+		WithArgs(int(domain.GCSPending)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "sender_id", "remaining_amount", "currency"}).AddRow(giftCardID, 1, amount.Amount, amount.Currency))
+	suite.mock.ExpectExec("^INSERT INTO wallets .+ VALUES \\(\\?, \\?, 0, \\?, NOW\\(\\), NOW\\(\\)\\)\\s+ON DUPLICATE KEY UPDATE held = held \\+ VALUES\\(held\\)").
+		WithArgs(uint(1), amount.Currency, amount.Amount).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.expectLedgerTransfer(nil, domain.LTTDeposit, domain.ExternalDepositsAccount("USD"), domain.WalletAvailableAccount(1, "USD"), amount)
+	suite.expectLedgerTransfer(giftCardID, domain.LTTHold, domain.WalletAvailableAccount(1, "USD"), domain.WalletHeldAccount(1, "USD"), amount)
+	suite.mock.ExpectExec("^UPDATE schema_migrations SET dirty = FALSE WHERE version = \\?$").
+		WithArgs(uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.expectUp(suite.migrations[1])
+	suite.expectUnlock()
+
+	done, err := suite.migrator.Up()
+
+	require.NoError(err)
+	require.Equal(suite.migrations, done)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigratorTestSuite) TestUp_LegacySchema_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("duplicate column name 'code'")
+
+	suite.expectLock(1)
+	suite.expectApplied(sqlmock.NewRows([]string{"version", "dirty"}))
+	suite.expectLegacyTables(2)
+	suite.mock.ExpectExec("^INSERT INTO schema_migrations").
+		WithArgs(uint(1), "create_users").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec("^ALTER TABLE gift_cards").
+		WillReturnError(expectedError)
+	suite.expectUnlock()
+
+	done, err := suite.migrator.Up()
+
+	require.ErrorIs(err, expectedError)
+	require.Empty(done)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigratorTestSuite) TestUp_Locked_Failure() {
+	require := suite.Require()
+
+	suite.expectLock(0)
+
+	done, err := suite.migrator.Up()
+
+	require.ErrorIs(err, ErrLocked)
+	require.Empty(done)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigratorTestSuite) TestUp_Dirty_Failure() {
+	require := suite.Require()
+
+	suite.expectLock(1)
+	suite.expectApplied(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, true))
+	suite.expectUnlock()
+
+	done, err := suite.migrator.Up()
+
+	require.ErrorIs(err, ErrDirtyMigration)
+	require.Empty(done)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigratorTestSuite) TestDown_Success() {
+	require := suite.Require()
+
+	suite.expectLock(1)
+	suite.expectApplied(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false).AddRow(2, false))
+	suite.expectDown(suite.migrations[1])
+	suite.expectUnlock()
+
+	done, err := suite.migrator.Down(1)
+
+	require.NoError(err)
+	require.Equal([]Migration{suite.migrations[1]}, done)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigratorTestSuite) TestTo_Down_Success() {
+	require := suite.Require()
+
+	suite.expectLock(1)
+	suite.expectApplied(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false).AddRow(2, false))
+	suite.expectDown(suite.migrations[1])
+	suite.expectDown(suite.migrations[0])
+	suite.expectUnlock()
+
+	done, err := suite.migrator.To(0)
+
+	require.NoError(err)
+	require.Equal([]Migration{suite.migrations[1], suite.migrations[0]}, done)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigratorTestSuite) TestTo_UnknownVersion_Failure() {
+	require := suite.Require()
+
+	done, err := suite.migrator.To(3)
+
+	require.ErrorIs(err, ErrUnknownVersion)
+	require.Empty(done)
+}
+
+func (suite *MigratorTestSuite) TestStatus_Success() {
+	require := suite.Require()
+	appliedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.mock.ExpectExec("^CREATE TABLE IF NOT EXISTS schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery("^SELECT version, dirty, applied_at FROM schema_migrations$").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty", "applied_at"}).AddRow(1, false, appliedAt))
+
+	statuses, err := suite.migrator.Status()
+
+	require.NoError(err)
+	require.Equal([]Status{
+		{Migration: suite.migrations[0], Applied: true, AppliedAt: &appliedAt},
+		{Migration: suite.migrations[1]},
+	}, statuses)
+}
+
+func (suite *MigratorTestSuite) TestUp_Postgres_Success() {
+	require := suite.Require()
+	suite.migrator.dialect = database.Postgres
+
+	suite.mock.ExpectQuery("^SELECT pg_try_advisory_lock\\(\\$1\\)$").
+		WithArgs(pgLockKey()).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	suite.expectApplied(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false))
+	suite.mock.ExpectExec("^INSERT INTO schema_migrations .+ VALUES \\(\\$1, \\$2, TRUE, NOW\\(\\)\\)$").
+		WithArgs(uint(2), "create_wallets").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta(strings.TrimSuffix(suite.migrations[1].Up, ";"))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^UPDATE schema_migrations SET dirty = FALSE WHERE version = \\$1$").
+		WithArgs(uint(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec("^SELECT pg_advisory_unlock\\(\\$1\\)$").
+		WithArgs(pgLockKey()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	done, err := suite.migrator.Up()
+
+	require.NoError(err)
+	require.Equal([]Migration{suite.migrations[1]}, done)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigratorTestSuite) TestUp_Statements_Success() {
+	require := suite.Require()
+	migration := Migration{Version: 1, Name: "create_users", Up: `-- The users.
+CREATE TABLE users (
+    id INT
+);
+-- Their emails.
+CREATE INDEX users_email ON users (email);
+-- Nothing to do after this.
+`}
+	suite.migrator = NewMigrator(suite.db, []Migration{migration})
+
+	suite.expectLock(1)
+	suite.expectApplied(sqlmock.NewRows([]string{"version", "dirty"}))
+	suite.expectLegacyTables(0)
+	suite.mock.ExpectExec("^INSERT INTO schema_migrations").
+		WithArgs(uint(1), "create_users").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(regexp.QuoteMeta("-- The users.\nCREATE TABLE users (\n    id INT\n)") + "$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec(regexp.QuoteMeta("-- Their emails.\nCREATE INDEX users_email ON users (email)") + "$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^UPDATE schema_migrations SET dirty = FALSE WHERE version = \\?$").
+		WithArgs(uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.expectUnlock()
+
+	done, err := suite.migrator.Up()
+
+	require.NoError(err)
+	require.Equal([]Migration{migration}, done)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigratorTestSuite) TestLoad_Embedded_Success() {
+	require := suite.Require()
+
+	mysqlMigrations, err := Load(database.MySQL)
+	require.NoError(err)
+	require.NotEmpty(mysqlMigrations)
+	for i, m := range mysqlMigrations {
+		require.Equal(uint(i+1), m.Version, "migration versions must be consecutive")
+	}
+
+	for _, dialect := range []database.Dialect{database.Postgres, database.SQLite} {
+		migrations, err := Load(dialect)
+		require.NoError(err)
+		require.Len(migrations, len(mysqlMigrations), "every migration needs a version for each dialect")
+		for i, m := range migrations {
+			require.Equal(mysqlMigrations[i].Version, m.Version, dialect.String())
+			require.Equal(mysqlMigrations[i].Name, m.Name, dialect.String())
+		}
+	}
+
+	for _, m := range mysqlMigrations {
+		for _, statement := range append(splitStatements(m.Up), splitStatements(m.Down)...) {
+			require.NotContains(statement, ";", "MySQL runs one statement at a time")
+		}
+	}
+}
+
+func (suite *MigratorTestSuite) TestLoad_Success() {
+	require := suite.Require()
+	fsys := fstest.MapFS{
+		"m/0002_b.up.sql":   {Data: []byte("up b")},
+		"m/0002_b.down.sql": {Data: []byte("down b")},
+		"m/0001_a.up.sql":   {Data: []byte("up a")},
+		"m/0001_a.down.sql": {Data: []byte("down a")},
+	}
+
+	migrations, err := load(fsys, "m")
+
+	require.NoError(err)
+	require.Equal([]Migration{
+		{Version: 1, Name: "a", Up: "up a", Down: "down a"},
+		{Version: 2, Name: "b", Up: "up b", Down: "down b"},
+	}, migrations)
+}
+
+func (suite *MigratorTestSuite) TestLoad_MissingDown_Failure() {
+	require := suite.Require()
+	fsys := fstest.MapFS{
+		"m/0001_a.up.sql": {Data: []byte("up a")},
+	}
+
+	_, err := load(fsys, "m")
+
+	require.EqualError(err, "migration 1 needs both an up and a down file")
+}
+
+func (suite *MigratorTestSuite) TestLoad_InvalidName_Failure() {
+	require := suite.Require()
+	fsys := fstest.MapFS{
+		"m/create_users.sql": {Data: []byte("up a")},
+	}
+
+	_, err := load(fsys, "m")
+
+	require.EqualError(err, `invalid migration file name "create_users.sql"`)
+}
+
+func TestMigrator(t *testing.T) {
+	suite.Run(t, new(MigratorTestSuite))
+}
@@ -0,0 +1,131 @@
+-- The baseline schema. A database that has the tables of the old destructive
+-- migrate command is converted to it first, see Migrator.adoptLegacySchema.
+CREATE TABLE IF NOT EXISTS gift_cards (
+    id INT AUTO_INCREMENT,
+    code CHAR(16) NOT NULL,
+    amount BIGINT,
+    remaining_amount BIGINT NOT NULL,
+    currency CHAR(3),
+    sender_id INT,
+    receiver_id INT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    status TINYINT,
+    expires_at DATETIME NULL,
+    PRIMARY KEY (id),
+    UNIQUE (code),
+    INDEX (status, expires_at)
+);
+CREATE TABLE IF NOT EXISTS gift_card_redemptions (
+    id INT AUTO_INCREMENT,
+    gift_card_id INT NOT NULL,
+    redeemer_id INT NOT NULL,
+    amount BIGINT NOT NULL,
+    remaining_amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME,
+    PRIMARY KEY (id),
+    INDEX (gift_card_id)
+);
+CREATE TABLE IF NOT EXISTS gift_card_status_history (
+    id INT AUTO_INCREMENT,
+    gift_card_id INT NOT NULL,
+    from_status TINYINT NULL,
+    to_status TINYINT NOT NULL,
+    actor_id INT NULL,
+    created_at DATETIME,
+    PRIMARY KEY (id),
+    INDEX (gift_card_id)
+);
+CREATE TABLE IF NOT EXISTS users (
+    id INT AUTO_INCREMENT,
+    username VARCHAR(255),
+    email VARCHAR(255),
+    password VARCHAR(255),
+    created_at DATETIME,
+    updated_at DATETIME,
+    PRIMARY KEY (id),
+    UNIQUE (username),
+    UNIQUE (email)
+);
+CREATE TABLE IF NOT EXISTS wallets (
+    id INT AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    balance BIGINT NOT NULL DEFAULT 0,
+    held BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME,
+    updated_at DATETIME,
+    PRIMARY KEY (id),
+    UNIQUE (user_id, currency)
+);
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id INT AUTO_INCREMENT,
+    gift_card_id INT NULL,
+    type TINYINT NOT NULL,
+    created_at DATETIME,
+    PRIMARY KEY (id),
+    INDEX (gift_card_id)
+);
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id INT AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code SMALLINT NULL,
+    response_body BLOB NULL,
+    created_at DATETIME,
+    PRIMARY KEY (id),
+    UNIQUE (user_id, idempotency_key)
+);
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id INT AUTO_INCREMENT,
+    transaction_id INT NOT NULL,
+    account VARCHAR(64) NOT NULL,
+    direction TINYINT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME,
+    PRIMARY KEY (id),
+    INDEX (transaction_id),
+    INDEX (account)
+);
+CREATE TABLE IF NOT EXISTS outbox (
+    id INT AUTO_INCREMENT,
+    event_type VARCHAR(64) NOT NULL,
+    aggregate_id INT NOT NULL,
+    payload JSON NOT NULL,
+    created_at DATETIME,
+    published_at DATETIME NULL,
+    PRIMARY KEY (id),
+    INDEX (published_at, id)
+);
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INT AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    secret CHAR(64) NOT NULL,
+    active BOOLEAN NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    PRIMARY KEY (id),
+    INDEX (user_id)
+);
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INT AUTO_INCREMENT,
+    webhook_id INT NOT NULL,
+    event_id INT NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    aggregate_id INT NOT NULL,
+    payload JSON NOT NULL,
+    status TINYINT NOT NULL,
+    attempts INT NOT NULL,
+    next_attempt_at DATETIME NOT NULL,
+    last_status_code SMALLINT NULL,
+    last_error VARCHAR(1024) NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    PRIMARY KEY (id),
+    INDEX (status, next_attempt_at),
+    INDEX (webhook_id, id)
+);
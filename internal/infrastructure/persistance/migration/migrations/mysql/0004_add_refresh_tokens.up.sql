@@ -0,0 +1,22 @@
+-- Refresh tokens are stored by the SHA-256 of the token. Every token rotated
+-- from the same login shares the family, reusing a rotated token revokes it.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id INT AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    family_id CHAR(32) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME NULL,
+    revoked_at DATETIME NULL,
+    created_at DATETIME,
+    PRIMARY KEY (id),
+    UNIQUE (token_hash),
+    INDEX (family_id)
+);
+-- Access tokens revoked before they expire, by their jti.
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti CHAR(32) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (jti),
+    INDEX (expires_at)
+);
@@ -0,0 +1,30 @@
+-- Audit events are append only. Every event is hashed with the hash of the
+-- event before it, audit_chain holds the hash of the last one and its row is
+-- locked while an event is added so the chain never forks. The snapshots are
+-- TEXT, a JSON column would reformat them and break their hash.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id INT AUTO_INCREMENT,
+    actor_id INT NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id INT NULL,
+    before_state TEXT NULL,
+    after_state TEXT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    user_agent TEXT NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (id),
+    INDEX (actor_id, id),
+    INDEX (target_type, target_id, id),
+    INDEX (action, id),
+    INDEX (created_at)
+);
+CREATE TABLE IF NOT EXISTS audit_chain (
+    id INT NOT NULL,
+    last_hash VARCHAR(64) NOT NULL,
+    PRIMARY KEY (id)
+);
+INSERT INTO audit_chain (id, last_hash) VALUES (1, '');
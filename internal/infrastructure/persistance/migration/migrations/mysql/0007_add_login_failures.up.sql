@@ -0,0 +1,11 @@
+-- The failed logins of an account (by email) or an IP, see
+-- domain.LoginFailures. A row is dropped once it expires.
+CREATE TABLE IF NOT EXISTS login_failures (
+    login_key VARCHAR(255) NOT NULL,
+    failures INT NOT NULL,
+    last_failed_at DATETIME(6) NOT NULL,
+    locked_until DATETIME(6) NULL,
+    expires_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (login_key),
+    INDEX (expires_at)
+);
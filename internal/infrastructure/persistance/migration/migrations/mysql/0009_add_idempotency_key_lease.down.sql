@@ -0,0 +1,6 @@
+ALTER TABLE idempotency_keys
+    DROP INDEX idempotency_keys_expires_at_idx,
+    DROP COLUMN expires_at,
+    DROP COLUMN locked_at,
+    DROP COLUMN response_headers,
+    DROP COLUMN token;
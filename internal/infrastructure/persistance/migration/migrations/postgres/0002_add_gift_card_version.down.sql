@@ -0,0 +1 @@
+ALTER TABLE gift_cards DROP COLUMN version;
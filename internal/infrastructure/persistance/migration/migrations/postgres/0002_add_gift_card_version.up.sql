@@ -0,0 +1,3 @@
+-- The version of a gift card is bumped on every change, updates compare and
+-- swap it and clients send it back in If-Match.
+ALTER TABLE gift_cards ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
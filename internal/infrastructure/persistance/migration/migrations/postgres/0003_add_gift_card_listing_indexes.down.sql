@@ -0,0 +1,2 @@
+DROP INDEX gift_cards_receiver_id_created_at_id_idx;
+DROP INDEX gift_cards_sender_id_created_at_id_idx;
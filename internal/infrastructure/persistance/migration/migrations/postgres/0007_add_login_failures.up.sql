@@ -0,0 +1,10 @@
+-- The failed logins of an account (by email) or an IP, see
+-- domain.LoginFailures. A row is dropped once it expires.
+CREATE TABLE IF NOT EXISTS login_failures (
+    login_key VARCHAR(255) PRIMARY KEY,
+    failures INTEGER NOT NULL,
+    last_failed_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS login_failures_expires_at_idx ON login_failures (expires_at);
@@ -0,0 +1,3 @@
+-- The remaining amounts of the cards accepted before 0008 are not known any
+-- more and their money stays in the wallets of their giftees.
+SELECT 1;
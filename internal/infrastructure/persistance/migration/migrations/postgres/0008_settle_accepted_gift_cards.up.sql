@@ -0,0 +1,7 @@
+-- Accepting a gift card used to credit its amount to the giftee's wallet and
+-- redemptions were paid out of that wallet. The amount of a card accepted from
+-- now on moves into the ledger account of the card instead, see
+-- domain.GiftCardAccount. The cards accepted before have no such account,
+-- their money is already in the wallets of their giftees, so nothing is left on
+-- them to redeem.
+UPDATE gift_cards SET remaining_amount = 0, version = version + 1 WHERE status = 0 AND remaining_amount > 0;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idempotency_keys_expires_at_idx;
+ALTER TABLE idempotency_keys
+    DROP COLUMN expires_at,
+    DROP COLUMN locked_at,
+    DROP COLUMN response_headers,
+    DROP COLUMN token;
@@ -0,0 +1,11 @@
+-- A request holds its idempotency key for a lease from locked_at, token tells
+-- the request that holds it, and the key is dropped once it expires, see
+-- domain.IdempotencyKey. The response headers are replayed with the body. The
+-- keys stored before expire right away.
+ALTER TABLE idempotency_keys
+    ADD COLUMN token VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN response_headers TEXT NULL,
+    ADD COLUMN locked_at TIMESTAMPTZ NULL,
+    ADD COLUMN expires_at TIMESTAMPTZ NULL;
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+UPDATE idempotency_keys SET locked_at = created_at, expires_at = created_at;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS gift_card_redemptions;
+DROP TABLE IF EXISTS gift_card_status_history;
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_transactions;
+DROP TABLE IF EXISTS wallets;
+DROP TABLE IF EXISTS gift_cards;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,117 @@
+-- The baseline schema, the SQLite counterpart of the MySQL one. Time columns
+-- are declared DATETIME so that the driver reads them back as times.
+CREATE TABLE IF NOT EXISTS gift_cards (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code CHAR(16) NOT NULL UNIQUE,
+    amount BIGINT,
+    remaining_amount BIGINT NOT NULL,
+    currency CHAR(3),
+    sender_id INTEGER,
+    receiver_id INTEGER,
+    created_at DATETIME,
+    updated_at DATETIME,
+    status SMALLINT,
+    expires_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS gift_cards_status_expires_at_idx ON gift_cards (status, expires_at);
+CREATE TABLE IF NOT EXISTS gift_card_redemptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    gift_card_id INTEGER NOT NULL,
+    redeemer_id INTEGER NOT NULL,
+    amount BIGINT NOT NULL,
+    remaining_amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS gift_card_redemptions_gift_card_id_idx ON gift_card_redemptions (gift_card_id);
+CREATE TABLE IF NOT EXISTS gift_card_status_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    gift_card_id INTEGER NOT NULL,
+    from_status SMALLINT NULL,
+    to_status SMALLINT NOT NULL,
+    actor_id INTEGER NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS gift_card_status_history_gift_card_id_idx ON gift_card_status_history (gift_card_id);
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) UNIQUE,
+    email VARCHAR(255) UNIQUE,
+    password VARCHAR(255),
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE TABLE IF NOT EXISTS wallets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL,
+    balance BIGINT NOT NULL DEFAULT 0,
+    held BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME,
+    updated_at DATETIME,
+    UNIQUE (user_id, currency)
+);
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    gift_card_id INTEGER NULL,
+    type SMALLINT NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS ledger_transactions_gift_card_id_idx ON ledger_transactions (gift_card_id);
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code SMALLINT NULL,
+    response_body BLOB NULL,
+    created_at DATETIME,
+    UNIQUE (user_id, idempotency_key)
+);
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL,
+    account VARCHAR(64) NOT NULL,
+    direction SMALLINT NOT NULL,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS ledger_entries_transaction_id_idx ON ledger_entries (transaction_id);
+CREATE INDEX IF NOT EXISTS ledger_entries_account_idx ON ledger_entries (account);
+CREATE TABLE IF NOT EXISTS outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type VARCHAR(64) NOT NULL,
+    aggregate_id INTEGER NOT NULL,
+    payload TEXT NOT NULL,
+    created_at DATETIME,
+    published_at DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS outbox_published_at_id_idx ON outbox (published_at, id);
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    secret CHAR(64) NOT NULL,
+    active BOOLEAN NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS webhooks_user_id_idx ON webhooks (user_id);
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL,
+    event_id INTEGER NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    aggregate_id INTEGER NOT NULL,
+    payload TEXT NOT NULL,
+    status SMALLINT NOT NULL,
+    attempts INTEGER NOT NULL,
+    next_attempt_at DATETIME NOT NULL,
+    last_status_code SMALLINT NULL,
+    last_error VARCHAR(1024) NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_status_next_attempt_at_idx ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_id_idx ON webhook_deliveries (webhook_id, id);
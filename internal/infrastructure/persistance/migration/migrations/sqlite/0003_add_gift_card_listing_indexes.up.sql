@@ -0,0 +1,3 @@
+-- The listings of a user page through their gift cards by (created_at, id).
+CREATE INDEX gift_cards_receiver_id_created_at_id_idx ON gift_cards (receiver_id, created_at, id);
+CREATE INDEX gift_cards_sender_id_created_at_id_idx ON gift_cards (sender_id, created_at, id);
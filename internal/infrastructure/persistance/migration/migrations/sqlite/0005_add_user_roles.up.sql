@@ -0,0 +1,2 @@
+-- The staff roles of a user, comma separated.
+ALTER TABLE users ADD COLUMN roles VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,28 @@
+-- Audit events are append only. Every event is hashed with the hash of the
+-- event before it, audit_chain holds the hash of the last one and its row is
+-- locked while an event is added so the chain never forks. The snapshots are
+-- TEXT, a JSON column would reformat them and break their hash.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id INTEGER NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id INTEGER NULL,
+    before_state TEXT NULL,
+    after_state TEXT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    user_agent TEXT NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_events_actor_id_id_idx ON audit_events (actor_id, id);
+CREATE INDEX IF NOT EXISTS audit_events_target_id_idx ON audit_events (target_type, target_id, id);
+CREATE INDEX IF NOT EXISTS audit_events_action_id_idx ON audit_events (action, id);
+CREATE INDEX IF NOT EXISTS audit_events_created_at_idx ON audit_events (created_at);
+CREATE TABLE IF NOT EXISTS audit_chain (
+    id INTEGER PRIMARY KEY,
+    last_hash VARCHAR(64) NOT NULL
+);
+INSERT INTO audit_chain (id, last_hash) VALUES (1, '');
@@ -0,0 +1,10 @@
+-- The failed logins of an account (by email) or an IP, see
+-- domain.LoginFailures. A row is dropped once it expires.
+CREATE TABLE IF NOT EXISTS login_failures (
+    login_key VARCHAR(255) PRIMARY KEY,
+    failures INTEGER NOT NULL,
+    last_failed_at DATETIME NOT NULL,
+    locked_until DATETIME NULL,
+    expires_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS login_failures_expires_at_idx ON login_failures (expires_at);
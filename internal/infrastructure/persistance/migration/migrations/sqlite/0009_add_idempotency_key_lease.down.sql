@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idempotency_keys_expires_at_idx;
+ALTER TABLE idempotency_keys DROP COLUMN expires_at;
+ALTER TABLE idempotency_keys DROP COLUMN locked_at;
+ALTER TABLE idempotency_keys DROP COLUMN response_headers;
+ALTER TABLE idempotency_keys DROP COLUMN token;
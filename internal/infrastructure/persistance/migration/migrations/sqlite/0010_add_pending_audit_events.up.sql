@@ -0,0 +1,17 @@
+-- Events that are not part of a change, like failed logins, wait here
+-- unchained so that recording them does not lock audit_chain. A worker
+-- chains them into audit_events in batches and deletes them, see
+-- AuditRepository.ChainPending.
+CREATE TABLE IF NOT EXISTS pending_audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id INTEGER NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id INTEGER NULL,
+    before_state TEXT NULL,
+    after_state TEXT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    user_agent TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 500
+)
+
+// AuditQuery selects the audit events of a listing, newest first. Unset
+// filters match every event.
+type AuditQuery struct {
+	ActorID    *uint
+	Action     domain.AuditAction
+	TargetType string
+	TargetID   *uint
+	// From and To match events recorded in between, both inclusive.
+	From *time.Time
+	To   *time.Time
+	// BeforeID matches the events recorded before the event with the id,
+	// the last id of a page is the BeforeID of the next one.
+	BeforeID uint
+	Limit    int
+}
+
+type AuditRepository interface {
+	// Record chains the event to the last event and adds it to the log.
+	Record(ctx context.Context, event *domain.AuditEvent) error
+	// Enqueue stores the event without chaining it, ChainPending adds it to
+	// the log later. It does not lock the chain, so it is meant for events
+	// that are not part of a change and that anybody can cause at any rate,
+	// like failed logins.
+	Enqueue(ctx context.Context, event *domain.AuditEvent) error
+	// ChainPending chains up to limit enqueued events in the order they were
+	// enqueued, adds them to the log with the time they were enqueued at and
+	// returns how many it chained.
+	ChainPending(ctx context.Context, limit int) (int, error)
+	Find(ctx context.Context, query AuditQuery) ([]domain.AuditEvent, error)
+	// FindAfter returns up to limit events recorded after the event with
+	// the id, in the order they were recorded.
+	FindAfter(ctx context.Context, afterID uint, limit int) ([]domain.AuditEvent, error)
+	// LastHash returns the hash of the last event, empty if there is none.
+	LastHash(ctx context.Context) (string, error)
+}
+
+type AuditEventEntity struct {
+	ID          uint
+	ActorID     sql.NullInt64
+	Action      string
+	TargetType  string
+	TargetID    sql.NullInt64
+	BeforeState sql.NullString
+	AfterState  sql.NullString
+	RequestID   string
+	IP          string
+	UserAgent   string
+	PrevHash    string
+	Hash        string
+	CreatedAt   time.Time
+}
+
+func (e AuditEventEntity) ToAggregate() domain.AuditEvent {
+	event := domain.AuditEvent{
+		ID:         e.ID,
+		Action:     domain.AuditAction(e.Action),
+		TargetType: e.TargetType,
+		RequestID:  e.RequestID,
+		IP:         e.IP,
+		UserAgent:  e.UserAgent,
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+		CreatedAt:  e.CreatedAt.UTC(),
+	}
+
+	if e.ActorID.Valid {
+		actorID := uint(e.ActorID.Int64)
+		event.ActorID = &actorID
+	}
+
+	if e.TargetID.Valid {
+		targetID := uint(e.TargetID.Int64)
+		event.TargetID = &targetID
+	}
+
+	if e.BeforeState.Valid {
+		event.Before = []byte(e.BeforeState.String)
+	}
+
+	if e.AfterState.Valid {
+		event.After = []byte(e.AfterState.String)
+	}
+
+	return event
+}
+
+type auditRepository struct {
+	db sqlDB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: newSQLDB(db)}
+}
+
+func (r *auditRepository) Record(ctx context.Context, event *domain.AuditEvent) error {
+	return withTx(ctx, r.db, func(tx sqlTx) error {
+		return recordAudit(ctx, tx, event)
+	})
+}
+
+func (r *auditRepository) Enqueue(ctx context.Context, event *domain.AuditEvent) error {
+	event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	query := "INSERT INTO pending_audit_events (actor_id, action, target_type, target_id, before_state, after_state, request_id, ip, user_agent, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	id, err := insert(ctx, r.db, query, event.ActorID, string(event.Action), event.TargetType, event.TargetID,
+		nullableString(event.Before), nullableString(event.After), event.RequestID, event.IP, event.UserAgent, event.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	event.ID = id
+
+	return nil
+}
+
+// ChainPending locks the head of the chain once for the whole batch, before it
+// reads the batch, so that concurrent calls take turns and never chain an
+// event twice.
+func (r *auditRepository) ChainPending(ctx context.Context, limit int) (int, error) {
+	var chained int
+	err := withTx(ctx, r.db, func(tx sqlTx) error {
+		var prevHash string
+		err := tx.QueryRowContext(ctx, "SELECT last_hash FROM audit_chain WHERE id = 1 FOR UPDATE").Scan(&prevHash)
+		if err != nil {
+			return err
+		}
+
+		q := "SELECT " + pendingAuditEventColumns + " FROM pending_audit_events ORDER BY id LIMIT ?"
+		events, err := findAuditEvents(ctx, tx, q, limit)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		for _, event := range events {
+			pendingID := event.ID
+			event.Chain(prevHash, event.CreatedAt)
+			if err := insertAuditEvent(ctx, tx, &event); err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, "DELETE FROM pending_audit_events WHERE id = ?", pendingID); err != nil {
+				return err
+			}
+
+			prevHash = event.Hash
+		}
+
+		chained = len(events)
+		_, err = tx.ExecContext(ctx, "UPDATE audit_chain SET last_hash = ? WHERE id = 1", prevHash)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return chained, nil
+}
+
+const auditEventColumns = "id, actor_id, action, target_type, target_id, before_state, after_state, request_id, ip, user_agent, prev_hash, hash, created_at"
+
+// pendingAuditEventColumns reads enqueued events like chained ones, they have
+// no hashes yet.
+const pendingAuditEventColumns = "id, actor_id, action, target_type, target_id, before_state, after_state, request_id, ip, user_agent, '' AS prev_hash, '' AS hash, created_at"
+
+func (r *auditRepository) Find(ctx context.Context, query AuditQuery) ([]domain.AuditEvent, error) {
+	var where []string
+	var args []any
+	if query.ActorID != nil {
+		where = append(where, "actor_id = ?")
+		args = append(args, *query.ActorID)
+	}
+
+	if query.Action != "" {
+		where = append(where, "action = ?")
+		args = append(args, string(query.Action))
+	}
+
+	if query.TargetType != "" {
+		where = append(where, "target_type = ?")
+		args = append(args, query.TargetType)
+	}
+
+	if query.TargetID != nil {
+		where = append(where, "target_id = ?")
+		args = append(args, *query.TargetID)
+	}
+
+	if query.From != nil {
+		where = append(where, "created_at >= ?")
+		args = append(args, query.From.UTC())
+	}
+
+	if query.To != nil {
+		where = append(where, "created_at <= ?")
+		args = append(args, query.To.UTC())
+	}
+
+	if query.BeforeID != 0 {
+		where = append(where, "id < ?")
+		args = append(args, query.BeforeID)
+	}
+
+	q := "SELECT " + auditEventColumns + " FROM audit_events"
+	if len(where) > 0 {
+		q += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	q += " ORDER BY id DESC LIMIT ?"
+	args = append(args, query.Limit)
+
+	return findAuditEvents(ctx, r.db, q, args...)
+}
+
+func (r *auditRepository) FindAfter(ctx context.Context, afterID uint, limit int) ([]domain.AuditEvent, error) {
+	q := "SELECT " + auditEventColumns + " FROM audit_events WHERE id > ? ORDER BY id LIMIT ?"
+
+	return findAuditEvents(ctx, r.db, q, afterID, limit)
+}
+
+func (r *auditRepository) LastHash(ctx context.Context) (string, error) {
+	var hash string
+	err := r.db.QueryRowContext(ctx, "SELECT last_hash FROM audit_chain WHERE id = 1").Scan(&hash)
+
+	return hash, err
+}
+
+func findAuditEvents(ctx context.Context, db executor, query string, args ...any) ([]domain.AuditEvent, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	var events []domain.AuditEvent
+	for rows.Next() {
+		var e AuditEventEntity
+		err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID, &e.BeforeState, &e.AfterState,
+			&e.RequestID, &e.IP, &e.UserAgent, &e.PrevHash, &e.Hash, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, e.ToAggregate())
+	}
+
+	return events, rows.Err()
+}
+
+// recordAudit chains the event to the last event and adds it to the log. It
+// is called with the transaction of the change the event describes, so the
+// event is stored if and only if the change is. The head of the chain stays
+// locked until the transaction ends, events are chained one at a time in the
+// order of their ids. It must be the last write of the transaction, so that
+// every transaction locks the chain after its other rows and none holds the
+// chain while waiting for a row another one holds.
+func recordAudit(ctx context.Context, tx sqlTx, event *domain.AuditEvent) error {
+	var prevHash string
+	err := tx.QueryRowContext(ctx, "SELECT last_hash FROM audit_chain WHERE id = 1 FOR UPDATE").Scan(&prevHash)
+	if err != nil {
+		return err
+	}
+
+	event.Chain(prevHash, time.Now())
+	if err := insertAuditEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE audit_chain SET last_hash = ? WHERE id = 1", event.Hash)
+
+	return err
+}
+
+// insertAuditEvent adds the chained event to the log and sets its id.
+func insertAuditEvent(ctx context.Context, tx sqlTx, event *domain.AuditEvent) error {
+	query := "INSERT INTO audit_events (actor_id, action, target_type, target_id, before_state, after_state, request_id, ip, user_agent, prev_hash, hash, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	id, err := insert(ctx, tx, query, event.ActorID, string(event.Action), event.TargetType, event.TargetID,
+		nullableString(event.Before), nullableString(event.After), event.RequestID, event.IP, event.UserAgent,
+		event.PrevHash, event.Hash, event.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	event.ID = id
+
+	return nil
+}
+
+// nullableString passes a snapshot as a string, or NULL when there is none.
+func nullableString(b []byte) *string {
+	if b == nil {
+		return nil
+	}
+
+	s := string(b)
+
+	return &s
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type AuditRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *auditRepository
+}
+
+func (suite *AuditRepositoryTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.repo = &auditRepository{
+		db: newSQLDB(suite.db),
+	}
+}
+
+func (suite *AuditRepositoryTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *AuditRepositoryTestSuite) TestNewAuditRepository() {
+	require := suite.Require()
+
+	db, _, _ := sqlmock.New()
+	repo := NewAuditRepository(db)
+
+	require.NotNil(repo)
+}
+
+func (suite *AuditRepositoryTestSuite) TestRecord_Success() {
+	require := suite.Require()
+	actorID := uint(10)
+	ctx := domain.WithRequestInfo(context.Background(), domain.RequestInfo{ID: "req", IP: "192.0.2.1", UserAgent: "curl"})
+	event, err := domain.NewAuditEvent(ctx, domain.AuditUserLoggedIn, &actorID, domain.AuditTargetUser, &actorID, nil, nil)
+	require.NoError(err)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT last_hash FROM audit_chain WHERE id = 1 FOR UPDATE$").
+		WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow("prev"))
+	suite.mock.ExpectExec("^INSERT INTO audit_events").
+		WithArgs(actorID, string(domain.AuditUserLoggedIn), domain.AuditTargetUser, actorID, nil, nil, "req", "192.0.2.1", "curl", "prev", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	suite.mock.ExpectExec("^UPDATE audit_chain SET last_hash = \\? WHERE id = 1$").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err = suite.repo.Record(context.Background(), &event)
+
+	require.NoError(err)
+	require.Equal(uint(7), event.ID)
+	require.Equal("prev", event.PrevHash)
+	require.Equal(event.ComputeHash(), event.Hash)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *AuditRepositoryTestSuite) TestEnqueue_Success() {
+	require := suite.Require()
+	event, err := domain.NewAuditEvent(context.Background(), domain.AuditUserLoginFailed, nil, domain.AuditTargetUser, nil, nil, map[string]string{"email": "foo@example.com"})
+	require.NoError(err)
+
+	suite.mock.ExpectExec("^INSERT INTO pending_audit_events").
+		WithArgs(nil, string(domain.AuditUserLoginFailed), domain.AuditTargetUser, nil, nil, `{"email":"foo@example.com"}`, "", "", "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	err = suite.repo.Enqueue(context.Background(), &event)
+
+	require.NoError(err)
+	require.Equal(uint(3), event.ID)
+	require.False(event.CreatedAt.IsZero())
+	require.Empty(event.Hash)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *AuditRepositoryTestSuite) TestChainPending_Success() {
+	require := suite.Require()
+	createdAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "actor_id", "action", "target_type", "target_id", "before_state", "after_state", "request_id", "ip", "user_agent", "prev_hash", "hash", "created_at"}).
+		AddRow(4, nil, "user.login_failed", "user", nil, nil, `{"email":"a@example.com"}`, "", "192.0.2.1", "", "", "", createdAt).
+		AddRow(5, nil, "user.login_failed", "user", nil, nil, `{"email":"b@example.com"}`, "", "192.0.2.1", "", "", "", createdAt)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT last_hash FROM audit_chain WHERE id = 1 FOR UPDATE$").
+		WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow("prev"))
+	suite.mock.ExpectQuery("^SELECT .+ FROM pending_audit_events ORDER BY id LIMIT \\?$").
+		WithArgs(10).
+		WillReturnRows(rows)
+	for i, id := range []int{4, 5} {
+		var prevHash any = sqlmock.AnyArg()
+		if i == 0 {
+			prevHash = "prev"
+		}
+
+		suite.mock.ExpectExec("^INSERT INTO audit_events").
+			WithArgs(nil, "user.login_failed", "user", nil, nil, sqlmock.AnyArg(), "", "192.0.2.1", "", prevHash, sqlmock.AnyArg(), createdAt).
+			WillReturnResult(sqlmock.NewResult(int64(10+i), 1))
+		suite.mock.ExpectExec("^DELETE FROM pending_audit_events WHERE id = \\?$").
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	suite.mock.ExpectExec("^UPDATE audit_chain SET last_hash = \\? WHERE id = 1$").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	chained, err := suite.repo.ChainPending(context.Background(), 10)
+
+	require.NoError(err)
+	require.Equal(2, chained)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *AuditRepositoryTestSuite) TestChainPending_Empty_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT last_hash FROM audit_chain WHERE id = 1 FOR UPDATE$").
+		WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow("prev"))
+	suite.mock.ExpectQuery("^SELECT .+ FROM pending_audit_events ORDER BY id LIMIT \\?$").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	suite.mock.ExpectCommit()
+
+	chained, err := suite.repo.ChainPending(context.Background(), 10)
+
+	require.NoError(err)
+	require.Zero(chained)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *AuditRepositoryTestSuite) TestFind_Success() {
+	require := suite.Require()
+	actorID := uint(10)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "actor_id", "action", "target_type", "target_id", "before_state", "after_state", "request_id", "ip", "user_agent", "prev_hash", "hash", "created_at"}).
+		AddRow(5, 10, "gift_card.created", "gift_card", 101, nil, `{"id":101}`, "req", "192.0.2.1", "curl", "prev", "hash", createdAt)
+
+	suite.mock.ExpectQuery("^SELECT .+ FROM audit_events WHERE actor_id = \\? AND action = \\? AND created_at >= \\? AND id < \\? ORDER BY id DESC LIMIT \\?$").
+		WithArgs(actorID, "gift_card.created", from, 9, 20).
+		WillReturnRows(rows)
+
+	events, err := suite.repo.Find(context.Background(), AuditQuery{ActorID: &actorID, Action: domain.AuditGiftCardCreated, From: &from, BeforeID: 9, Limit: 20})
+
+	require.NoError(err)
+	targetID := uint(101)
+	require.Equal([]domain.AuditEvent{{
+		ID:         5,
+		ActorID:    &actorID,
+		Action:     domain.AuditGiftCardCreated,
+		TargetType: domain.AuditTargetGiftCard,
+		TargetID:   &targetID,
+		After:      []byte(`{"id":101}`),
+		RequestID:  "req",
+		IP:         "192.0.2.1",
+		UserAgent:  "curl",
+		PrevHash:   "prev",
+		Hash:       "hash",
+		CreatedAt:  createdAt,
+	}}, events)
+}
+
+func (suite *AuditRepositoryTestSuite) TestFindAfter_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectQuery("^SELECT .+ FROM audit_events WHERE id > \\? ORDER BY id LIMIT \\?$").
+		WithArgs(3, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	events, err := suite.repo.FindAfter(context.Background(), 3, 100)
+
+	require.NoError(err)
+	require.Empty(events)
+}
+
+func (suite *AuditRepositoryTestSuite) TestLastHash_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectQuery("^SELECT last_hash FROM audit_chain WHERE id = 1$").
+		WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow("hash"))
+
+	hash, err := suite.repo.LastHash(context.Background())
+
+	require.NoError(err)
+	require.Equal("hash", hash)
+}
+
+// expectRecordAudit expects an audit event of the action to be chained to an
+// empty log, the snapshots are the expected JSON or nil.
+func expectRecordAudit(mock sqlmock.Sqlmock, action domain.AuditAction, actorID any, targetType string, targetID any, before, after any) {
+	mock.ExpectQuery("^SELECT last_hash FROM audit_chain WHERE id = 1 FOR UPDATE$").
+		WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow(""))
+	mock.ExpectExec("^INSERT INTO audit_events").
+		WithArgs(actorID, string(action), targetType, targetID, before, after, "", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("^UPDATE audit_chain SET last_hash").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestAuditRepository(t *testing.T) {
+	suite.Run(t, new(AuditRepositoryTestSuite))
+}
@@ -0,0 +1,1423 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/migration"
+)
+
+// conformanceRepositories are the repositories of one backend that share its
+// data.
+type conformanceRepositories struct {
+	users         UserRepository
+	giftCards     GiftCardRepository
+	wallets       WalletRepository
+	unitOfWork    UnitOfWork
+	refreshTokens RefreshTokenRepository
+	revokedTokens RevokedTokenRepository
+	audit         AuditRepository
+	loginFailures LoginFailureRepository
+	idempotency   IdempotencyRepository
+	// ledger is nil for the memory store, which keeps no ledger.
+	ledger LedgerRepository
+}
+
+// ConformanceTestSuite checks that every backend behaves the same way through
+// the repository interfaces. newRepositories returns the repositories of the
+// backend on an empty store for every test.
+type ConformanceTestSuite struct {
+	suite.Suite
+	newRepositories func(t *testing.T) conformanceRepositories
+	repos           conformanceRepositories
+}
+
+func (suite *ConformanceTestSuite) SetupTest() {
+	suite.repos = suite.newRepositories(suite.T())
+}
+
+// createUser creates a user with a wallet funded with balance.
+func (suite *ConformanceTestSuite) createUser(email string, balance int64) uint {
+	require := suite.Require()
+
+	u := &domain.User{Email: email, Password: "password"}
+	require.NoError(suite.repos.users.Create(context.Background(), u))
+	if balance > 0 {
+		require.NoError(suite.repos.wallets.Deposit(context.Background(), u.ID, domain.NewMoney(balance, domain.DefaultCurrency), nil))
+	}
+
+	return u.ID
+}
+
+func (suite *ConformanceTestSuite) createGiftCard(gifterID, gifteeID uint, amount int64, expiresAt *time.Time) *domain.GiftCard {
+	require := suite.Require()
+
+	code, err := domain.NewGiftCardCode()
+	require.NoError(err)
+
+	money := domain.NewMoney(amount, domain.DefaultCurrency)
+	giftCard := &domain.GiftCard{Code: code, Amount: money, RemainingAmount: money, GifterID: gifterID, GifteeID: gifteeID, ExpiresAt: expiresAt}
+	require.NoError(suite.repos.giftCards.Create(context.Background(), giftCard))
+	require.NotZero(giftCard.ID)
+
+	return giftCard
+}
+
+func (suite *ConformanceTestSuite) requireWallet(userID uint, balance, held int64) {
+	require := suite.Require()
+
+	wallet, err := suite.repos.wallets.FindByUserID(context.Background(), userID, domain.DefaultCurrency)
+	require.NoError(err)
+	require.NotNil(wallet)
+	require.Equal(balance, wallet.Balance.Amount, "balance")
+	require.Equal(held, wallet.Held.Amount, "held")
+}
+
+func (suite *ConformanceTestSuite) requireNoWallet(userID uint) {
+	require := suite.Require()
+
+	wallet, err := suite.repos.wallets.FindByUserID(context.Background(), userID, domain.DefaultCurrency)
+	require.NoError(err)
+	require.Nil(wallet)
+}
+
+func (suite *ConformanceTestSuite) TestUser_Create_Success() {
+	require := suite.Require()
+	u := &domain.User{Email: "foo@example.com", Password: "securePassword"}
+
+	require.NoError(suite.repos.users.Create(context.Background(), u))
+
+	found, err := suite.repos.users.FindByEmail(context.Background(), u.Email)
+	require.NoError(err)
+	require.NotNil(found)
+	require.Equal(u.ID, found.ID)
+	require.Equal(u.Email, found.Email)
+	require.Equal(u.Password, found.Password)
+}
+
+func (suite *ConformanceTestSuite) TestUser_Create_EmailTaken_Failure() {
+	require := suite.Require()
+	suite.createUser("foo@example.com", 0)
+
+	err := suite.repos.users.Create(context.Background(), &domain.User{Email: "foo@example.com", Password: "otherPassword"})
+
+	require.ErrorIs(err, domain.ErrEmailTaken)
+}
+
+func (suite *ConformanceTestSuite) TestUser_FindByEmail_NotFound() {
+	require := suite.Require()
+
+	found, err := suite.repos.users.FindByEmail(context.Background(), "nobody@example.com")
+
+	require.NoError(err)
+	require.Nil(found)
+}
+
+func (suite *ConformanceTestSuite) TestUser_SetRoles_Success() {
+	require := suite.Require()
+	u := &domain.User{Email: "support@example.com", Password: "password", Roles: []domain.Role{domain.RoleSupport}}
+	require.NoError(suite.repos.users.Create(context.Background(), u))
+
+	found, err := suite.repos.users.FindByID(context.Background(), u.ID)
+	require.NoError(err)
+	require.Equal([]domain.Role{domain.RoleSupport}, found.Roles)
+	require.False(found.CreatedAt.IsZero())
+
+	require.NoError(suite.repos.users.SetRoles(context.Background(), u.ID, []domain.Role{domain.RoleSupport, domain.RoleAdmin}))
+
+	found, err = suite.repos.users.FindByEmail(context.Background(), u.Email)
+	require.NoError(err)
+	require.Equal([]domain.Role{domain.RoleSupport, domain.RoleAdmin}, found.Roles)
+
+	require.NoError(suite.repos.users.SetRoles(context.Background(), u.ID, nil))
+
+	found, err = suite.repos.users.FindByID(context.Background(), u.ID)
+	require.NoError(err)
+	require.Empty(found.Roles)
+}
+
+func (suite *ConformanceTestSuite) TestUser_SetRoles_NotFound_Failure() {
+	require := suite.Require()
+
+	err := suite.repos.users.SetRoles(context.Background(), 404, []domain.Role{domain.RoleAdmin})
+
+	require.ErrorIs(err, domain.ErrUserNotFound)
+
+	found, err := suite.repos.users.FindByID(context.Background(), 404)
+	require.NoError(err)
+	require.Nil(found)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_Create_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	expiresAt := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, &expiresAt)
+
+	found, err := suite.repos.giftCards.FindByID(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.NotNil(found)
+	require.Equal(giftCard.Code, found.Code)
+	require.Equal(domain.GCSPending, found.Status)
+	require.Equal(gifterID, found.GifterID)
+	require.Equal(gifteeID, found.GifteeID)
+	require.Equal(giftCard.Amount, found.Amount)
+	require.Equal(giftCard.Amount, found.RemainingAmount)
+	require.NotNil(found.ExpiresAt)
+	require.True(expiresAt.Equal(*found.ExpiresAt))
+	suite.requireWallet(gifterID, 700, 300)
+
+	history, err := suite.repos.giftCards.FindStatusHistory(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Len(history, 1)
+	require.Nil(history[0].From)
+	require.Equal(domain.GCSPending, history[0].To)
+	require.Equal(&gifterID, history[0].ActorID)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_Create_InsufficientFunds_Failure() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 100)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	money := domain.NewMoney(300, domain.DefaultCurrency)
+
+	err := suite.repos.giftCards.Create(context.Background(), &domain.GiftCard{Code: "ABCD-EFGH-IJKL", Amount: money, RemainingAmount: money, GifterID: gifterID, GifteeID: gifteeID})
+
+	require.ErrorIs(err, domain.ErrInsufficientFunds)
+	suite.requireWallet(gifterID, 100, 0)
+
+	page, err := suite.repos.giftCards.FindSentGiftCardsByUserID(context.Background(), gifterID, GiftCardQuery{PageSize: 10, PageNumber: 1, WithTotal: true})
+	require.NoError(err)
+	require.Empty(page.GiftCards)
+	require.Zero(*page.Total)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_FindByID_NotFound() {
+	require := suite.Require()
+
+	found, err := suite.repos.giftCards.FindByID(context.Background(), 404)
+
+	require.NoError(err)
+	require.Nil(found)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_UpdateStatus_Accepted_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+
+	err := suite.repos.giftCards.UpdateStatus(context.Background(), giftCard.ID, nil, domain.GCSAccepted, &gifteeID)
+
+	require.NoError(err)
+	suite.requireWallet(gifterID, 700, 0)
+	suite.requireNoWallet(gifteeID)
+
+	found, err := suite.repos.giftCards.FindByID(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Equal(domain.GCSAccepted, found.Status)
+
+	history, err := suite.repos.giftCards.FindStatusHistory(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Len(history, 2)
+	require.Equal(domain.GCSPending, *history[1].From)
+	require.Equal(domain.GCSAccepted, history[1].To)
+	require.Equal(&gifteeID, history[1].ActorID)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_UpdateStatus_Rejected_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+
+	err := suite.repos.giftCards.UpdateStatus(context.Background(), giftCard.ID, nil, domain.GCSRejected, &gifteeID)
+
+	require.NoError(err)
+	suite.requireWallet(gifterID, 1000, 0)
+
+	wallet, err := suite.repos.wallets.FindByUserID(context.Background(), gifteeID, domain.DefaultCurrency)
+	require.NoError(err)
+	require.Nil(wallet)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_UpdateStatus_InvalidTransition_Failure() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), giftCard.ID, nil, domain.GCSRejected, &gifteeID))
+
+	err := suite.repos.giftCards.UpdateStatus(context.Background(), giftCard.ID, nil, domain.GCSAccepted, &gifteeID)
+
+	var transitionErr *domain.InvalidTransitionError
+	require.True(errors.As(err, &transitionErr))
+	require.Equal(domain.GCSRejected, transitionErr.From)
+	suite.requireWallet(gifterID, 1000, 0)
+
+	history, err := suite.repos.giftCards.FindStatusHistory(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Len(history, 2)
+}
+
+// TestGiftCard_ForceUpdateStatus_Success expires a card before its expiry
+// date and voids another one, both release the held amount.
+func (suite *ConformanceTestSuite) TestGiftCard_ForceUpdateStatus_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	adminID := suite.createUser("admin@example.com", 0)
+	expiresAt := time.Now().Add(time.Hour)
+	toExpire := suite.createGiftCard(gifterID, gifteeID, 300, &expiresAt)
+	toVoid := suite.createGiftCard(gifterID, gifteeID, 200, nil)
+
+	err := suite.repos.giftCards.UpdateStatus(context.Background(), toExpire.ID, nil, domain.GCSExpired, nil)
+	require.ErrorIs(err, domain.ErrGiftCardNotOverdue)
+
+	require.NoError(suite.repos.giftCards.ForceUpdateStatus(context.Background(), toExpire.ID, nil, domain.GCSExpired, &adminID))
+	require.NoError(suite.repos.giftCards.ForceUpdateStatus(context.Background(), toVoid.ID, &toVoid.Version, domain.GCSVoided, &adminID))
+
+	suite.requireWallet(gifterID, 1000, 0)
+	voided, err := suite.repos.giftCards.FindByID(context.Background(), toVoid.ID)
+	require.NoError(err)
+	require.Equal(domain.GCSVoided, voided.Status)
+
+	history, err := suite.repos.giftCards.FindStatusHistory(context.Background(), toExpire.ID)
+	require.NoError(err)
+	require.Len(history, 2)
+	require.Equal(domain.GCSExpired, history[1].To)
+	require.Equal(&adminID, history[1].ActorID)
+
+	var transitionErr *domain.InvalidTransitionError
+	err = suite.repos.giftCards.ForceUpdateStatus(context.Background(), toVoid.ID, nil, domain.GCSExpired, &adminID)
+	require.ErrorAs(err, &transitionErr)
+}
+
+// TestGiftCard_ForceUpdateStatus_VoidAccepted_Success checks that voiding an
+// accepted gift card refunds what was not redeemed to the gifter and leaves
+// the redemptions alone.
+func (suite *ConformanceTestSuite) TestGiftCard_ForceUpdateStatus_VoidAccepted_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	adminID := suite.createUser("admin@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), giftCard.ID, nil, domain.GCSAccepted, &gifteeID))
+	_, err := suite.repos.giftCards.Redeem(context.Background(), giftCard.Code, gifteeID, domain.NewMoney(120, domain.DefaultCurrency))
+	require.NoError(err)
+
+	require.NoError(suite.repos.giftCards.ForceUpdateStatus(context.Background(), giftCard.ID, nil, domain.GCSVoided, &adminID))
+
+	suite.requireWallet(gifterID, 880, 0)
+	suite.requireWallet(gifteeID, 120, 0)
+	voided, err := suite.repos.giftCards.FindByID(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Equal(domain.GCSVoided, voided.Status)
+	require.Equal(domain.NewMoney(0, domain.DefaultCurrency), voided.RemainingAmount)
+
+	_, err = suite.repos.giftCards.Redeem(context.Background(), giftCard.Code, gifteeID, domain.NewMoney(1, domain.DefaultCurrency))
+	require.ErrorIs(err, domain.ErrGiftCardNotRedeemable)
+
+	if suite.repos.ledger != nil {
+		snapshot, err := suite.repos.ledger.Snapshot(context.Background())
+		require.NoError(err)
+		require.Equal(domain.NewMoney(0, domain.DefaultCurrency), snapshot.AccountBalances[domain.GiftCardAccount(giftCard.ID, domain.DefaultCurrency)])
+		require.Equal(domain.NewMoney(880, domain.DefaultCurrency), snapshot.AccountBalances[domain.WalletAvailableAccount(gifterID, domain.DefaultCurrency)])
+		require.Empty(snapshot.GiftCards)
+		require.Empty(snapshot.UnbalancedTransactionIDs)
+	}
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_Version_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+	require.Equal(uint(1), giftCard.Version)
+
+	stale := uint(2)
+	err := suite.repos.giftCards.UpdateStatus(context.Background(), giftCard.ID, &stale, domain.GCSAccepted, &gifteeID)
+	require.ErrorIs(err, domain.ErrGiftCardVersionMismatch)
+
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), giftCard.ID, &giftCard.Version, domain.GCSAccepted, &gifteeID))
+	found, err := suite.repos.giftCards.FindByID(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Equal(domain.GCSAccepted, found.Status)
+	require.Equal(uint(2), found.Version)
+
+	_, err = suite.repos.giftCards.Redeem(context.Background(), giftCard.Code, gifterID, domain.NewMoney(100, domain.DefaultCurrency))
+	require.NoError(err)
+	found, err = suite.repos.giftCards.FindByID(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Equal(uint(3), found.Version)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_Dates_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+
+	created, err := suite.repos.giftCards.FindByID(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.False(created.CreationDate.IsZero())
+	require.False(created.UpdatedAt.IsZero())
+
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), giftCard.ID, nil, domain.GCSRejected, &gifteeID))
+	found, err := suite.repos.giftCards.FindByID(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.True(found.CreationDate.Equal(created.CreationDate))
+	require.False(found.UpdatedAt.Before(created.UpdatedAt))
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_UpdateStatus_NotFound_Failure() {
+	require := suite.Require()
+
+	err := suite.repos.giftCards.UpdateStatus(context.Background(), 404, nil, domain.GCSAccepted, nil)
+
+	require.ErrorIs(err, domain.ErrGiftCardNotFound)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_FindOverdueGiftCardIDs_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	now := time.Now().UTC().Truncate(time.Second)
+	later := now.Add(-time.Minute)
+	earlier := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	first := suite.createGiftCard(gifterID, gifteeID, 100, &later)
+	second := suite.createGiftCard(gifterID, gifteeID, 100, &earlier)
+	third := suite.createGiftCard(gifterID, gifteeID, 100, &later)
+	suite.createGiftCard(gifterID, gifteeID, 100, &future)
+	suite.createGiftCard(gifterID, gifteeID, 100, nil)
+
+	ids, err := suite.repos.giftCards.FindOverdueGiftCardIDs(context.Background(), now, 10)
+	require.NoError(err)
+	require.Equal([]uint{second.ID, first.ID, third.ID}, ids)
+
+	ids, err = suite.repos.giftCards.FindOverdueGiftCardIDs(context.Background(), now, 2)
+	require.NoError(err)
+	require.Equal([]uint{second.ID, first.ID}, ids)
+
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), second.ID, nil, domain.GCSExpired, nil))
+	ids, err = suite.repos.giftCards.FindOverdueGiftCardIDs(context.Background(), now, 10)
+	require.NoError(err)
+	require.Equal([]uint{first.ID, third.ID}, ids)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_Redeem_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 500)
+	redeemerID := suite.createUser("redeemer@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), giftCard.ID, nil, domain.GCSAccepted, &gifteeID))
+	suite.requireWallet(gifteeID, 500, 0)
+
+	redemption, err := suite.repos.giftCards.Redeem(context.Background(), giftCard.Code, redeemerID, domain.NewMoney(120, domain.DefaultCurrency))
+
+	require.NoError(err)
+	require.NotZero(redemption.ID)
+	require.Equal(giftCard.ID, redemption.GiftCardID)
+	require.Equal(redeemerID, redemption.RedeemerID)
+	require.Equal(domain.NewMoney(180, domain.DefaultCurrency), redemption.RemainingAmount)
+	suite.requireWallet(gifteeID, 500, 0)
+	suite.requireWallet(redeemerID, 120, 0)
+
+	found, err := suite.repos.giftCards.FindByID(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Equal(domain.NewMoney(180, domain.DefaultCurrency), found.RemainingAmount)
+
+	_, err = suite.repos.giftCards.Redeem(context.Background(), giftCard.Code, redeemerID, domain.NewMoney(181, domain.DefaultCurrency))
+	require.ErrorIs(err, domain.ErrRedemptionExceedsBalance)
+
+	_, err = suite.repos.giftCards.Redeem(context.Background(), giftCard.Code, gifteeID, domain.NewMoney(180, domain.DefaultCurrency))
+	require.NoError(err)
+	suite.requireWallet(gifteeID, 680, 0)
+	suite.requireWallet(gifterID, 700, 0)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_Redeem_Ledger_Success() {
+	require := suite.Require()
+	if suite.repos.ledger == nil {
+		suite.T().Skip("the backend keeps no ledger")
+	}
+
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), giftCard.ID, nil, domain.GCSAccepted, &gifteeID))
+	_, err := suite.repos.giftCards.Redeem(context.Background(), giftCard.Code, gifteeID, domain.NewMoney(120, domain.DefaultCurrency))
+	require.NoError(err)
+
+	snapshot, err := suite.repos.ledger.Snapshot(context.Background())
+
+	require.NoError(err)
+	require.Equal(domain.NewMoney(180, domain.DefaultCurrency), snapshot.AccountBalances[domain.GiftCardAccount(giftCard.ID, domain.DefaultCurrency)])
+	require.Equal(domain.NewMoney(120, domain.DefaultCurrency), snapshot.AccountBalances[domain.WalletAvailableAccount(gifteeID, domain.DefaultCurrency)])
+	require.Equal(domain.NewMoney(0, domain.DefaultCurrency), snapshot.AccountBalances[domain.WalletHeldAccount(gifterID, domain.DefaultCurrency)])
+	require.Equal([]domain.GiftCard{{ID: giftCard.ID, RemainingAmount: domain.NewMoney(180, domain.DefaultCurrency)}}, snapshot.GiftCards)
+	require.Empty(snapshot.UnbalancedTransactionIDs)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_Redeem_Failure() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+
+	_, err := suite.repos.giftCards.Redeem(context.Background(), "UNKNOWN-CODE", gifteeID, domain.NewMoney(100, domain.DefaultCurrency))
+	require.ErrorIs(err, domain.ErrGiftCardNotFound)
+
+	_, err = suite.repos.giftCards.Redeem(context.Background(), giftCard.Code, gifteeID, domain.NewMoney(100, domain.DefaultCurrency))
+	require.ErrorIs(err, domain.ErrGiftCardNotRedeemable)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_FindByUserID_Paging_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	var ids []uint
+	for i := 0; i < 5; i++ {
+		ids = append(ids, suite.createGiftCard(gifterID, gifteeID, 10, nil).ID)
+	}
+
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), ids[1], nil, domain.GCSAccepted, &gifteeID))
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), ids[3], nil, domain.GCSAccepted, &gifteeID))
+
+	received, err := suite.repos.giftCards.FindReceivedGiftCardsByUserID(context.Background(), gifteeID, GiftCardQuery{PageSize: 2, PageNumber: 2, WithTotal: true})
+	require.NoError(err)
+	require.Equal(5, *received.Total)
+	require.Equal([]uint{ids[2], ids[3]}, giftCardIDs(received.GiftCards))
+	require.NotEmpty(received.NextCursor)
+	require.NotEmpty(received.PrevCursor)
+
+	received, err = suite.repos.giftCards.FindReceivedGiftCardsByUserID(context.Background(), gifteeID, GiftCardQuery{PageSize: 2, PageNumber: 3})
+	require.NoError(err)
+	require.Nil(received.Total)
+	require.Equal([]uint{ids[4]}, giftCardIDs(received.GiftCards))
+	require.Empty(received.NextCursor)
+
+	received, err = suite.repos.giftCards.FindReceivedGiftCardsByUserID(context.Background(), gifteeID, GiftCardQuery{PageSize: 2, PageNumber: 4, WithTotal: true})
+	require.NoError(err)
+	require.Equal(5, *received.Total)
+	require.Empty(received.GiftCards)
+
+	sent, err := suite.repos.giftCards.FindSentGiftCardsByUserID(context.Background(), gifterID, GiftCardQuery{Statuses: []domain.GiftCardStatus{domain.GCSAccepted}, PageSize: 10, PageNumber: 1, WithTotal: true})
+	require.NoError(err)
+	require.Equal(2, *sent.Total)
+	require.Equal([]uint{ids[1], ids[3]}, giftCardIDs(sent.GiftCards))
+	require.Empty(sent.NextCursor)
+	require.Empty(sent.PrevCursor)
+
+	sent, err = suite.repos.giftCards.FindSentGiftCardsByUserID(context.Background(), gifteeID, GiftCardQuery{PageSize: 10, PageNumber: 1, WithTotal: true})
+	require.NoError(err)
+	require.Zero(*sent.Total)
+	require.Empty(sent.GiftCards)
+}
+
+// TestGiftCard_FindByUserID_Cursor_Success pages through the cards with the
+// cursors both ways, while a card is created in between.
+func (suite *ConformanceTestSuite) TestGiftCard_FindByUserID_Cursor_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	var ids []uint
+	for _, amount := range []int64{30, 10, 50, 10, 20} {
+		ids = append(ids, suite.createGiftCard(gifterID, gifteeID, amount, nil).ID)
+	}
+
+	for _, query := range []struct {
+		query    GiftCardQuery
+		expected []uint
+	}{
+		{query: GiftCardQuery{}, expected: ids},
+		{query: GiftCardQuery{Descending: true}, expected: []uint{ids[4], ids[3], ids[2], ids[1], ids[0]}},
+		{query: GiftCardQuery{SortBy: GiftCardSortAmount}, expected: []uint{ids[1], ids[3], ids[4], ids[0], ids[2]}},
+		{query: GiftCardQuery{SortBy: GiftCardSortAmount, Descending: true}, expected: []uint{ids[2], ids[0], ids[4], ids[3], ids[1]}},
+	} {
+		find := func(cursor string) GiftCardPage {
+			q := query.query
+			q.PageSize, q.PageNumber, q.Cursor = 2, 1, cursor
+			page, err := suite.repos.giftCards.FindReceivedGiftCardsByUserID(context.Background(), gifteeID, q)
+			require.NoError(err)
+
+			return page
+		}
+
+		first := find("")
+		require.Equal(query.expected[:2], giftCardIDs(first.GiftCards))
+		require.Empty(first.PrevCursor)
+
+		second := find(first.NextCursor)
+		require.Equal(query.expected[2:4], giftCardIDs(second.GiftCards))
+
+		last := find(second.NextCursor)
+		require.Equal(query.expected[4:], giftCardIDs(last.GiftCards))
+		require.Empty(last.NextCursor)
+
+		back := find(last.PrevCursor)
+		require.Equal(query.expected[2:4], giftCardIDs(back.GiftCards))
+		require.Equal(second.NextCursor, back.NextCursor)
+
+		back = find(back.PrevCursor)
+		require.Equal(query.expected[:2], giftCardIDs(back.GiftCards))
+		require.Empty(back.PrevCursor)
+		require.Equal(first.NextCursor, back.NextCursor)
+	}
+
+	page, err := suite.repos.giftCards.FindReceivedGiftCardsByUserID(context.Background(), gifteeID, GiftCardQuery{PageSize: 2, PageNumber: 1})
+	require.NoError(err)
+	created := suite.createGiftCard(gifterID, gifteeID, 10, nil)
+
+	page, err = suite.repos.giftCards.FindReceivedGiftCardsByUserID(context.Background(), gifteeID, GiftCardQuery{PageSize: 2, PageNumber: 1, Cursor: page.NextCursor})
+	require.NoError(err)
+	require.Equal(ids[2:4], giftCardIDs(page.GiftCards))
+
+	page, err = suite.repos.giftCards.FindReceivedGiftCardsByUserID(context.Background(), gifteeID, GiftCardQuery{PageSize: 2, PageNumber: 1, Cursor: page.NextCursor})
+	require.NoError(err)
+	require.Equal([]uint{ids[4], created.ID}, giftCardIDs(page.GiftCards))
+
+	_, err = suite.repos.giftCards.FindReceivedGiftCardsByUserID(context.Background(), gifteeID, GiftCardQuery{PageSize: 2, PageNumber: 1, Cursor: page.PrevCursor, Descending: true})
+	require.Error(err)
+
+	_, err = suite.repos.giftCards.FindReceivedGiftCardsByUserID(context.Background(), gifteeID, GiftCardQuery{PageSize: 2, PageNumber: 1, Cursor: "not-a-cursor"})
+	require.Error(err)
+}
+
+func (suite *ConformanceTestSuite) TestGiftCard_FindByUserID_Query_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	otherID := suite.createUser("other@example.com", 0)
+	small := suite.createGiftCard(gifterID, gifteeID, 10, nil)
+	large := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+	medium := suite.createGiftCard(gifterID, otherID, 100, nil)
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), large.ID, nil, domain.GCSAccepted, &gifteeID))
+	require.NoError(suite.repos.giftCards.UpdateStatus(context.Background(), medium.ID, nil, domain.GCSRejected, &otherID))
+
+	find := func(query GiftCardQuery) ([]uint, int) {
+		query.PageSize, query.PageNumber, query.WithTotal = 10, 1, true
+		sent, err := suite.repos.giftCards.FindSentGiftCardsByUserID(context.Background(), gifterID, query)
+		require.NoError(err)
+
+		return giftCardIDs(sent.GiftCards), *sent.Total
+	}
+
+	ids, total := find(GiftCardQuery{Statuses: []domain.GiftCardStatus{domain.GCSAccepted, domain.GCSRejected}})
+	require.Equal([]uint{large.ID, medium.ID}, ids)
+	require.Equal(2, total)
+
+	ids, _ = find(GiftCardQuery{CounterpartyID: &gifteeID})
+	require.Equal([]uint{small.ID, large.ID}, ids)
+
+	minAmount, maxAmount := domain.NewMoney(50, domain.DefaultCurrency), domain.NewMoney(300, domain.DefaultCurrency)
+	ids, _ = find(GiftCardQuery{MinAmount: &minAmount, MaxAmount: &maxAmount, SortBy: GiftCardSortAmount})
+	require.Equal([]uint{medium.ID, large.ID}, ids)
+
+	otherCurrency := domain.NewMoney(0, "EUR")
+	ids, total = find(GiftCardQuery{MinAmount: &otherCurrency})
+	require.Empty(ids)
+	require.Zero(total)
+
+	ids, _ = find(GiftCardQuery{SortBy: GiftCardSortAmount, Descending: true})
+	require.Equal([]uint{large.ID, medium.ID, small.ID}, ids)
+
+	ids, _ = find(GiftCardQuery{Descending: true})
+	require.Equal([]uint{medium.ID, large.ID, small.ID}, ids)
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	ids, _ = find(GiftCardQuery{CreatedFrom: &from, CreatedTo: &to})
+	require.Equal([]uint{small.ID, large.ID, medium.ID}, ids)
+
+	ids, total = find(GiftCardQuery{CreatedFrom: &to})
+	require.Empty(ids)
+	require.Zero(total)
+
+	ids, total = find(GiftCardQuery{CreatedTo: &from})
+	require.Empty(ids)
+	require.Zero(total)
+}
+
+// TestGiftCard_Create_Concurrent_Success creates gift cards from one wallet
+// at the same time, only as many as it can fund may succeed.
+func (suite *ConformanceTestSuite) TestGiftCard_Create_Concurrent_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 500)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			code, err := domain.NewGiftCardCode()
+			if err != nil {
+				errs <- err
+
+				return
+			}
+
+			money := domain.NewMoney(100, domain.DefaultCurrency)
+			errs <- suite.repos.giftCards.Create(context.Background(), &domain.GiftCard{Code: code, Amount: money, RemainingAmount: money, GifterID: gifterID, GifteeID: gifteeID})
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+
+			continue
+		}
+
+		require.ErrorIs(err, domain.ErrInsufficientFunds)
+	}
+
+	require.Equal(5, created)
+	suite.requireWallet(gifterID, 0, 500)
+}
+
+// TestGiftCard_CreateAndAccept_Concurrent_Success creates gift cards while
+// others of the same gifter are accepted. Both lock the wallet of the gifter
+// and the audit chain, they must do so in the same order not to deadlock.
+func (suite *ConformanceTestSuite) TestGiftCard_CreateAndAccept_Concurrent_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	var pending []*domain.GiftCard
+	for i := 0; i < 5; i++ {
+		pending = append(pending, suite.createGiftCard(gifterID, gifteeID, 100, nil))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*len(pending))
+	for _, giftCard := range pending {
+		wg.Add(2)
+		go func(id uint) {
+			defer wg.Done()
+			errs <- suite.repos.giftCards.UpdateStatus(context.Background(), id, nil, domain.GCSAccepted, &gifteeID)
+		}(giftCard.ID)
+		go func() {
+			defer wg.Done()
+			code, err := domain.NewGiftCardCode()
+			if err != nil {
+				errs <- err
+
+				return
+			}
+
+			money := domain.NewMoney(100, domain.DefaultCurrency)
+			errs <- suite.repos.giftCards.Create(context.Background(), &domain.GiftCard{Code: code, Amount: money, RemainingAmount: money, GifterID: gifterID, GifteeID: gifteeID})
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(err)
+	}
+
+	suite.requireWallet(gifterID, 0, 500)
+	events, err := suite.repos.audit.FindAfter(context.Background(), 0, 100)
+	require.NoError(err)
+	require.Len(events, 3+2*len(pending)+len(pending))
+	_, chainBreak := domain.VerifyAuditChain("", events)
+	require.Nil(chainBreak)
+}
+
+// acceptIfPending is the check-and-set the gift card service runs in a unit
+// of work: it locks the card and accepts it only if it is still pending.
+func acceptIfPending(ctx context.Context, repos Repositories, id, gifteeID uint) error {
+	giftCard, err := repos.GiftCards.FindByIDForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if giftCard.Status != domain.GCSPending {
+		return &domain.InvalidTransitionError{From: giftCard.Status, To: domain.GCSAccepted}
+	}
+
+	return repos.GiftCards.UpdateStatus(ctx, id, nil, domain.GCSAccepted, &gifteeID)
+}
+
+func (suite *ConformanceTestSuite) TestUnitOfWork_Commit_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+
+	err := suite.repos.unitOfWork.Do(context.Background(), func(ctx context.Context, repos Repositories) error {
+		return acceptIfPending(ctx, repos, giftCard.ID, gifteeID)
+	})
+
+	require.NoError(err)
+	found, err := suite.repos.giftCards.FindByID(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Equal(domain.GCSAccepted, found.Status)
+	suite.requireWallet(gifterID, 700, 0)
+	suite.requireNoWallet(gifteeID)
+}
+
+func (suite *ConformanceTestSuite) TestUnitOfWork_Rollback_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+	expectedError := errors.New("abort")
+
+	var created domain.GiftCard
+	err := suite.repos.unitOfWork.Do(context.Background(), func(ctx context.Context, repos Repositories) error {
+		err := acceptIfPending(ctx, repos, giftCard.ID, gifteeID)
+		if err != nil {
+			return err
+		}
+
+		code, err := domain.NewGiftCardCode()
+		if err != nil {
+			return err
+		}
+
+		money := domain.NewMoney(200, domain.DefaultCurrency)
+		created = domain.GiftCard{Code: code, Amount: money, RemainingAmount: money, GifterID: gifterID, GifteeID: gifteeID}
+		err = repos.GiftCards.Create(ctx, &created)
+		if err != nil {
+			return err
+		}
+
+		return expectedError
+	})
+
+	require.ErrorIs(err, expectedError)
+	require.NotZero(created.ID)
+	found, err := suite.repos.giftCards.FindByID(context.Background(), created.ID)
+	require.NoError(err)
+	require.Nil(found)
+
+	found, err = suite.repos.giftCards.FindByID(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Equal(domain.GCSPending, found.Status)
+	suite.requireWallet(gifterID, 700, 300)
+
+	history, err := suite.repos.giftCards.FindStatusHistory(context.Background(), giftCard.ID)
+	require.NoError(err)
+	require.Len(history, 1)
+}
+
+func (suite *ConformanceTestSuite) TestUnitOfWork_Concurrent_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("gifter@example.com", 1000)
+	gifteeID := suite.createUser("giftee@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 300, nil)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- suite.repos.unitOfWork.Do(context.Background(), func(ctx context.Context, repos Repositories) error {
+				return acceptIfPending(ctx, repos, giftCard.ID, gifteeID)
+			})
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	accepted := 0
+	for err := range errs {
+		if err == nil {
+			accepted++
+
+			continue
+		}
+
+		var transitionErr *domain.InvalidTransitionError
+		require.ErrorAs(err, &transitionErr)
+	}
+
+	require.Equal(1, accepted)
+	suite.requireWallet(gifterID, 700, 0)
+	suite.requireNoWallet(gifteeID)
+}
+
+func (suite *ConformanceTestSuite) TestRefreshToken_Use_Success() {
+	require := suite.Require()
+	userID := suite.createUser("foo@example.com", 0)
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	token := &domain.RefreshToken{UserID: userID, FamilyID: "family", TokenHash: "hash", ExpiresAt: expiresAt}
+
+	require.NoError(suite.repos.refreshTokens.Create(context.Background(), token))
+	require.NotZero(token.ID)
+
+	found, err := suite.repos.refreshTokens.FindByHash(context.Background(), "hash")
+	require.NoError(err)
+	require.NotNil(found)
+	require.Equal(token.ID, found.ID)
+	require.Equal("family", found.FamilyID)
+	require.True(expiresAt.Equal(found.ExpiresAt))
+	require.True(found.IsActive(time.Now()))
+
+	require.NoError(suite.repos.refreshTokens.Use(context.Background(), token.ID))
+	require.ErrorIs(suite.repos.refreshTokens.Use(context.Background(), token.ID), domain.ErrRefreshTokenReused)
+
+	found, err = suite.repos.refreshTokens.FindByHash(context.Background(), "hash")
+	require.NoError(err)
+	require.True(found.IsUsed())
+}
+
+func (suite *ConformanceTestSuite) TestRefreshToken_RevokeFamily_Success() {
+	require := suite.Require()
+	userID := suite.createUser("foo@example.com", 0)
+	expiresAt := time.Now().Add(time.Hour)
+	for _, token := range []*domain.RefreshToken{
+		{UserID: userID, FamilyID: "family", TokenHash: "first", ExpiresAt: expiresAt},
+		{UserID: userID, FamilyID: "family", TokenHash: "second", ExpiresAt: expiresAt},
+		{UserID: userID, FamilyID: "other", TokenHash: "third", ExpiresAt: expiresAt},
+	} {
+		require.NoError(suite.repos.refreshTokens.Create(context.Background(), token))
+	}
+
+	require.NoError(suite.repos.refreshTokens.RevokeFamily(context.Background(), "family"))
+
+	for hash, active := range map[string]bool{"first": false, "second": false, "third": true} {
+		found, err := suite.repos.refreshTokens.FindByHash(context.Background(), hash)
+		require.NoError(err)
+		require.Equal(active, found.IsActive(time.Now()), hash)
+	}
+
+	second, err := suite.repos.refreshTokens.FindByHash(context.Background(), "second")
+	require.NoError(err)
+	require.ErrorIs(suite.repos.refreshTokens.Use(context.Background(), second.ID), domain.ErrRefreshTokenReused)
+}
+
+func (suite *ConformanceTestSuite) TestRefreshToken_FindByHash_NotFound() {
+	require := suite.Require()
+
+	found, err := suite.repos.refreshTokens.FindByHash(context.Background(), "hash")
+
+	require.NoError(err)
+	require.Nil(found)
+}
+
+func (suite *ConformanceTestSuite) TestRevokedToken_Revoke_Success() {
+	require := suite.Require()
+	expiresAt := time.Now().Add(time.Hour)
+
+	revoked, err := suite.repos.revokedTokens.IsRevoked(context.Background(), "jti")
+	require.NoError(err)
+	require.False(revoked)
+
+	require.NoError(suite.repos.revokedTokens.Revoke(context.Background(), "jti", expiresAt))
+	require.NoError(suite.repos.revokedTokens.Revoke(context.Background(), "jti", expiresAt))
+
+	revoked, err = suite.repos.revokedTokens.IsRevoked(context.Background(), "jti")
+	require.NoError(err)
+	require.True(revoked)
+}
+
+// TestRevokedToken_Revoke_DropsExpired_Success checks that revoking a token
+// drops the ones that expired, not the ones that are still valid.
+func (suite *ConformanceTestSuite) TestRevokedToken_Revoke_DropsExpired_Success() {
+	require := suite.Require()
+
+	require.NoError(suite.repos.revokedTokens.Revoke(context.Background(), "expired", time.Now().Add(-time.Minute)))
+	require.NoError(suite.repos.revokedTokens.Revoke(context.Background(), "valid", time.Now().Add(time.Minute)))
+	require.NoError(suite.repos.revokedTokens.Revoke(context.Background(), "jti", time.Now().Add(time.Hour)))
+
+	for jti, expected := range map[string]bool{"expired": false, "valid": true, "jti": true} {
+		revoked, err := suite.repos.revokedTokens.IsRevoked(context.Background(), jti)
+		require.NoError(err)
+		require.Equal(expected, revoked, jti)
+	}
+}
+
+// TestAudit_Chain_Success checks that the changes record their events in a
+// chain that adds up, and that an event of a change that is rolled back is
+// not recorded.
+func (suite *ConformanceTestSuite) TestAudit_Chain_Success() {
+	require := suite.Require()
+	gifterID := suite.createUser("foo@example.com", 10000)
+	gifteeID := suite.createUser("bar@example.com", 0)
+	giftCard := suite.createGiftCard(gifterID, gifteeID, 10000, nil)
+	ctx := domain.WithRequestInfo(context.Background(), domain.RequestInfo{ID: "req", IP: "192.0.2.1", UserAgent: "curl"})
+	require.NoError(suite.repos.giftCards.UpdateStatus(ctx, giftCard.ID, nil, domain.GCSAccepted, &gifteeID))
+	err := suite.repos.unitOfWork.Do(context.Background(), func(ctx context.Context, repos Repositories) error {
+		if err := repos.Users.Create(ctx, &domain.User{Email: "baz@example.com", Password: "password"}); err != nil {
+			return err
+		}
+
+		return errors.New("rolled back")
+	})
+	require.Error(err)
+
+	events, err := suite.repos.audit.FindAfter(context.Background(), 0, 10)
+	require.NoError(err)
+	require.Len(events, 5)
+	var actions []domain.AuditAction
+	for _, event := range events {
+		actions = append(actions, event.Action)
+	}
+
+	require.Equal([]domain.AuditAction{domain.AuditUserRegistered, domain.AuditWalletDeposited, domain.AuditUserRegistered, domain.AuditGiftCardCreated, domain.AuditGiftCardStatusChanged}, actions)
+	lastHash, chainBreak := domain.VerifyAuditChain("", events)
+	require.Nil(chainBreak)
+	hash, err := suite.repos.audit.LastHash(context.Background())
+	require.NoError(err)
+	require.Equal(lastHash, hash)
+
+	deposited := events[1]
+	require.Nil(deposited.ActorID)
+	require.Equal(&gifterID, deposited.TargetID)
+	require.JSONEq(`{"user_id":`+uintString(gifterID)+`,"amount":"100.00","currency":"USD"}`, string(deposited.After))
+
+	statusChanged := events[4]
+	require.Equal(&gifteeID, statusChanged.ActorID)
+	require.Equal(domain.AuditTargetGiftCard, statusChanged.TargetType)
+	require.Equal(&giftCard.ID, statusChanged.TargetID)
+	require.JSONEq(`{"id":`+uintString(giftCard.ID)+`,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":"pending","gifter_id":`+uintString(gifterID)+`,"giftee_id":`+uintString(gifteeID)+`,"version":1}`, string(statusChanged.Before))
+	require.JSONEq(`{"id":`+uintString(giftCard.ID)+`,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":"accepted","gifter_id":`+uintString(gifterID)+`,"giftee_id":`+uintString(gifteeID)+`,"version":2}`, string(statusChanged.After))
+	require.Equal("req", statusChanged.RequestID)
+	require.Equal("192.0.2.1", statusChanged.IP)
+	require.Equal("curl", statusChanged.UserAgent)
+}
+
+func (suite *ConformanceTestSuite) TestAudit_Find_Success() {
+	require := suite.Require()
+	fooID := suite.createUser("foo@example.com", 0)
+	barID := suite.createUser("bar@example.com", 0)
+	event, err := domain.NewAuditEvent(context.Background(), domain.AuditUserLoggedIn, &fooID, domain.AuditTargetUser, &fooID, nil, nil)
+	require.NoError(err)
+	require.NoError(suite.repos.audit.Record(context.Background(), &event))
+	require.NotZero(event.ID)
+
+	events, err := suite.repos.audit.Find(context.Background(), AuditQuery{ActorID: &fooID, Limit: 10})
+	require.NoError(err)
+	require.Len(events, 2)
+	require.Equal(event.ID, events[0].ID)
+	require.Equal(domain.AuditUserLoggedIn, events[0].Action)
+	require.Equal(event.Hash, events[0].Hash)
+	require.True(event.CreatedAt.Equal(events[0].CreatedAt))
+	require.Equal(domain.AuditUserRegistered, events[1].Action)
+
+	events, err = suite.repos.audit.Find(context.Background(), AuditQuery{Action: domain.AuditUserRegistered, TargetID: &barID, Limit: 10})
+	require.NoError(err)
+	require.Len(events, 1)
+	require.Equal(&barID, events[0].TargetID)
+
+	events, err = suite.repos.audit.Find(context.Background(), AuditQuery{BeforeID: event.ID, Limit: 1})
+	require.NoError(err)
+	require.Len(events, 1)
+	require.Equal(&barID, events[0].TargetID)
+
+	from := event.CreatedAt.Add(time.Hour)
+	events, err = suite.repos.audit.Find(context.Background(), AuditQuery{From: &from, Limit: 10})
+	require.NoError(err)
+	require.Empty(events)
+}
+
+// TestAudit_ChainPending_Success checks that enqueued events are only listed
+// once they are chained, in the order they were enqueued, and that they
+// continue the chain of the recorded events.
+func (suite *ConformanceTestSuite) TestAudit_ChainPending_Success() {
+	require := suite.Require()
+	suite.createUser("foo@example.com", 0)
+	var enqueued []domain.AuditEvent
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		event, err := domain.NewAuditEvent(context.Background(), domain.AuditUserLoginFailed, nil, domain.AuditTargetUser, nil, nil, map[string]string{"email": email})
+		require.NoError(err)
+		require.NoError(suite.repos.audit.Enqueue(context.Background(), &event))
+		enqueued = append(enqueued, event)
+	}
+
+	events, err := suite.repos.audit.Find(context.Background(), AuditQuery{Action: domain.AuditUserLoginFailed, Limit: 10})
+	require.NoError(err)
+	require.Empty(events)
+
+	chained, err := suite.repos.audit.ChainPending(context.Background(), 2)
+	require.NoError(err)
+	require.Equal(2, chained)
+	chained, err = suite.repos.audit.ChainPending(context.Background(), 2)
+	require.NoError(err)
+	require.Equal(1, chained)
+	chained, err = suite.repos.audit.ChainPending(context.Background(), 2)
+	require.NoError(err)
+	require.Zero(chained)
+
+	events, err = suite.repos.audit.FindAfter(context.Background(), 0, 10)
+	require.NoError(err)
+	require.Len(events, 4)
+	require.Equal(domain.AuditUserRegistered, events[0].Action)
+	for i, event := range events[1:] {
+		require.Equal(domain.AuditUserLoginFailed, event.Action)
+		require.Equal(string(enqueued[i].After), string(event.After))
+		require.True(enqueued[i].CreatedAt.Equal(event.CreatedAt))
+	}
+
+	lastHash, chainBreak := domain.VerifyAuditChain("", events)
+	require.Nil(chainBreak)
+	hash, err := suite.repos.audit.LastHash(context.Background())
+	require.NoError(err)
+	require.Equal(lastHash, hash)
+}
+
+func (suite *ConformanceTestSuite) TestLoginFailure_Attempt_Success() {
+	require := suite.Require()
+	policy := domain.LoginPolicy{Window: time.Hour, LockoutAfter: 3, LockoutDuration: 2 * time.Hour}
+	now := time.Now()
+	key := domain.AccountLoginKey("foo@example.com")
+
+	found, err := suite.repos.loginFailures.Find(context.Background(), key)
+	require.NoError(err)
+	require.Nil(found)
+
+	for i := 1; i <= 3; i++ {
+		failures, err := suite.repos.loginFailures.Attempt(context.Background(), key, now, policy)
+		require.NoError(err)
+		require.Equal(i, failures.Count)
+	}
+
+	found, err = suite.repos.loginFailures.Find(context.Background(), key)
+	require.NoError(err)
+	require.NotNil(found)
+	require.Equal(3, found.Count)
+	require.NotNil(found.LockedUntil)
+	require.WithinDuration(now.Add(2*time.Hour), *found.LockedUntil, time.Millisecond)
+	require.WithinDuration(now.Add(2*time.Hour), found.ExpiresAt, time.Millisecond)
+
+	_, err = suite.repos.loginFailures.Attempt(context.Background(), key, now.Add(time.Hour), policy)
+	var blocked *domain.LoginBlockedError
+	require.ErrorAs(err, &blocked)
+	require.True(blocked.Locked)
+
+	found, err = suite.repos.loginFailures.Find(context.Background(), key)
+	require.NoError(err)
+	require.Equal(3, found.Count)
+
+	events, err := suite.repos.audit.Find(context.Background(), AuditQuery{Action: domain.AuditLoginLocked, Limit: 10})
+	require.NoError(err)
+	require.Len(events, 1)
+	require.Contains(string(events[0].After), `"key":"account:foo@example.com","failures":3`)
+
+	require.NoError(suite.repos.loginFailures.Reset(context.Background(), key))
+	found, err = suite.repos.loginFailures.Find(context.Background(), key)
+	require.NoError(err)
+	require.Nil(found)
+}
+
+// TestLoginFailure_Attempt_Concurrent_Success checks that concurrent attempts
+// of a key are counted one after the other, so a burst of them cannot pass
+// the delay together.
+func (suite *ConformanceTestSuite) TestLoginFailure_Attempt_Concurrent_Success() {
+	require := suite.Require()
+	policy := domain.LoginPolicy{Window: time.Hour, FreeFailures: 2, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	now := time.Now()
+	key := domain.IPLoginKey("192.0.2.1")
+
+	var wg sync.WaitGroup
+	var allowed, blocked atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := suite.repos.loginFailures.Attempt(context.Background(), key, now, policy)
+			var blockedErr *domain.LoginBlockedError
+			switch {
+			case err == nil:
+				allowed.Add(1)
+			case errors.As(err, &blockedErr):
+				blocked.Add(1)
+			default:
+				suite.T().Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	require.Equal(int32(3), allowed.Load())
+	require.Equal(int32(7), blocked.Load())
+}
+
+func (suite *ConformanceTestSuite) TestLoginFailure_Release_Success() {
+	require := suite.Require()
+	policy := domain.LoginPolicy{Window: time.Hour, LockoutAfter: 2, LockoutDuration: time.Hour}
+	now := time.Now()
+	key := domain.IPLoginKey("192.0.2.1")
+
+	require.NoError(suite.repos.loginFailures.Release(context.Background(), key, policy))
+
+	for i := 0; i < 2; i++ {
+		_, err := suite.repos.loginFailures.Attempt(context.Background(), key, now, policy)
+		require.NoError(err)
+	}
+
+	require.NoError(suite.repos.loginFailures.Release(context.Background(), key, policy))
+
+	found, err := suite.repos.loginFailures.Find(context.Background(), key)
+	require.NoError(err)
+	require.Equal(1, found.Count)
+	require.Nil(found.LockedUntil)
+	_, err = suite.repos.loginFailures.Attempt(context.Background(), key, now, policy)
+	require.NoError(err)
+}
+
+// TestLoginFailure_Attempt_Expired_Success checks that failures are forgotten
+// once they expire, and that expired failures of other keys are dropped.
+func (suite *ConformanceTestSuite) TestLoginFailure_Attempt_Expired_Success() {
+	require := suite.Require()
+	policy := domain.LoginPolicy{Window: time.Hour}
+	now := time.Now()
+
+	for _, key := range []string{domain.IPLoginKey("192.0.2.1"), domain.IPLoginKey("192.0.2.2")} {
+		_, err := suite.repos.loginFailures.Attempt(context.Background(), key, now.Add(-2*time.Hour), policy)
+		require.NoError(err)
+	}
+
+	failures, err := suite.repos.loginFailures.Attempt(context.Background(), domain.IPLoginKey("192.0.2.1"), now, policy)
+	require.NoError(err)
+	require.Equal(1, failures.Count)
+
+	found, err := suite.repos.loginFailures.Find(context.Background(), domain.IPLoginKey("192.0.2.2"))
+	require.NoError(err)
+	require.Nil(found)
+}
+
+func (suite *ConformanceTestSuite) TestLoginFailure_Unlock_Success() {
+	require := suite.Require()
+	userID := suite.createUser("foo@example.com", 0)
+	actorID := uint(99)
+	key := domain.AccountLoginKey("foo@example.com")
+
+	unlocked, err := suite.repos.loginFailures.Unlock(context.Background(), key, &actorID, &userID)
+	require.NoError(err)
+	require.False(unlocked)
+
+	_, err = suite.repos.loginFailures.Attempt(context.Background(), key, time.Now(), domain.LoginPolicy{Window: time.Hour})
+	require.NoError(err)
+	unlocked, err = suite.repos.loginFailures.Unlock(context.Background(), key, &actorID, &userID)
+	require.NoError(err)
+	require.True(unlocked)
+
+	found, err := suite.repos.loginFailures.Find(context.Background(), key)
+	require.NoError(err)
+	require.Nil(found)
+
+	events, err := suite.repos.audit.Find(context.Background(), AuditQuery{Action: domain.AuditLoginUnlocked, Limit: 10})
+	require.NoError(err)
+	require.Len(events, 1)
+	require.Equal(&actorID, events[0].ActorID)
+	require.Equal(&userID, events[0].TargetID)
+	require.JSONEq(`{"key":"account:foo@example.com","failures":1}`, string(events[0].Before))
+}
+
+func (suite *ConformanceTestSuite) TestIdempotency_Reserve_Success() {
+	require := suite.Require()
+	now := time.Now().Truncate(time.Second)
+	reservation, err := domain.NewIdempotencyKey(10, "key", "hash", now, time.Hour)
+	require.NoError(err)
+
+	stored, err := suite.repos.idempotency.Reserve(context.Background(), reservation, time.Minute)
+	require.NoError(err)
+	require.Equal(reservation.Token, stored.Token)
+
+	reservation.StatusCode = 201
+	reservation.ResponseHeaders = map[string][]string{"Etag": {`"1"`}}
+	reservation.ResponseBody = []byte(`{"id":1}`)
+	require.NoError(suite.repos.idempotency.Complete(context.Background(), reservation))
+
+	retry, err := domain.NewIdempotencyKey(10, "key", "hash", now.Add(2*time.Minute), time.Hour)
+	require.NoError(err)
+	stored, err = suite.repos.idempotency.Reserve(context.Background(), retry, time.Minute)
+	require.NoError(err)
+	require.Equal(reservation.Token, stored.Token)
+	require.Equal(201, stored.StatusCode)
+	require.Equal(reservation.ResponseHeaders, stored.ResponseHeaders)
+	require.Equal(reservation.ResponseBody, stored.ResponseBody)
+}
+
+// TestIdempotency_Reserve_TakeOver_Success checks that a key whose request
+// did not complete within the lease is taken over, and that the request that
+// lost it no longer completes or releases it.
+func (suite *ConformanceTestSuite) TestIdempotency_Reserve_TakeOver_Success() {
+	require := suite.Require()
+	now := time.Now().Truncate(time.Second)
+	stale, err := domain.NewIdempotencyKey(10, "key", "hash", now, time.Hour)
+	require.NoError(err)
+	_, err = suite.repos.idempotency.Reserve(context.Background(), stale, time.Minute)
+	require.NoError(err)
+
+	early, err := domain.NewIdempotencyKey(10, "key", "hash", now.Add(30*time.Second), time.Hour)
+	require.NoError(err)
+	stored, err := suite.repos.idempotency.Reserve(context.Background(), early, time.Minute)
+	require.NoError(err)
+	require.Equal(stale.Token, stored.Token)
+
+	retry, err := domain.NewIdempotencyKey(10, "key", "hash", now.Add(2*time.Minute), time.Hour)
+	require.NoError(err)
+	stored, err = suite.repos.idempotency.Reserve(context.Background(), retry, time.Minute)
+	require.NoError(err)
+	require.Equal(retry.Token, stored.Token)
+
+	require.NoError(suite.repos.idempotency.Release(context.Background(), stale))
+	stale.StatusCode = 500
+	require.NoError(suite.repos.idempotency.Complete(context.Background(), stale))
+
+	stored, err = suite.repos.idempotency.Reserve(context.Background(), early, time.Minute)
+	require.NoError(err)
+	require.Equal(retry.Token, stored.Token)
+	require.False(stored.IsCompleted())
+}
+
+// TestIdempotency_Reserve_Expired_Success checks that a completed key is
+// reserved anew once it expires, also for a different request, while the
+// expired keys of other users are dropped.
+func (suite *ConformanceTestSuite) TestIdempotency_Reserve_Expired_Success() {
+	require := suite.Require()
+	now := time.Now().Truncate(time.Second)
+
+	for _, userID := range []uint{10, 11} {
+		reservation, err := domain.NewIdempotencyKey(userID, "key", "hash", now.Add(-2*time.Hour), time.Hour)
+		require.NoError(err)
+		_, err = suite.repos.idempotency.Reserve(context.Background(), reservation, time.Minute)
+		require.NoError(err)
+		reservation.StatusCode = 201
+		require.NoError(suite.repos.idempotency.Complete(context.Background(), reservation))
+	}
+
+	reservation, err := domain.NewIdempotencyKey(10, "key", "other", now, time.Hour)
+	require.NoError(err)
+	stored, err := suite.repos.idempotency.Reserve(context.Background(), reservation, time.Minute)
+	require.NoError(err)
+	require.Equal(reservation.Token, stored.Token)
+	require.Equal("other", stored.RequestHash)
+
+	other, err := domain.NewIdempotencyKey(11, "key", "other", now, time.Hour)
+	require.NoError(err)
+	stored, err = suite.repos.idempotency.Reserve(context.Background(), other, time.Minute)
+	require.NoError(err)
+	require.Equal(other.Token, stored.Token)
+}
+
+func giftCardIDs(giftCards []domain.GiftCard) []uint {
+	var ids []uint
+	for _, giftCard := range giftCards {
+		ids = append(ids, giftCard.ID)
+	}
+
+	return ids
+}
+
+func uintString(u uint) string {
+	return strconv.FormatUint(uint64(u), 10)
+}
+
+func TestMemoryConformance(t *testing.T) {
+	suite.Run(t, &ConformanceTestSuite{newRepositories: func(t *testing.T) conformanceRepositories {
+		store := NewMemoryStore()
+
+		return conformanceRepositories{
+			users:         NewMemoryUserRepository(store),
+			giftCards:     NewMemoryGiftCardRepository(store),
+			wallets:       NewMemoryWalletRepository(store),
+			unitOfWork:    NewMemoryUnitOfWork(store),
+			refreshTokens: NewMemoryRefreshTokenRepository(store),
+			revokedTokens: NewMemoryRevokedTokenRepository(store),
+			audit:         NewMemoryAuditRepository(store),
+			loginFailures: NewMemoryLoginFailureRepository(store),
+			idempotency:   NewMemoryIdempotencyRepository(store),
+		}
+	}})
+}
+
+func TestSQLiteConformance(t *testing.T) {
+	suite.Run(t, &ConformanceTestSuite{newRepositories: func(t *testing.T) conformanceRepositories {
+		d := config.SQLDatabase{Driver: "sqlite", DB: filepath.Join(t.TempDir(), "gift-card.db")}
+
+		return sqlConformanceRepositories(t, d.Driver, d.String())
+	}})
+}
+
+// The MySQL and Postgres suites run against the databases the
+// GIFT_CARD_TEST_MYSQL_DSN and GIFT_CARD_TEST_POSTGRES_DSN variables point
+// at. Every test rolls back all migrations first, so they must not point at
+// a database with data worth keeping.
+
+func TestMySQLConformance(t *testing.T) {
+	dsn := os.Getenv("GIFT_CARD_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("GIFT_CARD_TEST_MYSQL_DSN is not set")
+	}
+
+	suite.Run(t, &ConformanceTestSuite{newRepositories: func(t *testing.T) conformanceRepositories {
+		return sqlConformanceRepositories(t, "mysql", dsn)
+	}})
+}
+
+func TestPostgresConformance(t *testing.T) {
+	dsn := os.Getenv("GIFT_CARD_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("GIFT_CARD_TEST_POSTGRES_DSN is not set")
+	}
+
+	suite.Run(t, &ConformanceTestSuite{newRepositories: func(t *testing.T) conformanceRepositories {
+		return sqlConformanceRepositories(t, "postgres", dsn)
+	}})
+}
+
+// sqlConformanceRepositories opens the database, migrates it from scratch and
+// returns the SQL repositories on it.
+func sqlConformanceRepositories(t *testing.T, driver, dsn string) conformanceRepositories {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+	migrations, err := migration.Load(database.DialectOf(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator := migration.NewMigrator(db, migrations)
+	if _, err := migrator.To(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	return conformanceRepositories{
+		users:         NewUserRepository(db),
+		giftCards:     NewGiftCardRepository(db),
+		wallets:       NewWalletRepository(db),
+		unitOfWork:    NewUnitOfWork(db),
+		refreshTokens: NewRefreshTokenRepository(db),
+		revokedTokens: NewRevokedTokenRepository(db),
+		audit:         NewAuditRepository(db),
+		loginFailures: NewLoginFailureRepository(db),
+		idempotency:   NewIdempotencyRepository(db),
+		ledger:        NewLedgerRepository(db),
+	}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+// executor is satisfied by both sqlDB and sqlTx, so the same queries can run
+// on their own or as part of a transaction. Queries are written with ?
+// placeholders and rebound for the dialect of the database. Every query takes
+// the context of the request it serves and is cancelled along with it.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	Dialect() database.Dialect
+}
+
+type sqlDB struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func newSQLDB(db *sql.DB) sqlDB {
+	return sqlDB{db: db, dialect: database.DialectOf(db)}
+}
+
+func (db sqlDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.db.ExecContext(ctx, db.dialect.Rebind(query), db.dialect.BindArgs(args)...)
+}
+
+func (db sqlDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.db.QueryContext(ctx, db.dialect.Rebind(query), db.dialect.BindArgs(args)...)
+}
+
+func (db sqlDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return db.db.QueryRowContext(ctx, db.dialect.Rebind(query), db.dialect.BindArgs(args)...)
+}
+
+func (db sqlDB) Dialect() database.Dialect {
+	return db.dialect
+}
+
+// BeginTx starts a transaction whose queries are rebound like the ones of db.
+func (db sqlDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (sqlTx, error) {
+	tx, err := db.db.BeginTx(ctx, opts)
+	if err != nil {
+		return sqlTx{}, err
+	}
+
+	return sqlTx{tx: tx, dialect: db.dialect}, nil
+}
+
+type sqlTx struct {
+	tx      *sql.Tx
+	dialect database.Dialect
+}
+
+func (tx sqlTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.tx.ExecContext(ctx, tx.dialect.Rebind(query), tx.dialect.BindArgs(args)...)
+}
+
+func (tx sqlTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tx.tx.QueryContext(ctx, tx.dialect.Rebind(query), tx.dialect.BindArgs(args)...)
+}
+
+func (tx sqlTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return tx.tx.QueryRowContext(ctx, tx.dialect.Rebind(query), tx.dialect.BindArgs(args)...)
+}
+
+func (tx sqlTx) Dialect() database.Dialect {
+	return tx.dialect
+}
+
+func (tx sqlTx) Commit() error {
+	return tx.tx.Commit()
+}
+
+func (tx sqlTx) Rollback() error {
+	return tx.tx.Rollback()
+}
+
+// conn is an executor that can run a function in a transaction. Repositories
+// built on an sqlTx join it instead of starting a transaction of their own,
+// which lets a unit of work span several of them.
+type conn interface {
+	executor
+	inTx(ctx context.Context, fn func(tx sqlTx) error) error
+}
+
+func (db sqlDB) inTx(ctx context.Context, fn func(tx sqlTx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// inTx runs fn in the transaction itself, it is committed or rolled back by
+// whoever began it.
+func (tx sqlTx) inTx(_ context.Context, fn func(tx sqlTx) error) error {
+	return fn(tx)
+}
+
+// withTx runs fn inside a transaction and commits it if fn succeeds. The
+// transaction is rolled back if ctx is cancelled before it commits. When db
+// is already a transaction fn joins it.
+func withTx(ctx context.Context, db conn, fn func(tx sqlTx) error) error {
+	return db.inTx(ctx, fn)
+}
+
+// insert runs an INSERT statement and returns the id of the new row. Postgres
+// does not report the last insert id, so the id is returned by the statement
+// itself there.
+func insert(ctx context.Context, db executor, query string, args ...any) (uint, error) {
+	if db.Dialect() == database.Postgres {
+		var id uint
+		err := db.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+
+		return id, err
+	}
+
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+
+	return uint(id), err
+}
@@ -1,72 +1,172 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmehdipour/gift-card/internal/domain"
 )
 
 type GiftCardRepository interface {
-	Create(giftCard *domain.GiftCard) error
-	FindByID(id uint) (*domain.GiftCard, error)
-	UpdateStatus(id uint, status domain.GiftCardStatus) error
-	FindReceivedGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error)
-	FindSentGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error)
+	Create(ctx context.Context, giftCard *domain.GiftCard) error
+	FindByID(ctx context.Context, id uint) (*domain.GiftCard, error)
+	FindByIDForUpdate(ctx context.Context, id uint) (*domain.GiftCard, error)
+	UpdateStatus(ctx context.Context, id uint, version *uint, status domain.GiftCardStatus, actorID *uint) error
+	ForceUpdateStatus(ctx context.Context, id uint, version *uint, status domain.GiftCardStatus, actorID *uint) error
+	FindStatusHistory(ctx context.Context, id uint) ([]domain.GiftCardStatusChange, error)
+	FindOverdueGiftCardIDs(ctx context.Context, now time.Time, limit int) ([]uint, error)
+	Redeem(ctx context.Context, code string, redeemerID uint, amount domain.Money) (*domain.GiftCardRedemption, error)
+	FindReceivedGiftCardsByUserID(ctx context.Context, userID uint, query GiftCardQuery) (GiftCardPage, error)
+	FindSentGiftCardsByUserID(ctx context.Context, userID uint, query GiftCardQuery) (GiftCardPage, error)
 }
 
 type GiftCardEntity struct {
+	ID              uint
+	Code            string
+	Amount          int64
+	RemainingAmount int64
+	Currency        string
+	SenderID        uint
+	ReceiverID      uint
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	ExpiresAt       sql.NullTime
+	Status          int
+	Version         uint
+}
+
+func (g GiftCardEntity) ToAggregate() domain.GiftCard {
+	giftCard := domain.GiftCard{
+		ID:              g.ID,
+		Code:            g.Code,
+		Status:          domain.GiftCardStatus(g.Status),
+		GifterID:        g.SenderID,
+		GifteeID:        g.ReceiverID,
+		Amount:          domain.NewMoney(g.Amount, g.Currency),
+		RemainingAmount: domain.NewMoney(g.RemainingAmount, g.Currency),
+		CreationDate:    g.CreatedAt,
+		UpdatedAt:       g.UpdatedAt,
+		Version:         g.Version,
+	}
+
+	if g.ExpiresAt.Valid {
+		expiresAt := g.ExpiresAt.Time
+		giftCard.ExpiresAt = &expiresAt
+	}
+
+	return giftCard
+}
+
+type GiftCardStatusChangeEntity struct {
 	ID         uint
-	Amount     float64
-	SenderID   uint
-	ReceiverID uint
+	GiftCardID uint
+	FromStatus sql.NullInt16
+	ToStatus   int
+	ActorID    sql.NullInt64
 	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	Status     int
 }
 
-func (g GiftCardEntity) ToAggregate() domain.GiftCard {
-	return domain.GiftCard{
-		ID:           g.ID,
-		Status:       domain.GiftCardStatus(g.Status),
-		GifterID:     g.SenderID,
-		GifteeID:     g.ReceiverID,
-		Amount:       g.Amount,
-		CreationDate: g.CreatedAt,
+func (h GiftCardStatusChangeEntity) ToAggregate() domain.GiftCardStatusChange {
+	change := domain.GiftCardStatusChange{
+		ID:         h.ID,
+		GiftCardID: h.GiftCardID,
+		To:         domain.GiftCardStatus(h.ToStatus),
+		CreatedAt:  h.CreatedAt,
+	}
+
+	if h.FromStatus.Valid {
+		from := domain.GiftCardStatus(h.FromStatus.Int16)
+		change.From = &from
 	}
+
+	if h.ActorID.Valid {
+		actorID := uint(h.ActorID.Int64)
+		change.ActorID = &actorID
+	}
+
+	return change
 }
 
 type giftCardRepository struct {
-	db *sql.DB
+	db conn
 }
 
 func NewGiftCardRepository(db *sql.DB) GiftCardRepository {
-	return &giftCardRepository{db: db}
+	return &giftCardRepository{db: newSQLDB(db)}
 }
 
-func (r *giftCardRepository) Create(giftCard *domain.GiftCard) error {
-	query := `INSERT INTO gift_cards (amount, sender_id, receiver_id, status, updated_at, created_at) VALUES (?, ?, ?, 2, NOW(), NOW())`
-	res, err := r.db.Exec(query, giftCard.Amount, giftCard.GifterID, giftCard.GifteeID)
-	if err != nil {
-		return err
-	}
+// Create inserts the gift card and holds its amount on the wallet of the
+// gifter in the same transaction. The creation is the first entry of the
+// status history of the card and a GiftCardCreated event is added to the
+// outbox along with it.
+func (r *giftCardRepository) Create(ctx context.Context, giftCard *domain.GiftCard) error {
+	return withTx(ctx, r.db, func(tx sqlTx) error {
+		query := `INSERT INTO gift_cards (code, amount, remaining_amount, currency, sender_id, receiver_id, status, expires_at, updated_at, created_at) VALUES (?, ?, ?, ?, ?, ?, 2, ?, NOW(), NOW())`
+		id, err := insert(ctx, tx, query, giftCard.Code, giftCard.Amount.Amount, giftCard.RemainingAmount.Amount, giftCard.Amount.Currency, giftCard.GifterID, giftCard.GifteeID, giftCard.ExpiresAt)
+		if err != nil {
+			return err
+		}
 
-	id, err := res.LastInsertId()
-	if err != nil {
-		return err
-	}
+		giftCard.ID = id
+		giftCard.Version = 1
 
-	giftCard.ID = uint(id)
+		err = (&walletLedger{db: tx}).hold(ctx, giftCard.GifterID, giftCard.ID, giftCard.Amount)
+		if err != nil {
+			return err
+		}
+
+		err = recordStatusChange(ctx, tx, domain.GiftCardStatusChange{
+			GiftCardID: giftCard.ID,
+			To:         domain.GCSPending,
+			ActorID:    &giftCard.GifterID,
+		})
+		if err != nil {
+			return err
+		}
+
+		event, err := domain.NewGiftCardCreatedEvent(*giftCard)
+		if err != nil {
+			return err
+		}
+
+		err = recordEvent(ctx, tx, &event)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		created := *giftCard
+		created.Status = domain.GCSPending
+		audit, err := domain.NewAuditEvent(ctx, domain.AuditGiftCardCreated, &giftCard.GifterID, domain.AuditTargetGiftCard, &giftCard.ID, nil, domain.NewAuditGiftCard(created))
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, &audit)
+	})
+}
+
+func (r *giftCardRepository) FindByID(ctx context.Context, id uint) (*domain.GiftCard, error) {
+	return findGiftCardByID(ctx, r.db, id, "")
 }
 
-func (r *giftCardRepository) FindByID(id uint) (*domain.GiftCard, error) {
+// FindByIDForUpdate finds the gift card like FindByID and locks its row until
+// the end of the transaction, so it cannot change between being checked and
+// being updated. Outside of a unit of work the lock is released as soon as
+// the card is read.
+func (r *giftCardRepository) FindByIDForUpdate(ctx context.Context, id uint) (*domain.GiftCard, error) {
+	return findGiftCardByID(ctx, r.db, id, " FOR UPDATE")
+}
+
+// findGiftCardByID returns the gift card with the given id or nil if there is
+// none, lock is appended to the query.
+func findGiftCardByID(ctx context.Context, db executor, id uint, lock string) (*domain.GiftCard, error) {
 	e := new(GiftCardEntity)
-	err := r.db.
-		QueryRow("SELECT id, sender_id, receiver_id, amount, status FROM gift_cards WHERE id = ?", id).
-		Scan(&e.ID, &e.SenderID, &e.ReceiverID, &e.Amount, &e.Status)
+	err := db.
+		QueryRowContext(ctx, "SELECT id, code, sender_id, receiver_id, amount, remaining_amount, currency, status, expires_at, version, created_at, updated_at FROM gift_cards WHERE id = ?"+lock, id).
+		Scan(&e.ID, &e.Code, &e.SenderID, &e.ReceiverID, &e.Amount, &e.RemainingAmount, &e.Currency, &e.Status, &e.ExpiresAt, &e.Version, &e.CreatedAt, &e.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -80,89 +180,402 @@ func (r *giftCardRepository) FindByID(id uint) (*domain.GiftCard, error) {
 	return &domainGiftCard, nil
 }
 
-func (r *giftCardRepository) UpdateStatus(id uint, status domain.GiftCardStatus) error {
-	query := "UPDATE gift_cards SET status = ?, updated_at = NOW() WHERE id = ?"
-	_, err := r.db.Exec(query, uint(status), id)
+// UpdateStatus moves the gift card to status if its state machine allows it
+// and settles the held amount: accepting moves it into the ledger account of
+// the card and any other final status releases it back to the gifter. The
+// funds, the status, its history entry, the GiftCardStatusChanged event and
+// its deliveries to the webhooks of the gifter and the giftee are all written
+// in the same transaction.
+//
+// The update compares and swaps the version of the card, which is bumped by
+// every change. If version is given and the card is at another version by the
+// time it is updated, domain.ErrGiftCardVersionMismatch is returned.
+func (r *giftCardRepository) UpdateStatus(ctx context.Context, id uint, version *uint, status domain.GiftCardStatus, actorID *uint) error {
+	return r.updateStatus(ctx, id, version, status, actorID, false)
+}
 
-	return err
+// ForceUpdateStatus is UpdateStatus whatever the expiry date of the card, see
+// domain.GiftCard.ForceTransitionTo.
+func (r *giftCardRepository) ForceUpdateStatus(ctx context.Context, id uint, version *uint, status domain.GiftCardStatus, actorID *uint) error {
+	return r.updateStatus(ctx, id, version, status, actorID, true)
 }
 
-func (r *giftCardRepository) FindReceivedGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error) {
-	offset := (pageNumber - 1) * pageSize
-	query := "SELECT id, status, sender_id, receiver_id, amount FROM gift_cards WHERE receiver_id = ?"
-	if status != nil {
-		query += fmt.Sprintf(" AND status = %d", *status)
+func (r *giftCardRepository) updateStatus(ctx context.Context, id uint, version *uint, status domain.GiftCardStatus, actorID *uint, force bool) error {
+	return withTx(ctx, r.db, func(tx sqlTx) error {
+		giftCard, err := findGiftCardByID(ctx, tx, id, " FOR UPDATE")
+		if err != nil {
+			return err
+		}
+
+		if giftCard == nil {
+			return domain.ErrGiftCardNotFound
+		}
+
+		if version != nil && *version != giftCard.Version {
+			return domain.ErrGiftCardVersionMismatch
+		}
+
+		from, remaining := giftCard.Status, giftCard.RemainingAmount
+		before := domain.NewAuditGiftCard(*giftCard)
+		if force {
+			err = giftCard.ForceTransitionTo(status)
+		} else {
+			err = giftCard.TransitionTo(status, time.Now())
+		}
+
+		if err != nil {
+			return err
+		}
+
+		query := "UPDATE gift_cards SET status = ?, version = version + 1, updated_at = NOW() WHERE id = ? AND version = ?"
+		res, err := tx.ExecContext(ctx, query, uint(status), id, giftCard.Version)
+		if err != nil {
+			return err
+		}
+
+		updated, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if updated == 0 {
+			return domain.ErrGiftCardVersionMismatch
+		}
+
+		err = recordStatusChange(ctx, tx, domain.GiftCardStatusChange{GiftCardID: id, From: &from, To: status, ActorID: actorID})
+		if err != nil {
+			return err
+		}
+
+		event, err := domain.NewGiftCardStatusChangedEvent(*giftCard, from, actorID)
+		if err != nil {
+			return err
+		}
+
+		err = recordEvent(ctx, tx, &event)
+		if err != nil {
+			return err
+		}
+
+		err = enqueueWebhookDeliveries(ctx, tx, event, time.Now(), giftCard.GifterID, giftCard.GifteeID)
+		if err != nil {
+			return err
+		}
+
+		ledger := &walletLedger{db: tx}
+		switch {
+		case status == domain.GCSAccepted:
+			err = ledger.transfer(ctx, giftCard.GifterID, giftCard.ID, giftCard.Amount)
+		case from == domain.GCSAccepted:
+			err = refundGiftCard(ctx, ledger, giftCard.GifterID, giftCard.ID, remaining)
+		default:
+			err = ledger.release(ctx, giftCard.GifterID, giftCard.ID, giftCard.Amount)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		giftCard.Version++
+		audit, err := domain.NewAuditEvent(ctx, domain.AuditGiftCardStatusChanged, actorID, domain.AuditTargetGiftCard, &id, before, domain.NewAuditGiftCard(*giftCard))
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, &audit)
+	})
+}
+
+// refundGiftCard empties a voided accepted gift card and refunds its
+// remaining amount to the gifter.
+func refundGiftCard(ctx context.Context, ledger *walletLedger, gifterID, giftCardID uint, remaining domain.Money) error {
+	if !remaining.IsPositive() {
+		return nil
 	}
 
-	query += fmt.Sprintf(" LIMIT %d OFFSET %d", pageSize, offset)
-	rows, err := r.db.Query(query, userID)
+	_, err := ledger.db.ExecContext(ctx, "UPDATE gift_cards SET remaining_amount = 0 WHERE id = ?", giftCardID)
 	if err != nil {
-		return nil, 0, err
+		return err
+	}
+
+	return ledger.refund(ctx, gifterID, giftCardID, remaining)
+}
+
+func (r *giftCardRepository) FindStatusHistory(ctx context.Context, id uint) ([]domain.GiftCardStatusChange, error) {
+	query := "SELECT id, gift_card_id, from_status, to_status, actor_id, created_at FROM gift_card_status_history WHERE gift_card_id = ? ORDER BY id"
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
 	}
 
 	defer rows.Close()
-	var giftCards []domain.GiftCard
+	var history []domain.GiftCardStatusChange
 	for rows.Next() {
-		var g GiftCardEntity
-		err := rows.Scan(&g.ID, &g.Status, &g.SenderID, &g.ReceiverID, &g.Amount)
+		var e GiftCardStatusChangeEntity
+		err := rows.Scan(&e.ID, &e.GiftCardID, &e.FromStatus, &e.ToStatus, &e.ActorID, &e.CreatedAt)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 
-		giftCards = append(giftCards, g.ToAggregate())
+		history = append(history, e.ToAggregate())
+	}
+
+	return history, rows.Err()
+}
+
+// FindOverdueGiftCardIDs returns up to limit pending gift cards whose expiry
+// date is not after now, the ones that expired first come first.
+func (r *giftCardRepository) FindOverdueGiftCardIDs(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	query := "SELECT id FROM gift_cards WHERE status = ? AND expires_at <= ? ORDER BY expires_at, id LIMIT ?"
+	rows, err := r.db.QueryContext(ctx, query, int(domain.GCSPending), now, limit)
+	if err != nil {
+		return nil, err
 	}
 
-	// Fetch total count
-	var totalCount int
-	totalCountQuery := "SELECT COUNT(*) FROM gift_cards WHERE receiver_id = ?"
-	if status != nil {
-		totalCountQuery += fmt.Sprintf(" AND status = %d", *status)
+	defer rows.Close()
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
 	}
 
-	err = r.db.QueryRow(totalCountQuery, userID).Scan(&totalCount)
+	return ids, rows.Err()
+}
+
+// Redeem spends amount of the accepted gift card with the given code on
+// behalf of the redeemer. The amount moves from the ledger account of the card
+// to the wallet of the redeemer and the remaining amount of the card, the
+// redemption record, the ledger entries and the GiftCardRedeemed event are all
+// stored in one transaction.
+func (r *giftCardRepository) Redeem(ctx context.Context, code string, redeemerID uint, amount domain.Money) (*domain.GiftCardRedemption, error) {
+	var redemption *domain.GiftCardRedemption
+	err := withTx(ctx, r.db, func(tx sqlTx) error {
+		e := new(GiftCardEntity)
+		err := tx.
+			QueryRowContext(ctx, "SELECT id, code, sender_id, receiver_id, amount, remaining_amount, currency, status, expires_at, version FROM gift_cards WHERE code = ? FOR UPDATE", code).
+			Scan(&e.ID, &e.Code, &e.SenderID, &e.ReceiverID, &e.Amount, &e.RemainingAmount, &e.Currency, &e.Status, &e.ExpiresAt, &e.Version)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return domain.ErrGiftCardNotFound
+			}
+
+			return err
+		}
+
+		giftCard := e.ToAggregate()
+		before := domain.NewAuditGiftCard(giftCard)
+		err = giftCard.Redeem(amount)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE gift_cards SET remaining_amount = ?, version = version + 1, updated_at = NOW() WHERE id = ?", giftCard.RemainingAmount.Amount, giftCard.ID)
+		if err != nil {
+			return err
+		}
+
+		err = (&walletLedger{db: tx}).redeem(ctx, redeemerID, giftCard.ID, amount)
+		if err != nil {
+			return err
+		}
+
+		query := "INSERT INTO gift_card_redemptions (gift_card_id, redeemer_id, amount, remaining_amount, currency, created_at) VALUES (?, ?, ?, ?, ?, NOW())"
+		id, err := insert(ctx, tx, query, giftCard.ID, redeemerID, amount.Amount, giftCard.RemainingAmount.Amount, amount.Currency)
+		if err != nil {
+			return err
+		}
+
+		redemption = &domain.GiftCardRedemption{
+			ID:              id,
+			GiftCardID:      giftCard.ID,
+			RedeemerID:      redeemerID,
+			Amount:          amount,
+			RemainingAmount: giftCard.RemainingAmount,
+		}
+
+		event, err := domain.NewGiftCardRedeemedEvent(*redemption)
+		if err != nil {
+			return err
+		}
+
+		err = recordEvent(ctx, tx, &event)
+		if err != nil {
+			return err
+		}
+
+		giftCard.Version++
+		audit, err := domain.NewAuditEvent(ctx, domain.AuditGiftCardRedeemed, &redeemerID, domain.AuditTargetGiftCard, &giftCard.ID, before, domain.NewAuditGiftCard(giftCard))
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, &audit)
+	})
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	return giftCards, totalCount, nil
+	return redemption, nil
 }
 
-func (r *giftCardRepository) FindSentGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error) {
-	offset := (pageNumber - 1) * pageSize
-	query := "SELECT id, status, sender_id, receiver_id, amount FROM gift_cards WHERE sender_id = ?"
-	if status != nil {
-		query += fmt.Sprintf(" AND status = %d", *status)
+func recordStatusChange(ctx context.Context, db executor, change domain.GiftCardStatusChange) error {
+	var from *int
+	if change.From != nil {
+		f := int(*change.From)
+		from = &f
 	}
 
-	query += fmt.Sprintf(" LIMIT %d OFFSET %d", pageSize, offset)
+	query := "INSERT INTO gift_card_status_history (gift_card_id, from_status, to_status, actor_id, created_at) VALUES (?, ?, ?, ?, NOW())"
+	_, err := db.ExecContext(ctx, query, change.GiftCardID, from, int(change.To), change.ActorID)
 
-	rows, err := r.db.Query(query, userID)
+	return err
+}
+
+func (r *giftCardRepository) FindReceivedGiftCardsByUserID(ctx context.Context, userID uint, query GiftCardQuery) (GiftCardPage, error) {
+	return r.findGiftCards(ctx, "receiver_id", "sender_id", userID, query)
+}
+
+func (r *giftCardRepository) FindSentGiftCardsByUserID(ctx context.Context, userID uint, query GiftCardQuery) (GiftCardPage, error) {
+	return r.findGiftCards(ctx, "sender_id", "receiver_id", userID, query)
+}
+
+// findGiftCards returns the page of the gift cards of the user in
+// userColumn that match the query, along with the number of all of them if
+// the query asks for it. The counterparty of the query is looked up in
+// counterpartyColumn.
+func (r *giftCardRepository) findGiftCards(ctx context.Context, userColumn, counterpartyColumn string, userID uint, query GiftCardQuery) (GiftCardPage, error) {
+	cursor, err := query.cursor()
+	if err != nil {
+		return GiftCardPage{}, err
+	}
+
+	where, args := giftCardQueryWhere(userColumn, counterpartyColumn, userID, query)
+	keyset, keysetArgs := giftCardQueryKeyset(query, cursor)
+	rows, err := r.db.QueryContext(ctx, "SELECT id, code, status, sender_id, receiver_id, amount, remaining_amount, currency, expires_at, version, created_at FROM gift_cards"+where+keyset+giftCardQueryOrder(query, cursor), append(args, keysetArgs...)...)
 	if err != nil {
-		return nil, 0, err
+		return GiftCardPage{}, err
 	}
 
 	defer rows.Close()
 	var giftCards []domain.GiftCard
 	for rows.Next() {
 		var g GiftCardEntity
-		err := rows.Scan(&g.ID, &g.Status, &g.SenderID, &g.ReceiverID, &g.Amount)
+		err := rows.Scan(&g.ID, &g.Code, &g.Status, &g.SenderID, &g.ReceiverID, &g.Amount, &g.RemainingAmount, &g.Currency, &g.ExpiresAt, &g.Version, &g.CreatedAt)
 		if err != nil {
-			return nil, 0, err
+			return GiftCardPage{}, err
 		}
 
 		giftCards = append(giftCards, g.ToAggregate())
 	}
 
-	// Fetch total count
-	var totalCount int
-	totalCountQuery := "SELECT COUNT(*) FROM gift_cards WHERE sender_id = ?"
-	if status != nil {
-		totalCountQuery += fmt.Sprintf(" AND status = %d", *status)
+	if err := rows.Err(); err != nil {
+		return GiftCardPage{}, err
 	}
-	err = r.db.QueryRow(totalCountQuery, userID).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, err
+
+	page := newGiftCardPage(query, cursor, giftCards)
+	if query.WithTotal {
+		var totalCount int
+		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM gift_cards"+where, args...).Scan(&totalCount)
+		if err != nil {
+			return GiftCardPage{}, err
+		}
+
+		page.Total = &totalCount
+	}
+
+	return page, nil
+}
+
+// giftCardQueryWhere returns the WHERE clause of the query and its arguments.
+// Values are always bound, only the column names are written into the clause.
+func giftCardQueryWhere(userColumn, counterpartyColumn string, userID uint, query GiftCardQuery) (string, []any) {
+	where := " WHERE " + userColumn + " = ?"
+	args := []any{userID}
+
+	if len(query.Statuses) > 0 {
+		where += " AND status IN (?" + strings.Repeat(", ?", len(query.Statuses)-1) + ")"
+		for _, status := range query.Statuses {
+			args = append(args, int(status))
+		}
+	}
+
+	if query.CreatedFrom != nil {
+		where += " AND created_at >= ?"
+		args = append(args, *query.CreatedFrom)
+	}
+
+	if query.CreatedTo != nil {
+		where += " AND created_at <= ?"
+		args = append(args, *query.CreatedTo)
+	}
+
+	if currency := query.currency(); currency != "" {
+		where += " AND currency = ?"
+		args = append(args, currency)
+	}
+
+	if query.MinAmount != nil {
+		where += " AND amount >= ?"
+		args = append(args, query.MinAmount.Amount)
+	}
+
+	if query.MaxAmount != nil {
+		where += " AND amount <= ?"
+		args = append(args, query.MaxAmount.Amount)
+	}
+
+	if query.CounterpartyID != nil {
+		where += " AND " + counterpartyColumn + " = ?"
+		args = append(args, *query.CounterpartyID)
+	}
+
+	return where, args
+}
+
+// giftCardQueryKeyset returns the condition of the cards after the cursor in
+// the direction the page is found in, and its arguments.
+func giftCardQueryKeyset(query GiftCardQuery, cursor *giftCardCursor) (string, []any) {
+	if cursor == nil {
+		return "", nil
+	}
+
+	column, key := "created_at", any(cursor.CreatedAt)
+	if query.SortBy == GiftCardSortAmount {
+		column, key = "amount", cursor.Amount
+	}
+
+	comparison := ">"
+	if query.Descending != cursor.Backward {
+		comparison = "<"
+	}
+
+	condition := fmt.Sprintf(" AND (%s %s ? OR (%s = ? AND id %s ?))", column, comparison, column, comparison)
+
+	return condition, []any{key, key, cursor.ID}
+}
+
+// giftCardQueryOrder returns the ORDER BY and LIMIT clauses of the query. One
+// card more than the page size is asked for to know if there are more.
+func giftCardQueryOrder(query GiftCardQuery, cursor *giftCardCursor) string {
+	column := "created_at"
+	if query.SortBy == GiftCardSortAmount {
+		column = "amount"
+	}
+
+	direction := "ASC"
+	if query.Descending != cursor.backward() {
+		direction = "DESC"
+	}
+
+	order := fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %d", column, direction, direction, query.PageSize+1)
+	if cursor == nil {
+		order += fmt.Sprintf(" OFFSET %d", (query.PageNumber-1)*query.PageSize)
 	}
 
-	return giftCards, totalCount, nil
+	return order
 }
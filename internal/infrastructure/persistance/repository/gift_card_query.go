@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+const (
+	DefaultGiftCardPageSize = 10
+	MaxGiftCardPageSize     = 100
+	// MaxGiftCardPageNumber bounds the offset of a page found by its number,
+	// the pages after it are found by cursor.
+	MaxGiftCardPageNumber = 10000
+)
+
+type GiftCardSort string
+
+const (
+	GiftCardSortCreatedAt GiftCardSort = "created_at"
+	GiftCardSortAmount    GiftCardSort = "amount"
+)
+
+// GiftCardQuery selects, orders and pages the gift cards of a listing. Unset
+// filters match every card, so the zero value with a page size and number
+// lists all of them by creation date, oldest first. Cards that sort the same
+// are ordered by id, which keeps the pages stable.
+//
+// A page is found either by its number or by a cursor of a page listed
+// before. Cursors point at a card rather than an offset, so they are as fast
+// on the last page as on the first and no card is skipped or listed twice
+// when cards are created in between.
+type GiftCardQuery struct {
+	// Statuses matches cards in any of the statuses.
+	Statuses []domain.GiftCardStatus
+	// CreatedFrom and CreatedTo match cards created in between, both
+	// inclusive.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// MinAmount and MaxAmount match cards of their currency whose amount is
+	// in between, both inclusive.
+	MinAmount *domain.Money
+	MaxAmount *domain.Money
+	// CounterpartyID matches cards sent to or received from the user,
+	// depending on the listing.
+	CounterpartyID *uint
+	SortBy         GiftCardSort
+	Descending     bool
+	PageSize       int
+	PageNumber     int
+	// Cursor is the NextCursor or PrevCursor of another page of the same
+	// listing. It takes the place of PageNumber when it is set.
+	Cursor string
+	// WithTotal asks for the number of all the matching cards, which takes
+	// counting every one of them.
+	WithTotal bool
+}
+
+// GiftCardPage is a page of a gift card listing. The cursors are empty when
+// there is no page in their direction, Total is nil unless it was asked for.
+type GiftCardPage struct {
+	GiftCards  []domain.GiftCard
+	Total      *int
+	NextCursor string
+	PrevCursor string
+}
+
+var errInvalidGiftCardCursor = errors.New("invalid cursor")
+
+// giftCardCursor is what a cursor points at: the sort key of a card the page
+// starts after, either going forward in the order of the listing or, for the
+// page before, backward. The sort of the listing is kept to tell the cursor of
+// another listing apart.
+type giftCardCursor struct {
+	SortBy     GiftCardSort `json:"s"`
+	Descending bool         `json:"d,omitempty"`
+	Backward   bool         `json:"b,omitempty"`
+	CreatedAt  time.Time    `json:"c"`
+	Amount     int64        `json:"a,omitempty"`
+	ID         uint         `json:"i"`
+}
+
+// newGiftCardCursor returns the cursor of the page after the card, or before
+// it if backward is set.
+func newGiftCardCursor(query GiftCardQuery, g domain.GiftCard, backward bool) string {
+	data, _ := json.Marshal(giftCardCursor{
+		SortBy:     query.sortBy(),
+		Descending: query.Descending,
+		Backward:   backward,
+		CreatedAt:  g.CreationDate,
+		Amount:     g.Amount.Amount,
+		ID:         g.ID,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// cursor returns what the cursor of the query points at, nil if it has none.
+func (q GiftCardQuery) cursor() (*giftCardCursor, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, errInvalidGiftCardCursor
+	}
+
+	var c giftCardCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return nil, errInvalidGiftCardCursor
+	}
+
+	if c.SortBy != q.sortBy() || c.Descending != q.Descending {
+		return nil, errors.New("the cursor is of a listing in another order")
+	}
+
+	return &c, nil
+}
+
+// Validate returns an error describing the first thing wrong with the query.
+func (q GiftCardQuery) Validate() error {
+	for _, status := range q.Statuses {
+		if !status.IsValid() {
+			return errors.New("invalid gift-card status")
+		}
+	}
+
+	if q.CreatedFrom != nil && q.CreatedTo != nil && q.CreatedFrom.After(*q.CreatedTo) {
+		return errors.New("created_from must not be after created_to")
+	}
+
+	if q.MinAmount != nil && q.MaxAmount != nil {
+		cmp, err := q.MinAmount.Cmp(*q.MaxAmount)
+		if err != nil {
+			return err
+		}
+
+		if cmp > 0 {
+			return errors.New("min_amount must not be more than max_amount")
+		}
+	}
+
+	switch q.SortBy {
+	case "", GiftCardSortCreatedAt, GiftCardSortAmount:
+	default:
+		return fmt.Errorf("cannot sort gift cards by %q", q.SortBy)
+	}
+
+	if q.PageSize < 1 || q.PageSize > MaxGiftCardPageSize {
+		return fmt.Errorf("page size must be between 1 and %d", MaxGiftCardPageSize)
+	}
+
+	if q.PageNumber < 1 || q.PageNumber > MaxGiftCardPageNumber {
+		return fmt.Errorf("page must be between 1 and %d", MaxGiftCardPageNumber)
+	}
+
+	_, err := q.cursor()
+
+	return err
+}
+
+// sortBy returns the sort of the query, creation date if it has none.
+func (q GiftCardQuery) sortBy() GiftCardSort {
+	if q.SortBy == "" {
+		return GiftCardSortCreatedAt
+	}
+
+	return q.SortBy
+}
+
+// currency returns the currency the amount filters restrict the cards to, if
+// any.
+func (q GiftCardQuery) currency() string {
+	if q.MinAmount != nil {
+		return q.MinAmount.Currency
+	}
+
+	if q.MaxAmount != nil {
+		return q.MaxAmount.Currency
+	}
+
+	return ""
+}
+
+// backward reports whether the page of the query is found going backward
+// from its cursor.
+func (c *giftCardCursor) backward() bool {
+	return c != nil && c.Backward
+}
+
+// newGiftCardPage returns the page of the query out of the cards found for
+// it, which are one more than the page size if there are more cards after it
+// in the direction they were found in.
+func newGiftCardPage(query GiftCardQuery, cursor *giftCardCursor, giftCards []domain.GiftCard) GiftCardPage {
+	hasMore := len(giftCards) > query.PageSize
+	if hasMore {
+		giftCards = giftCards[:query.PageSize]
+	}
+
+	backward := cursor.backward()
+	if backward {
+		slices.Reverse(giftCards)
+	}
+
+	page := GiftCardPage{GiftCards: giftCards}
+	if len(giftCards) == 0 {
+		return page
+	}
+
+	hasNext, hasPrev := hasMore, cursor != nil || query.PageNumber > 1
+	if backward {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	if hasNext {
+		page.NextCursor = newGiftCardCursor(query, giftCards[len(giftCards)-1], false)
+	}
+
+	if hasPrev {
+		page.PrevCursor = newGiftCardCursor(query, giftCards[0], true)
+	}
+
+	return page
+}
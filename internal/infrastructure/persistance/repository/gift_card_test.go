@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/suite"
@@ -21,7 +24,7 @@ type GiftCardRepositoryTestSuite struct {
 func (suite *GiftCardRepositoryTestSuite) SetupTest() {
 	suite.db, suite.mock, _ = sqlmock.New()
 	suite.repo = &giftCardRepository{
-		db: suite.db,
+		db: newSQLDB(suite.db),
 	}
 }
 
@@ -39,22 +42,63 @@ func (suite *GiftCardRepositoryTestSuite) TestNewGiftCardRepository() {
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestCreate_Success() {
+	require := suite.Require()
+	id := uint(101)
+	expiresAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	g := &domain.GiftCard{
+		Code:            "0123456789ABCDEZ",
+		GifterID:        10,
+		GifteeID:        20,
+		Amount:          domain.NewMoney(10000, "USD"),
+		RemainingAmount: domain.NewMoney(10000, "USD"),
+		ExpiresAt:       &expiresAt,
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^INSERT INTO gift_cards").
+		WithArgs(g.Code, g.Amount.Amount, g.RemainingAmount.Amount, g.Amount.Currency, g.GifterID, g.GifteeID, expiresAt).
+		WillReturnResult(sqlmock.NewResult(int64(id), 1))
+	suite.mock.ExpectExec("^UPDATE wallets SET balance = balance - \\?, held = held \\+ \\?").
+		WithArgs(g.Amount.Amount, g.Amount.Amount, g.GifterID, g.Amount.Currency, g.Amount.Amount).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectLedgerTransfer(suite.mock, id, domain.LTTHold, domain.WalletAvailableAccount(g.GifterID, "USD"), domain.WalletHeldAccount(g.GifterID, "USD"), g.Amount)
+	suite.mock.ExpectExec("^INSERT INTO gift_card_status_history").
+		WithArgs(id, nil, int(domain.GCSPending), g.GifterID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectRecordEvent(suite.mock, domain.EventGiftCardCreated, id, `{"gift_card_id":101,"gifter_id":10,"giftee_id":20,"amount":"100.00","currency":"USD","expires_at":"2024-02-01T00:00:00Z"}`)
+	expectRecordAudit(suite.mock, domain.AuditGiftCardCreated, g.GifterID, domain.AuditTargetGiftCard, id, nil,
+		`{"id":101,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":"pending","gifter_id":10,"giftee_id":20,"expires_at":"2024-02-01T00:00:00Z","version":1}`)
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.Create(context.Background(), g)
+
+	require.NoError(err)
+	require.Equal(id, g.ID)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestCreate_InsufficientFunds_Failure() {
 	require := suite.Require()
 	id := uint(101)
 	g := &domain.GiftCard{
 		GifterID: 10,
 		GifteeID: 20,
-		Amount:   100,
+		Amount:   domain.NewMoney(10000, "USD"),
 	}
 
+	suite.mock.ExpectBegin()
 	suite.mock.ExpectExec("^INSERT INTO gift_cards").
-		WithArgs(g.Amount, g.GifterID, g.GifteeID).
+		WithArgs(g.Code, g.Amount.Amount, g.RemainingAmount.Amount, g.Amount.Currency, g.GifterID, g.GifteeID, g.ExpiresAt).
 		WillReturnResult(sqlmock.NewResult(int64(id), 1))
+	suite.mock.ExpectExec("^UPDATE wallets").
+		WithArgs(g.Amount.Amount, g.Amount.Amount, g.GifterID, g.Amount.Currency, g.Amount.Amount).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectRollback()
 
-	err := suite.repo.Create(g)
+	err := suite.repo.Create(context.Background(), g)
 
-	require.NoError(err)
-	require.Equal(id, g.ID)
+	require.ErrorIs(err, domain.ErrInsufficientFunds)
+	require.NoError(suite.mock.ExpectationsWereMet())
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestCreate_Failure() {
@@ -62,15 +106,17 @@ func (suite *GiftCardRepositoryTestSuite) TestCreate_Failure() {
 	g := &domain.GiftCard{
 		GifterID: 10,
 		GifteeID: 20,
-		Amount:   100,
+		Amount:   domain.NewMoney(10000, "USD"),
 	}
 	expectedError := errors.New("error in inserting to gift_cards table")
 
+	suite.mock.ExpectBegin()
 	suite.mock.ExpectExec("^INSERT INTO gift_cards").
-		WithArgs(g.Amount, g.GifterID, g.GifteeID).
+		WithArgs(g.Code, g.Amount.Amount, g.RemainingAmount.Amount, g.Amount.Currency, g.GifterID, g.GifteeID, g.ExpiresAt).
 		WillReturnError(expectedError)
+	suite.mock.ExpectRollback()
 
-	err := suite.repo.Create(g)
+	err := suite.repo.Create(context.Background(), g)
 
 	require.EqualError(err, expectedError.Error())
 }
@@ -80,15 +126,17 @@ func (suite *GiftCardRepositoryTestSuite) TestCreate_LastInsertIdError_Failure()
 	g := &domain.GiftCard{
 		GifterID: 10,
 		GifteeID: 20,
-		Amount:   100,
+		Amount:   domain.NewMoney(10000, "USD"),
 	}
 	expectedError := errors.New("LastInsertId error")
 
+	suite.mock.ExpectBegin()
 	suite.mock.ExpectExec("^INSERT INTO gift_cards").
-		WithArgs(g.Amount, g.GifterID, g.GifteeID).
+		WithArgs(g.Code, g.Amount.Amount, g.RemainingAmount.Amount, g.Amount.Currency, g.GifterID, g.GifteeID, g.ExpiresAt).
 		WillReturnResult(sqlmock.NewErrorResult(errors.New("LastInsertId error")))
+	suite.mock.ExpectRollback()
 
-	err := suite.repo.Create(g)
+	err := suite.repo.Create(context.Background(), g)
 
 	require.Error(err)
 	require.EqualError(err, expectedError.Error())
@@ -103,7 +151,7 @@ func (suite *GiftCardRepositoryTestSuite) TestFindByID_DBError_Failure() {
 		WithArgs(id).
 		WillReturnError(errors.New("database failure"))
 
-	result, err := suite.repo.FindByID(id)
+	result, err := suite.repo.FindByID(context.Background(), id)
 
 	require.Error(err)
 	require.EqualError(err, expectedError)
@@ -118,7 +166,7 @@ func (suite *GiftCardRepositoryTestSuite) TestFindByID_NotFound() {
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
-	result, err := suite.repo.FindByID(id)
+	result, err := suite.repo.FindByID(context.Background(), id)
 	require.NoError(err)
 	require.Equal((*domain.GiftCard)(nil), result)
 }
@@ -126,36 +174,254 @@ func (suite *GiftCardRepositoryTestSuite) TestFindByID_NotFound() {
 func (suite *GiftCardRepositoryTestSuite) TestFindByID_Success() {
 	require := suite.Require()
 	id := uint(10)
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
 	expectedResult := &domain.GiftCard{
-		ID:       10,
-		GifterID: 10,
-		GifteeID: 20,
-		Amount:   100,
-		Status:   1,
+		ID:              10,
+		GifterID:        10,
+		GifteeID:        20,
+		Code:            "0123456789ABCDEZ",
+		Amount:          domain.NewMoney(10000, "USD"),
+		RemainingAmount: domain.NewMoney(10000, "USD"),
+		Status:          1,
+		CreationDate:    createdAt,
+		UpdatedAt:       updatedAt,
+		Version:         1,
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "sender_id", "receiver_id", "amount", "status"}).AddRow(10, 10, 20, 100, 1)
+	rows := sqlmock.NewRows([]string{"id", "code", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "status", "expires_at", "version", "created_at", "updated_at"}).
+		AddRow(10, "0123456789ABCDEZ", 10, 20, 10000, 10000, "USD", 1, nil, 1, createdAt, updatedAt)
 	suite.mock.ExpectQuery("^SELECT .+ FROM gift_cards").
 		WithArgs(id).
 		WillReturnRows(rows)
 
-	result, err := suite.repo.FindByID(id)
+	result, err := suite.repo.FindByID(context.Background(), id)
 	require.NoError(err)
 	require.Equal(expectedResult, result)
 }
 
-func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_Success() {
+func (suite *GiftCardRepositoryTestSuite) TestFindByIDForUpdate_Success() {
+	require := suite.Require()
+	id := uint(10)
+
+	suite.expectLockGiftCard(id, domain.GCSPending, nil)
+
+	result, err := suite.repo.FindByIDForUpdate(context.Background(), id)
+	require.NoError(err)
+	require.NotNil(result)
+	require.Equal(id, result.ID)
+	require.Equal(domain.GCSPending, result.Status)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestFindByIDForUpdate_NotFound() {
+	require := suite.Require()
+	id := uint(10)
+
+	suite.mock.ExpectQuery("^SELECT .+ FROM gift_cards WHERE id = \\? FOR UPDATE$").
+		WithArgs(id).
+		WillReturnError(sql.ErrNoRows)
+
+	result, err := suite.repo.FindByIDForUpdate(context.Background(), id)
+	require.NoError(err)
+	require.Nil(result)
+}
+
+func (suite *GiftCardRepositoryTestSuite) expectLockGiftCard(id uint, status domain.GiftCardStatus, expiresAt any) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := createdAt
+	rows := sqlmock.NewRows([]string{"id", "code", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "status", "expires_at", "version", "created_at", "updated_at"}).
+		AddRow(id, "0123456789ABCDEZ", 10, 20, 10000, 10000, "USD", int(status), expiresAt, 1, createdAt, updatedAt)
+	suite.mock.ExpectQuery("^SELECT .+ FROM gift_cards WHERE id = \\? FOR UPDATE$").
+		WithArgs(id).
+		WillReturnRows(rows)
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_Accepted_Success() {
 	require := suite.Require()
 	id := uint(101)
+	actorID := uint(20)
 	status := domain.GCSAccepted
 
-	suite.mock.ExpectExec("^UPDATE gift_cards SET status").
-		WithArgs(status, id).
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id, domain.GCSPending, nil)
+	suite.mock.ExpectExec("^UPDATE gift_cards SET status = \\?, version = version \\+ 1, updated_at = NOW\\(\\) WHERE id = \\? AND version = \\?$").
+		WithArgs(status, id, 1).
 		WillReturnResult(sqlmock.NewResult(101, 1))
+	suite.mock.ExpectExec("^INSERT INTO gift_card_status_history").
+		WithArgs(id, int(domain.GCSPending), int(status), actorID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectRecordEvent(suite.mock, domain.EventGiftCardStatusChanged, id, `{"gift_card_id":101,"gifter_id":10,"giftee_id":20,"from":"pending","to":"accepted","actor_id":20}`)
+	expectEnqueueWebhookDeliveries(suite.mock, 1, domain.EventGiftCardStatusChanged, id, `{"gift_card_id":101,"gifter_id":10,"giftee_id":20,"from":"pending","to":"accepted","actor_id":20}`, []uint{10, 20})
+	suite.mock.ExpectExec("^UPDATE wallets SET held = held - \\?").
+		WithArgs(int64(10000), uint(10), "USD", int64(10000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectLedgerTransfer(suite.mock, id, domain.LTTTransfer, domain.WalletHeldAccount(10, "USD"), domain.GiftCardAccount(id, "USD"), domain.NewMoney(10000, "USD"))
+	expectRecordAudit(suite.mock, domain.AuditGiftCardStatusChanged, actorID, domain.AuditTargetGiftCard, id,
+		`{"id":101,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":"pending","gifter_id":10,"giftee_id":20,"version":1}`, `{"id":101,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":"accepted","gifter_id":10,"giftee_id":20,"version":2}`)
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.UpdateStatus(context.Background(), id, nil, status, &actorID)
 
-	err := suite.repo.UpdateStatus(id, status)
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_Rejected_Success() {
+	require := suite.Require()
+	id := uint(101)
+	actorID := uint(20)
+	status := domain.GCSRejected
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id, domain.GCSPending, nil)
+	suite.mock.ExpectExec("^UPDATE gift_cards SET status = \\?, version = version \\+ 1, updated_at = NOW\\(\\) WHERE id = \\? AND version = \\?$").
+		WithArgs(status, id, 1).
+		WillReturnResult(sqlmock.NewResult(101, 1))
+	suite.mock.ExpectExec("^INSERT INTO gift_card_status_history").
+		WithArgs(id, int(domain.GCSPending), int(status), actorID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectRecordEvent(suite.mock, domain.EventGiftCardStatusChanged, id, `{"gift_card_id":101,"gifter_id":10,"giftee_id":20,"from":"pending","to":"rejected","actor_id":20}`)
+	expectEnqueueWebhookDeliveries(suite.mock, 1, domain.EventGiftCardStatusChanged, id, `{"gift_card_id":101,"gifter_id":10,"giftee_id":20,"from":"pending","to":"rejected","actor_id":20}`, []uint{10, 20}, 3, 4)
+	suite.mock.ExpectExec("^UPDATE wallets SET balance = balance \\+ \\?, held = held - \\?").
+		WithArgs(int64(10000), int64(10000), uint(10), "USD", int64(10000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectLedgerTransfer(suite.mock, id, domain.LTTRelease, domain.WalletHeldAccount(10, "USD"), domain.WalletAvailableAccount(10, "USD"), domain.NewMoney(10000, "USD"))
+	expectRecordAudit(suite.mock, domain.AuditGiftCardStatusChanged, actorID, domain.AuditTargetGiftCard, id,
+		`{"id":101,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":"pending","gifter_id":10,"giftee_id":20,"version":1}`, `{"id":101,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":"rejected","gifter_id":10,"giftee_id":20,"version":2}`)
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.UpdateStatus(context.Background(), id, nil, status, &actorID)
 
 	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_Expired_Success() {
+	require := suite.Require()
+	id := uint(101)
+	expiresAt := time.Now().Add(-time.Minute)
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id, domain.GCSPending, expiresAt)
+	suite.mock.ExpectExec("^UPDATE gift_cards SET status").
+		WithArgs(domain.GCSExpired, id, 1).
+		WillReturnResult(sqlmock.NewResult(101, 1))
+	suite.mock.ExpectExec("^INSERT INTO gift_card_status_history").
+		WithArgs(id, int(domain.GCSPending), int(domain.GCSExpired), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectRecordEvent(suite.mock, domain.EventGiftCardStatusChanged, id, `{"gift_card_id":101,"gifter_id":10,"giftee_id":20,"from":"pending","to":"expired","actor_id":null}`)
+	expectEnqueueWebhookDeliveries(suite.mock, 1, domain.EventGiftCardStatusChanged, id, `{"gift_card_id":101,"gifter_id":10,"giftee_id":20,"from":"pending","to":"expired","actor_id":null}`, []uint{10, 20})
+	suite.mock.ExpectExec("^UPDATE wallets SET balance = balance \\+ \\?, held = held - \\?").
+		WithArgs(int64(10000), int64(10000), uint(10), "USD", int64(10000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectLedgerTransfer(suite.mock, id, domain.LTTRelease, domain.WalletHeldAccount(10, "USD"), domain.WalletAvailableAccount(10, "USD"), domain.NewMoney(10000, "USD"))
+	expectRecordAudit(suite.mock, domain.AuditGiftCardStatusChanged, nil, domain.AuditTargetGiftCard, id, sqlmock.AnyArg(), sqlmock.AnyArg())
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.UpdateStatus(context.Background(), id, nil, domain.GCSExpired, nil)
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_Overdue_Failure() {
+	require := suite.Require()
+	id := uint(101)
+	actorID := uint(20)
+	expiresAt := time.Now().Add(-time.Minute)
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id, domain.GCSPending, expiresAt)
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.UpdateStatus(context.Background(), id, nil, domain.GCSAccepted, &actorID)
+
+	require.ErrorIs(err, domain.ErrGiftCardExpired)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_ExpireNotOverdue_Failure() {
+	require := suite.Require()
+	id := uint(101)
+	expiresAt := time.Now().Add(time.Hour)
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id, domain.GCSPending, expiresAt)
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.UpdateStatus(context.Background(), id, nil, domain.GCSExpired, nil)
+
+	require.ErrorIs(err, domain.ErrGiftCardNotOverdue)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_InvalidTransition_Failure() {
+	require := suite.Require()
+	id := uint(101)
+	actorID := uint(20)
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id, domain.GCSAccepted, nil)
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.UpdateStatus(context.Background(), id, nil, domain.GCSRejected, &actorID)
+
+	var transitionErr *domain.InvalidTransitionError
+	require.ErrorAs(err, &transitionErr)
+	require.Equal(domain.GCSAccepted, transitionErr.From)
+	require.Equal(domain.GCSRejected, transitionErr.To)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_NotFound_Failure() {
+	require := suite.Require()
+	id := uint(101)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT .+ FROM gift_cards").
+		WithArgs(id).
+		WillReturnError(sql.ErrNoRows)
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.UpdateStatus(context.Background(), id, nil, domain.GCSAccepted, nil)
+
+	require.ErrorIs(err, domain.ErrGiftCardNotFound)
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_VersionMismatch_Failure() {
+	require := suite.Require()
+	id := uint(101)
+	actorID := uint(20)
+	version := uint(2)
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id, domain.GCSPending, nil)
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.UpdateStatus(context.Background(), id, &version, domain.GCSAccepted, &actorID)
+
+	require.ErrorIs(err, domain.ErrGiftCardVersionMismatch)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_VersionSwap_Failure() {
+	require := suite.Require()
+	id := uint(101)
+	actorID := uint(20)
+	version := uint(1)
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id, domain.GCSPending, nil)
+	suite.mock.ExpectExec("^UPDATE gift_cards SET status").
+		WithArgs(domain.GCSAccepted, id, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.UpdateStatus(context.Background(), id, &version, domain.GCSAccepted, &actorID)
+
+	require.ErrorIs(err, domain.ErrGiftCardVersionMismatch)
+	require.NoError(suite.mock.ExpectationsWereMet())
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_DBerror_Failure() {
@@ -164,13 +430,178 @@ func (suite *GiftCardRepositoryTestSuite) TestUpdateStatus_DBerror_Failure() {
 	status := domain.GCSAccepted
 	expectedError := errors.New("something went wrong")
 
-	suite.mock.ExpectExec("^UPDATE gift_cards SET status").
-		WithArgs(status, id).
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id, domain.GCSPending, nil)
+	suite.mock.ExpectExec("^UPDATE gift_cards SET status = \\?, version = version \\+ 1, updated_at = NOW\\(\\) WHERE id = \\? AND version = \\?$").
+		WithArgs(status, id, 1).
+		WillReturnError(expectedError)
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.UpdateStatus(context.Background(), id, nil, status, nil)
+
+	require.Equal(expectedError, err)
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestFindOverdueGiftCardIDs_Success() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(7)
+	suite.mock.ExpectQuery("^SELECT id FROM gift_cards WHERE status = \\? AND expires_at <= \\?").
+		WithArgs(int(domain.GCSPending), now, 100).
+		WillReturnRows(rows)
+
+	ids, err := suite.repo.FindOverdueGiftCardIDs(context.Background(), now, 100)
+
+	require.NoError(err)
+	require.Equal([]uint{3, 7}, ids)
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestFindOverdueGiftCardIDs_DBError_Failure() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedError := errors.New("something went wrong")
+
+	suite.mock.ExpectQuery("^SELECT id FROM gift_cards").
+		WithArgs(int(domain.GCSPending), now, 100).
 		WillReturnError(expectedError)
 
-	err := suite.repo.UpdateStatus(id, status)
+	ids, err := suite.repo.FindOverdueGiftCardIDs(context.Background(), now, 100)
 
 	require.Equal(expectedError, err)
+	require.Empty(ids)
+}
+
+func (suite *GiftCardRepositoryTestSuite) expectLockGiftCardByCode(code string, status domain.GiftCardStatus, remainingAmount int64) {
+	rows := sqlmock.NewRows([]string{"id", "code", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "status", "expires_at", "version"}).
+		AddRow(101, code, 10, 20, 10000, remainingAmount, "USD", int(status), nil, 1)
+	suite.mock.ExpectQuery("^SELECT .+ FROM gift_cards WHERE code = \\? FOR UPDATE$").
+		WithArgs(code).
+		WillReturnRows(rows)
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestRedeem_Success() {
+	require := suite.Require()
+	code := "0123456789ABCDEZ"
+	redeemerID := uint(30)
+	amount := domain.NewMoney(2500, "USD")
+	expectedResult := &domain.GiftCardRedemption{
+		ID:              5,
+		GiftCardID:      101,
+		RedeemerID:      redeemerID,
+		Amount:          amount,
+		RemainingAmount: domain.NewMoney(5000, "USD"),
+	}
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCardByCode(code, domain.GCSAccepted, 7500)
+	suite.mock.ExpectExec("^UPDATE gift_cards SET remaining_amount = \\?, version = version \\+ 1").
+		WithArgs(int64(5000), uint(101)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec("^INSERT INTO wallets").
+		WithArgs(redeemerID, "USD", amount.Amount).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	expectLedgerTransfer(suite.mock, uint(101), domain.LTTRedemption, domain.GiftCardAccount(101, "USD"), domain.WalletAvailableAccount(redeemerID, "USD"), amount)
+	suite.mock.ExpectExec("^INSERT INTO gift_card_redemptions").
+		WithArgs(uint(101), redeemerID, amount.Amount, int64(5000), "USD").
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	expectRecordEvent(suite.mock, domain.EventGiftCardRedeemed, uint(101), `{"gift_card_id":101,"redemption_id":5,"redeemer_id":30,"amount":"25.00","remaining_amount":"50.00","currency":"USD"}`)
+	expectRecordAudit(suite.mock, domain.AuditGiftCardRedeemed, redeemerID, domain.AuditTargetGiftCard, uint(101),
+		`{"id":101,"amount":"100.00","remaining_amount":"75.00","currency":"USD","status":"accepted","gifter_id":10,"giftee_id":20,"version":1}`,
+		`{"id":101,"amount":"100.00","remaining_amount":"50.00","currency":"USD","status":"accepted","gifter_id":10,"giftee_id":20,"version":2}`)
+	suite.mock.ExpectCommit()
+
+	redemption, err := suite.repo.Redeem(context.Background(), code, redeemerID, amount)
+
+	require.NoError(err)
+	require.Equal(expectedResult, redemption)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestRedeem_NotFound_Failure() {
+	require := suite.Require()
+	code := "0123456789ABCDEZ"
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT .+ FROM gift_cards WHERE code = \\? FOR UPDATE$").
+		WithArgs(code).
+		WillReturnError(sql.ErrNoRows)
+	suite.mock.ExpectRollback()
+
+	redemption, err := suite.repo.Redeem(context.Background(), code, 30, domain.NewMoney(2500, "USD"))
+
+	require.ErrorIs(err, domain.ErrGiftCardNotFound)
+	require.Nil(redemption)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestRedeem_NotAccepted_Failure() {
+	require := suite.Require()
+	code := "0123456789ABCDEZ"
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCardByCode(code, domain.GCSPending, 10000)
+	suite.mock.ExpectRollback()
+
+	redemption, err := suite.repo.Redeem(context.Background(), code, 30, domain.NewMoney(2500, "USD"))
+
+	require.ErrorIs(err, domain.ErrGiftCardNotRedeemable)
+	require.Nil(redemption)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestRedeem_ExceedsBalance_Failure() {
+	require := suite.Require()
+	code := "0123456789ABCDEZ"
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCardByCode(code, domain.GCSAccepted, 2000)
+	suite.mock.ExpectRollback()
+
+	redemption, err := suite.repo.Redeem(context.Background(), code, 30, domain.NewMoney(2500, "USD"))
+
+	require.ErrorIs(err, domain.ErrRedemptionExceedsBalance)
+	require.Nil(redemption)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestFindStatusHistory_Success() {
+	require := suite.Require()
+	id := uint(101)
+	actorID := uint(20)
+	pending := domain.GCSPending
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedResult := []domain.GiftCardStatusChange{
+		{ID: 1, GiftCardID: id, To: domain.GCSPending, ActorID: &actorID, CreatedAt: createdAt},
+		{ID: 2, GiftCardID: id, From: &pending, To: domain.GCSExpired, CreatedAt: createdAt},
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "gift_card_id", "from_status", "to_status", "actor_id", "created_at"}).
+		AddRow(1, id, nil, int(domain.GCSPending), actorID, createdAt).
+		AddRow(2, id, int(domain.GCSPending), int(domain.GCSExpired), nil, createdAt)
+	suite.mock.ExpectQuery("^SELECT .+ FROM gift_card_status_history WHERE gift_card_id = \\? ORDER BY id$").
+		WithArgs(id).
+		WillReturnRows(rows)
+
+	history, err := suite.repo.FindStatusHistory(context.Background(), id)
+
+	require.NoError(err)
+	require.Equal(expectedResult, history)
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestFindStatusHistory_DBError_Failure() {
+	require := suite.Require()
+	id := uint(101)
+	expectedError := errors.New("something went wrong")
+
+	suite.mock.ExpectQuery("^SELECT .+ FROM gift_card_status_history").
+		WithArgs(id).
+		WillReturnError(expectedError)
+
+	history, err := suite.repo.FindStatusHistory(context.Background(), id)
+
+	require.Equal(expectedError, err)
+	require.Empty(history)
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestFindReceivedGiftCardsByUserID_FindDBError_Failure() {
@@ -180,14 +611,13 @@ func (suite *GiftCardRepositoryTestSuite) TestFindReceivedGiftCardsByUserID_Find
 	expectedError := errors.New("something went wrong")
 
 	suite.mock.ExpectQuery("^SELECT .* FROM gift_cards").
-		WithArgs(id).
+		WithArgs(id, int(status)).
 		WillReturnError(expectedError)
 
-	giftCards, total, err := suite.repo.FindReceivedGiftCardsByUserID(id, &status, 10, 1)
+	page, err := suite.repo.FindReceivedGiftCardsByUserID(context.Background(), id, GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1, WithTotal: true})
 
 	require.Equal(expectedError, err)
-	require.Equal(total, 0)
-	require.Empty(giftCards)
+	require.Empty(page)
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestFindReceivedGiftCardsByUserID_CountDBError_Failure() {
@@ -195,20 +625,21 @@ func (suite *GiftCardRepositoryTestSuite) TestFindReceivedGiftCardsByUserID_Coun
 	id := uint(102)
 	status := domain.GCSAccepted
 	expectedError := errors.New("something went wrong")
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	rows := sqlmock.NewRows([]string{"id", "status", "sender_id", "receiver_id", "amount"}).AddRow(10, 1, 10, 20, 100)
+	rows := sqlmock.NewRows([]string{"id", "code", "status", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "expires_at", "version", "created_at"}).
+		AddRow(10, "0123456789ABCDEZ", 1, 10, 20, 10000, 10000, "USD", nil, 1, createdAt)
 	suite.mock.ExpectQuery("^SELECT .* FROM gift_cards").
-		WithArgs(id).
+		WithArgs(id, int(status)).
 		WillReturnRows(rows)
-	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards WHERE receiver_id = \? AND status = 0$`).
-		WithArgs(id).
+	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards WHERE receiver_id = \? AND status IN \(\?\)$`).
+		WithArgs(id, int(status)).
 		WillReturnError(expectedError)
 
-	giftCards, total, err := suite.repo.FindReceivedGiftCardsByUserID(id, &status, 10, 1)
+	page, err := suite.repo.FindReceivedGiftCardsByUserID(context.Background(), id, GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1, WithTotal: true})
 
 	require.EqualError(expectedError, err.Error())
-	require.Zero(total)
-	require.Empty(giftCards)
+	require.Empty(page)
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestFindReceivedGiftCardsByUserID_Success() {
@@ -216,54 +647,67 @@ func (suite *GiftCardRepositoryTestSuite) TestFindReceivedGiftCardsByUserID_Succ
 	id := uint(102)
 	status := domain.GCSAccepted
 	expectedTotal := 1
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	expectedResult := []domain.GiftCard{{
-		ID:       10,
-		GifterID: 10,
-		GifteeID: 20,
-		Amount:   100,
-		Status:   1,
+		ID:              10,
+		GifterID:        10,
+		GifteeID:        20,
+		Code:            "0123456789ABCDEZ",
+		Amount:          domain.NewMoney(10000, "USD"),
+		RemainingAmount: domain.NewMoney(10000, "USD"),
+		Status:          1,
+		Version:         1,
+		CreationDate:    createdAt,
 	}}
 
-	rows := sqlmock.NewRows([]string{"id", "status", "sender_id", "receiver_id", "amount"}).AddRow(10, 1, 10, 20, 100)
+	rows := sqlmock.NewRows([]string{"id", "code", "status", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "expires_at", "version", "created_at"}).
+		AddRow(10, "0123456789ABCDEZ", 1, 10, 20, 10000, 10000, "USD", nil, 1, createdAt)
 	suite.mock.ExpectQuery("^SELECT .* FROM gift_cards").
-		WithArgs(id).
+		WithArgs(id, int(status)).
 		WillReturnRows(rows)
-	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards WHERE receiver_id = \? AND status = 0$`).
-		WithArgs(id).
+	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards WHERE receiver_id = \? AND status IN \(\?\)$`).
+		WithArgs(id, int(status)).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(expectedTotal))
 
-	giftCards, total, err := suite.repo.FindReceivedGiftCardsByUserID(id, &status, 10, 1)
+	page, err := suite.repo.FindReceivedGiftCardsByUserID(context.Background(), id, GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1, WithTotal: true})
 
 	require.NoError(err)
-	require.Equal(expectedTotal, total)
-	require.Equal(expectedResult, giftCards)
+	require.Equal(&expectedTotal, page.Total)
+	require.Equal(expectedResult, page.GiftCards)
+	require.Empty(page.NextCursor)
+	require.Empty(page.PrevCursor)
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestFindReceivedGiftCardsByUserID_WithoutStatus_Success() {
 	require := suite.Require()
 	id := uint(102)
-	expectedTotal := 1
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	expectedResult := []domain.GiftCard{{
-		ID:       10,
-		GifterID: 10,
-		GifteeID: 20,
-		Amount:   100,
-		Status:   1,
+		ID:              10,
+		GifterID:        10,
+		GifteeID:        20,
+		Code:            "0123456789ABCDEZ",
+		Amount:          domain.NewMoney(10000, "USD"),
+		RemainingAmount: domain.NewMoney(10000, "USD"),
+		Status:          1,
+		Version:         1,
+		CreationDate:    createdAt,
 	}}
 
-	rows := sqlmock.NewRows([]string{"id", "status", "sender_id", "receiver_id", "amount"}).AddRow(10, 1, 10, 20, 100)
+	rows := sqlmock.NewRows([]string{"id", "code", "status", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "expires_at", "version", "created_at"}).
+		AddRow(10, "0123456789ABCDEZ", 1, 10, 20, 10000, 10000, "USD", nil, 1, createdAt)
 	suite.mock.ExpectQuery("^SELECT .* FROM gift_cards").
 		WithArgs(id).
 		WillReturnRows(rows)
-	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards WHERE receiver_id = \?$`).
-		WithArgs(id).
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(expectedTotal))
 
-	giftCards, total, err := suite.repo.FindReceivedGiftCardsByUserID(id, nil, 10, 1)
+	page, err := suite.repo.FindReceivedGiftCardsByUserID(context.Background(), id, GiftCardQuery{PageSize: 10, PageNumber: 1})
 
 	require.NoError(err)
-	require.Equal(expectedTotal, total)
-	require.Equal(expectedResult, giftCards)
+	require.Nil(page.Total)
+	require.Equal(expectedResult, page.GiftCards)
+	require.Empty(page.NextCursor)
+	require.Empty(page.PrevCursor)
+	require.NoError(suite.mock.ExpectationsWereMet())
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestFindSentGiftCardsByUserID_FindDBError_Failure() {
@@ -273,14 +717,13 @@ func (suite *GiftCardRepositoryTestSuite) TestFindSentGiftCardsByUserID_FindDBEr
 	expectedError := errors.New("something went wrong")
 
 	suite.mock.ExpectQuery("^SELECT .* FROM gift_cards").
-		WithArgs(id).
+		WithArgs(id, int(status)).
 		WillReturnError(expectedError)
 
-	giftCards, total, err := suite.repo.FindSentGiftCardsByUserID(id, &status, 10, 1)
+	page, err := suite.repo.FindSentGiftCardsByUserID(context.Background(), id, GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1, WithTotal: true})
 
 	require.Equal(expectedError, err)
-	require.Equal(total, 0)
-	require.Empty(giftCards)
+	require.Empty(page)
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestFindSentGiftCardsByUserID_CountDBError_Failure() {
@@ -288,20 +731,21 @@ func (suite *GiftCardRepositoryTestSuite) TestFindSentGiftCardsByUserID_CountDBE
 	id := uint(102)
 	status := domain.GCSAccepted
 	expectedError := errors.New("something went wrong")
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	rows := sqlmock.NewRows([]string{"id", "status", "sender_id", "receiver_id", "amount"}).AddRow(10, 1, 10, 20, 100)
+	rows := sqlmock.NewRows([]string{"id", "code", "status", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "expires_at", "version", "created_at"}).
+		AddRow(10, "0123456789ABCDEZ", 1, 10, 20, 10000, 10000, "USD", nil, 1, createdAt)
 	suite.mock.ExpectQuery("^SELECT .* FROM gift_cards").
-		WithArgs(id).
+		WithArgs(id, int(status)).
 		WillReturnRows(rows)
-	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards WHERE sender_id = \? AND status = 0$`).
-		WithArgs(id).
+	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards WHERE sender_id = \? AND status IN \(\?\)$`).
+		WithArgs(id, int(status)).
 		WillReturnError(expectedError)
 
-	giftCards, total, err := suite.repo.FindSentGiftCardsByUserID(id, &status, 10, 1)
+	page, err := suite.repo.FindSentGiftCardsByUserID(context.Background(), id, GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1, WithTotal: true})
 
 	require.EqualError(expectedError, err.Error())
-	require.Zero(total)
-	require.Empty(giftCards)
+	require.Empty(page)
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestFindSentGiftCardsByUserID_Success() {
@@ -309,54 +753,153 @@ func (suite *GiftCardRepositoryTestSuite) TestFindSentGiftCardsByUserID_Success(
 	id := uint(102)
 	status := domain.GCSAccepted
 	expectedTotal := 1
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	expectedResult := []domain.GiftCard{{
-		ID:       10,
-		GifterID: 10,
-		GifteeID: 20,
-		Amount:   100,
-		Status:   1,
+		ID:              10,
+		GifterID:        10,
+		GifteeID:        20,
+		Code:            "0123456789ABCDEZ",
+		Amount:          domain.NewMoney(10000, "USD"),
+		RemainingAmount: domain.NewMoney(10000, "USD"),
+		Status:          1,
+		Version:         1,
+		CreationDate:    createdAt,
 	}}
 
-	rows := sqlmock.NewRows([]string{"id", "status", "sender_id", "receiver_id", "amount"}).AddRow(10, 1, 10, 20, 100)
+	rows := sqlmock.NewRows([]string{"id", "code", "status", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "expires_at", "version", "created_at"}).
+		AddRow(10, "0123456789ABCDEZ", 1, 10, 20, 10000, 10000, "USD", nil, 1, createdAt)
 	suite.mock.ExpectQuery("^SELECT .* FROM gift_cards").
-		WithArgs(id).
+		WithArgs(id, int(status)).
 		WillReturnRows(rows)
-	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards WHERE sender_id = \? AND status = 0$`).
-		WithArgs(id).
+	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards WHERE sender_id = \? AND status IN \(\?\)$`).
+		WithArgs(id, int(status)).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(expectedTotal))
 
-	giftCards, total, err := suite.repo.FindSentGiftCardsByUserID(id, &status, 10, 1)
+	page, err := suite.repo.FindSentGiftCardsByUserID(context.Background(), id, GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1, WithTotal: true})
 
 	require.NoError(err)
-	require.Equal(expectedTotal, total)
-	require.Equal(expectedResult, giftCards)
+	require.Equal(&expectedTotal, page.Total)
+	require.Equal(expectedResult, page.GiftCards)
+	require.Empty(page.NextCursor)
+	require.Empty(page.PrevCursor)
 }
 
 func (suite *GiftCardRepositoryTestSuite) TestFindSentGiftCardsByUserID_WithoutStatus_Success() {
 	require := suite.Require()
 	id := uint(102)
-	expectedTotal := 1
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	expectedResult := []domain.GiftCard{{
-		ID:       10,
-		GifterID: 10,
-		GifteeID: 20,
-		Amount:   100,
-		Status:   1,
+		ID:              10,
+		GifterID:        10,
+		GifteeID:        20,
+		Code:            "0123456789ABCDEZ",
+		Amount:          domain.NewMoney(10000, "USD"),
+		RemainingAmount: domain.NewMoney(10000, "USD"),
+		Status:          1,
+		Version:         1,
+		CreationDate:    createdAt,
 	}}
 
-	rows := sqlmock.NewRows([]string{"id", "status", "sender_id", "receiver_id", "amount"}).AddRow(10, 1, 10, 20, 100)
+	rows := sqlmock.NewRows([]string{"id", "code", "status", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "expires_at", "version", "created_at"}).
+		AddRow(10, "0123456789ABCDEZ", 1, 10, 20, 10000, 10000, "USD", nil, 1, createdAt)
 	suite.mock.ExpectQuery("^SELECT .* FROM gift_cards").
 		WithArgs(id).
 		WillReturnRows(rows)
-	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards WHERE sender_id = \?$`).
-		WithArgs(id).
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(expectedTotal))
 
-	giftCards, total, err := suite.repo.FindSentGiftCardsByUserID(id, nil, 10, 1)
+	page, err := suite.repo.FindSentGiftCardsByUserID(context.Background(), id, GiftCardQuery{PageSize: 10, PageNumber: 1})
+
+	require.NoError(err)
+	require.Nil(page.Total)
+	require.Equal(expectedResult, page.GiftCards)
+	require.Empty(page.NextCursor)
+	require.Empty(page.PrevCursor)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestFindSentGiftCardsByUserID_Query_Success() {
+	require := suite.Require()
+	id := uint(102)
+	counterpartyID := uint(20)
+	createdFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdTo := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	minAmount := domain.NewMoney(1000, "EUR")
+	maxAmount := domain.NewMoney(5000, "EUR")
+	query := GiftCardQuery{
+		Statuses:       []domain.GiftCardStatus{domain.GCSAccepted, domain.GCSPending},
+		CreatedFrom:    &createdFrom,
+		CreatedTo:      &createdTo,
+		MinAmount:      &minAmount,
+		MaxAmount:      &maxAmount,
+		CounterpartyID: &counterpartyID,
+		SortBy:         GiftCardSortAmount,
+		Descending:     true,
+		PageSize:       5,
+		PageNumber:     3,
+		WithTotal:      true,
+	}
+	where := `WHERE sender_id = \? AND status IN \(\?, \?\) AND created_at >= \? AND created_at <= \? AND currency = \? AND amount >= \? AND amount <= \? AND receiver_id = \?`
+	args := []driver.Value{id, int(domain.GCSAccepted), int(domain.GCSPending), createdFrom, createdTo, "EUR", int64(1000), int64(5000), counterpartyID}
+
+	suite.mock.ExpectQuery(`^SELECT .* FROM gift_cards ` + where + ` ORDER BY amount DESC, id DESC LIMIT 6 OFFSET 10$`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "expires_at", "version", "created_at"}))
+	suite.mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM gift_cards ` + where + `$`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+
+	page, err := suite.repo.FindSentGiftCardsByUserID(context.Background(), id, query)
 
 	require.NoError(err)
-	require.Equal(expectedTotal, total)
-	require.Equal(expectedResult, giftCards)
+	require.Equal(10, *page.Total)
+	require.Empty(page.GiftCards)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestFindSentGiftCardsByUserID_Cursor_Success() {
+	require := suite.Require()
+	id := uint(102)
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "code", "status", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "expires_at", "version", "created_at"}
+	cursor := newGiftCardCursor(GiftCardQuery{}, domain.GiftCard{ID: 10, CreationDate: createdAt}, false)
+
+	suite.mock.ExpectQuery(`^SELECT .* FROM gift_cards WHERE sender_id = \? AND \(created_at > \? OR \(created_at = \? AND id > \?\)\) ORDER BY created_at ASC, id ASC LIMIT 3$`).
+		WithArgs(id, createdAt, createdAt, uint(10)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(11, "0123456789ABCDEZ", 1, 102, 20, 10000, 10000, "USD", nil, 1, createdAt).
+			AddRow(12, "0123456789ABCDEY", 1, 102, 20, 10000, 10000, "USD", nil, 1, createdAt).
+			AddRow(13, "0123456789ABCDEX", 1, 102, 20, 10000, 10000, "USD", nil, 1, createdAt))
+
+	page, err := suite.repo.FindSentGiftCardsByUserID(context.Background(), id, GiftCardQuery{PageSize: 2, PageNumber: 1, Cursor: cursor})
+
+	require.NoError(err)
+	require.Nil(page.Total)
+	require.Len(page.GiftCards, 2)
+	require.Equal(newGiftCardCursor(GiftCardQuery{}, page.GiftCards[1], false), page.NextCursor)
+	require.Equal(newGiftCardCursor(GiftCardQuery{}, page.GiftCards[0], true), page.PrevCursor)
+
+	backward := newGiftCardCursor(GiftCardQuery{}, page.GiftCards[0], true)
+	suite.mock.ExpectQuery(`^SELECT .* FROM gift_cards WHERE sender_id = \? AND \(created_at < \? OR \(created_at = \? AND id < \?\)\) ORDER BY created_at DESC, id DESC LIMIT 3$`).
+		WithArgs(id, createdAt, createdAt, uint(11)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(10, "0123456789ABCDEW", 1, 102, 20, 10000, 10000, "USD", nil, 1, createdAt))
+
+	page, err = suite.repo.FindSentGiftCardsByUserID(context.Background(), id, GiftCardQuery{PageSize: 2, PageNumber: 1, Cursor: backward})
+
+	require.NoError(err)
+	require.Equal([]uint{10}, giftCardIDs(page.GiftCards))
+	require.NotEmpty(page.NextCursor)
+	require.Empty(page.PrevCursor)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *GiftCardRepositoryTestSuite) TestFindSentGiftCardsByUserID_InvalidCursor_Failure() {
+	require := suite.Require()
+
+	page, err := suite.repo.FindSentGiftCardsByUserID(context.Background(), 102, GiftCardQuery{PageSize: 2, PageNumber: 1, Cursor: "!"})
+
+	require.ErrorIs(err, errInvalidGiftCardCursor)
+	require.Empty(page)
+	require.NoError(suite.mock.ExpectationsWereMet())
 }
 
 func TestGiftCardRepository(t *testing.T) {
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, reservation domain.IdempotencyKey, lease time.Duration) (*domain.IdempotencyKey, error)
+	Complete(ctx context.Context, reservation domain.IdempotencyKey) error
+	Release(ctx context.Context, reservation domain.IdempotencyKey) error
+}
+
+type IdempotencyKeyEntity struct {
+	ID              uint
+	UserID          uint
+	Key             string
+	RequestHash     string
+	Token           string
+	StatusCode      sql.NullInt32
+	ResponseHeaders sql.NullString
+	ResponseBody    []byte
+	LockedAt        time.Time
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+}
+
+func (k IdempotencyKeyEntity) ToAggregate() (domain.IdempotencyKey, error) {
+	var headers map[string][]string
+	if k.ResponseHeaders.Valid {
+		if err := json.Unmarshal([]byte(k.ResponseHeaders.String), &headers); err != nil {
+			return domain.IdempotencyKey{}, err
+		}
+	}
+
+	return domain.IdempotencyKey{
+		Key:             k.Key,
+		UserID:          k.UserID,
+		RequestHash:     k.RequestHash,
+		Token:           k.Token,
+		StatusCode:      int(k.StatusCode.Int32),
+		ResponseHeaders: headers,
+		ResponseBody:    k.ResponseBody,
+		LockedAt:        k.LockedAt,
+		ExpiresAt:       k.ExpiresAt,
+		CreatedAt:       k.CreatedAt,
+	}, nil
+}
+
+type idempotencyRepository struct {
+	db sqlDB
+}
+
+func NewIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: newSQLDB(db)}
+}
+
+// Reserve stores the reservation unless the key already exists and cannot be
+// taken over yet, see domain.IdempotencyKey.CanBeTakenOver. It returns the
+// key as stored afterwards, which is the reservation if it was reserved by
+// this call. The row is made sure to exist before it is locked, so that
+// concurrent requests with a new key are decided one after the other. Expired
+// keys of any user are dropped on the way.
+func (r *idempotencyRepository) Reserve(ctx context.Context, reservation domain.IdempotencyKey, lease time.Duration) (*domain.IdempotencyKey, error) {
+	var stored *domain.IdempotencyKey
+	err := withTx(ctx, r.db, func(tx sqlTx) error {
+		now := reservation.LockedAt
+		_, err := tx.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= ? AND NOT (user_id = ? AND idempotency_key = ?)", now, reservation.UserID, reservation.Key)
+		if err != nil {
+			return err
+		}
+
+		query := `INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, token, locked_at, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE id = id`
+		if tx.Dialect() != database.MySQL {
+			query = `INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, token, locked_at, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id, idempotency_key) DO NOTHING`
+		}
+
+		_, err = tx.ExecContext(ctx, query, reservation.UserID, reservation.Key, reservation.RequestHash, reservation.Token, now, reservation.ExpiresAt, reservation.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		stored, err = findIdempotencyKey(ctx, tx, reservation.UserID, reservation.Key, " FOR UPDATE")
+		if err != nil {
+			return err
+		}
+
+		if stored.Token == reservation.Token || !stored.CanBeTakenOver(now, lease) {
+			return nil
+		}
+
+		query = `UPDATE idempotency_keys SET request_hash = ?, token = ?, status_code = NULL, response_headers = NULL, response_body = NULL,
+locked_at = ?, expires_at = ?, created_at = ? WHERE user_id = ? AND idempotency_key = ?`
+		_, err = tx.ExecContext(ctx, query, reservation.RequestHash, reservation.Token, now, reservation.ExpiresAt, reservation.CreatedAt, reservation.UserID, reservation.Key)
+		if err != nil {
+			return err
+		}
+
+		stored = &reservation
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// Complete stores the response of the reservation, unless another request
+// took the key over meanwhile.
+func (r *idempotencyRepository) Complete(ctx context.Context, reservation domain.IdempotencyKey) error {
+	headers, err := json.Marshal(reservation.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE idempotency_keys SET status_code = ?, response_headers = ?, response_body = ? WHERE user_id = ? AND idempotency_key = ? AND token = ?"
+	_, err = r.db.ExecContext(ctx, query, reservation.StatusCode, string(headers), reservation.ResponseBody, reservation.UserID, reservation.Key, reservation.Token)
+
+	return err
+}
+
+// Release removes a reservation whose request did not complete so that it
+// can be retried.
+func (r *idempotencyRepository) Release(ctx context.Context, reservation domain.IdempotencyKey) error {
+	query := "DELETE FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ? AND token = ? AND status_code IS NULL"
+	_, err := r.db.ExecContext(ctx, query, reservation.UserID, reservation.Key, reservation.Token)
+
+	return err
+}
+
+// findIdempotencyKey returns the key of the user. suffix is appended to the
+// query, to lock the row.
+func findIdempotencyKey(ctx context.Context, db executor, userID uint, key, suffix string) (*domain.IdempotencyKey, error) {
+	var e IdempotencyKeyEntity
+	query := `SELECT id, user_id, idempotency_key, request_hash, token, status_code, response_headers, response_body, locked_at, expires_at, created_at
+FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ?` + suffix
+	err := db.
+		QueryRowContext(ctx, query, userID, key).
+		Scan(&e.ID, &e.UserID, &e.Key, &e.RequestHash, &e.Token, &e.StatusCode, &e.ResponseHeaders, &e.ResponseBody, &e.LockedAt, &e.ExpiresAt, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	idempotencyKey, err := e.ToAggregate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &idempotencyKey, nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+type IdempotencyRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *idempotencyRepository
+}
+
+func (suite *IdempotencyRepositoryTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.repo = &idempotencyRepository{
+		db: newSQLDB(suite.db),
+	}
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestNewIdempotencyRepository() {
+	require := suite.Require()
+
+	db, _, _ := sqlmock.New()
+	repo := NewIdempotencyRepository(db)
+
+	require.NotNil(repo)
+}
+
+var idempotencyKeyColumns = []string{"id", "user_id", "idempotency_key", "request_hash", "token", "status_code", "response_headers", "response_body", "locked_at", "expires_at", "created_at"}
+
+func newIdempotencyReservation(now time.Time) domain.IdempotencyKey {
+	return domain.IdempotencyKey{
+		Key:         "key",
+		UserID:      10,
+		RequestHash: "hash",
+		Token:       "token",
+		LockedAt:    now,
+		ExpiresAt:   now.Add(time.Hour),
+		CreatedAt:   now,
+	}
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReserve_NewKey_Success() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	reservation := newIdempotencyReservation(now)
+	rows := sqlmock.NewRows(idempotencyKeyColumns).
+		AddRow(1, 10, "key", "hash", "token", nil, nil, nil, now, now.Add(time.Hour), now)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^DELETE FROM idempotency_keys WHERE expires_at <= \\? AND NOT \\(user_id = \\? AND idempotency_key = \\?\\)$").
+		WithArgs(now, uint(10), "key").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^INSERT INTO idempotency_keys .+ ON DUPLICATE KEY UPDATE id = id$").
+		WithArgs(uint(10), "key", "hash", "token", now, now.Add(time.Hour), now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectQuery("^SELECT .+ FROM idempotency_keys WHERE user_id = \\? AND idempotency_key = \\? FOR UPDATE$").
+		WithArgs(uint(10), "key").
+		WillReturnRows(rows)
+	suite.mock.ExpectCommit()
+
+	stored, err := suite.repo.Reserve(context.Background(), reservation, time.Minute)
+
+	require.NoError(err)
+	require.Equal(&reservation, stored)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReserve_Postgres_Success() {
+	require := suite.Require()
+	suite.repo.db.dialect = database.Postgres
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	reservation := newIdempotencyReservation(now)
+	rows := sqlmock.NewRows(idempotencyKeyColumns).
+		AddRow(1, 10, "key", "hash", "token", nil, nil, nil, now, now.Add(time.Hour), now)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^DELETE FROM idempotency_keys WHERE expires_at <= \\$1 AND NOT \\(user_id = \\$2 AND idempotency_key = \\$3\\)$").
+		WithArgs(now, uint(10), "key").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^INSERT INTO idempotency_keys .+ VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7\\)\nON CONFLICT \\(user_id, idempotency_key\\) DO NOTHING$").
+		WithArgs(uint(10), "key", "hash", "token", now, now.Add(time.Hour), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery("^SELECT .+ FROM idempotency_keys WHERE user_id = \\$1 AND idempotency_key = \\$2 FOR UPDATE$").
+		WithArgs(uint(10), "key").
+		WillReturnRows(rows)
+	suite.mock.ExpectCommit()
+
+	stored, err := suite.repo.Reserve(context.Background(), reservation, time.Minute)
+
+	require.NoError(err)
+	require.Equal(&reservation, stored)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReserve_ExistingKey_Success() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := now.Add(-2 * time.Minute)
+	reservation := newIdempotencyReservation(now)
+	expectedResult := &domain.IdempotencyKey{
+		Key:             "key",
+		UserID:          10,
+		RequestHash:     "hash",
+		Token:           "other",
+		StatusCode:      201,
+		ResponseHeaders: map[string][]string{"Etag": {`"1"`}},
+		ResponseBody:    []byte(`{"id":1}`),
+		LockedAt:        createdAt,
+		ExpiresAt:       createdAt.Add(time.Hour),
+		CreatedAt:       createdAt,
+	}
+	rows := sqlmock.NewRows(idempotencyKeyColumns).
+		AddRow(1, 10, "key", "hash", "other", 201, `{"Etag":["\"1\""]}`, []byte(`{"id":1}`), createdAt, createdAt.Add(time.Hour), createdAt)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^DELETE FROM idempotency_keys").
+		WithArgs(now, uint(10), "key").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^INSERT INTO idempotency_keys").
+		WithArgs(uint(10), "key", "hash", "token", now, now.Add(time.Hour), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery("^SELECT .+ FROM idempotency_keys WHERE user_id = \\? AND idempotency_key = \\? FOR UPDATE$").
+		WithArgs(uint(10), "key").
+		WillReturnRows(rows)
+	suite.mock.ExpectCommit()
+
+	stored, err := suite.repo.Reserve(context.Background(), reservation, time.Minute)
+
+	require.NoError(err)
+	require.Equal(expectedResult, stored)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReserve_TakeOver_Success() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lockedAt := now.Add(-2 * time.Minute)
+	reservation := newIdempotencyReservation(now)
+	rows := sqlmock.NewRows(idempotencyKeyColumns).
+		AddRow(1, 10, "key", "other", "other", nil, nil, nil, lockedAt, lockedAt.Add(time.Hour), lockedAt)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^DELETE FROM idempotency_keys").
+		WithArgs(now, uint(10), "key").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^INSERT INTO idempotency_keys").
+		WithArgs(uint(10), "key", "hash", "token", now, now.Add(time.Hour), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery("^SELECT .+ FROM idempotency_keys WHERE user_id = \\? AND idempotency_key = \\? FOR UPDATE$").
+		WithArgs(uint(10), "key").
+		WillReturnRows(rows)
+	suite.mock.ExpectExec("^UPDATE idempotency_keys SET request_hash = \\?, token = \\?, status_code = NULL, response_headers = NULL, response_body = NULL,\nlocked_at = \\?, expires_at = \\?, created_at = \\? WHERE user_id = \\? AND idempotency_key = \\?$").
+		WithArgs("hash", "token", now, now.Add(time.Hour), now, uint(10), "key").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	stored, err := suite.repo.Reserve(context.Background(), reservation, time.Minute)
+
+	require.NoError(err)
+	require.Equal(&reservation, stored)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReserve_DBError_Failure() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedError := errors.New("database failure")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^DELETE FROM idempotency_keys").
+		WithArgs(now, uint(10), "key").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^INSERT INTO idempotency_keys").
+		WithArgs(uint(10), "key", "hash", "token", now, now.Add(time.Hour), now).
+		WillReturnError(expectedError)
+	suite.mock.ExpectRollback()
+
+	stored, err := suite.repo.Reserve(context.Background(), newIdempotencyReservation(now), time.Minute)
+
+	require.EqualError(err, expectedError.Error())
+	require.Nil(stored)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestComplete_Success() {
+	require := suite.Require()
+	reservation := newIdempotencyReservation(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	reservation.StatusCode = 201
+	reservation.ResponseHeaders = map[string][]string{"Etag": {`"1"`}}
+	reservation.ResponseBody = []byte(`{"id":1}`)
+
+	suite.mock.ExpectExec("^UPDATE idempotency_keys SET status_code = \\?, response_headers = \\?, response_body = \\? WHERE user_id = \\? AND idempotency_key = \\? AND token = \\?$").
+		WithArgs(201, `{"Etag":["\"1\""]}`, []byte(`{"id":1}`), uint(10), "key", "token").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.Complete(context.Background(), reservation)
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestRelease_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectExec("^DELETE FROM idempotency_keys WHERE user_id = \\? AND idempotency_key = \\? AND token = \\? AND status_code IS NULL$").
+		WithArgs(uint(10), "key", "token").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.Release(context.Background(), newIdempotencyReservation(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository(t *testing.T) {
+	suite.Run(t, new(IdempotencyRepositoryTestSuite))
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type LedgerRepository interface {
+	Snapshot(ctx context.Context) (*domain.LedgerSnapshot, error)
+}
+
+type ledgerRepository struct {
+	db sqlDB
+}
+
+func NewLedgerRepository(db *sql.DB) LedgerRepository {
+	return &ledgerRepository{db: newSQLDB(db)}
+}
+
+// Snapshot reads the ledger balances, the cached wallet balances and the
+// remaining amounts of the accepted gift cards in one read-only transaction so
+// that they all reflect the same point in time.
+func (r *ledgerRepository) Snapshot(ctx context.Context) (*domain.LedgerSnapshot, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	snapshot := &domain.LedgerSnapshot{AccountBalances: map[string]domain.Money{}}
+
+	balanceQuery := "SELECT account, currency, SUM(CASE WHEN direction = 1 THEN amount ELSE -amount END) FROM ledger_entries GROUP BY account, currency"
+	rows, err := tx.QueryContext(ctx, balanceQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var account, currency string
+		var balance int64
+		if err := rows.Scan(&account, &currency, &balance); err != nil {
+			return nil, err
+		}
+
+		snapshot.AccountBalances[account] = domain.NewMoney(balance, currency)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	walletRows, err := tx.QueryContext(ctx, "SELECT id, user_id, currency, balance, held, updated_at FROM wallets")
+	if err != nil {
+		return nil, err
+	}
+
+	defer walletRows.Close()
+	for walletRows.Next() {
+		var e WalletEntity
+		if err := walletRows.Scan(&e.ID, &e.UserID, &e.Currency, &e.Balance, &e.Held, &e.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		snapshot.Wallets = append(snapshot.Wallets, e.ToAggregate())
+	}
+
+	if err := walletRows.Err(); err != nil {
+		return nil, err
+	}
+
+	giftCardRows, err := tx.QueryContext(ctx, "SELECT id, remaining_amount, currency FROM gift_cards WHERE status = ?", int(domain.GCSAccepted))
+	if err != nil {
+		return nil, err
+	}
+
+	defer giftCardRows.Close()
+	for giftCardRows.Next() {
+		var id uint
+		var remainingAmount int64
+		var currency string
+		if err := giftCardRows.Scan(&id, &remainingAmount, &currency); err != nil {
+			return nil, err
+		}
+
+		snapshot.GiftCards = append(snapshot.GiftCards, domain.GiftCard{ID: id, RemainingAmount: domain.NewMoney(remainingAmount, currency)})
+	}
+
+	if err := giftCardRows.Err(); err != nil {
+		return nil, err
+	}
+
+	unbalancedQuery := `SELECT transaction_id FROM ledger_entries GROUP BY transaction_id
+HAVING SUM(CASE WHEN direction = 1 THEN amount ELSE -amount END) <> 0 OR COUNT(DISTINCT currency) > 1`
+	transactionRows, err := tx.QueryContext(ctx, unbalancedQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	defer transactionRows.Close()
+	for transactionRows.Next() {
+		var id uint
+		if err := transactionRows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		snapshot.UnbalancedTransactionIDs = append(snapshot.UnbalancedTransactionIDs, id)
+	}
+
+	if err := transactionRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type LedgerRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *ledgerRepository
+}
+
+func (suite *LedgerRepositoryTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.repo = &ledgerRepository{
+		db: newSQLDB(suite.db),
+	}
+}
+
+func (suite *LedgerRepositoryTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *LedgerRepositoryTestSuite) TestNewLedgerRepository() {
+	require := suite.Require()
+
+	db, _, _ := sqlmock.New()
+	repo := NewLedgerRepository(db)
+
+	require.NotNil(repo)
+}
+
+func (suite *LedgerRepositoryTestSuite) TestSnapshot_Success() {
+	require := suite.Require()
+	updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedResult := &domain.LedgerSnapshot{
+		AccountBalances: map[string]domain.Money{
+			domain.ExternalDepositsAccount("USD"):   domain.NewMoney(-100000, "USD"),
+			domain.WalletAvailableAccount(1, "USD"): domain.NewMoney(90000, "USD"),
+			domain.WalletHeldAccount(1, "USD"):      domain.NewMoney(5000, "USD"),
+			domain.GiftCardAccount(101, "USD"):      domain.NewMoney(5000, "USD"),
+		},
+		Wallets: []domain.Wallet{{
+			ID:        1,
+			UserID:    1,
+			Balance:   domain.NewMoney(90000, "USD"),
+			Held:      domain.NewMoney(5000, "USD"),
+			UpdatedAt: updatedAt,
+		}},
+		GiftCards:                []domain.GiftCard{{ID: 101, RemainingAmount: domain.NewMoney(5000, "USD")}},
+		UnbalancedTransactionIDs: []uint{3},
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT account, currency, SUM(.+) FROM ledger_entries GROUP BY account, currency$").
+		WillReturnRows(sqlmock.NewRows([]string{"account", "currency", "balance"}).
+			AddRow(domain.ExternalDepositsAccount("USD"), "USD", -100000).
+			AddRow(domain.WalletAvailableAccount(1, "USD"), "USD", 90000).
+			AddRow(domain.WalletHeldAccount(1, "USD"), "USD", 5000).
+			AddRow(domain.GiftCardAccount(101, "USD"), "USD", 5000))
+	suite.mock.ExpectQuery("^SELECT .+ FROM wallets$").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "currency", "balance", "held", "updated_at"}).
+			AddRow(1, 1, "USD", 90000, 5000, updatedAt))
+	suite.mock.ExpectQuery("^SELECT id, remaining_amount, currency FROM gift_cards WHERE status = \\?$").
+		WithArgs(int(domain.GCSAccepted)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "remaining_amount", "currency"}).
+			AddRow(101, 5000, "USD"))
+	suite.mock.ExpectQuery("^SELECT transaction_id FROM ledger_entries GROUP BY transaction_id HAVING").
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id"}).AddRow(3))
+	suite.mock.ExpectRollback()
+
+	result, err := suite.repo.Snapshot(context.Background())
+
+	require.NoError(err)
+	require.Equal(expectedResult, result)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LedgerRepositoryTestSuite) TestSnapshot_DBError_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("database failure")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT account").
+		WillReturnError(expectedError)
+	suite.mock.ExpectRollback()
+
+	result, err := suite.repo.Snapshot(context.Background())
+
+	require.EqualError(err, expectedError.Error())
+	require.Nil(result)
+}
+
+func TestLedgerRepository(t *testing.T) {
+	suite.Run(t, new(LedgerRepositoryTestSuite))
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+// LoginFailureRepository keeps the failed logins of accounts and IPs, by the
+// keys of domain.AccountLoginKey and domain.IPLoginKey.
+type LoginFailureRepository interface {
+	// Find returns the failures of the key, nil if there are none.
+	Find(ctx context.Context, key string) (*domain.LoginFailures, error)
+	// Attempt counts a login of the key at now as failed before its
+	// password is checked, under a lock of the key, and drops the failures
+	// that expired. A login that has to wait under the policy fails with a
+	// *domain.LoginBlockedError and is not counted. An attempt that locks
+	// the key is recorded in the audit log.
+	Attempt(ctx context.Context, key string, now time.Time, policy domain.LoginPolicy) (*domain.LoginFailures, error)
+	// Release takes back the attempt of a login of the key that succeeded.
+	Release(ctx context.Context, key string, policy domain.LoginPolicy) error
+	// Reset forgets the failures of the key, after a successful login.
+	Reset(ctx context.Context, key string) error
+	// Unlock forgets the failures of the key on behalf of the actor and
+	// records it in the audit log. It reports whether there were any.
+	Unlock(ctx context.Context, key string, actorID *uint, targetID *uint) (bool, error)
+}
+
+type LoginFailuresEntity struct {
+	Key          string
+	Count        int
+	LastFailedAt time.Time
+	LockedUntil  sql.NullTime
+	ExpiresAt    time.Time
+}
+
+func (e LoginFailuresEntity) ToAggregate() domain.LoginFailures {
+	failures := domain.LoginFailures{
+		Key:          e.Key,
+		Count:        e.Count,
+		LastFailedAt: e.LastFailedAt,
+		ExpiresAt:    e.ExpiresAt,
+	}
+
+	if e.LockedUntil.Valid {
+		lockedUntil := e.LockedUntil.Time
+		failures.LockedUntil = &lockedUntil
+	}
+
+	return failures
+}
+
+type loginFailureRepository struct {
+	db sqlDB
+}
+
+func NewLoginFailureRepository(db *sql.DB) LoginFailureRepository {
+	return &loginFailureRepository{db: newSQLDB(db)}
+}
+
+func (r *loginFailureRepository) Find(ctx context.Context, key string) (*domain.LoginFailures, error) {
+	return findLoginFailures(ctx, r.db, key, "")
+}
+
+// Attempt makes sure the row of the key exists before it locks it, so that
+// concurrent attempts of a new key are counted one after the other.
+func (r *loginFailureRepository) Attempt(ctx context.Context, key string, now time.Time, policy domain.LoginPolicy) (*domain.LoginFailures, error) {
+	var failures *domain.LoginFailures
+	err := withTx(ctx, r.db, func(tx sqlTx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM login_failures WHERE expires_at <= ? AND login_key <> ?", now, key)
+		if err != nil {
+			return err
+		}
+
+		query := "INSERT INTO login_failures (login_key, failures, last_failed_at, expires_at) VALUES (?, 0, ?, ?) ON DUPLICATE KEY UPDATE failures = failures"
+		if tx.Dialect() != database.MySQL {
+			query = "INSERT INTO login_failures (login_key, failures, last_failed_at, expires_at) VALUES (?, 0, ?, ?) ON CONFLICT (login_key) DO NOTHING"
+		}
+
+		if _, err := tx.ExecContext(ctx, query, key, now, now); err != nil {
+			return err
+		}
+
+		failures, err = findLoginFailures(ctx, tx, key, " FOR UPDATE")
+		if err != nil {
+			return err
+		}
+
+		locked, err := failures.Attempt(now, policy)
+		if err != nil {
+			return err
+		}
+
+		if err := updateLoginFailures(ctx, tx, failures); err != nil || !locked {
+			return err
+		}
+
+		event, err := domain.NewAuditEvent(ctx, domain.AuditLoginLocked, nil, domain.AuditTargetLogin, nil, nil, domain.NewAuditLoginFailures(*failures))
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, &event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return failures, nil
+}
+
+func (r *loginFailureRepository) Release(ctx context.Context, key string, policy domain.LoginPolicy) error {
+	return withTx(ctx, r.db, func(tx sqlTx) error {
+		failures, err := findLoginFailures(ctx, tx, key, " FOR UPDATE")
+		if err != nil || failures == nil {
+			return err
+		}
+
+		failures.Release(policy)
+
+		return updateLoginFailures(ctx, tx, failures)
+	})
+}
+
+func (r *loginFailureRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM login_failures WHERE login_key = ?", key)
+
+	return err
+}
+
+func (r *loginFailureRepository) Unlock(ctx context.Context, key string, actorID *uint, targetID *uint) (bool, error) {
+	var unlocked bool
+	err := withTx(ctx, r.db, func(tx sqlTx) error {
+		failures, err := findLoginFailures(ctx, tx, key, " FOR UPDATE")
+		if err != nil || failures == nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM login_failures WHERE login_key = ?", key); err != nil {
+			return err
+		}
+
+		unlocked = true
+		event, err := domain.NewAuditEvent(ctx, domain.AuditLoginUnlocked, actorID, domain.AuditTargetUser, targetID, domain.NewAuditLoginFailures(*failures), nil)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, &event)
+	})
+
+	return unlocked, err
+}
+
+func updateLoginFailures(ctx context.Context, tx sqlTx, failures *domain.LoginFailures) error {
+	_, err := tx.ExecContext(ctx, "UPDATE login_failures SET failures = ?, last_failed_at = ?, locked_until = ?, expires_at = ? WHERE login_key = ?",
+		failures.Count, failures.LastFailedAt, failures.LockedUntil, failures.ExpiresAt, failures.Key)
+
+	return err
+}
+
+// findLoginFailures returns the failures of the key, nil if there are none.
+// suffix is appended to the query, to lock the row.
+func findLoginFailures(ctx context.Context, db executor, key, suffix string) (*domain.LoginFailures, error) {
+	var e LoginFailuresEntity
+	err := db.
+		QueryRowContext(ctx, "SELECT login_key, failures, last_failed_at, locked_until, expires_at FROM login_failures WHERE login_key = ?"+suffix, key).
+		Scan(&e.Key, &e.Count, &e.LastFailedAt, &e.LockedUntil, &e.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	failures := e.ToAggregate()
+
+	return &failures, nil
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+type LoginFailureRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *loginFailureRepository
+}
+
+func (suite *LoginFailureRepositoryTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.repo = &loginFailureRepository{
+		db: newSQLDB(suite.db),
+	}
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TestNewLoginFailureRepository() {
+	require := suite.Require()
+
+	db, _, _ := sqlmock.New()
+	repo := NewLoginFailureRepository(db)
+
+	require.NotNil(repo)
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TestFind_NotFound() {
+	require := suite.Require()
+
+	suite.mock.ExpectQuery("^SELECT login_key, failures, last_failed_at, locked_until, expires_at FROM login_failures WHERE login_key = \\?$").
+		WithArgs("ip:192.0.2.1").
+		WillReturnRows(sqlmock.NewRows([]string{"login_key", "failures", "last_failed_at", "locked_until", "expires_at"}))
+
+	failures, err := suite.repo.Find(context.Background(), "ip:192.0.2.1")
+
+	require.NoError(err)
+	require.Nil(failures)
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TestAttempt_Success() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastFailedAt := now.Add(-time.Minute)
+	policy := domain.LoginPolicy{Window: time.Hour}
+	rows := sqlmock.NewRows([]string{"login_key", "failures", "last_failed_at", "locked_until", "expires_at"}).
+		AddRow("ip:192.0.2.1", 1, lastFailedAt, nil, lastFailedAt.Add(time.Hour))
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^DELETE FROM login_failures WHERE expires_at <= \\? AND login_key <> \\?$").
+		WithArgs(now, "ip:192.0.2.1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^INSERT INTO login_failures .+ ON DUPLICATE KEY UPDATE failures = failures$").
+		WithArgs("ip:192.0.2.1", now, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery("^SELECT .+ FROM login_failures WHERE login_key = \\? FOR UPDATE$").
+		WithArgs("ip:192.0.2.1").
+		WillReturnRows(rows)
+	suite.mock.ExpectExec("^UPDATE login_failures SET failures = \\?, last_failed_at = \\?, locked_until = \\?, expires_at = \\? WHERE login_key = \\?$").
+		WithArgs(2, now, nil, now.Add(time.Hour), "ip:192.0.2.1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	failures, err := suite.repo.Attempt(context.Background(), "ip:192.0.2.1", now, policy)
+
+	require.NoError(err)
+	require.Equal(2, failures.Count)
+	require.Nil(failures.LockedUntil)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TestAttempt_Locked_Postgres_Success() {
+	require := suite.Require()
+	suite.repo.db = sqlDB{db: suite.db, dialect: database.Postgres}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := domain.LoginPolicy{Window: time.Hour, LockoutAfter: 1, LockoutDuration: 2 * time.Hour}
+	lockedUntil := now.Add(2 * time.Hour)
+	rows := sqlmock.NewRows([]string{"login_key", "failures", "last_failed_at", "locked_until", "expires_at"}).
+		AddRow("account:foo@example.com", 0, now, nil, now)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^DELETE FROM login_failures WHERE expires_at <= \\$1 AND login_key <> \\$2$").
+		WithArgs(now, "account:foo@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^INSERT INTO login_failures .+ VALUES \\(\\$1, 0, \\$2, \\$3\\) ON CONFLICT \\(login_key\\) DO NOTHING$").
+		WithArgs("account:foo@example.com", now, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery("^SELECT .+ FROM login_failures WHERE login_key = \\$1 FOR UPDATE$").
+		WithArgs("account:foo@example.com").
+		WillReturnRows(rows)
+	suite.mock.ExpectExec("^UPDATE login_failures SET").
+		WithArgs(1, now, &lockedUntil, lockedUntil, "account:foo@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery("^SELECT last_hash FROM audit_chain WHERE id = 1 FOR UPDATE$").
+		WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow(""))
+	suite.mock.ExpectQuery("^INSERT INTO audit_events .+ RETURNING id$").
+		WithArgs(nil, string(domain.AuditLoginLocked), domain.AuditTargetLogin, nil, nil,
+			`{"key":"account:foo@example.com","failures":1,"locked_until":"2024-01-01T02:00:00Z"}`,
+			"", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	suite.mock.ExpectExec("^UPDATE audit_chain SET last_hash = \\$1 WHERE id = 1$").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	failures, err := suite.repo.Attempt(context.Background(), "account:foo@example.com", now, policy)
+
+	require.NoError(err)
+	require.Equal(&lockedUntil, failures.LockedUntil)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TestAttempt_Blocked_Failure() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lockedUntil := now.Add(time.Hour)
+	policy := domain.LoginPolicy{Window: time.Hour, LockoutAfter: 1, LockoutDuration: time.Hour}
+	rows := sqlmock.NewRows([]string{"login_key", "failures", "last_failed_at", "locked_until", "expires_at"}).
+		AddRow("account:foo@example.com", 1, now, lockedUntil, lockedUntil)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^DELETE FROM login_failures WHERE expires_at <= \\? AND login_key <> \\?$").
+		WithArgs(now, "account:foo@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^INSERT INTO login_failures .+ ON DUPLICATE KEY UPDATE failures = failures$").
+		WithArgs("account:foo@example.com", now, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery("^SELECT .+ FROM login_failures WHERE login_key = \\? FOR UPDATE$").
+		WithArgs("account:foo@example.com").
+		WillReturnRows(rows)
+	suite.mock.ExpectRollback()
+
+	failures, err := suite.repo.Attempt(context.Background(), "account:foo@example.com", now, policy)
+
+	var blocked *domain.LoginBlockedError
+	require.ErrorAs(err, &blocked)
+	require.True(blocked.Locked)
+	require.Equal(lockedUntil, blocked.RetryAt)
+	require.Nil(failures)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TestRelease_Success() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lockedUntil := now.Add(time.Hour)
+	policy := domain.LoginPolicy{Window: time.Hour, LockoutAfter: 3, LockoutDuration: time.Hour}
+	rows := sqlmock.NewRows([]string{"login_key", "failures", "last_failed_at", "locked_until", "expires_at"}).
+		AddRow("ip:192.0.2.1", 3, now, lockedUntil, lockedUntil)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT .+ FROM login_failures WHERE login_key = \\? FOR UPDATE$").
+		WithArgs("ip:192.0.2.1").
+		WillReturnRows(rows)
+	suite.mock.ExpectExec("^UPDATE login_failures SET failures = \\?, last_failed_at = \\?, locked_until = \\?, expires_at = \\? WHERE login_key = \\?$").
+		WithArgs(2, now, nil, lockedUntil, "ip:192.0.2.1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.Release(context.Background(), "ip:192.0.2.1", policy)
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TestRelease_NotFound() {
+	require := suite.Require()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT .+ FROM login_failures WHERE login_key = \\? FOR UPDATE$").
+		WithArgs("ip:192.0.2.1").
+		WillReturnRows(sqlmock.NewRows([]string{"login_key", "failures", "last_failed_at", "locked_until", "expires_at"}))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.Release(context.Background(), "ip:192.0.2.1", domain.LoginPolicy{})
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TestReset_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectExec("^DELETE FROM login_failures WHERE login_key = \\?$").
+		WithArgs("account:foo@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.Reset(context.Background(), "account:foo@example.com")
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TestUnlock_NotFound() {
+	require := suite.Require()
+	actorID := uint(3)
+	userID := uint(10)
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT .+ FROM login_failures WHERE login_key = \\? FOR UPDATE$").
+		WithArgs("account:foo@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"login_key", "failures", "last_failed_at", "locked_until", "expires_at"}))
+	suite.mock.ExpectCommit()
+
+	unlocked, err := suite.repo.Unlock(context.Background(), "account:foo@example.com", &actorID, &userID)
+
+	require.NoError(err)
+	require.False(unlocked)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *LoginFailureRepositoryTestSuite) TestUnlock_Success() {
+	require := suite.Require()
+	actorID := uint(3)
+	userID := uint(10)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"login_key", "failures", "last_failed_at", "locked_until", "expires_at"}).
+		AddRow("account:foo@example.com", 2, now, nil, now.Add(time.Hour))
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT .+ FROM login_failures WHERE login_key = \\? FOR UPDATE$").
+		WithArgs("account:foo@example.com").
+		WillReturnRows(rows)
+	suite.mock.ExpectExec("^DELETE FROM login_failures WHERE login_key = \\?$").
+		WithArgs("account:foo@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRecordAudit(suite.mock, domain.AuditLoginUnlocked, actorID, domain.AuditTargetUser, userID, `{"key":"account:foo@example.com","failures":2}`, nil)
+	suite.mock.ExpectCommit()
+
+	unlocked, err := suite.repo.Unlock(context.Background(), "account:foo@example.com", &actorID, &userID)
+
+	require.NoError(err)
+	require.True(unlocked)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func TestLoginFailureRepository(t *testing.T) {
+	suite.Run(t, new(LoginFailureRepositoryTestSuite))
+}
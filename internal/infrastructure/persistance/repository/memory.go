@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type memoryWalletKey struct {
+	userID   uint
+	currency string
+}
+
+type memoryIdempotencyKey struct {
+	userID uint
+	key    string
+}
+
+// MemoryStore holds the data of the memory repositories. Repositories built
+// on the same store see each other's writes like the SQL ones sharing a
+// database. A single lock serialises every method, so each of them is atomic
+// the way a transaction is. The store keeps no ledger and no outbox relay,
+// it is meant for demos and tests, not for real money. The methods return too
+// quickly to be worth cancelling, so they only take the request of the audit
+// log from their context.
+//
+// The repositories take the lock through their mu, which is a no-op for the
+// ones a memory unit of work hands out since the unit holds the lock already.
+type MemoryStore struct {
+	mu sync.Mutex
+	memoryData
+}
+
+type memoryData struct {
+	lastIDs         map[string]uint
+	users           map[uint]domain.User
+	wallets         map[memoryWalletKey]*domain.Wallet
+	giftCards       map[uint]*domain.GiftCard
+	history         []domain.GiftCardStatusChange
+	redemptions     []domain.GiftCardRedemption
+	events          []domain.Event
+	idempotencyKeys map[memoryIdempotencyKey]domain.IdempotencyKey
+	webhooks        map[uint]domain.Webhook
+	deliveries      map[uint]*domain.WebhookDelivery
+	refreshTokens   map[uint]domain.RefreshToken
+	revokedTokens   map[string]time.Time
+	auditEvents     []domain.AuditEvent
+	pendingAudit    []domain.AuditEvent
+	loginFailures   map[string]domain.LoginFailures
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{memoryData: memoryData{
+		lastIDs:         make(map[string]uint),
+		users:           make(map[uint]domain.User),
+		wallets:         make(map[memoryWalletKey]*domain.Wallet),
+		giftCards:       make(map[uint]*domain.GiftCard),
+		idempotencyKeys: make(map[memoryIdempotencyKey]domain.IdempotencyKey),
+		webhooks:        make(map[uint]domain.Webhook),
+		deliveries:      make(map[uint]*domain.WebhookDelivery),
+		refreshTokens:   make(map[uint]domain.RefreshToken),
+		revokedTokens:   make(map[string]time.Time),
+		loginFailures:   make(map[string]domain.LoginFailures),
+	}}
+}
+
+// clone returns a deep copy of the data, a unit of work restores it when it
+// fails.
+func (d memoryData) clone() memoryData {
+	c := memoryData{
+		lastIDs:         maps.Clone(d.lastIDs),
+		users:           maps.Clone(d.users),
+		wallets:         make(map[memoryWalletKey]*domain.Wallet, len(d.wallets)),
+		giftCards:       make(map[uint]*domain.GiftCard, len(d.giftCards)),
+		history:         slices.Clone(d.history),
+		redemptions:     slices.Clone(d.redemptions),
+		events:          slices.Clone(d.events),
+		idempotencyKeys: maps.Clone(d.idempotencyKeys),
+		webhooks:        maps.Clone(d.webhooks),
+		deliveries:      make(map[uint]*domain.WebhookDelivery, len(d.deliveries)),
+		refreshTokens:   maps.Clone(d.refreshTokens),
+		revokedTokens:   maps.Clone(d.revokedTokens),
+		auditEvents:     slices.Clone(d.auditEvents),
+		pendingAudit:    slices.Clone(d.pendingAudit),
+		loginFailures:   maps.Clone(d.loginFailures),
+	}
+
+	for key, wallet := range d.wallets {
+		w := *wallet
+		c.wallets[key] = &w
+	}
+
+	for id, giftCard := range d.giftCards {
+		g := copyGiftCard(giftCard)
+		c.giftCards[id] = &g
+	}
+
+	for id, delivery := range d.deliveries {
+		wd := copyWebhookDelivery(delivery)
+		c.deliveries[id] = &wd
+	}
+
+	return c
+}
+
+// unlocked is the mu of the repositories of a memory unit of work.
+type unlocked struct{}
+
+func (unlocked) Lock()   {}
+func (unlocked) Unlock() {}
+
+// nextID returns the next id of table, ids start at 1 like auto increment
+// columns do.
+func (s *MemoryStore) nextID(table string) uint {
+	s.lastIDs[table]++
+
+	return s.lastIDs[table]
+}
+
+// The wallet methods below mirror walletLedger and are called with the lock
+// held. They check before they change anything, so a failed call leaves the
+// store as it was.
+
+func (s *MemoryStore) credit(userID uint, amount domain.Money, now time.Time) {
+	key := memoryWalletKey{userID: userID, currency: amount.Currency}
+	wallet, ok := s.wallets[key]
+	if !ok {
+		wallet = &domain.Wallet{
+			ID:      s.nextID("wallets"),
+			UserID:  userID,
+			Balance: domain.NewMoney(0, amount.Currency),
+			Held:    domain.NewMoney(0, amount.Currency),
+		}
+		s.wallets[key] = wallet
+	}
+
+	wallet.Balance.Amount += amount.Amount
+	wallet.UpdatedAt = now
+}
+
+func (s *MemoryStore) hold(userID uint, amount domain.Money, now time.Time) error {
+	wallet, ok := s.wallets[memoryWalletKey{userID: userID, currency: amount.Currency}]
+	if !ok || wallet.Balance.Amount < amount.Amount {
+		return domain.ErrInsufficientFunds
+	}
+
+	wallet.Balance.Amount -= amount.Amount
+	wallet.Held.Amount += amount.Amount
+	wallet.UpdatedAt = now
+
+	return nil
+}
+
+// unhold takes amount out of the held balance of the user. With toBalance
+// the amount goes back to the available balance, otherwise it leaves the
+// wallet.
+func (s *MemoryStore) unhold(userID uint, amount domain.Money, toBalance bool, now time.Time) error {
+	wallet, ok := s.wallets[memoryWalletKey{userID: userID, currency: amount.Currency}]
+	if !ok || wallet.Held.Amount < amount.Amount {
+		return fmt.Errorf("wallet of user %d does not hold %s", userID, amount)
+	}
+
+	wallet.Held.Amount -= amount.Amount
+	if toBalance {
+		wallet.Balance.Amount += amount.Amount
+	}
+
+	wallet.UpdatedAt = now
+
+	return nil
+}
+
+func (s *MemoryStore) recordStatusChange(change domain.GiftCardStatusChange, now time.Time) {
+	change.ID = s.nextID("gift_card_status_history")
+	change.CreatedAt = now
+	s.history = append(s.history, change)
+}
+
+func (s *MemoryStore) recordEvent(event *domain.Event, now time.Time) {
+	event.ID = s.nextID("outbox")
+	event.CreatedAt = now
+	s.events = append(s.events, *event)
+}
+
+// recordAudit chains the event to the last event and adds it to the log, like
+// its SQL counterpart.
+func (s *MemoryStore) recordAudit(event *domain.AuditEvent, now time.Time) {
+	event.ID = s.nextID("audit_events")
+	event.Chain(s.lastAuditHash(), now)
+	s.auditEvents = append(s.auditEvents, *event)
+}
+
+func (s *MemoryStore) lastAuditHash() string {
+	if len(s.auditEvents) == 0 {
+		return ""
+	}
+
+	return s.auditEvents[len(s.auditEvents)-1].Hash
+}
+
+// enqueueWebhookDeliveries adds a pending delivery of the event for every
+// active webhook of the given users, like its SQL counterpart.
+func (s *MemoryStore) enqueueWebhookDeliveries(event domain.Event, now time.Time, userIDs ...uint) {
+	isUser := make(map[uint]bool, len(userIDs))
+	for _, userID := range userIDs {
+		isUser[userID] = true
+	}
+
+	var webhookIDs []uint
+	for id, webhook := range s.webhooks {
+		if webhook.Active && isUser[webhook.UserID] {
+			webhookIDs = append(webhookIDs, id)
+		}
+	}
+
+	sort.Slice(webhookIDs, func(i, j int) bool { return webhookIDs[i] < webhookIDs[j] })
+	for _, webhookID := range webhookIDs {
+		id := s.nextID("webhook_deliveries")
+		s.deliveries[id] = &domain.WebhookDelivery{
+			ID:            id,
+			WebhookID:     webhookID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			AggregateID:   event.AggregateID,
+			Payload:       append([]byte(nil), event.Payload...),
+			Status:        domain.WDSPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+	}
+}
+
+// page returns the part of items on the given page, pages start at 1.
+func page[T any](items []T, pageSize int, pageNumber int) []T {
+	return pageAt(items, (pageNumber-1)*pageSize, pageSize)
+}
+
+// pageAt returns at most limit items starting at offset.
+func pageAt[T any](items []T, offset int, limit int) []T {
+	if offset < 0 || limit <= 0 || offset >= len(items) {
+		return nil
+	}
+
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[offset:end]
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	c := *t
+
+	return &c
+}
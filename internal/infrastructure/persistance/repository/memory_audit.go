@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type memoryAuditRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryAuditRepository(store *MemoryStore) AuditRepository {
+	return &memoryAuditRepository{store: store}
+}
+
+func (r *memoryAuditRepository) Record(_ context.Context, event *domain.AuditEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.recordAudit(event, time.Now())
+
+	return nil
+}
+
+func (r *memoryAuditRepository) Enqueue(_ context.Context, event *domain.AuditEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event.ID = r.store.nextID("pending_audit_events")
+	event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	r.store.pendingAudit = append(r.store.pendingAudit, copyAuditEvent(*event))
+
+	return nil
+}
+
+func (r *memoryAuditRepository) ChainPending(_ context.Context, limit int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	n := min(limit, len(r.store.pendingAudit))
+	for _, event := range r.store.pendingAudit[:n] {
+		r.store.recordAudit(&event, event.CreatedAt)
+	}
+
+	r.store.pendingAudit = slices.Delete(r.store.pendingAudit, 0, n)
+
+	return n, nil
+}
+
+// Find lists the events of the query newest first, see auditRepository.Find.
+func (r *memoryAuditRepository) Find(_ context.Context, query AuditQuery) ([]domain.AuditEvent, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var events []domain.AuditEvent
+	for i := len(r.store.auditEvents) - 1; i >= 0 && len(events) < query.Limit; i-- {
+		event := r.store.auditEvents[i]
+		if query.matches(event) {
+			events = append(events, copyAuditEvent(event))
+		}
+	}
+
+	return events, nil
+}
+
+func (r *memoryAuditRepository) FindAfter(_ context.Context, afterID uint, limit int) ([]domain.AuditEvent, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var events []domain.AuditEvent
+	for _, event := range r.store.auditEvents {
+		if len(events) == limit {
+			break
+		}
+
+		if event.ID > afterID {
+			events = append(events, copyAuditEvent(event))
+		}
+	}
+
+	return events, nil
+}
+
+func (r *memoryAuditRepository) LastHash(_ context.Context) (string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.lastAuditHash(), nil
+}
+
+// matches reports whether the event passes the filters of the query, like the
+// WHERE clause of auditRepository.Find.
+func (q AuditQuery) matches(e domain.AuditEvent) bool {
+	switch {
+	case q.ActorID != nil && (e.ActorID == nil || *e.ActorID != *q.ActorID):
+		return false
+	case q.Action != "" && e.Action != q.Action:
+		return false
+	case q.TargetType != "" && e.TargetType != q.TargetType:
+		return false
+	case q.TargetID != nil && (e.TargetID == nil || *e.TargetID != *q.TargetID):
+		return false
+	case q.From != nil && e.CreatedAt.Before(*q.From):
+		return false
+	case q.To != nil && e.CreatedAt.After(*q.To):
+		return false
+	case q.BeforeID != 0 && e.ID >= q.BeforeID:
+		return false
+	}
+
+	return true
+}
+
+func copyAuditEvent(e domain.AuditEvent) domain.AuditEvent {
+	e.ActorID = copyUint(e.ActorID)
+	e.TargetID = copyUint(e.TargetID)
+	e.Before = slices.Clone(e.Before)
+	e.After = slices.Clone(e.After)
+
+	return e
+}
@@ -0,0 +1,385 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type memoryGiftCardRepository struct {
+	store *MemoryStore
+	mu    sync.Locker
+}
+
+func NewMemoryGiftCardRepository(store *MemoryStore) GiftCardRepository {
+	return &memoryGiftCardRepository{store: store, mu: &store.mu}
+}
+
+// Create stores the gift card as pending and holds its amount on the wallet
+// of the gifter, see giftCardRepository.Create.
+func (r *memoryGiftCardRepository) Create(ctx context.Context, giftCard *domain.GiftCard) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	err := r.store.hold(giftCard.GifterID, giftCard.Amount, now)
+	if err != nil {
+		return err
+	}
+
+	giftCard.ID = r.store.nextID("gift_cards")
+	giftCard.Version = 1
+	stored := *giftCard
+	stored.Status = domain.GCSPending
+	stored.CreationDate = now
+	stored.UpdatedAt = now
+	stored.ExpiresAt = copyTime(giftCard.ExpiresAt)
+	r.store.giftCards[stored.ID] = &stored
+
+	r.store.recordStatusChange(domain.GiftCardStatusChange{
+		GiftCardID: stored.ID,
+		To:         domain.GCSPending,
+		ActorID:    copyUint(&stored.GifterID),
+	}, now)
+
+	event, err := domain.NewGiftCardCreatedEvent(*giftCard)
+	if err != nil {
+		return err
+	}
+
+	audit, err := domain.NewAuditEvent(ctx, domain.AuditGiftCardCreated, &stored.GifterID, domain.AuditTargetGiftCard, &stored.ID, nil, domain.NewAuditGiftCard(stored))
+	if err != nil {
+		return err
+	}
+
+	r.store.recordEvent(&event, now)
+	r.store.recordAudit(&audit, now)
+
+	return nil
+}
+
+func (r *memoryGiftCardRepository) FindByID(_ context.Context, id uint) (*domain.GiftCard, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	giftCard, ok := r.store.giftCards[id]
+	if !ok {
+		return nil, nil
+	}
+
+	g := copyGiftCard(giftCard)
+
+	return &g, nil
+}
+
+// FindByIDForUpdate finds the gift card like FindByID. A memory unit of work
+// holds the lock of the whole store, so there is no row to lock.
+func (r *memoryGiftCardRepository) FindByIDForUpdate(ctx context.Context, id uint) (*domain.GiftCard, error) {
+	return r.FindByID(ctx, id)
+}
+
+// UpdateStatus moves the gift card to status if its state machine allows it
+// and settles the held amount, see giftCardRepository.UpdateStatus.
+func (r *memoryGiftCardRepository) UpdateStatus(ctx context.Context, id uint, version *uint, status domain.GiftCardStatus, actorID *uint) error {
+	return r.updateStatus(ctx, id, version, status, actorID, false)
+}
+
+// ForceUpdateStatus is UpdateStatus whatever the expiry date of the card, see
+// domain.GiftCard.ForceTransitionTo.
+func (r *memoryGiftCardRepository) ForceUpdateStatus(ctx context.Context, id uint, version *uint, status domain.GiftCardStatus, actorID *uint) error {
+	return r.updateStatus(ctx, id, version, status, actorID, true)
+}
+
+func (r *memoryGiftCardRepository) updateStatus(ctx context.Context, id uint, version *uint, status domain.GiftCardStatus, actorID *uint, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.store.giftCards[id]
+	if !ok {
+		return domain.ErrGiftCardNotFound
+	}
+
+	if version != nil && *version != stored.Version {
+		return domain.ErrGiftCardVersionMismatch
+	}
+
+	now := time.Now()
+	giftCard := copyGiftCard(stored)
+	from := giftCard.Status
+	var err error
+	if force {
+		err = giftCard.ForceTransitionTo(status)
+	} else {
+		err = giftCard.TransitionTo(status, now)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	updated := giftCard
+	updated.Version++
+	audit, err := domain.NewAuditEvent(ctx, domain.AuditGiftCardStatusChanged, actorID, domain.AuditTargetGiftCard, &id, domain.NewAuditGiftCard(*stored), domain.NewAuditGiftCard(updated))
+	if err != nil {
+		return err
+	}
+
+	// An accepted card keeps its amount out of every wallet, its remaining
+	// amount stands for the ledger account of the card and goes back to the
+	// gifter when the card is voided.
+	if from == domain.GCSAccepted {
+		r.store.credit(giftCard.GifterID, stored.RemainingAmount, now)
+	} else {
+		err = r.store.unhold(giftCard.GifterID, giftCard.Amount, status != domain.GCSAccepted, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	event, err := domain.NewGiftCardStatusChangedEvent(giftCard, from, actorID)
+	if err != nil {
+		return err
+	}
+
+	stored.Status = status
+	stored.RemainingAmount = giftCard.RemainingAmount
+	stored.Version++
+	stored.UpdatedAt = now
+	r.store.recordStatusChange(domain.GiftCardStatusChange{GiftCardID: id, From: &from, To: status, ActorID: copyUint(actorID)}, now)
+	r.store.recordEvent(&event, now)
+	r.store.enqueueWebhookDeliveries(event, now, giftCard.GifterID, giftCard.GifteeID)
+	r.store.recordAudit(&audit, now)
+
+	return nil
+}
+
+func (r *memoryGiftCardRepository) FindStatusHistory(_ context.Context, id uint) ([]domain.GiftCardStatusChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var history []domain.GiftCardStatusChange
+	for _, change := range r.store.history {
+		if change.GiftCardID == id {
+			if change.From != nil {
+				from := *change.From
+				change.From = &from
+			}
+
+			change.ActorID = copyUint(change.ActorID)
+			history = append(history, change)
+		}
+	}
+
+	return history, nil
+}
+
+// FindOverdueGiftCardIDs returns up to limit pending gift cards whose expiry
+// date is not after now, the ones that expired first come first.
+func (r *memoryGiftCardRepository) FindOverdueGiftCardIDs(_ context.Context, now time.Time, limit int) ([]uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var overdue []*domain.GiftCard
+	for _, giftCard := range r.store.giftCards {
+		if giftCard.IsOverdue(now) {
+			overdue = append(overdue, giftCard)
+		}
+	}
+
+	sort.Slice(overdue, func(i, j int) bool {
+		if !overdue[i].ExpiresAt.Equal(*overdue[j].ExpiresAt) {
+			return overdue[i].ExpiresAt.Before(*overdue[j].ExpiresAt)
+		}
+
+		return overdue[i].ID < overdue[j].ID
+	})
+
+	var ids []uint
+	for _, giftCard := range overdue {
+		if len(ids) == limit {
+			break
+		}
+
+		ids = append(ids, giftCard.ID)
+	}
+
+	return ids, nil
+}
+
+// Redeem spends amount of the accepted gift card with the given code on
+// behalf of the redeemer, see giftCardRepository.Redeem.
+func (r *memoryGiftCardRepository) Redeem(ctx context.Context, code string, redeemerID uint, amount domain.Money) (*domain.GiftCardRedemption, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stored *domain.GiftCard
+	for _, giftCard := range r.store.giftCards {
+		if giftCard.Code == code {
+			stored = giftCard
+
+			break
+		}
+	}
+
+	if stored == nil {
+		return nil, domain.ErrGiftCardNotFound
+	}
+
+	now := time.Now()
+	giftCard := copyGiftCard(stored)
+	err := giftCard.Redeem(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := giftCard
+	updated.Version++
+	audit, err := domain.NewAuditEvent(ctx, domain.AuditGiftCardRedeemed, &redeemerID, domain.AuditTargetGiftCard, &giftCard.ID, domain.NewAuditGiftCard(*stored), domain.NewAuditGiftCard(updated))
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.credit(redeemerID, amount, now)
+	stored.RemainingAmount = giftCard.RemainingAmount
+	stored.Version++
+	stored.UpdatedAt = now
+
+	redemption := domain.GiftCardRedemption{
+		ID:              r.store.nextID("gift_card_redemptions"),
+		GiftCardID:      giftCard.ID,
+		RedeemerID:      redeemerID,
+		Amount:          amount,
+		RemainingAmount: giftCard.RemainingAmount,
+	}
+	r.store.redemptions = append(r.store.redemptions, redemption)
+
+	event, err := domain.NewGiftCardRedeemedEvent(redemption)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.recordEvent(&event, now)
+	r.store.recordAudit(&audit, now)
+
+	return &redemption, nil
+}
+
+func (r *memoryGiftCardRepository) FindReceivedGiftCardsByUserID(_ context.Context, userID uint, query GiftCardQuery) (GiftCardPage, error) {
+	return r.find(func(g *domain.GiftCard) (bool, uint) { return g.GifteeID == userID, g.GifterID }, query)
+}
+
+func (r *memoryGiftCardRepository) FindSentGiftCardsByUserID(_ context.Context, userID uint, query GiftCardQuery) (GiftCardPage, error) {
+	return r.find(func(g *domain.GiftCard) (bool, uint) { return g.GifterID == userID, g.GifteeID }, query)
+}
+
+// find returns the page of the gift cards of the user that match the query,
+// see giftCardRepository.findGiftCards. of reports whether a card is the
+// user's and who the counterparty is.
+func (r *memoryGiftCardRepository) find(of func(g *domain.GiftCard) (bool, uint), query GiftCardQuery) (GiftCardPage, error) {
+	cursor, err := query.cursor()
+	if err != nil {
+		return GiftCardPage{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched []domain.GiftCard
+	for _, giftCard := range r.store.giftCards {
+		isUser, counterpartyID := of(giftCard)
+		if isUser && matchesGiftCardQuery(giftCard, counterpartyID, query) {
+			matched = append(matched, copyGiftCard(giftCard))
+		}
+	}
+
+	descending := query.Descending != cursor.backward()
+	slices.SortFunc(matched, func(a, b domain.GiftCard) int {
+		if descending {
+			a, b = b, a
+		}
+
+		return compareGiftCards(query.SortBy, a, b)
+	})
+
+	offset := (query.PageNumber - 1) * query.PageSize
+	if cursor != nil {
+		key := domain.GiftCard{ID: cursor.ID, CreationDate: cursor.CreatedAt, Amount: domain.Money{Amount: cursor.Amount}}
+		offset = sort.Search(len(matched), func(i int) bool {
+			if descending {
+				return compareGiftCards(query.SortBy, matched[i], key) < 0
+			}
+
+			return compareGiftCards(query.SortBy, matched[i], key) > 0
+		})
+	}
+
+	page := newGiftCardPage(query, cursor, pageAt(matched, offset, query.PageSize+1))
+	if query.WithTotal {
+		total := len(matched)
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+// compareGiftCards compares two cards in the order of the sort, then by id.
+func compareGiftCards(sortBy GiftCardSort, a, b domain.GiftCard) int {
+	switch {
+	case sortBy == GiftCardSortAmount && a.Amount.Amount != b.Amount.Amount:
+		return cmp.Compare(a.Amount.Amount, b.Amount.Amount)
+	case sortBy != GiftCardSortAmount && !a.CreationDate.Equal(b.CreationDate):
+		return a.CreationDate.Compare(b.CreationDate)
+	default:
+		return cmp.Compare(a.ID, b.ID)
+	}
+}
+
+func matchesGiftCardQuery(g *domain.GiftCard, counterpartyID uint, query GiftCardQuery) bool {
+	if len(query.Statuses) > 0 && !slices.Contains(query.Statuses, g.Status) {
+		return false
+	}
+
+	if query.CreatedFrom != nil && g.CreationDate.Before(*query.CreatedFrom) {
+		return false
+	}
+
+	if query.CreatedTo != nil && g.CreationDate.After(*query.CreatedTo) {
+		return false
+	}
+
+	if currency := query.currency(); currency != "" && g.Amount.Currency != currency {
+		return false
+	}
+
+	if query.MinAmount != nil && g.Amount.Amount < query.MinAmount.Amount {
+		return false
+	}
+
+	if query.MaxAmount != nil && g.Amount.Amount > query.MaxAmount.Amount {
+		return false
+	}
+
+	return query.CounterpartyID == nil || *query.CounterpartyID == counterpartyID
+}
+
+func copyGiftCard(g *domain.GiftCard) domain.GiftCard {
+	c := *g
+	c.ExpiresAt = copyTime(g.ExpiresAt)
+
+	return c
+}
+
+func copyUint(u *uint) *uint {
+	if u == nil {
+		return nil
+	}
+
+	c := *u
+
+	return &c
+}
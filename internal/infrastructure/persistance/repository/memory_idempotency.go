@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type memoryIdempotencyRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryIdempotencyRepository(store *MemoryStore) IdempotencyRepository {
+	return &memoryIdempotencyRepository{store: store}
+}
+
+// Reserve stores the reservation unless the key already exists and cannot be
+// taken over yet, see idempotencyRepository.Reserve.
+func (r *memoryIdempotencyRepository) Reserve(_ context.Context, reservation domain.IdempotencyKey, lease time.Duration) (*domain.IdempotencyKey, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := reservation.LockedAt
+	k := memoryIdempotencyKey{userID: reservation.UserID, key: reservation.Key}
+	for other, stored := range r.store.idempotencyKeys {
+		if other != k && !now.Before(stored.ExpiresAt) {
+			delete(r.store.idempotencyKeys, other)
+		}
+	}
+
+	if stored, ok := r.store.idempotencyKeys[k]; ok && !stored.CanBeTakenOver(now, lease) {
+		stored = copyIdempotencyKey(stored)
+
+		return &stored, nil
+	}
+
+	r.store.idempotencyKeys[k] = copyIdempotencyKey(reservation)
+
+	return &reservation, nil
+}
+
+// Complete stores the response of the reservation, unless another request
+// took the key over meanwhile.
+func (r *memoryIdempotencyRepository) Complete(_ context.Context, reservation domain.IdempotencyKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	k := memoryIdempotencyKey{userID: reservation.UserID, key: reservation.Key}
+	stored, ok := r.store.idempotencyKeys[k]
+	if !ok || stored.Token != reservation.Token {
+		return nil
+	}
+
+	stored.StatusCode = reservation.StatusCode
+	stored.ResponseHeaders = reservation.ResponseHeaders
+	stored.ResponseBody = reservation.ResponseBody
+	r.store.idempotencyKeys[k] = copyIdempotencyKey(stored)
+
+	return nil
+}
+
+// Release removes a reservation whose request did not complete so that it
+// can be retried.
+func (r *memoryIdempotencyRepository) Release(_ context.Context, reservation domain.IdempotencyKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	k := memoryIdempotencyKey{userID: reservation.UserID, key: reservation.Key}
+	if stored, ok := r.store.idempotencyKeys[k]; ok && stored.Token == reservation.Token && !stored.IsCompleted() {
+		delete(r.store.idempotencyKeys, k)
+	}
+
+	return nil
+}
+
+func copyIdempotencyKey(k domain.IdempotencyKey) domain.IdempotencyKey {
+	if k.ResponseHeaders != nil {
+		headers := make(map[string][]string, len(k.ResponseHeaders))
+		for name, values := range k.ResponseHeaders {
+			headers[name] = append([]string(nil), values...)
+		}
+
+		k.ResponseHeaders = headers
+	}
+
+	k.ResponseBody = append([]byte(nil), k.ResponseBody...)
+
+	return k
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type memoryLoginFailureRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryLoginFailureRepository(store *MemoryStore) LoginFailureRepository {
+	return &memoryLoginFailureRepository{store: store}
+}
+
+func (r *memoryLoginFailureRepository) Find(_ context.Context, key string) (*domain.LoginFailures, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	failures, ok := r.store.loginFailures[key]
+	if !ok {
+		return nil, nil
+	}
+
+	failures.LockedUntil = copyTime(failures.LockedUntil)
+
+	return &failures, nil
+}
+
+func (r *memoryLoginFailureRepository) Attempt(ctx context.Context, key string, now time.Time, policy domain.LoginPolicy) (*domain.LoginFailures, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for k, failures := range r.store.loginFailures {
+		if k != key && !now.Before(failures.ExpiresAt) {
+			delete(r.store.loginFailures, k)
+		}
+	}
+
+	failures, ok := r.store.loginFailures[key]
+	if !ok {
+		failures = domain.LoginFailures{Key: key, LastFailedAt: now, ExpiresAt: now}
+	}
+
+	failures.LockedUntil = copyTime(failures.LockedUntil)
+	locked, err := failures.Attempt(now, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if locked {
+		event, err := domain.NewAuditEvent(ctx, domain.AuditLoginLocked, nil, domain.AuditTargetLogin, nil, nil, domain.NewAuditLoginFailures(failures))
+		if err != nil {
+			return nil, err
+		}
+
+		r.store.recordAudit(&event, now)
+	}
+
+	r.store.loginFailures[key] = failures
+	failures.LockedUntil = copyTime(failures.LockedUntil)
+
+	return &failures, nil
+}
+
+func (r *memoryLoginFailureRepository) Release(_ context.Context, key string, policy domain.LoginPolicy) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	failures, ok := r.store.loginFailures[key]
+	if !ok {
+		return nil
+	}
+
+	failures.LockedUntil = copyTime(failures.LockedUntil)
+	failures.Release(policy)
+	r.store.loginFailures[key] = failures
+
+	return nil
+}
+
+func (r *memoryLoginFailureRepository) Reset(_ context.Context, key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.loginFailures, key)
+
+	return nil
+}
+
+func (r *memoryLoginFailureRepository) Unlock(ctx context.Context, key string, actorID *uint, targetID *uint) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	failures, ok := r.store.loginFailures[key]
+	if !ok {
+		return false, nil
+	}
+
+	event, err := domain.NewAuditEvent(ctx, domain.AuditLoginUnlocked, actorID, domain.AuditTargetUser, targetID, domain.NewAuditLoginFailures(failures), nil)
+	if err != nil {
+		return false, err
+	}
+
+	delete(r.store.loginFailures, key)
+	r.store.recordAudit(&event, time.Now())
+
+	return true, nil
+}
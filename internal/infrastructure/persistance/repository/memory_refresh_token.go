@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type memoryRefreshTokenRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryRefreshTokenRepository(store *MemoryStore) RefreshTokenRepository {
+	return &memoryRefreshTokenRepository{store: store}
+}
+
+func (r *memoryRefreshTokenRepository) Create(_ context.Context, token *domain.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token.ID = r.store.nextID("refresh_tokens")
+	stored := copyRefreshToken(*token)
+	stored.CreatedAt = time.Now()
+	r.store.refreshTokens[token.ID] = stored
+
+	return nil
+}
+
+// FindByHash returns the token with the hash, nil if there is none.
+func (r *memoryRefreshTokenRepository) FindByHash(_ context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, token := range r.store.refreshTokens {
+		if token.TokenHash == tokenHash {
+			t := copyRefreshToken(token)
+
+			return &t, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Use marks the token used, see refreshTokenRepository.Use.
+func (r *memoryRefreshTokenRepository) Use(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.refreshTokens[id]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return domain.ErrRefreshTokenReused
+	}
+
+	now := time.Now()
+	token.UsedAt = &now
+	r.store.refreshTokens[id] = token
+
+	return nil
+}
+
+// RevokeFamily revokes every token of the family that is not revoked yet.
+func (r *memoryRefreshTokenRepository) RevokeFamily(_ context.Context, familyID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range r.store.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			revokedAt := now
+			token.RevokedAt = &revokedAt
+			r.store.refreshTokens[id] = token
+		}
+	}
+
+	return nil
+}
+
+func copyRefreshToken(t domain.RefreshToken) domain.RefreshToken {
+	t.UsedAt = copyTime(t.UsedAt)
+	t.RevokedAt = copyTime(t.RevokedAt)
+
+	return t
+}
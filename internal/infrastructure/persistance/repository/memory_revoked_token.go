@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+type memoryRevokedTokenRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryRevokedTokenRepository(store *MemoryStore) RevokedTokenRepository {
+	return &memoryRevokedTokenRepository{store: store}
+}
+
+// Revoke adds the token to the denylist and drops the tokens that expired
+// from it. Revoking a token twice is not an error.
+func (r *memoryRevokedTokenRepository) Revoke(_ context.Context, jti string, expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range r.store.revokedTokens {
+		if exp.Before(now) {
+			delete(r.store.revokedTokens, id)
+		}
+	}
+
+	if _, ok := r.store.revokedTokens[jti]; !ok {
+		r.store.revokedTokens[jti] = expiresAt
+	}
+
+	return nil
+}
+
+func (r *memoryRevokedTokenRepository) IsRevoked(_ context.Context, jti string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	_, ok := r.store.revokedTokens[jti]
+
+	return ok, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+)
+
+type memoryUnitOfWork struct {
+	store *MemoryStore
+}
+
+func NewMemoryUnitOfWork(store *MemoryStore) UnitOfWork {
+	return &memoryUnitOfWork{store: store}
+}
+
+// Do holds the lock of the store while fn runs and puts the data of the store
+// back the way it was if fn fails. Copying the store makes a unit as slow as
+// the store is big, which is fine for what the store is meant for.
+func (u *memoryUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	saved := u.store.memoryData.clone()
+	err := fn(ctx, Repositories{
+		Users:     &memoryUserRepository{store: u.store, mu: unlocked{}},
+		GiftCards: &memoryGiftCardRepository{store: u.store, mu: unlocked{}},
+		Wallets:   &memoryWalletRepository{store: u.store, mu: unlocked{}},
+		Webhooks:  &memoryWebhookRepository{store: u.store, mu: unlocked{}},
+	})
+	if err != nil {
+		u.store.memoryData = saved
+
+		return err
+	}
+
+	return nil
+}
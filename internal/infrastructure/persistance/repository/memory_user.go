@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type memoryUserRepository struct {
+	store *MemoryStore
+	mu    sync.Locker
+}
+
+func NewMemoryUserRepository(store *MemoryStore) UserRepository {
+	return &memoryUserRepository{store: store, mu: &store.mu}
+}
+
+// Create stores the user and returns domain.ErrEmailTaken if its email is
+// already registered.
+func (r *memoryUserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.store.users {
+		if u.Email == user.Email {
+			return domain.ErrEmailTaken
+		}
+	}
+
+	now := time.Now()
+	user.ID = r.store.nextID("users")
+	stored := copyUser(*user)
+	stored.CreatedAt = now
+	event, err := domain.NewAuditEvent(ctx, domain.AuditUserRegistered, &user.ID, domain.AuditTargetUser, &user.ID, nil, domain.NewAuditUser(stored))
+	if err != nil {
+		return err
+	}
+
+	r.store.users[user.ID] = stored
+	r.store.recordAudit(&event, now)
+
+	return nil
+}
+
+// FindByID returns the user with the id, nil if there is none.
+func (r *memoryUserRepository) FindByID(_ context.Context, id uint) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return nil, nil
+	}
+
+	u = copyUser(u)
+
+	return &u, nil
+}
+
+// FindByEmail returns the user with the email, nil if there is none.
+func (r *memoryUserRepository) FindByEmail(_ context.Context, email string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.store.users {
+		if u.Email == email {
+			u = copyUser(u)
+
+			return &u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// SetRoles replaces the roles of the user, it returns domain.ErrUserNotFound
+// if there is no such user.
+func (r *memoryUserRepository) SetRoles(ctx context.Context, id uint, roles []domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+
+	updated := copyUser(u)
+	updated.Roles = append([]domain.Role(nil), roles...)
+	event, err := domain.NewAuditEvent(ctx, domain.AuditUserRolesChanged, nil, domain.AuditTargetUser, &id, domain.NewAuditUser(u), domain.NewAuditUser(updated))
+	if err != nil {
+		return err
+	}
+
+	r.store.users[id] = updated
+	r.store.recordAudit(&event, time.Now())
+
+	return nil
+}
+
+func copyUser(u domain.User) domain.User {
+	u.Roles = append([]domain.Role(nil), u.Roles...)
+
+	return u
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type memoryWalletRepository struct {
+	store *MemoryStore
+	mu    sync.Locker
+}
+
+func NewMemoryWalletRepository(store *MemoryStore) WalletRepository {
+	return &memoryWalletRepository{store: store, mu: &store.mu}
+}
+
+func (r *memoryWalletRepository) FindByUserID(_ context.Context, userID uint, currency string) (*domain.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet, ok := r.store.wallets[memoryWalletKey{userID: userID, currency: currency}]
+	if !ok {
+		return nil, nil
+	}
+
+	w := *wallet
+
+	return &w, nil
+}
+
+func (r *memoryWalletRepository) Deposit(ctx context.Context, userID uint, amount domain.Money, actorID *uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event, err := domain.NewAuditEvent(ctx, domain.AuditWalletDeposited, actorID, domain.AuditTargetUser, &userID, nil, domain.NewAuditDeposit(userID, amount))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	r.store.credit(userID, amount, now)
+	r.store.recordAudit(&event, now)
+
+	return nil
+}
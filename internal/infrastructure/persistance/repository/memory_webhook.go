@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type memoryWebhookRepository struct {
+	store *MemoryStore
+	mu    sync.Locker
+}
+
+func NewMemoryWebhookRepository(store *MemoryStore) WebhookRepository {
+	return &memoryWebhookRepository{store: store, mu: &store.mu}
+}
+
+func (r *memoryWebhookRepository) Create(_ context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook.ID = r.store.nextID("webhooks")
+	stored := *webhook
+	stored.CreatedAt = time.Now()
+	r.store.webhooks[webhook.ID] = stored
+
+	return nil
+}
+
+func (r *memoryWebhookRepository) FindByID(_ context.Context, id uint) (*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, ok := r.store.webhooks[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &webhook, nil
+}
+
+func (r *memoryWebhookRepository) FindByUserID(_ context.Context, userID uint) ([]domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var webhooks []domain.Webhook
+	for _, webhook := range r.store.webhooks {
+		if webhook.UserID == userID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+
+	return webhooks, nil
+}
+
+func (r *memoryWebhookRepository) Update(_ context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.store.webhooks[webhook.ID]
+	if !ok {
+		return domain.ErrWebhookNotFound
+	}
+
+	stored.URL = webhook.URL
+	stored.Active = webhook.Active
+	r.store.webhooks[webhook.ID] = stored
+
+	return nil
+}
+
+// Delete removes the webhook along with its delivery log.
+func (r *memoryWebhookRepository) Delete(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.store.webhooks[id]; !ok {
+		return domain.ErrWebhookNotFound
+	}
+
+	delete(r.store.webhooks, id)
+	for deliveryID, delivery := range r.store.deliveries {
+		if delivery.WebhookID == id {
+			delete(r.store.deliveries, deliveryID)
+		}
+	}
+
+	return nil
+}
+
+// FindDeliveriesByWebhookID returns a page of the deliveries of the webhook,
+// the latest first, and the total number of its deliveries.
+func (r *memoryWebhookRepository) FindDeliveriesByWebhookID(_ context.Context, webhookID uint, pageSize int, pageNumber int) ([]domain.WebhookDelivery, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deliveries []domain.WebhookDelivery
+	for _, delivery := range r.store.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, copyWebhookDelivery(delivery))
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+
+	return page(deliveries, pageSize, pageNumber), len(deliveries), nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries that are due at
+// now and pushes their next attempt back by lease, see
+// webhookRepository.ClaimDueDeliveries.
+func (r *memoryWebhookRepository) ClaimDueDeliveries(_ context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*domain.WebhookDelivery
+	for _, delivery := range r.store.deliveries {
+		if delivery.Status == domain.WDSPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+
+		return due[i].ID < due[j].ID
+	})
+
+	var deliveries []domain.WebhookDelivery
+	for _, delivery := range due {
+		if len(deliveries) == limit {
+			break
+		}
+
+		deliveries = append(deliveries, copyWebhookDelivery(delivery))
+		delivery.NextAttemptAt = now.Add(lease)
+	}
+
+	return deliveries, nil
+}
+
+func (r *memoryWebhookRepository) UpdateDelivery(_ context.Context, delivery domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.store.deliveries[delivery.ID]
+	if !ok {
+		return nil
+	}
+
+	lastError := delivery.LastError
+	if len(lastError) > maxWebhookLastErrorLength {
+		lastError = lastError[:maxWebhookLastErrorLength]
+	}
+
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastStatusCode = delivery.LastStatusCode
+	stored.LastError = lastError
+	stored.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func copyWebhookDelivery(d *domain.WebhookDelivery) domain.WebhookDelivery {
+	c := *d
+	c.Payload = append([]byte(nil), d.Payload...)
+
+	return c
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/stretchr/testify/mock"
 
 	"github.com/jmehdipour/gift-card/internal/domain"
@@ -10,15 +13,15 @@ type UserRepositoryMock struct {
 	mock.Mock
 }
 
-func (u *UserRepositoryMock) Create(user *domain.User) error {
-	args := u.Called(user)
+func (u *UserRepositoryMock) Create(ctx context.Context, user *domain.User) error {
+	args := u.Called(ctx, user)
 	user.ID = 15
 
 	return args.Error(0)
 }
 
-func (u *UserRepositoryMock) FindByEmail(email string) (*domain.User, error) {
-	args := u.Called(email)
+func (u *UserRepositoryMock) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	args := u.Called(ctx, email)
 
 	var r0 *domain.User
 	if args.Get(0) != nil {
@@ -28,19 +31,47 @@ func (u *UserRepositoryMock) FindByEmail(email string) (*domain.User, error) {
 	return r0, args.Error(1)
 }
 
+func (u *UserRepositoryMock) FindByID(ctx context.Context, id uint) (*domain.User, error) {
+	args := u.Called(ctx, id)
+
+	var r0 *domain.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.User)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (u *UserRepositoryMock) SetRoles(ctx context.Context, id uint, roles []domain.Role) error {
+	args := u.Called(ctx, id, roles)
+
+	return args.Error(0)
+}
+
 type GiftCardRepositoryMock struct {
 	mock.Mock
 }
 
-func (r *GiftCardRepositoryMock) Create(giftCard *domain.GiftCard) error {
-	args := r.Called(giftCard)
+func (r *GiftCardRepositoryMock) Create(ctx context.Context, giftCard *domain.GiftCard) error {
+	args := r.Called(ctx, giftCard)
 	giftCard.ID = 15
 
 	return args.Error(0)
 }
 
-func (r *GiftCardRepositoryMock) FindByID(id uint) (*domain.GiftCard, error) {
-	args := r.Called(id)
+func (r *GiftCardRepositoryMock) FindByID(ctx context.Context, id uint) (*domain.GiftCard, error) {
+	args := r.Called(ctx, id)
+
+	var r0 *domain.GiftCard
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.GiftCard)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *GiftCardRepositoryMock) FindByIDForUpdate(ctx context.Context, id uint) (*domain.GiftCard, error) {
+	args := r.Called(ctx, id)
 
 	var r0 *domain.GiftCard
 	if args.Get(0) != nil {
@@ -50,30 +81,363 @@ func (r *GiftCardRepositoryMock) FindByID(id uint) (*domain.GiftCard, error) {
 	return r0, args.Error(1)
 }
 
-func (r *GiftCardRepositoryMock) UpdateStatus(id uint, status domain.GiftCardStatus) error {
-	args := r.Called(id, status)
+func (r *GiftCardRepositoryMock) UpdateStatus(ctx context.Context, id uint, version *uint, status domain.GiftCardStatus, actorID *uint) error {
+	args := r.Called(ctx, id, version, status, actorID)
 
 	return args.Error(0)
 }
 
-func (r *GiftCardRepositoryMock) FindReceivedGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error) {
-	args := r.Called(userID, status, pageSize, pageNumber)
+func (r *GiftCardRepositoryMock) ForceUpdateStatus(ctx context.Context, id uint, version *uint, status domain.GiftCardStatus, actorID *uint) error {
+	args := r.Called(ctx, id, version, status, actorID)
 
-	var r0 []domain.GiftCard
+	return args.Error(0)
+}
+
+func (r *GiftCardRepositoryMock) FindStatusHistory(ctx context.Context, id uint) ([]domain.GiftCardStatusChange, error) {
+	args := r.Called(ctx, id)
+
+	var r0 []domain.GiftCardStatusChange
 	if args.Get(0) != nil {
-		r0 = args.Get(0).([]domain.GiftCard)
+		r0 = args.Get(0).([]domain.GiftCardStatusChange)
 	}
 
-	return r0, args.Int(1), args.Error(2)
+	return r0, args.Error(1)
+}
+
+func (r *GiftCardRepositoryMock) FindOverdueGiftCardIDs(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	args := r.Called(ctx, now, limit)
+
+	var r0 []uint
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]uint)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *GiftCardRepositoryMock) Redeem(ctx context.Context, code string, redeemerID uint, amount domain.Money) (*domain.GiftCardRedemption, error) {
+	args := r.Called(ctx, code, redeemerID, amount)
+
+	var r0 *domain.GiftCardRedemption
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.GiftCardRedemption)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *GiftCardRepositoryMock) FindReceivedGiftCardsByUserID(ctx context.Context, userID uint, query GiftCardQuery) (GiftCardPage, error) {
+	args := r.Called(ctx, userID, query)
+
+	return args.Get(0).(GiftCardPage), args.Error(1)
+}
+
+func (r *GiftCardRepositoryMock) FindSentGiftCardsByUserID(ctx context.Context, userID uint, query GiftCardQuery) (GiftCardPage, error) {
+	args := r.Called(ctx, userID, query)
+
+	return args.Get(0).(GiftCardPage), args.Error(1)
+}
+
+type WalletRepositoryMock struct {
+	mock.Mock
+}
+
+func (w *WalletRepositoryMock) FindByUserID(ctx context.Context, userID uint, currency string) (*domain.Wallet, error) {
+	args := w.Called(ctx, userID, currency)
+
+	var r0 *domain.Wallet
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.Wallet)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (w *WalletRepositoryMock) Deposit(ctx context.Context, userID uint, amount domain.Money, actorID *uint) error {
+	args := w.Called(ctx, userID, amount, actorID)
+
+	return args.Error(0)
+}
+
+// UnitOfWorkMock runs the function it is given with Repositories, which
+// tests set to the mocks of the repositories the unit is expected to use.
+type UnitOfWorkMock struct {
+	mock.Mock
+	Repositories Repositories
+}
+
+func (u *UnitOfWorkMock) Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	args := u.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+
+	return fn(ctx, u.Repositories)
+}
+
+type LedgerRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *LedgerRepositoryMock) Snapshot(ctx context.Context) (*domain.LedgerSnapshot, error) {
+	args := r.Called(ctx)
+
+	var r0 *domain.LedgerSnapshot
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.LedgerSnapshot)
+	}
+
+	return r0, args.Error(1)
+}
+
+type IdempotencyRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *IdempotencyRepositoryMock) Reserve(ctx context.Context, reservation domain.IdempotencyKey, lease time.Duration) (*domain.IdempotencyKey, error) {
+	args := r.Called(ctx, reservation, lease)
+
+	var r0 *domain.IdempotencyKey
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.IdempotencyKey)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *IdempotencyRepositoryMock) Complete(ctx context.Context, reservation domain.IdempotencyKey) error {
+	args := r.Called(ctx, reservation)
+
+	return args.Error(0)
+}
+
+func (r *IdempotencyRepositoryMock) Release(ctx context.Context, reservation domain.IdempotencyKey) error {
+	args := r.Called(ctx, reservation)
+
+	return args.Error(0)
+}
+
+type OutboxRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *OutboxRepositoryMock) Relay(ctx context.Context, limit int, publish func(event domain.Event) error) (int, error) {
+	args := r.Called(ctx, limit, publish)
+
+	return args.Int(0), args.Error(1)
+}
+
+type WebhookRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *WebhookRepositoryMock) Create(ctx context.Context, webhook *domain.Webhook) error {
+	args := r.Called(ctx, webhook)
+
+	return args.Error(0)
+}
+
+func (r *WebhookRepositoryMock) FindByID(ctx context.Context, id uint) (*domain.Webhook, error) {
+	args := r.Called(ctx, id)
+
+	var r0 *domain.Webhook
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.Webhook)
+	}
+
+	return r0, args.Error(1)
 }
 
-func (r *GiftCardRepositoryMock) FindSentGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error) {
-	args := r.Called(userID, status, pageSize, pageNumber)
+func (r *WebhookRepositoryMock) FindByUserID(ctx context.Context, userID uint) ([]domain.Webhook, error) {
+	args := r.Called(ctx, userID)
 
-	var r0 []domain.GiftCard
+	var r0 []domain.Webhook
 	if args.Get(0) != nil {
-		r0 = args.Get(0).([]domain.GiftCard)
+		r0 = args.Get(0).([]domain.Webhook)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *WebhookRepositoryMock) Update(ctx context.Context, webhook *domain.Webhook) error {
+	args := r.Called(ctx, webhook)
+
+	return args.Error(0)
+}
+
+func (r *WebhookRepositoryMock) Delete(ctx context.Context, id uint) error {
+	args := r.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (r *WebhookRepositoryMock) FindDeliveriesByWebhookID(ctx context.Context, webhookID uint, pageSize int, pageNumber int) ([]domain.WebhookDelivery, int, error) {
+	args := r.Called(ctx, webhookID, pageSize, pageNumber)
+
+	var r0 []domain.WebhookDelivery
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]domain.WebhookDelivery)
 	}
 
 	return r0, args.Int(1), args.Error(2)
 }
+
+func (r *WebhookRepositoryMock) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	args := r.Called(ctx, now, lease, limit)
+
+	var r0 []domain.WebhookDelivery
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]domain.WebhookDelivery)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *WebhookRepositoryMock) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	args := r.Called(ctx, delivery)
+
+	return args.Error(0)
+}
+
+type RefreshTokenRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *RefreshTokenRepositoryMock) Create(ctx context.Context, token *domain.RefreshToken) error {
+	args := r.Called(ctx, token)
+
+	return args.Error(0)
+}
+
+func (r *RefreshTokenRepositoryMock) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	args := r.Called(ctx, tokenHash)
+
+	var r0 *domain.RefreshToken
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.RefreshToken)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *RefreshTokenRepositoryMock) Use(ctx context.Context, id uint) error {
+	args := r.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (r *RefreshTokenRepositoryMock) RevokeFamily(ctx context.Context, familyID string) error {
+	args := r.Called(ctx, familyID)
+
+	return args.Error(0)
+}
+
+type RevokedTokenRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *RevokedTokenRepositoryMock) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	args := r.Called(ctx, jti, expiresAt)
+
+	return args.Error(0)
+}
+
+func (r *RevokedTokenRepositoryMock) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	args := r.Called(ctx, jti)
+
+	return args.Bool(0), args.Error(1)
+}
+
+type AuditRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *AuditRepositoryMock) Record(ctx context.Context, event *domain.AuditEvent) error {
+	args := r.Called(ctx, event)
+
+	return args.Error(0)
+}
+
+func (r *AuditRepositoryMock) Enqueue(ctx context.Context, event *domain.AuditEvent) error {
+	args := r.Called(ctx, event)
+
+	return args.Error(0)
+}
+
+func (r *AuditRepositoryMock) ChainPending(ctx context.Context, limit int) (int, error) {
+	args := r.Called(ctx, limit)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (r *AuditRepositoryMock) Find(ctx context.Context, query AuditQuery) ([]domain.AuditEvent, error) {
+	args := r.Called(ctx, query)
+
+	var r0 []domain.AuditEvent
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]domain.AuditEvent)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *AuditRepositoryMock) FindAfter(ctx context.Context, afterID uint, limit int) ([]domain.AuditEvent, error) {
+	args := r.Called(ctx, afterID, limit)
+
+	var r0 []domain.AuditEvent
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]domain.AuditEvent)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *AuditRepositoryMock) LastHash(ctx context.Context) (string, error) {
+	args := r.Called(ctx)
+
+	return args.String(0), args.Error(1)
+}
+
+type LoginFailureRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *LoginFailureRepositoryMock) Find(ctx context.Context, key string) (*domain.LoginFailures, error) {
+	args := r.Called(ctx, key)
+
+	var r0 *domain.LoginFailures
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.LoginFailures)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *LoginFailureRepositoryMock) Attempt(ctx context.Context, key string, now time.Time, policy domain.LoginPolicy) (*domain.LoginFailures, error) {
+	args := r.Called(ctx, key, now, policy)
+
+	var r0 *domain.LoginFailures
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.LoginFailures)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (r *LoginFailureRepositoryMock) Release(ctx context.Context, key string, policy domain.LoginPolicy) error {
+	args := r.Called(ctx, key, policy)
+
+	return args.Error(0)
+}
+
+func (r *LoginFailureRepositoryMock) Reset(ctx context.Context, key string) error {
+	args := r.Called(ctx, key)
+
+	return args.Error(0)
+}
+
+func (r *LoginFailureRepositoryMock) Unlock(ctx context.Context, key string, actorID *uint, targetID *uint) (bool, error) {
+	args := r.Called(ctx, key, actorID, targetID)
+
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type OutboxRepository interface {
+	Relay(ctx context.Context, limit int, publish func(event domain.Event) error) (int, error)
+}
+
+type OutboxEventEntity struct {
+	ID          uint
+	EventType   string
+	AggregateID uint
+	Payload     []byte
+	CreatedAt   time.Time
+}
+
+func (e OutboxEventEntity) ToAggregate() domain.Event {
+	return domain.Event{
+		ID:          e.ID,
+		Type:        domain.EventType(e.EventType),
+		AggregateID: e.AggregateID,
+		Payload:     e.Payload,
+		CreatedAt:   e.CreatedAt,
+	}
+}
+
+type outboxRepository struct {
+	db sqlDB
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: newSQLDB(db)}
+}
+
+// Relay locks up to limit unpublished events, hands them to publish in the
+// order they were recorded and marks every published event. It stops at the
+// first event publish fails for, the events published before it stay marked
+// and the rest are retried on the next call. Locked events are skipped, so
+// several relays can run at once without publishing an event twice.
+func (r *outboxRepository) Relay(ctx context.Context, limit int, publish func(event domain.Event) error) (int, error) {
+	published := 0
+	var publishErr error
+	err := withTx(ctx, r.db, func(tx sqlTx) error {
+		query := "SELECT id, event_type, aggregate_id, payload, created_at FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED"
+		rows, err := tx.QueryContext(ctx, query, limit)
+		if err != nil {
+			return err
+		}
+
+		var events []domain.Event
+		for rows.Next() {
+			var e OutboxEventEntity
+			err := rows.Scan(&e.ID, &e.EventType, &e.AggregateID, &e.Payload, &e.CreatedAt)
+			if err != nil {
+				_ = rows.Close()
+
+				return err
+			}
+
+			events = append(events, e.ToAggregate())
+		}
+
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if publishErr = publish(event); publishErr != nil {
+				break
+			}
+
+			_, err := tx.ExecContext(ctx, "UPDATE outbox SET published_at = NOW() WHERE id = ?", event.ID)
+			if err != nil {
+				return err
+			}
+
+			published++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return published, publishErr
+}
+
+// recordEvent adds the event to the outbox and sets its ID. It is called with
+// the transaction of the state change the event describes, so the event is
+// stored if and only if the change is. The payload is passed as a string,
+// lib/pq would send bytes as bytea which a JSON column does not accept.
+func recordEvent(ctx context.Context, db executor, event *domain.Event) error {
+	query := "INSERT INTO outbox (event_type, aggregate_id, payload, created_at) VALUES (?, ?, ?, NOW())"
+	id, err := insert(ctx, db, query, string(event.Type), event.AggregateID, string(event.Payload))
+	if err != nil {
+		return err
+	}
+
+	event.ID = id
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type OutboxRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *outboxRepository
+}
+
+func (suite *OutboxRepositoryTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.repo = &outboxRepository{
+		db: newSQLDB(suite.db),
+	}
+}
+
+func (suite *OutboxRepositoryTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *OutboxRepositoryTestSuite) TestNewOutboxRepository() {
+	require := suite.Require()
+
+	db, _, _ := sqlmock.New()
+	repo := NewOutboxRepository(db)
+
+	require.NotNil(repo)
+}
+
+func (suite *OutboxRepositoryTestSuite) expectUnpublishedEvents(limit int, createdAt time.Time) {
+	rows := sqlmock.NewRows([]string{"id", "event_type", "aggregate_id", "payload", "created_at"}).
+		AddRow(1, string(domain.EventGiftCardCreated), 101, []byte(`{"gift_card_id":101}`), createdAt).
+		AddRow(2, string(domain.EventGiftCardStatusChanged), 101, []byte(`{"gift_card_id":101}`), createdAt)
+	suite.mock.ExpectQuery("^SELECT .+ FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT \\? FOR UPDATE SKIP LOCKED$").
+		WithArgs(limit).
+		WillReturnRows(rows)
+}
+
+func (suite *OutboxRepositoryTestSuite) TestRelay_Success() {
+	require := suite.Require()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedEvents := []domain.Event{
+		{ID: 1, Type: domain.EventGiftCardCreated, AggregateID: 101, Payload: []byte(`{"gift_card_id":101}`), CreatedAt: createdAt},
+		{ID: 2, Type: domain.EventGiftCardStatusChanged, AggregateID: 101, Payload: []byte(`{"gift_card_id":101}`), CreatedAt: createdAt},
+	}
+
+	suite.mock.ExpectBegin()
+	suite.expectUnpublishedEvents(10, createdAt)
+	suite.mock.ExpectExec("^UPDATE outbox SET published_at = NOW\\(\\) WHERE id = \\?$").
+		WithArgs(uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec("^UPDATE outbox SET published_at = NOW\\(\\) WHERE id = \\?$").
+		WithArgs(uint(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	var events []domain.Event
+	published, err := suite.repo.Relay(context.Background(), 10, func(event domain.Event) error {
+		events = append(events, event)
+
+		return nil
+	})
+
+	require.NoError(err)
+	require.Equal(2, published)
+	require.Equal(expectedEvents, events)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *OutboxRepositoryTestSuite) TestRelay_PublishError_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("publish error")
+
+	suite.mock.ExpectBegin()
+	suite.expectUnpublishedEvents(10, time.Now())
+	suite.mock.ExpectExec("^UPDATE outbox SET published_at").
+		WithArgs(uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	published, err := suite.repo.Relay(context.Background(), 10, func(event domain.Event) error {
+		if event.ID == 2 {
+			return expectedError
+		}
+
+		return nil
+	})
+
+	require.ErrorIs(err, expectedError)
+	require.Equal(1, published)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *OutboxRepositoryTestSuite) TestRelay_DBError_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("db error")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT .+ FROM outbox").
+		WithArgs(10).
+		WillReturnError(expectedError)
+	suite.mock.ExpectRollback()
+
+	published, err := suite.repo.Relay(context.Background(), 10, func(event domain.Event) error {
+		return nil
+	})
+
+	require.ErrorIs(err, expectedError)
+	require.Zero(published)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func expectRecordEvent(mock sqlmock.Sqlmock, t domain.EventType, aggregateID uint, payload string) {
+	mock.ExpectExec("^INSERT INTO outbox").
+		WithArgs(string(t), aggregateID, payload).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestOutboxRepository(t *testing.T) {
+	suite.Run(t, new(OutboxRepositoryTestSuite))
+}
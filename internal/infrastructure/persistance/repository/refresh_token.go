@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *domain.RefreshToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	Use(ctx context.Context, id uint) error
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+type RefreshTokenEntity struct {
+	ID        uint
+	UserID    uint
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	RevokedAt sql.NullTime
+	CreatedAt time.Time
+}
+
+func (t RefreshTokenEntity) ToAggregate() domain.RefreshToken {
+	token := domain.RefreshToken{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		FamilyID:  t.FamilyID,
+		TokenHash: t.TokenHash,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+	}
+
+	if t.UsedAt.Valid {
+		usedAt := t.UsedAt.Time
+		token.UsedAt = &usedAt
+	}
+
+	if t.RevokedAt.Valid {
+		revokedAt := t.RevokedAt.Time
+		token.RevokedAt = &revokedAt
+	}
+
+	return token
+}
+
+type refreshTokenRepository struct {
+	db sqlDB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: newSQLDB(db)}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	query := "INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?, NOW())"
+	id, err := insert(ctx, r.db, query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	token.ID = id
+
+	return nil
+}
+
+// FindByHash returns the token with the hash, nil if there is none.
+func (r *refreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var e RefreshTokenEntity
+	err := r.db.
+		QueryRowContext(ctx, "SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at FROM refresh_tokens WHERE token_hash = ?", tokenHash).
+		Scan(&e.ID, &e.UserID, &e.FamilyID, &e.TokenHash, &e.ExpiresAt, &e.UsedAt, &e.RevokedAt, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	token := e.ToAggregate()
+
+	return &token, nil
+}
+
+// Use marks the token used. Only one of the requests racing to use a token
+// gets to, the others and any later one get domain.ErrRefreshTokenReused, as
+// does a token that was revoked.
+func (r *refreshTokenRepository) Use(ctx context.Context, id uint) error {
+	query := "UPDATE refresh_tokens SET used_at = NOW() WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL"
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrRefreshTokenReused
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every token of the family that is not revoked yet.
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, familyID)
+
+	return err
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type RefreshTokenRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *refreshTokenRepository
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.repo = &refreshTokenRepository{
+		db: newSQLDB(suite.db),
+	}
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestNewRefreshTokenRepository() {
+	require := suite.Require()
+
+	db, _, _ := sqlmock.New()
+	repo := NewRefreshTokenRepository(db)
+
+	require.NotNil(repo)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestCreate_Success() {
+	require := suite.Require()
+	expiresAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	token := &domain.RefreshToken{UserID: 10, FamilyID: "family", TokenHash: "hash", ExpiresAt: expiresAt}
+
+	suite.mock.ExpectExec("^INSERT INTO refresh_tokens \\(user_id, family_id, token_hash, expires_at, created_at\\) VALUES \\(\\?, \\?, \\?, \\?, NOW\\(\\)\\)$").
+		WithArgs(uint(10), "family", "hash", expiresAt).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+
+	err := suite.repo.Create(context.Background(), token)
+
+	require.NoError(err)
+	require.Equal(uint(5), token.ID)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestFindByHash_Success() {
+	require := suite.Require()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(time.Hour)
+	usedAt := createdAt.Add(time.Minute)
+	expectedResult := &domain.RefreshToken{
+		ID:        5,
+		UserID:    10,
+		FamilyID:  "family",
+		TokenHash: "hash",
+		ExpiresAt: expiresAt,
+		UsedAt:    &usedAt,
+		CreatedAt: createdAt,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "family_id", "token_hash", "expires_at", "used_at", "revoked_at", "created_at"}).
+		AddRow(5, 10, "family", "hash", expiresAt, usedAt, nil, createdAt)
+	suite.mock.ExpectQuery("^SELECT .+ FROM refresh_tokens WHERE token_hash = \\?$").
+		WithArgs("hash").
+		WillReturnRows(rows)
+
+	token, err := suite.repo.FindByHash(context.Background(), "hash")
+
+	require.NoError(err)
+	require.Equal(expectedResult, token)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestFindByHash_NotFound_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectQuery("^SELECT .+ FROM refresh_tokens WHERE token_hash = \\?$").
+		WithArgs("hash").
+		WillReturnError(sql.ErrNoRows)
+
+	token, err := suite.repo.FindByHash(context.Background(), "hash")
+
+	require.NoError(err)
+	require.Nil(token)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestFindByHash_DBError_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("database failure")
+
+	suite.mock.ExpectQuery("^SELECT .+ FROM refresh_tokens WHERE token_hash = \\?$").
+		WithArgs("hash").
+		WillReturnError(expectedError)
+
+	token, err := suite.repo.FindByHash(context.Background(), "hash")
+
+	require.EqualError(err, expectedError.Error())
+	require.Nil(token)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestUse_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectExec("^UPDATE refresh_tokens SET used_at = NOW\\(\\) WHERE id = \\? AND used_at IS NULL AND revoked_at IS NULL$").
+		WithArgs(uint(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.Use(context.Background(), 5)
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestUse_AlreadyUsed_Failure() {
+	require := suite.Require()
+
+	suite.mock.ExpectExec("^UPDATE refresh_tokens SET used_at = NOW\\(\\)").
+		WithArgs(uint(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.Use(context.Background(), 5)
+
+	require.ErrorIs(err, domain.ErrRefreshTokenReused)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestRevokeFamily_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectExec("^UPDATE refresh_tokens SET revoked_at = NOW\\(\\) WHERE family_id = \\? AND revoked_at IS NULL$").
+		WithArgs("family").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := suite.repo.RevokeFamily(context.Background(), "family")
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenRepository(t *testing.T) {
+	suite.Run(t, new(RefreshTokenRepositoryTestSuite))
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+// RevokedTokenRepository is the denylist of access tokens that were revoked
+// before they expire, by their jti. A token is only kept until it expires,
+// after that it is rejected anyway.
+type RevokedTokenRepository interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type revokedTokenRepository struct {
+	db sqlDB
+}
+
+func NewRevokedTokenRepository(db *sql.DB) RevokedTokenRepository {
+	return &revokedTokenRepository{db: newSQLDB(db)}
+}
+
+// Revoke adds the token to the denylist and drops the tokens that expired
+// from it. Revoking a token twice is not an error.
+func (r *revokedTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < NOW()")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, "INSERT INTO revoked_tokens (jti, expires_at) VALUES (?, ?)", jti, expiresAt)
+	if database.IsUniqueViolation(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked int
+	err := r.db.QueryRowContext(ctx, "SELECT 1 FROM revoked_tokens WHERE jti = ?", jti).Scan(&revoked)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/suite"
+)
+
+type RevokedTokenRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *revokedTokenRepository
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.repo = &revokedTokenRepository{
+		db: newSQLDB(suite.db),
+	}
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestNewRevokedTokenRepository() {
+	require := suite.Require()
+
+	db, _, _ := sqlmock.New()
+	repo := NewRevokedTokenRepository(db)
+
+	require.NotNil(repo)
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestRevoke_Success() {
+	require := suite.Require()
+	expiresAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.mock.ExpectExec("^DELETE FROM revoked_tokens WHERE expires_at < NOW\\(\\)$").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectExec("^INSERT INTO revoked_tokens \\(jti, expires_at\\) VALUES \\(\\?, \\?\\)$").
+		WithArgs("jti", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.Revoke(context.Background(), "jti", expiresAt)
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestRevoke_AlreadyRevoked_Success() {
+	require := suite.Require()
+	expiresAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.mock.ExpectExec("^DELETE FROM revoked_tokens").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec("^INSERT INTO revoked_tokens").
+		WithArgs("jti", expiresAt).
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'jti' for key 'PRIMARY'"})
+
+	err := suite.repo.Revoke(context.Background(), "jti", expiresAt)
+
+	require.NoError(err)
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestRevoke_DBError_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("database failure")
+
+	suite.mock.ExpectExec("^DELETE FROM revoked_tokens").
+		WillReturnError(expectedError)
+
+	err := suite.repo.Revoke(context.Background(), "jti", time.Now())
+
+	require.EqualError(err, expectedError.Error())
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestIsRevoked_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectQuery("^SELECT 1 FROM revoked_tokens WHERE jti = \\?$").
+		WithArgs("jti").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	revoked, err := suite.repo.IsRevoked(context.Background(), "jti")
+
+	require.NoError(err)
+	require.True(revoked)
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestIsRevoked_NotRevoked_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectQuery("^SELECT 1 FROM revoked_tokens WHERE jti = \\?$").
+		WithArgs("jti").
+		WillReturnError(sql.ErrNoRows)
+
+	revoked, err := suite.repo.IsRevoked(context.Background(), "jti")
+
+	require.NoError(err)
+	require.False(revoked)
+}
+
+func TestRevokedTokenRepository(t *testing.T) {
+	suite.Run(t, new(RevokedTokenRepositoryTestSuite))
+}
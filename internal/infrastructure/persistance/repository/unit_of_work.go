@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Repositories are the repositories a unit of work hands to its function.
+// They share its transaction and must not be used once the function returns.
+type Repositories struct {
+	Users     UserRepository
+	GiftCards GiftCardRepository
+	Wallets   WalletRepository
+	Webhooks  WebhookRepository
+}
+
+// UnitOfWork runs several repository operations atomically. Do commits what
+// fn did if it returns nil and rolls all of it back otherwise, including when
+// ctx is cancelled before the unit is committed.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
+
+type unitOfWork struct {
+	db sqlDB
+}
+
+func NewUnitOfWork(db *sql.DB) UnitOfWork {
+	return &unitOfWork{db: newSQLDB(db)}
+}
+
+// Do runs fn in a database transaction. Methods of the repositories that
+// write in a transaction of their own join this one instead, and rows read
+// with a ...ForUpdate method stay locked until it ends.
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	return withTx(ctx, u.db, func(tx sqlTx) error {
+		return fn(ctx, Repositories{
+			Users:     &userRepository{db: tx},
+			GiftCards: &giftCardRepository{db: tx},
+			Wallets:   &walletRepository{db: tx},
+			Webhooks:  &webhookRepository{db: tx},
+		})
+	})
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type UnitOfWorkTestSuite struct {
+	suite.Suite
+	db         *sql.DB
+	mock       sqlmock.Sqlmock
+	unitOfWork *unitOfWork
+}
+
+func (suite *UnitOfWorkTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.unitOfWork = &unitOfWork{
+		db: newSQLDB(suite.db),
+	}
+}
+
+func (suite *UnitOfWorkTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *UnitOfWorkTestSuite) TestNewUnitOfWork() {
+	require := suite.Require()
+
+	db, _, _ := sqlmock.New()
+	unitOfWork := NewUnitOfWork(db)
+
+	require.NotNil(unitOfWork)
+}
+
+func (suite *UnitOfWorkTestSuite) expectLockGiftCard(id uint) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := createdAt
+	rows := sqlmock.NewRows([]string{"id", "code", "sender_id", "receiver_id", "amount", "remaining_amount", "currency", "status", "expires_at", "version", "created_at", "updated_at"}).
+		AddRow(id, "0123456789ABCDEZ", 10, 20, 10000, 10000, "USD", int(domain.GCSPending), nil, 1, createdAt, updatedAt)
+	suite.mock.ExpectQuery("^SELECT .+ FROM gift_cards WHERE id = \\? FOR UPDATE$").
+		WithArgs(id).
+		WillReturnRows(rows)
+}
+
+func (suite *UnitOfWorkTestSuite) TestDo_Success() {
+	require := suite.Require()
+	id := uint(101)
+	actorID := uint(20)
+
+	// The status update joins the transaction of the unit instead of
+	// beginning its own.
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id)
+	suite.expectLockGiftCard(id)
+	suite.mock.ExpectExec("^UPDATE gift_cards SET status").
+		WithArgs(domain.GCSRejected, id, 1).
+		WillReturnResult(sqlmock.NewResult(101, 1))
+	suite.mock.ExpectExec("^INSERT INTO gift_card_status_history").
+		WithArgs(id, int(domain.GCSPending), int(domain.GCSRejected), actorID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectRecordEvent(suite.mock, domain.EventGiftCardStatusChanged, id, `{"gift_card_id":101,"gifter_id":10,"giftee_id":20,"from":"pending","to":"rejected","actor_id":20}`)
+	expectEnqueueWebhookDeliveries(suite.mock, 1, domain.EventGiftCardStatusChanged, id, `{"gift_card_id":101,"gifter_id":10,"giftee_id":20,"from":"pending","to":"rejected","actor_id":20}`, []uint{10, 20})
+	suite.mock.ExpectExec("^UPDATE wallets SET balance = balance \\+ \\?, held = held - \\?").
+		WithArgs(int64(10000), int64(10000), uint(10), "USD", int64(10000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectLedgerTransfer(suite.mock, id, domain.LTTRelease, domain.WalletHeldAccount(10, "USD"), domain.WalletAvailableAccount(10, "USD"), domain.NewMoney(10000, "USD"))
+	expectRecordAudit(suite.mock, domain.AuditGiftCardStatusChanged, actorID, domain.AuditTargetGiftCard, id, sqlmock.AnyArg(), sqlmock.AnyArg())
+	suite.mock.ExpectCommit()
+
+	err := suite.unitOfWork.Do(context.Background(), func(ctx context.Context, repos Repositories) error {
+		giftCard, err := repos.GiftCards.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return repos.GiftCards.UpdateStatus(ctx, giftCard.ID, nil, domain.GCSRejected, &actorID)
+	})
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UnitOfWorkTestSuite) TestDo_Failure() {
+	require := suite.Require()
+	id := uint(101)
+	expectedError := errors.New("not allowed")
+
+	suite.mock.ExpectBegin()
+	suite.expectLockGiftCard(id)
+	suite.mock.ExpectRollback()
+
+	err := suite.unitOfWork.Do(context.Background(), func(ctx context.Context, repos Repositories) error {
+		_, err := repos.GiftCards.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return expectedError
+	})
+
+	require.ErrorIs(err, expectedError)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UnitOfWorkTestSuite) TestDo_BeginError_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("database failure")
+
+	suite.mock.ExpectBegin().WillReturnError(expectedError)
+
+	called := false
+	err := suite.unitOfWork.Do(context.Background(), func(ctx context.Context, repos Repositories) error {
+		called = true
+
+		return nil
+	})
+
+	require.ErrorIs(err, expectedError)
+	require.False(called)
+}
+
+func TestUnitOfWork(t *testing.T) {
+	suite.Run(t, new(UnitOfWorkTestSuite))
+}
@@ -1,21 +1,29 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
 )
 
 type UserRepository interface {
-	Create(user *domain.User) error
-	FindByEmail(email string) (*domain.User, error)
+	Create(ctx context.Context, user *domain.User) error
+	FindByID(ctx context.Context, id uint) (*domain.User, error)
+	FindByEmail(ctx context.Context, email string) (*domain.User, error)
+	SetRoles(ctx context.Context, id uint, roles []domain.Role) error
 }
 
+// UserEntity is a row of users, Roles are the roles of the user separated by
+// commas.
 type UserEntity struct {
 	ID        uint
 	Email     string
 	Password  string
+	Roles     string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -25,39 +33,79 @@ func (u UserEntity) ToAggregate() domain.User {
 		ID:        u.ID,
 		Email:     u.Email,
 		Password:  u.Password,
+		Roles:     parseRoles(u.Roles),
 		CreatedAt: u.CreatedAt,
 	}
 }
 
+func formatRoles(roles []domain.Role) string {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+
+	return strings.Join(names, ",")
+}
+
+func parseRoles(roles string) []domain.Role {
+	if roles == "" {
+		return nil
+	}
+
+	var parsed []domain.Role
+	for _, role := range strings.Split(roles, ",") {
+		parsed = append(parsed, domain.Role(role))
+	}
+
+	return parsed
+}
+
 type userRepository struct {
-	db *sql.DB
+	db conn
 }
 
 func NewUserRepository(db *sql.DB) UserRepository {
-	return &userRepository{db: db}
+	return &userRepository{db: newSQLDB(db)}
 }
 
-func (r *userRepository) Create(user *domain.User) error {
-	query := `INSERT INTO users(email, password, created_at, updated_at) VALUES(?, ?, NOW(), NOW())`
-	result, err := r.db.Exec(query, user.Email, user.Password)
-	if err != nil {
-		return err
-	}
+// Create inserts the user and returns domain.ErrEmailTaken if its email is
+// already registered. The registration is audited as done by the user.
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	return withTx(ctx, r.db, func(tx sqlTx) error {
+		query := `INSERT INTO users(email, password, roles, created_at, updated_at) VALUES(?, ?, ?, NOW(), NOW())`
+		id, err := insert(ctx, tx, query, user.Email, user.Password, formatRoles(user.Roles))
+		if database.IsUniqueViolation(err) {
+			return domain.ErrEmailTaken
+		}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
+		if err != nil {
+			return err
+		}
 
-	user.ID = uint(id)
+		user.ID = id
+		event, err := domain.NewAuditEvent(ctx, domain.AuditUserRegistered, &id, domain.AuditTargetUser, &id, nil, domain.NewAuditUser(*user))
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, &event)
+	})
+}
+
+// FindByID returns the user with the id, nil if there is none.
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
+	return r.findUser(ctx, "id = ?", id)
+}
 
-	return nil
+// FindByEmail returns the user with the email, nil if there is none.
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.findUser(ctx, "email = ?", email)
 }
 
-func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
+func (r *userRepository) findUser(ctx context.Context, where string, arg interface{}) (*domain.User, error) {
 	var e UserEntity
-	err := r.db.QueryRow("SELECT id, email, password FROM users WHERE email = ?", email).
-		Scan(&e.ID, &e.Email, &e.Password)
+	err := r.db.QueryRowContext(ctx, "SELECT id, email, password, roles, created_at FROM users WHERE "+where, arg).
+		Scan(&e.ID, &e.Email, &e.Password, &e.Roles, &e.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -70,3 +118,35 @@ func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
 
 	return &domainUser, nil
 }
+
+// SetRoles replaces the roles of the user, it returns domain.ErrUserNotFound
+// if there is no such user. The change is audited without an actor, roles
+// are only set by the operators.
+func (r *userRepository) SetRoles(ctx context.Context, id uint, roles []domain.Role) error {
+	return withTx(ctx, r.db, func(tx sqlTx) error {
+		var e UserEntity
+		err := tx.QueryRowContext(ctx, "SELECT id, email, roles FROM users WHERE id = ? FOR UPDATE", id).Scan(&e.ID, &e.Email, &e.Roles)
+		if err == sql.ErrNoRows {
+			return domain.ErrUserNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE users SET roles = ?, updated_at = NOW() WHERE id = ?", formatRoles(roles), id)
+		if err != nil {
+			return err
+		}
+
+		before := e.ToAggregate()
+		after := before
+		after.Roles = roles
+		event, err := domain.NewAuditEvent(ctx, domain.AuditUserRolesChanged, nil, domain.AuditTargetUser, &id, domain.NewAuditUser(before), domain.NewAuditUser(after))
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, &event)
+	})
+}
@@ -1,14 +1,18 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
 )
 
 type UserRepositoryTestSuite struct {
@@ -21,7 +25,7 @@ type UserRepositoryTestSuite struct {
 func (suite *UserRepositoryTestSuite) SetupTest() {
 	suite.db, suite.mock, _ = sqlmock.New()
 	suite.repo = &userRepository{
-		db: suite.db,
+		db: newSQLDB(suite.db),
 	}
 }
 
@@ -46,14 +50,44 @@ func (suite *UserRepositoryTestSuite) TestCreate_Success() {
 		Password: "securePassword",
 	}
 
+	suite.mock.ExpectBegin()
 	suite.mock.ExpectExec("^INSERT INTO users").
-		WithArgs(u.Email, u.Password).
+		WithArgs(u.Email, u.Password, "").
 		WillReturnResult(sqlmock.NewResult(int64(id), 1))
+	expectRecordAudit(suite.mock, domain.AuditUserRegistered, id, domain.AuditTargetUser, id, nil, `{"id":101,"email":"foo@example.com","roles":[]}`)
+	suite.mock.ExpectCommit()
 
-	err := suite.repo.Create(u)
+	err := suite.repo.Create(context.Background(), u)
 
 	require.NoError(err)
 	require.Equal(id, u.ID)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestCreate_Postgres_Success() {
+	require := suite.Require()
+	suite.repo.db = sqlDB{db: suite.db, dialect: database.Postgres}
+	u := &domain.User{
+		Email:    "foo@example.com",
+		Password: "securePassword",
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^INSERT INTO users\\(email, password, roles, created_at, updated_at\\) VALUES\\(\\$1, \\$2, \\$3, NOW\\(\\), NOW\\(\\)\\) RETURNING id$").
+		WithArgs(u.Email, u.Password, "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(101))
+	suite.mock.ExpectQuery("^SELECT last_hash FROM audit_chain WHERE id = 1 FOR UPDATE$").
+		WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow(""))
+	suite.mock.ExpectQuery("^INSERT INTO audit_events .+ VALUES \\(\\$1, .+, \\$12\\) RETURNING id$").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	suite.mock.ExpectExec("^UPDATE audit_chain SET last_hash = \\$1 WHERE id = 1$").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.Create(context.Background(), u)
+
+	require.NoError(err)
+	require.Equal(uint(101), u.ID)
 }
 
 func (suite *UserRepositoryTestSuite) TestCreate_Failure() {
@@ -64,15 +98,35 @@ func (suite *UserRepositoryTestSuite) TestCreate_Failure() {
 	}
 	expectedError := errors.New("error in inserting to users table")
 
+	suite.mock.ExpectBegin()
 	suite.mock.ExpectExec("^INSERT INTO users").
-		WithArgs(u.Email, u.Password).
+		WithArgs(u.Email, u.Password, "").
 		WillReturnError(expectedError)
+	suite.mock.ExpectRollback()
 
-	err := suite.repo.Create(u)
+	err := suite.repo.Create(context.Background(), u)
 
 	require.EqualError(err, expectedError.Error())
 }
 
+func (suite *UserRepositoryTestSuite) TestCreate_EmailTaken_Failure() {
+	require := suite.Require()
+	u := &domain.User{
+		Email:    "foo@example.com",
+		Password: "securePassword",
+	}
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^INSERT INTO users").
+		WithArgs(u.Email, u.Password, "").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'foo@example.com' for key 'email'"})
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.Create(context.Background(), u)
+
+	require.ErrorIs(err, domain.ErrEmailTaken)
+}
+
 func (suite *UserRepositoryTestSuite) TestCreate_LastInsertIdError_Failure() {
 	require := suite.Require()
 	u := &domain.User{
@@ -81,11 +135,13 @@ func (suite *UserRepositoryTestSuite) TestCreate_LastInsertIdError_Failure() {
 	}
 	expectedError := errors.New("LastInsertId error")
 
+	suite.mock.ExpectBegin()
 	suite.mock.ExpectExec("^INSERT INTO users").
-		WithArgs(u.Email, u.Password).
+		WithArgs(u.Email, u.Password, "").
 		WillReturnResult(sqlmock.NewErrorResult(errors.New("LastInsertId error")))
+	suite.mock.ExpectRollback()
 
-	err := suite.repo.Create(u)
+	err := suite.repo.Create(context.Background(), u)
 
 	require.Error(err)
 	require.EqualError(err, expectedError.Error())
@@ -100,13 +156,24 @@ func (suite *UserRepositoryTestSuite) TestFindByID_DBError_Failure() {
 		WithArgs(email).
 		WillReturnError(errors.New("database failure"))
 
-	result, err := suite.repo.FindByEmail(email)
+	result, err := suite.repo.FindByEmail(context.Background(), email)
 
 	require.Error(err)
 	require.EqualError(err, expectedError)
 	require.Empty(result)
 }
 
+func (suite *UserRepositoryTestSuite) TestFindByEmail_Canceled_Failure() {
+	require := suite.Require()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := suite.repo.FindByEmail(ctx, "foo@example.com")
+
+	require.ErrorIs(err, context.Canceled)
+	require.Nil(result)
+}
+
 func (suite *UserRepositoryTestSuite) TestFindByID_NotFound() {
 	require := suite.Require()
 	email := "foo@example.com"
@@ -115,7 +182,7 @@ func (suite *UserRepositoryTestSuite) TestFindByID_NotFound() {
 		WithArgs(email).
 		WillReturnError(sql.ErrNoRows)
 
-	result, err := suite.repo.FindByEmail(email)
+	result, err := suite.repo.FindByEmail(context.Background(), email)
 	require.NoError(err)
 	require.Empty(result)
 }
@@ -123,23 +190,81 @@ func (suite *UserRepositoryTestSuite) TestFindByID_NotFound() {
 func (suite *UserRepositoryTestSuite) TestFindByID_Success() {
 	require := suite.Require()
 	email := "foo@example.com"
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	expectedResult := &domain.User{
-		ID:       10,
-		Email:    email,
-		Password: "securePassword",
+		ID:        10,
+		Email:     email,
+		Password:  "securePassword",
+		CreatedAt: createdAt,
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "email", "password"}).
-		AddRow(expectedResult.ID, expectedResult.Email, expectedResult.Password)
-	suite.mock.ExpectQuery("^SELECT .+ FROM users").
+	rows := sqlmock.NewRows([]string{"id", "email", "password", "roles", "created_at"}).
+		AddRow(expectedResult.ID, expectedResult.Email, expectedResult.Password, "", createdAt)
+	suite.mock.ExpectQuery("^SELECT id, email, password, roles, created_at FROM users WHERE email = \\?$").
 		WithArgs(email).
 		WillReturnRows(rows)
 
-	result, err := suite.repo.FindByEmail(email)
+	result, err := suite.repo.FindByEmail(context.Background(), email)
+	require.NoError(err)
+	require.Equal(expectedResult, result)
+}
+
+func (suite *UserRepositoryTestSuite) TestFindByID_Roles_Success() {
+	require := suite.Require()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedResult := &domain.User{
+		ID:        10,
+		Email:     "foo@example.com",
+		Password:  "securePassword",
+		Roles:     []domain.Role{domain.RoleSupport, domain.RoleAdmin},
+		CreatedAt: createdAt,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "email", "password", "roles", "created_at"}).
+		AddRow(expectedResult.ID, expectedResult.Email, expectedResult.Password, "support,admin", createdAt)
+	suite.mock.ExpectQuery("^SELECT id, email, password, roles, created_at FROM users WHERE id = \\?$").
+		WithArgs(uint(10)).
+		WillReturnRows(rows)
+
+	result, err := suite.repo.FindByID(context.Background(), 10)
 	require.NoError(err)
 	require.Equal(expectedResult, result)
 }
 
+func (suite *UserRepositoryTestSuite) TestSetRoles_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT id, email, roles FROM users WHERE id = \\? FOR UPDATE$").
+		WithArgs(uint(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "roles"}).AddRow(10, "foo@example.com", "support"))
+	suite.mock.ExpectExec("^UPDATE users SET roles = \\?, updated_at = NOW\\(\\) WHERE id = \\?$").
+		WithArgs("support,admin", uint(10)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRecordAudit(suite.mock, domain.AuditUserRolesChanged, nil, domain.AuditTargetUser, uint(10),
+		`{"id":10,"email":"foo@example.com","roles":["support"]}`, `{"id":10,"email":"foo@example.com","roles":["support","admin"]}`)
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.SetRoles(context.Background(), 10, []domain.Role{domain.RoleSupport, domain.RoleAdmin})
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryTestSuite) TestSetRoles_NotFound_Failure() {
+	require := suite.Require()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT id, email, roles FROM users WHERE id = \\? FOR UPDATE$").
+		WithArgs(uint(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "roles"}))
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.SetRoles(context.Background(), 10, nil)
+
+	require.ErrorIs(err, domain.ErrUserNotFound)
+}
+
 func TestUserRepository(t *testing.T) {
 	suite.Run(t, new(UserRepositoryTestSuite))
 }
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+type WalletRepository interface {
+	FindByUserID(ctx context.Context, userID uint, currency string) (*domain.Wallet, error)
+	// Deposit adds amount from outside to the available balance of the
+	// user, on behalf of the actor, nil for the seed. It is recorded in the
+	// ledger against domain.ExternalDepositsAccount and in the audit log.
+	Deposit(ctx context.Context, userID uint, amount domain.Money, actorID *uint) error
+}
+
+type WalletEntity struct {
+	ID        uint
+	UserID    uint
+	Currency  string
+	Balance   int64
+	Held      int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (w WalletEntity) ToAggregate() domain.Wallet {
+	return domain.Wallet{
+		ID:        w.ID,
+		UserID:    w.UserID,
+		Balance:   domain.NewMoney(w.Balance, w.Currency),
+		Held:      domain.NewMoney(w.Held, w.Currency),
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+type walletRepository struct {
+	db conn
+}
+
+func NewWalletRepository(db *sql.DB) WalletRepository {
+	return &walletRepository{db: newSQLDB(db)}
+}
+
+func (r *walletRepository) FindByUserID(ctx context.Context, userID uint, currency string) (*domain.Wallet, error) {
+	var e WalletEntity
+	err := r.db.QueryRowContext(ctx, "SELECT id, user_id, currency, balance, held, updated_at FROM wallets WHERE user_id = ? AND currency = ?", userID, currency).
+		Scan(&e.ID, &e.UserID, &e.Currency, &e.Balance, &e.Held, &e.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	domainWallet := e.ToAggregate()
+
+	return &domainWallet, nil
+}
+
+func (r *walletRepository) Deposit(ctx context.Context, userID uint, amount domain.Money, actorID *uint) error {
+	return withTx(ctx, r.db, func(tx sqlTx) error {
+		err := (&walletLedger{db: tx}).deposit(ctx, userID, amount)
+		if err != nil {
+			return err
+		}
+
+		event, err := domain.NewAuditEvent(ctx, domain.AuditWalletDeposited, actorID, domain.AuditTargetUser, &userID, nil, domain.NewAuditDeposit(userID, amount))
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, &event)
+	})
+}
+
+// walletLedger moves funds between wallets and records every movement as a
+// balanced transaction in the ledger. It is meant to run on a transaction so
+// that the balance change and its ledger entries are stored together.
+type walletLedger struct {
+	db executor
+}
+
+func (l *walletLedger) deposit(ctx context.Context, userID uint, amount domain.Money) error {
+	err := l.credit(ctx, userID, amount)
+	if err != nil {
+		return err
+	}
+
+	return l.record(ctx, domain.NewLedgerTransfer(domain.LTTDeposit, nil,
+		domain.ExternalDepositsAccount(amount.Currency), domain.WalletAvailableAccount(userID, amount.Currency), amount))
+}
+
+// hold moves amount from the available balance of the user to its held
+// balance on behalf of the given gift card.
+func (l *walletLedger) hold(ctx context.Context, userID, giftCardID uint, amount domain.Money) error {
+	query := "UPDATE wallets SET balance = balance - ?, held = held + ?, updated_at = NOW() WHERE user_id = ? AND currency = ? AND balance >= ?"
+	res, err := l.db.ExecContext(ctx, query, amount.Amount, amount.Amount, userID, amount.Currency, amount.Amount)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrInsufficientFunds
+	}
+
+	return l.record(ctx, domain.NewLedgerTransfer(domain.LTTHold, &giftCardID,
+		domain.WalletAvailableAccount(userID, amount.Currency), domain.WalletHeldAccount(userID, amount.Currency), amount))
+}
+
+// release gives a held amount back to the available balance of the user.
+func (l *walletLedger) release(ctx context.Context, userID, giftCardID uint, amount domain.Money) error {
+	err := l.unhold(ctx, userID, amount, true)
+	if err != nil {
+		return err
+	}
+
+	return l.record(ctx, domain.NewLedgerTransfer(domain.LTTRelease, &giftCardID,
+		domain.WalletHeldAccount(userID, amount.Currency), domain.WalletAvailableAccount(userID, amount.Currency), amount))
+}
+
+// transfer moves a held amount of the gifter into the ledger account of the
+// gift card, where it stays until it is redeemed.
+func (l *walletLedger) transfer(ctx context.Context, gifterID, giftCardID uint, amount domain.Money) error {
+	err := l.unhold(ctx, gifterID, amount, false)
+	if err != nil {
+		return err
+	}
+
+	return l.record(ctx, domain.NewLedgerTransfer(domain.LTTTransfer, &giftCardID,
+		domain.WalletHeldAccount(gifterID, amount.Currency), domain.GiftCardAccount(giftCardID, amount.Currency), amount))
+}
+
+// redeem pays amount out of the ledger account of the gift card to the
+// available balance of the redeemer. The caller checks the remaining amount of
+// the card, which is the balance of its account.
+func (l *walletLedger) redeem(ctx context.Context, redeemerID, giftCardID uint, amount domain.Money) error {
+	err := l.credit(ctx, redeemerID, amount)
+	if err != nil {
+		return err
+	}
+
+	return l.record(ctx, domain.NewLedgerTransfer(domain.LTTRedemption, &giftCardID,
+		domain.GiftCardAccount(giftCardID, amount.Currency), domain.WalletAvailableAccount(redeemerID, amount.Currency), amount))
+}
+
+// refund pays the rest of a voided gift card out of its ledger account back to
+// the available balance of the gifter.
+func (l *walletLedger) refund(ctx context.Context, gifterID, giftCardID uint, amount domain.Money) error {
+	err := l.credit(ctx, gifterID, amount)
+	if err != nil {
+		return err
+	}
+
+	return l.record(ctx, domain.NewLedgerTransfer(domain.LTTRefund, &giftCardID,
+		domain.GiftCardAccount(giftCardID, amount.Currency), domain.WalletAvailableAccount(gifterID, amount.Currency), amount))
+}
+
+// credit adds amount to the available balance of the user and creates its
+// wallet if it does not exist yet.
+func (l *walletLedger) credit(ctx context.Context, userID uint, amount domain.Money) error {
+	query := `INSERT INTO wallets (user_id, currency, balance, held, created_at, updated_at) VALUES (?, ?, ?, 0, NOW(), NOW())
+ON DUPLICATE KEY UPDATE balance = balance + VALUES(balance), updated_at = NOW()`
+	if l.db.Dialect() != database.MySQL {
+		query = `INSERT INTO wallets (user_id, currency, balance, held, created_at, updated_at) VALUES (?, ?, ?, 0, NOW(), NOW())
+ON CONFLICT (user_id, currency) DO UPDATE SET balance = wallets.balance + EXCLUDED.balance, updated_at = NOW()`
+	}
+
+	_, err := l.db.ExecContext(ctx, query, userID, amount.Currency, amount.Amount)
+
+	return err
+}
+
+// unhold takes amount out of the held balance of the user and fails if the
+// wallet does not hold enough. With toBalance the amount goes back to the
+// available balance, otherwise it leaves the wallet.
+func (l *walletLedger) unhold(ctx context.Context, userID uint, amount domain.Money, toBalance bool) error {
+	query := "UPDATE wallets SET held = held - ?, updated_at = NOW() WHERE user_id = ? AND currency = ? AND held >= ?"
+	args := []any{amount.Amount, userID, amount.Currency, amount.Amount}
+	if toBalance {
+		query = "UPDATE wallets SET balance = balance + ?, held = held - ?, updated_at = NOW() WHERE user_id = ? AND currency = ? AND held >= ?"
+		args = []any{amount.Amount, amount.Amount, userID, amount.Currency, amount.Amount}
+	}
+
+	res, err := l.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("wallet of user %d does not hold %s", userID, amount)
+	}
+
+	return nil
+}
+
+// record appends a balanced transaction and its entries to the ledger.
+func (l *walletLedger) record(ctx context.Context, t domain.LedgerTransaction) error {
+	if !t.IsBalanced() {
+		return fmt.Errorf("ledger transaction of type %d is not balanced", t.Type)
+	}
+
+	id, err := insert(ctx, l.db, "INSERT INTO ledger_transactions (gift_card_id, type, created_at) VALUES (?, ?, NOW())", t.GiftCardID, int(t.Type))
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO ledger_entries (transaction_id, account, direction, amount, currency, created_at) VALUES (?, ?, ?, ?, ?, NOW())"
+	for _, e := range t.Entries {
+		_, err = l.db.ExecContext(ctx, query, id, e.Account, int(e.Direction), e.Amount.Amount, e.Amount.Currency)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+)
+
+type WalletRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *walletRepository
+}
+
+func (suite *WalletRepositoryTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.repo = &walletRepository{
+		db: newSQLDB(suite.db),
+	}
+}
+
+func (suite *WalletRepositoryTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *WalletRepositoryTestSuite) TestNewWalletRepository() {
+	require := suite.Require()
+
+	db, _, _ := sqlmock.New()
+	repo := NewWalletRepository(db)
+
+	require.NotNil(repo)
+}
+
+func (suite *WalletRepositoryTestSuite) TestFindByUserID_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedResult := &domain.Wallet{
+		ID:        1,
+		UserID:    userID,
+		Balance:   domain.NewMoney(90000, "USD"),
+		Held:      domain.NewMoney(10000, "USD"),
+		UpdatedAt: updatedAt,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "currency", "balance", "held", "updated_at"}).
+		AddRow(1, userID, "USD", 90000, 10000, updatedAt)
+	suite.mock.ExpectQuery("^SELECT .+ FROM wallets").
+		WithArgs(userID, "USD").
+		WillReturnRows(rows)
+
+	result, err := suite.repo.FindByUserID(context.Background(), userID, "USD")
+
+	require.NoError(err)
+	require.Equal(expectedResult, result)
+}
+
+func (suite *WalletRepositoryTestSuite) TestFindByUserID_NotFound() {
+	require := suite.Require()
+	userID := uint(10)
+
+	suite.mock.ExpectQuery("^SELECT .+ FROM wallets").
+		WithArgs(userID, "USD").
+		WillReturnError(sql.ErrNoRows)
+
+	result, err := suite.repo.FindByUserID(context.Background(), userID, "USD")
+
+	require.NoError(err)
+	require.Nil(result)
+}
+
+func (suite *WalletRepositoryTestSuite) TestFindByUserID_DBError_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	expectedError := errors.New("database failure")
+
+	suite.mock.ExpectQuery("^SELECT .+ FROM wallets").
+		WithArgs(userID, "USD").
+		WillReturnError(expectedError)
+
+	result, err := suite.repo.FindByUserID(context.Background(), userID, "USD")
+
+	require.EqualError(err, expectedError.Error())
+	require.Nil(result)
+}
+
+func (suite *WalletRepositoryTestSuite) TestDeposit_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	actorID := uint(1)
+	amount := domain.NewMoney(25000, "USD")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^INSERT INTO wallets").
+		WithArgs(userID, amount.Currency, amount.Amount).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectLedgerTransfer(suite.mock, nil, domain.LTTDeposit, domain.ExternalDepositsAccount("USD"), domain.WalletAvailableAccount(userID, "USD"), amount)
+	expectRecordAudit(suite.mock, domain.AuditWalletDeposited, &actorID, domain.AuditTargetUser, &userID, nil, `{"user_id":10,"amount":"250.00","currency":"USD"}`)
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.Deposit(context.Background(), userID, amount, &actorID)
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *WalletRepositoryTestSuite) TestDeposit_Postgres_Success() {
+	require := suite.Require()
+	suite.repo.db = sqlDB{db: suite.db, dialect: database.Postgres}
+	userID := uint(10)
+	amount := domain.NewMoney(25000, "USD")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^INSERT INTO wallets .+ ON CONFLICT \\(user_id, currency\\) DO UPDATE SET balance = wallets.balance \\+ EXCLUDED.balance").
+		WithArgs(userID, amount.Currency, amount.Amount).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery("^INSERT INTO ledger_transactions .+ VALUES \\(\\$1, \\$2, NOW\\(\\)\\) RETURNING id$").
+		WithArgs(nil, int(domain.LTTDeposit)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	suite.mock.ExpectExec("^INSERT INTO ledger_entries .+ VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, NOW\\(\\)\\)$").
+		WithArgs(uint(1), domain.ExternalDepositsAccount("USD"), int(domain.LEDDebit), amount.Amount, amount.Currency).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectExec("^INSERT INTO ledger_entries").
+		WithArgs(uint(1), domain.WalletAvailableAccount(userID, "USD"), int(domain.LEDCredit), amount.Amount, amount.Currency).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	suite.mock.ExpectQuery("^SELECT last_hash FROM audit_chain WHERE id = 1 FOR UPDATE$").
+		WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow(""))
+	suite.mock.ExpectQuery("^INSERT INTO audit_events .+ RETURNING id$").
+		WithArgs(nil, string(domain.AuditWalletDeposited), domain.AuditTargetUser, &userID, nil, `{"user_id":10,"amount":"250.00","currency":"USD"}`, "", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	suite.mock.ExpectExec("^UPDATE audit_chain SET last_hash = \\$1 WHERE id = 1$").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.Deposit(context.Background(), userID, amount, nil)
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *WalletRepositoryTestSuite) TestDeposit_DBError_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	amount := domain.NewMoney(25000, "USD")
+	expectedError := errors.New("database failure")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^INSERT INTO wallets").
+		WithArgs(userID, amount.Currency, amount.Amount).
+		WillReturnError(expectedError)
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.Deposit(context.Background(), userID, amount, nil)
+
+	require.EqualError(err, expectedError.Error())
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+// expectLedgerTransfer expects a ledger transaction that moves amount from one
+// account to another.
+func expectLedgerTransfer(mock sqlmock.Sqlmock, giftCardID any, t domain.LedgerTransactionType, from, to string, amount domain.Money) {
+	mock.ExpectExec("^INSERT INTO ledger_transactions").
+		WithArgs(giftCardID, int(t)).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectExec("^INSERT INTO ledger_entries").
+		WithArgs(int64(7), from, int(domain.LEDDebit), amount.Amount, amount.Currency).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("^INSERT INTO ledger_entries").
+		WithArgs(int64(7), to, int(domain.LEDCredit), amount.Amount, amount.Currency).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+}
+
+func TestWalletRepository(t *testing.T) {
+	suite.Run(t, new(WalletRepositoryTestSuite))
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	FindByID(ctx context.Context, id uint) (*domain.Webhook, error)
+	FindByUserID(ctx context.Context, userID uint) ([]domain.Webhook, error)
+	Update(ctx context.Context, webhook *domain.Webhook) error
+	Delete(ctx context.Context, id uint) error
+	FindDeliveriesByWebhookID(ctx context.Context, webhookID uint, pageSize int, pageNumber int) ([]domain.WebhookDelivery, int, error)
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+}
+
+type WebhookEntity struct {
+	ID        uint
+	UserID    uint
+	URL       string
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+}
+
+func (w WebhookEntity) ToAggregate() domain.Webhook {
+	return domain.Webhook{
+		ID:        w.ID,
+		UserID:    w.UserID,
+		URL:       w.URL,
+		Secret:    w.Secret,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+type WebhookDeliveryEntity struct {
+	ID             uint
+	WebhookID      uint
+	EventID        uint
+	EventType      string
+	AggregateID    uint
+	Payload        []byte
+	Status         int
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode sql.NullInt32
+	LastError      sql.NullString
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (d WebhookDeliveryEntity) ToAggregate() domain.WebhookDelivery {
+	return domain.WebhookDelivery{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		EventType:      domain.EventType(d.EventType),
+		AggregateID:    d.AggregateID,
+		Payload:        d.Payload,
+		Status:         domain.WebhookDeliveryStatus(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: int(d.LastStatusCode.Int32),
+		LastError:      d.LastError.String,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
+
+// maxWebhookLastErrorLength is the size of the last_error column.
+const maxWebhookLastErrorLength = 1024
+
+const webhookDeliveryColumns = "id, webhook_id, event_id, event_type, aggregate_id, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at"
+
+func scanWebhookDelivery(rows *sql.Rows) (domain.WebhookDelivery, error) {
+	var e WebhookDeliveryEntity
+	err := rows.Scan(&e.ID, &e.WebhookID, &e.EventID, &e.EventType, &e.AggregateID, &e.Payload, &e.Status, &e.Attempts, &e.NextAttemptAt, &e.LastStatusCode, &e.LastError, &e.CreatedAt, &e.UpdatedAt)
+
+	return e.ToAggregate(), err
+}
+
+type webhookRepository struct {
+	db conn
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: newSQLDB(db)}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	query := "INSERT INTO webhooks (user_id, url, secret, active, created_at, updated_at) VALUES (?, ?, ?, ?, NOW(), NOW())"
+	id, err := insert(ctx, r.db, query, webhook.UserID, webhook.URL, webhook.Secret, webhook.Active)
+	if err != nil {
+		return err
+	}
+
+	webhook.ID = id
+
+	return nil
+}
+
+func (r *webhookRepository) FindByID(ctx context.Context, id uint) (*domain.Webhook, error) {
+	e := new(WebhookEntity)
+	err := r.db.
+		QueryRowContext(ctx, "SELECT id, user_id, url, secret, active, created_at FROM webhooks WHERE id = ?", id).
+		Scan(&e.ID, &e.UserID, &e.URL, &e.Secret, &e.Active, &e.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	webhook := e.ToAggregate()
+
+	return &webhook, nil
+}
+
+func (r *webhookRepository) FindByUserID(ctx context.Context, userID uint) ([]domain.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, user_id, url, secret, active, created_at FROM webhooks WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	var webhooks []domain.Webhook
+	for rows.Next() {
+		var e WebhookEntity
+		err := rows.Scan(&e.ID, &e.UserID, &e.URL, &e.Secret, &e.Active, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, e.ToAggregate())
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r *webhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	query := "UPDATE webhooks SET url = ?, active = ?, updated_at = NOW() WHERE id = ?"
+	res, err := r.db.ExecContext(ctx, query, webhook.URL, webhook.Active, webhook.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// Delete removes the webhook along with its delivery log.
+func (r *webhookRepository) Delete(ctx context.Context, id uint) error {
+	return withTx(ctx, r.db, func(tx sqlTx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE webhook_id = ?", id)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return domain.ErrWebhookNotFound
+		}
+
+		return nil
+	})
+}
+
+// FindDeliveriesByWebhookID returns a page of the deliveries of the webhook,
+// the latest first, and the total number of its deliveries.
+func (r *webhookRepository) FindDeliveriesByWebhookID(ctx context.Context, webhookID uint, pageSize int, pageNumber int) ([]domain.WebhookDelivery, int, error) {
+	offset := (pageNumber - 1) * pageSize
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ? OFFSET ?"
+	rows, err := r.db.QueryContext(ctx, query, webhookID, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+	var deliveries []domain.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var totalCount int
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = ?", webhookID).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, totalCount, nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries that are due at
+// now and pushes their next attempt back by lease, so that other workers skip
+// them while they are being sent. A delivery whose worker dies before
+// recording the attempt is picked up again once the lease runs out.
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := withTx(ctx, r.db, func(tx sqlTx) error {
+		query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ? FOR UPDATE SKIP LOCKED"
+		rows, err := tx.QueryContext(ctx, query, int(domain.WDSPending), now, limit)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			delivery, err := scanWebhookDelivery(rows)
+			if err != nil {
+				_ = rows.Close()
+
+				return err
+			}
+
+			deliveries = append(deliveries, delivery)
+		}
+
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			_, err := tx.ExecContext(ctx, "UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ?", now.Add(lease), delivery.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	var statusCode *int
+	if delivery.LastStatusCode != 0 {
+		statusCode = &delivery.LastStatusCode
+	}
+
+	var lastError *string
+	if delivery.LastError != "" {
+		e := delivery.LastError
+		if len(e) > maxWebhookLastErrorLength {
+			e = e[:maxWebhookLastErrorLength]
+		}
+
+		lastError = &e
+	}
+
+	query := "UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, updated_at = NOW() WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, int(delivery.Status), delivery.Attempts, delivery.NextAttemptAt, statusCode, lastError, delivery.ID)
+
+	return err
+}
+
+// enqueueWebhookDeliveries adds a pending delivery of the event for every
+// active webhook of the given users. It is called with the transaction that
+// recorded the event.
+func enqueueWebhookDeliveries(ctx context.Context, db executor, event domain.Event, now time.Time, userIDs ...uint) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(userIDs)), ", ")
+	args := make([]any, 0, len(userIDs))
+	for _, userID := range userIDs {
+		args = append(args, userID)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT id FROM webhooks WHERE active = TRUE AND user_id IN ("+placeholders+") ORDER BY id", args...)
+	if err != nil {
+		return err
+	}
+
+	var webhookIDs []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+
+			return err
+		}
+
+		webhookIDs = append(webhookIDs, id)
+	}
+
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	query := "INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, aggregate_id, payload, status, attempts, next_attempt_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, 0, ?, NOW(), NOW())"
+	for _, webhookID := range webhookIDs {
+		_, err := db.ExecContext(ctx, query, webhookID, event.ID, string(event.Type), event.AggregateID, string(event.Payload), int(domain.WDSPending), now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
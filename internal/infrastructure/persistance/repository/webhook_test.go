@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+type WebhookRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *webhookRepository
+}
+
+func (suite *WebhookRepositoryTestSuite) SetupTest() {
+	suite.db, suite.mock, _ = sqlmock.New()
+	suite.repo = &webhookRepository{
+		db: newSQLDB(suite.db),
+	}
+}
+
+func (suite *WebhookRepositoryTestSuite) TeardownTest() {
+	_ = suite.db.Close()
+}
+
+func (suite *WebhookRepositoryTestSuite) TestNewWebhookRepository() {
+	require := suite.Require()
+
+	db, _, _ := sqlmock.New()
+	repo := NewWebhookRepository(db)
+
+	require.NotNil(repo)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestCreate_Success() {
+	require := suite.Require()
+	w := &domain.Webhook{UserID: 10, URL: "https://example.com/hook", Secret: "secret", Active: true}
+
+	suite.mock.ExpectExec("^INSERT INTO webhooks").
+		WithArgs(w.UserID, w.URL, w.Secret, w.Active).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	err := suite.repo.Create(context.Background(), w)
+
+	require.NoError(err)
+	require.Equal(uint(3), w.ID)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *WebhookRepositoryTestSuite) TestFindByID_Success() {
+	require := suite.Require()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedResult := &domain.Webhook{ID: 3, UserID: 10, URL: "https://example.com/hook", Secret: "secret", Active: true, CreatedAt: createdAt}
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "url", "secret", "active", "created_at"}).
+		AddRow(3, 10, "https://example.com/hook", "secret", true, createdAt)
+	suite.mock.ExpectQuery("^SELECT .+ FROM webhooks WHERE id = \\?$").
+		WithArgs(uint(3)).
+		WillReturnRows(rows)
+
+	webhook, err := suite.repo.FindByID(context.Background(), 3)
+
+	require.NoError(err)
+	require.Equal(expectedResult, webhook)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestFindByID_NotFound() {
+	require := suite.Require()
+
+	suite.mock.ExpectQuery("^SELECT .+ FROM webhooks WHERE id = \\?$").
+		WithArgs(uint(3)).
+		WillReturnError(sql.ErrNoRows)
+
+	webhook, err := suite.repo.FindByID(context.Background(), 3)
+
+	require.NoError(err)
+	require.Nil(webhook)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestFindByUserID_Success() {
+	require := suite.Require()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedResult := []domain.Webhook{{ID: 3, UserID: 10, URL: "https://example.com/hook", Secret: "secret", Active: true, CreatedAt: createdAt}}
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "url", "secret", "active", "created_at"}).
+		AddRow(3, 10, "https://example.com/hook", "secret", true, createdAt)
+	suite.mock.ExpectQuery("^SELECT .+ FROM webhooks WHERE user_id = \\? ORDER BY id$").
+		WithArgs(uint(10)).
+		WillReturnRows(rows)
+
+	webhooks, err := suite.repo.FindByUserID(context.Background(), 10)
+
+	require.NoError(err)
+	require.Equal(expectedResult, webhooks)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestUpdate_NotFound_Failure() {
+	require := suite.Require()
+	w := &domain.Webhook{ID: 3, URL: "https://example.com/hook", Active: false}
+
+	suite.mock.ExpectExec("^UPDATE webhooks SET url = \\?, active = \\?").
+		WithArgs(w.URL, w.Active, w.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.Update(context.Background(), w)
+
+	require.ErrorIs(err, domain.ErrWebhookNotFound)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestDelete_Success() {
+	require := suite.Require()
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec("^DELETE FROM webhook_deliveries WHERE webhook_id = \\?$").
+		WithArgs(uint(3)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.mock.ExpectExec("^DELETE FROM webhooks WHERE id = \\?$").
+		WithArgs(uint(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.Delete(context.Background(), 3)
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *WebhookRepositoryTestSuite) TestFindDeliveriesByWebhookID_Success() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedResult := []domain.WebhookDelivery{{
+		ID:             7,
+		WebhookID:      3,
+		EventID:        5,
+		EventType:      domain.EventGiftCardStatusChanged,
+		AggregateID:    101,
+		Payload:        []byte(`{}`),
+		Status:         domain.WDSPending,
+		Attempts:       1,
+		NextAttemptAt:  now,
+		LastStatusCode: 500,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}}
+
+	rows := sqlmock.NewRows([]string{"id", "webhook_id", "event_id", "event_type", "aggregate_id", "payload", "status", "attempts", "next_attempt_at", "last_status_code", "last_error", "created_at", "updated_at"}).
+		AddRow(7, 3, 5, string(domain.EventGiftCardStatusChanged), 101, []byte(`{}`), 0, 1, now, 500, nil, now, now)
+	suite.mock.ExpectQuery("^SELECT .+ FROM webhook_deliveries WHERE webhook_id = \\? ORDER BY id DESC LIMIT \\? OFFSET \\?$").
+		WithArgs(uint(3), 10, 10).
+		WillReturnRows(rows)
+	suite.mock.ExpectQuery("^SELECT COUNT\\(\\*\\) FROM webhook_deliveries WHERE webhook_id = \\?$").
+		WithArgs(uint(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+
+	deliveries, total, err := suite.repo.FindDeliveriesByWebhookID(context.Background(), 3, 10, 2)
+
+	require.NoError(err)
+	require.Equal(expectedResult, deliveries)
+	require.Equal(11, total)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestClaimDueDeliveries_Success() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id", "webhook_id", "event_id", "event_type", "aggregate_id", "payload", "status", "attempts", "next_attempt_at", "last_status_code", "last_error", "created_at", "updated_at"}).
+		AddRow(7, 3, 5, string(domain.EventGiftCardStatusChanged), 101, []byte(`{}`), 0, 0, now, nil, nil, now, now)
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT .+ FROM webhook_deliveries WHERE status = \\? AND next_attempt_at <= \\? ORDER BY next_attempt_at, id LIMIT \\? FOR UPDATE SKIP LOCKED$").
+		WithArgs(int(domain.WDSPending), now, 10).
+		WillReturnRows(rows)
+	suite.mock.ExpectExec("^UPDATE webhook_deliveries SET next_attempt_at = \\? WHERE id = \\?$").
+		WithArgs(now.Add(time.Minute), uint(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	deliveries, err := suite.repo.ClaimDueDeliveries(context.Background(), now, time.Minute, 10)
+
+	require.NoError(err)
+	require.Len(deliveries, 1)
+	require.Equal(uint(7), deliveries[0].ID)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *WebhookRepositoryTestSuite) TestClaimDueDeliveries_DBError_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("db error")
+
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery("^SELECT .+ FROM webhook_deliveries").
+		WillReturnError(expectedError)
+	suite.mock.ExpectRollback()
+
+	deliveries, err := suite.repo.ClaimDueDeliveries(context.Background(), time.Now(), time.Minute, 10)
+
+	require.ErrorIs(err, expectedError)
+	require.Nil(deliveries)
+}
+
+func (suite *WebhookRepositoryTestSuite) TestUpdateDelivery_Success() {
+	require := suite.Require()
+	nextAttemptAt := time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC)
+	delivery := domain.WebhookDelivery{ID: 7, Status: domain.WDSPending, Attempts: 1, NextAttemptAt: nextAttemptAt, LastError: "connection refused"}
+
+	suite.mock.ExpectExec("^UPDATE webhook_deliveries SET status = \\?, attempts = \\?, next_attempt_at = \\?, last_status_code = \\?, last_error = \\?").
+		WithArgs(int(domain.WDSPending), 1, nextAttemptAt, nil, "connection refused", uint(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.UpdateDelivery(context.Background(), delivery)
+
+	require.NoError(err)
+	require.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func expectEnqueueWebhookDeliveries(mock sqlmock.Sqlmock, eventID uint, t domain.EventType, aggregateID uint, payload string, userIDs []uint, webhookIDs ...uint) {
+	args := make([]driver.Value, 0, len(userIDs))
+	for _, userID := range userIDs {
+		args = append(args, userID)
+	}
+
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, webhookID := range webhookIDs {
+		rows.AddRow(webhookID)
+	}
+
+	mock.ExpectQuery("^SELECT id FROM webhooks WHERE active = TRUE AND user_id IN").
+		WithArgs(args...).
+		WillReturnRows(rows)
+	for _, webhookID := range webhookIDs {
+		mock.ExpectExec("^INSERT INTO webhook_deliveries").
+			WithArgs(webhookID, eventID, string(t), aggregateID, payload, int(domain.WDSPending), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+}
+
+func TestWebhookRepository(t *testing.T) {
+	suite.Run(t, new(WebhookRepositoryTestSuite))
+}
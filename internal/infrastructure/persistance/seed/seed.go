@@ -0,0 +1,87 @@
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+// Seed fills the database with two users, test0@example.com and
+// test1@example.com with the password "password", funded wallets and a few
+// gift cards from test0 to test1. An admin, admin@example.com, is only seeded
+// with adminPassword, an empty one seeds no admin. It goes through the
+// repositories, so the funds of the gift cards are held and moved in the
+// ledger like any other. Migrations keep the data around, so it returns false
+// without changing anything when the users already exist.
+func Seed(ctx context.Context, db *sql.DB, adminPassword string) (bool, error) {
+	return SeedRepositories(ctx, repository.NewUserRepository(db), repository.NewWalletRepository(db), repository.NewGiftCardRepository(db), adminPassword)
+}
+
+// SeedRepositories fills the repositories with the users, wallets and gift
+// cards described by Seed. The gift cards are created pending and decided on
+// by the giftee. It returns false when the users already exist.
+func SeedRepositories(ctx context.Context, users repository.UserRepository, wallets repository.WalletRepository, giftCards repository.GiftCardRepository, adminPassword string) (bool, error) {
+	existing, err := users.FindByEmail(ctx, "test0@example.com")
+	if err != nil {
+		return false, fmt.Errorf("check seeded: %w", err)
+	}
+
+	if existing != nil {
+		return false, nil
+	}
+
+	var userIDs []uint
+	for i := 0; i < 2; i++ {
+		u := domain.User{Email: fmt.Sprintf("test%d@example.com", i)}
+		_ = u.SetPassword("password")
+		err = users.Create(ctx, &u)
+		if err != nil {
+			return false, fmt.Errorf("insert user: %w", err)
+		}
+
+		userIDs = append(userIDs, u.ID)
+	}
+
+	if adminPassword != "" {
+		admin := domain.User{Email: "admin@example.com", Roles: []domain.Role{domain.RoleAdmin}}
+		if err := admin.SetPassword(adminPassword); err != nil {
+			return false, fmt.Errorf("set admin password: %w", err)
+		}
+
+		err = users.Create(ctx, &admin)
+		if err != nil {
+			return false, fmt.Errorf("insert admin: %w", err)
+		}
+	}
+
+	for _, userID := range userIDs {
+		err = wallets.Deposit(ctx, userID, domain.NewMoney(100000, domain.DefaultCurrency), nil)
+		if err != nil {
+			return false, fmt.Errorf("deposit to wallet: %w", err)
+		}
+	}
+
+	for _, status := range []domain.GiftCardStatus{0, 0, 1, 1} {
+		code, err := domain.NewGiftCardCode()
+		if err != nil {
+			return false, fmt.Errorf("generate gift-card code: %w", err)
+		}
+
+		amount := domain.NewMoney(10000, domain.DefaultCurrency)
+		giftCard := domain.GiftCard{Code: code, Amount: amount, RemainingAmount: amount, GifterID: userIDs[0], GifteeID: userIDs[1]}
+		err = giftCards.Create(ctx, &giftCard)
+		if err != nil {
+			return false, fmt.Errorf("insert gift-card: %w", err)
+		}
+
+		err = giftCards.UpdateStatus(ctx, giftCard.ID, nil, status, &giftCard.GifteeID)
+		if err != nil {
+			return false, fmt.Errorf("update gift-card status: %w", err)
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+// The admin handlers act on the data of any user on behalf of the staff, the
+// routes check the permissions before they run.
+
+type AdminUserResponse struct {
+	ID          uint                `json:"id"`
+	Email       string              `json:"email"`
+	Roles       []domain.Role       `json:"roles"`
+	Permissions []domain.Permission `json:"permissions"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+func newAdminUserResponse(u domain.User) AdminUserResponse {
+	response := AdminUserResponse{
+		ID:          u.ID,
+		Email:       u.Email,
+		Roles:       u.Roles,
+		Permissions: u.Permissions(),
+		CreatedAt:   u.CreatedAt,
+	}
+	if response.Roles == nil {
+		response.Roles = []domain.Role{}
+	}
+
+	if response.Permissions == nil {
+		response.Permissions = []domain.Permission{}
+	}
+
+	return response
+}
+
+// AdminFindUserHandler looks a user up by the email query parameter.
+func AdminFindUserHandler(userService service.UserService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		email := ctx.QueryParam("email")
+		if email == "" {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "email is required"})
+		}
+
+		user, err := userService.FindUserByEmail(ctx.Request().Context(), email)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "User not found"})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to find user"})
+		}
+
+		return ctx.JSON(http.StatusOK, newAdminUserResponse(*user))
+	}
+}
+
+// AdminUnlockUserHandler forgets the failed logins of the user of the id
+// parameter, so the user can log in again before a lockout ends.
+func AdminUnlockUserHandler(userService service.UserService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		userID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid user ID"})
+		}
+
+		actorID := ctx.Get("user_id").(uint)
+		user, err := userService.UnlockUser(ctx.Request().Context(), uint(userID), &actorID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "User not found"})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to unlock user"})
+		}
+
+		return ctx.JSON(http.StatusOK, newAdminUserResponse(*user))
+	}
+}
+
+type AdminDepositRequest struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// Money parses the deposited amount, the currency defaults to
+// domain.DefaultCurrency when it is not given.
+func (r AdminDepositRequest) Money() (domain.Money, error) {
+	currency := strings.ToUpper(r.Currency)
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
+	amount, err := domain.ParseMoney(r.Amount.String(), currency)
+	if err != nil {
+		return domain.Money{}, err
+	}
+
+	if !amount.IsPositive() {
+		return domain.Money{}, domain.ErrInvalidAmount
+	}
+
+	return amount, nil
+}
+
+type WalletResponse struct {
+	UserID   uint   `json:"user_id"`
+	Balance  string `json:"balance"`
+	Held     string `json:"held"`
+	Currency string `json:"currency"`
+}
+
+func newWalletResponse(w domain.Wallet) WalletResponse {
+	return WalletResponse{
+		UserID:   w.UserID,
+		Balance:  w.Balance.Decimal(),
+		Held:     w.Held.Decimal(),
+		Currency: w.Balance.Currency,
+	}
+}
+
+// AdminDepositHandler funds the wallet of the user of the id parameter with
+// money paid in outside the service, such as a bank transfer, and returns the
+// wallet.
+func AdminDepositHandler(walletService service.WalletService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		userID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid user ID"})
+		}
+
+		request := new(AdminDepositRequest)
+		if err := ctx.Bind(request); err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid request body"})
+		}
+
+		amount, err := request.Money()
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
+		}
+
+		actorID := ctx.Get("user_id").(uint)
+		wallet, err := walletService.Deposit(ctx.Request().Context(), uint(userID), amount, &actorID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "User not found"})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to deposit"})
+		}
+
+		return ctx.JSON(http.StatusOK, newWalletResponse(*wallet))
+	}
+}
+
+// AdminGetReceivedGiftCardsHandler lists the gift cards the user of the id
+// parameter received, it takes the query of GetReceivedGiftCardsHandler.
+func AdminGetReceivedGiftCardsHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+	return adminGetGiftCardsHandler(giftCardService.GetReceivedGiftCardsByUserID)
+}
+
+// AdminGetSentGiftCardsHandler lists the gift cards the user of the id
+// parameter sent, it takes the query of GetSentGiftCardsHandler.
+func AdminGetSentGiftCardsHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+	return adminGetGiftCardsHandler(giftCardService.GetSentGiftCardsByUserID)
+}
+
+func adminGetGiftCardsHandler(find func(context.Context, uint, repository.GiftCardQuery) (repository.GiftCardPage, error)) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		userID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid user ID"})
+		}
+
+		query, err := newGiftCardQuery(ctx)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
+		}
+
+		page, err := find(ctx.Request().Context(), uint(userID), query)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift cards"})
+		}
+
+		return ctx.JSON(http.StatusOK, newGetGiftCards(query, page))
+	}
+}
+
+// AdminExpireGiftCardHandler expires a pending gift card now, before its
+// expiry date if need be.
+func AdminExpireGiftCardHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+	return adminUpdateGiftCardStatusHandler(giftCardService, domain.GCSExpired, "Failed to expire gift card")
+}
+
+// AdminVoidGiftCardHandler voids a pending or accepted gift card. The held
+// amount of a pending card goes back to the gifter, and so does the remaining
+// amount of an accepted one, what was already redeemed stays redeemed.
+func AdminVoidGiftCardHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+	return adminUpdateGiftCardStatusHandler(giftCardService, domain.GCSVoided, "Failed to void gift card")
+}
+
+// adminUpdateGiftCardStatusHandler moves the gift card to status. If-Match is
+// optional, with it the card must still be at that version.
+func adminUpdateGiftCardStatusHandler(giftCardService service.GiftCardService, status domain.GiftCardStatus, failure string) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		giftCardID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid gift card ID"})
+		}
+
+		version, err := ifMatchVersion(ctx)
+		if err != nil && !errors.Is(err, errIfMatchRequired) {
+			return ctx.JSON(http.StatusPreconditionFailed, MessageResponse{Message: err.Error()})
+		}
+
+		userID := ctx.Get("user_id").(uint)
+		giftCard, err := giftCardService.ForceUpdateStatus(ctx.Request().Context(), uint(giftCardID), version, status, userID)
+		if errors.Is(err, domain.ErrGiftCardNotFound) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Gift card not found"})
+		}
+
+		if errors.Is(err, domain.ErrGiftCardVersionMismatch) {
+			return ctx.JSON(http.StatusPreconditionFailed, MessageResponse{Message: err.Error()})
+		}
+
+		var transitionErr *domain.InvalidTransitionError
+		if errors.As(err, &transitionErr) {
+			return ctx.JSON(http.StatusConflict, MessageResponse{Message: transitionErr.Error()})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: failure})
+		}
+
+		ctx.Response().Header().Set(HeaderETag, giftCardETag(giftCard.Version))
+
+		return ctx.JSON(http.StatusOK, newGiftCardResponse(*giftCard))
+	}
+}
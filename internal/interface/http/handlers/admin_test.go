@@ -0,0 +1,516 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+func adminFindUserNewEchoContext(email string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodGet, "/admin/users?email="+url.QueryEscape(email), nil)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", uint(1))
+
+	return ctx, response
+}
+
+func adminGetGiftCardsNewEchoContext(userID string, query string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/admin/users/%s/gift-cards/received?%s", userID, query), nil)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", uint(1))
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(userID)
+
+	return ctx, response
+}
+
+func adminUpdateGiftCardStatusNewEchoContext(actorID uint, giftCardID uint, action string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/admin/gift-cards/%d/%s", giftCardID, action), nil)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", actorID)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(fmt.Sprintf("%d", giftCardID))
+
+	return ctx, response
+}
+
+func adminDepositNewEchoContext(userID string, requestBody string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/admin/users/%s/deposits", userID), strings.NewReader(requestBody))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", uint(1))
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(userID)
+
+	return ctx, response
+}
+
+type AdminFindUserHandlerTestSuite struct {
+	suite.Suite
+	userService *service.UserServiceMock
+}
+
+func (suite *AdminFindUserHandlerTestSuite) SetupSuite() {
+	suite.userService = new(service.UserServiceMock)
+}
+
+func (suite *AdminFindUserHandlerTestSuite) TestAdminFindUserHandler_Success() {
+	require := suite.Require()
+	email := "support@example.com"
+	user := domain.User{ID: 7, Email: email, Roles: []domain.Role{domain.RoleSupport}, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	expectedResponse := `{"id":7,"email":"support@example.com","roles":["support"],"permissions":["gift_cards:expire","gift_cards:read","gift_cards:void","users:read","users:unlock"],"created_at":"2024-01-02T03:04:05Z"}`
+
+	defer suite.userService.On("FindUserByEmail", mock.Anything, email).Return(&user, nil).Unset()
+
+	ctx, response := adminFindUserNewEchoContext(email)
+	err := AdminFindUserHandler(suite.userService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminFindUserHandlerTestSuite) TestAdminFindUserHandler_WithoutRoles_Success() {
+	require := suite.Require()
+	email := "test@example.com"
+	user := domain.User{ID: 8, Email: email, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	expectedResponse := `{"id":8,"email":"test@example.com","roles":[],"permissions":[],"created_at":"2024-01-02T03:04:05Z"}`
+
+	defer suite.userService.On("FindUserByEmail", mock.Anything, email).Return(&user, nil).Unset()
+
+	ctx, response := adminFindUserNewEchoContext(email)
+	err := AdminFindUserHandler(suite.userService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminFindUserHandlerTestSuite) TestAdminFindUserHandler_EmailMissing_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "email is required"}`
+
+	ctx, response := adminFindUserNewEchoContext("")
+	err := AdminFindUserHandler(suite.userService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminFindUserHandlerTestSuite) TestAdminFindUserHandler_UserNotFound_Failure() {
+	require := suite.Require()
+	email := "missing@example.com"
+	expectedResponse := `{"message": "User not found"}`
+
+	defer suite.userService.On("FindUserByEmail", mock.Anything, email).Return(nil, domain.ErrUserNotFound).Unset()
+
+	ctx, response := adminFindUserNewEchoContext(email)
+	err := AdminFindUserHandler(suite.userService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminFindUserHandlerTestSuite) TestAdminFindUserHandler_ServiceError_Failure() {
+	require := suite.Require()
+	email := "test@example.com"
+	expectedResponse := `{"message": "Failed to find user"}`
+
+	defer suite.userService.On("FindUserByEmail", mock.Anything, email).Return(nil, errors.New("database error")).Unset()
+
+	ctx, response := adminFindUserNewEchoContext(email)
+	err := AdminFindUserHandler(suite.userService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func adminUnlockUserNewEchoContext(actorID uint, userID string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/admin/users/%s/unlock", userID), nil)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", actorID)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(userID)
+
+	return ctx, response
+}
+
+type AdminUnlockUserHandlerTestSuite struct {
+	suite.Suite
+	userService *service.UserServiceMock
+}
+
+func (suite *AdminUnlockUserHandlerTestSuite) SetupSuite() {
+	suite.userService = new(service.UserServiceMock)
+}
+
+func (suite *AdminUnlockUserHandlerTestSuite) TestAdminUnlockUserHandler_Success() {
+	require := suite.Require()
+	actorID := uint(1)
+	user := &domain.User{ID: 7, Email: "foo@example.com", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	expectedResponse := `{"id":7,"email":"foo@example.com","roles":[],"permissions":[],"created_at":"2024-01-02T03:04:05Z"}`
+
+	defer suite.userService.On("UnlockUser", mock.Anything, uint(7), &actorID).Return(user, nil).Unset()
+
+	ctx, response := adminUnlockUserNewEchoContext(actorID, "7")
+	err := AdminUnlockUserHandler(suite.userService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminUnlockUserHandlerTestSuite) TestAdminUnlockUserHandler_InvalidUser_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "Invalid user ID"}`
+
+	ctx, response := adminUnlockUserNewEchoContext(1, "foo")
+	err := AdminUnlockUserHandler(suite.userService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminUnlockUserHandlerTestSuite) TestAdminUnlockUserHandler_ServiceErrors_Failure() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{domain.ErrUserNotFound, http.StatusNotFound, `{"message": "User not found"}`},
+		{errors.New("database error"), http.StatusInternalServerError, `{"message": "Failed to unlock user"}`},
+	} {
+		call := suite.userService.On("UnlockUser", mock.Anything, uint(7), mock.Anything).Return(nil, tc.err)
+
+		ctx, response := adminUnlockUserNewEchoContext(1, "7")
+		err := AdminUnlockUserHandler(suite.userService)(ctx)
+		call.Unset()
+
+		require.NoError(err)
+		require.Equal(tc.expectedCode, response.Code)
+		require.JSONEq(tc.expectedBody, response.Body.String())
+	}
+}
+
+type AdminGetGiftCardsHandlerTestSuite struct {
+	suite.Suite
+	giftCardService *service.GiftCardServiceMock
+}
+
+func (suite *AdminGetGiftCardsHandlerTestSuite) SetupSuite() {
+	suite.giftCardService = new(service.GiftCardServiceMock)
+}
+
+func (suite *AdminGetGiftCardsHandlerTestSuite) TestAdminGetReceivedGiftCardsHandler_Success() {
+	require := suite.Require()
+	userID := uint(20)
+	status := domain.GCSPending
+	giftCards := []domain.GiftCard{{ID: 10, Amount: domain.NewMoney(10000, "USD"), RemainingAmount: domain.NewMoney(10000, "USD"), Status: status, GifterID: 10, GifteeID: userID, Version: 1}}
+	expectedResponse := `{"gift_cards":[{"id":10,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":2,"gifter_id":10,"giftee_id":20,"version":1}],"page":1,"page_size":10}`
+
+	defer suite.giftCardService.On("GetReceivedGiftCardsByUserID", mock.Anything, userID, repository.GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1}).
+		Return(repository.GiftCardPage{GiftCards: giftCards}, nil).Unset()
+
+	ctx, response := adminGetGiftCardsNewEchoContext("20", "status=2")
+	err := AdminGetReceivedGiftCardsHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminGetGiftCardsHandlerTestSuite) TestAdminGetSentGiftCardsHandler_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCards := []domain.GiftCard{{ID: 10, Amount: domain.NewMoney(10000, "USD"), RemainingAmount: domain.NewMoney(10000, "USD"), Status: domain.GCSPending, GifterID: userID, GifteeID: 20, Version: 1}}
+	expectedResponse := `{"gift_cards":[{"id":10,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":2,"gifter_id":10,"giftee_id":20,"version":1}],"page":1,"page_size":10}`
+
+	defer suite.giftCardService.On("GetSentGiftCardsByUserID", mock.Anything, userID, repository.GiftCardQuery{PageSize: 10, PageNumber: 1}).
+		Return(repository.GiftCardPage{GiftCards: giftCards}, nil).Unset()
+
+	ctx, response := adminGetGiftCardsNewEchoContext("10", "")
+	err := AdminGetSentGiftCardsHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminGetGiftCardsHandlerTestSuite) TestAdminGetReceivedGiftCardsHandler_InvalidUser_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "Invalid user ID"}`
+
+	ctx, response := adminGetGiftCardsNewEchoContext("foo", "")
+	err := AdminGetReceivedGiftCardsHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminGetGiftCardsHandlerTestSuite) TestAdminGetReceivedGiftCardsHandler_InvalidGiftCardStatus_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "invalid gift-card status"}`
+
+	ctx, response := adminGetGiftCardsNewEchoContext("20", "status=6")
+	err := AdminGetReceivedGiftCardsHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminGetGiftCardsHandlerTestSuite) TestAdminGetSentGiftCardsHandler_ServiceError_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	expectedResponse := `{"message": "Failed to get gift cards"}`
+
+	defer suite.giftCardService.On("GetSentGiftCardsByUserID", mock.Anything, userID, repository.GiftCardQuery{PageSize: 10, PageNumber: 1}).
+		Return(repository.GiftCardPage{}, errors.New("database error")).Unset()
+
+	ctx, response := adminGetGiftCardsNewEchoContext("10", "")
+	err := AdminGetSentGiftCardsHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+type AdminUpdateGiftCardStatusHandlerTestSuite struct {
+	suite.Suite
+	giftCardService *service.GiftCardServiceMock
+}
+
+func (suite *AdminUpdateGiftCardStatusHandlerTestSuite) SetupSuite() {
+	suite.giftCardService = new(service.GiftCardServiceMock)
+}
+
+func (suite *AdminUpdateGiftCardStatusHandlerTestSuite) TestAdminVoidGiftCardHandler_Success() {
+	require := suite.Require()
+	actorID := uint(1)
+	giftCardID := uint(101)
+	giftCard := domain.GiftCard{ID: giftCardID, Amount: domain.NewMoney(10000, "USD"), RemainingAmount: domain.NewMoney(10000, "USD"), Status: domain.GCSVoided, GifterID: 10, GifteeID: 20, Version: 2}
+	expectedResponse := `{"id":101,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":5,"gifter_id":10,"giftee_id":20,"version":2}`
+
+	defer suite.giftCardService.On("ForceUpdateStatus", mock.Anything, giftCardID, (*uint)(nil), domain.GCSVoided, actorID).Return(&giftCard, nil).Unset()
+
+	ctx, response := adminUpdateGiftCardStatusNewEchoContext(actorID, giftCardID, "void")
+	err := AdminVoidGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+	require.Equal(`"2"`, response.Header().Get(HeaderETag))
+}
+
+func (suite *AdminUpdateGiftCardStatusHandlerTestSuite) TestAdminExpireGiftCardHandler_IfMatch_Success() {
+	require := suite.Require()
+	actorID := uint(1)
+	giftCardID := uint(101)
+	version := uint(1)
+	giftCard := domain.GiftCard{ID: giftCardID, Amount: domain.NewMoney(10000, "USD"), RemainingAmount: domain.NewMoney(10000, "USD"), Status: domain.GCSExpired, GifterID: 10, GifteeID: 20, Version: 2}
+	expectedResponse := `{"id":101,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":4,"gifter_id":10,"giftee_id":20,"version":2}`
+
+	defer suite.giftCardService.On("ForceUpdateStatus", mock.Anything, giftCardID, &version, domain.GCSExpired, actorID).Return(&giftCard, nil).Unset()
+
+	ctx, response := adminUpdateGiftCardStatusNewEchoContext(actorID, giftCardID, "expire")
+	ctx.Request().Header.Set(HeaderIfMatch, `"1"`)
+	err := AdminExpireGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminUpdateGiftCardStatusHandlerTestSuite) TestAdminExpireGiftCardHandler_IfMatchMalformed_Failure() {
+	require := suite.Require()
+
+	ctx, response := adminUpdateGiftCardStatusNewEchoContext(1, 101, "expire")
+	ctx.Request().Header.Set(HeaderIfMatch, "foo")
+	err := AdminExpireGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusPreconditionFailed, response.Code)
+}
+
+func (suite *AdminUpdateGiftCardStatusHandlerTestSuite) TestAdminVoidGiftCardHandler_InvalidGiftCard_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "Invalid gift card ID"}`
+
+	ctx, response := adminUpdateGiftCardStatusNewEchoContext(1, 101, "void")
+	ctx.SetParamValues("foo")
+	err := AdminVoidGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminUpdateGiftCardStatusHandlerTestSuite) TestAdminVoidGiftCardHandler_ServiceErrors_Failure() {
+	require := suite.Require()
+	actorID := uint(1)
+	giftCardID := uint(101)
+
+	for _, tc := range []struct {
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{domain.ErrGiftCardNotFound, http.StatusNotFound, `{"message": "Gift card not found"}`},
+		{domain.ErrGiftCardVersionMismatch, http.StatusPreconditionFailed, `{"message": "gift card has been changed since it was read"}`},
+		{&domain.InvalidTransitionError{From: domain.GCSAccepted, To: domain.GCSVoided}, http.StatusConflict, `{"message": "gift card cannot move from accepted to voided"}`},
+		{errors.New("database error"), http.StatusInternalServerError, `{"message": "Failed to void gift card"}`},
+	} {
+		call := suite.giftCardService.On("ForceUpdateStatus", mock.Anything, giftCardID, (*uint)(nil), domain.GCSVoided, actorID).Return(nil, tc.err)
+
+		ctx, response := adminUpdateGiftCardStatusNewEchoContext(actorID, giftCardID, "void")
+		err := AdminVoidGiftCardHandler(suite.giftCardService)(ctx)
+		call.Unset()
+
+		require.NoError(err)
+		require.Equal(tc.expectedCode, response.Code, tc.err.Error())
+		require.JSONEq(tc.expectedBody, response.Body.String())
+	}
+}
+
+type AdminDepositHandlerTestSuite struct {
+	suite.Suite
+	walletService *service.WalletServiceMock
+}
+
+func (suite *AdminDepositHandlerTestSuite) SetupSuite() {
+	suite.walletService = new(service.WalletServiceMock)
+}
+
+func (suite *AdminDepositHandlerTestSuite) TestAdminDepositHandler_Success() {
+	require := suite.Require()
+	actorID := uint(1)
+	amount := domain.NewMoney(25050, "EUR")
+	wallet := domain.Wallet{ID: 3, UserID: 10, Balance: domain.NewMoney(30050, "EUR"), Held: domain.NewMoney(1000, "EUR")}
+	expectedResponse := `{"user_id":10,"balance":"300.50","held":"10.00","currency":"EUR"}`
+
+	defer suite.walletService.On("Deposit", mock.Anything, uint(10), amount, &actorID).Return(&wallet, nil).Unset()
+
+	ctx, response := adminDepositNewEchoContext("10", `{"amount": "250.50", "currency": "eur"}`)
+	err := AdminDepositHandler(suite.walletService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminDepositHandlerTestSuite) TestAdminDepositHandler_DefaultCurrency_Success() {
+	require := suite.Require()
+	actorID := uint(1)
+	amount := domain.NewMoney(10000, domain.DefaultCurrency)
+	wallet := domain.Wallet{ID: 3, UserID: 10, Balance: amount, Held: domain.NewMoney(0, domain.DefaultCurrency)}
+	expectedResponse := `{"user_id":10,"balance":"100.00","held":"0.00","currency":"USD"}`
+
+	defer suite.walletService.On("Deposit", mock.Anything, uint(10), amount, &actorID).Return(&wallet, nil).Unset()
+
+	ctx, response := adminDepositNewEchoContext("10", `{"amount": 100}`)
+	err := AdminDepositHandler(suite.walletService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminDepositHandlerTestSuite) TestAdminDepositHandler_InvalidRequest_Failure() {
+	require := suite.Require()
+	walletService := new(service.WalletServiceMock)
+
+	for _, tc := range []struct {
+		userID           string
+		requestBody      string
+		expectedResponse string
+	}{
+		{"foo", `{"amount": 100}`, `{"message": "Invalid user ID"}`},
+		{"10", `{"amount":`, `{"message": "Invalid request body"}`},
+		{"10", `{"amount": 0}`, `{"message": "invalid amount"}`},
+		{"10", `{"amount": -5}`, `{"message": "invalid amount"}`},
+		{"10", `{"amount": 1.001}`, `{"message": "invalid amount"}`},
+		{"10", `{"amount": 100, "currency": "XXX"}`, `{"message": "invalid currency"}`},
+	} {
+		ctx, response := adminDepositNewEchoContext(tc.userID, tc.requestBody)
+		err := AdminDepositHandler(walletService)(ctx)
+
+		require.NoError(err)
+		require.Equal(http.StatusBadRequest, response.Code, tc.requestBody)
+		require.JSONEq(tc.expectedResponse, response.Body.String(), tc.requestBody)
+	}
+
+	walletService.AssertNotCalled(suite.T(), "Deposit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AdminDepositHandlerTestSuite) TestAdminDepositHandler_Failure() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		err              error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{domain.ErrUserNotFound, http.StatusNotFound, `{"message": "User not found"}`},
+		{errors.New("service error"), http.StatusInternalServerError, `{"message": "Failed to deposit"}`},
+	} {
+		call := suite.walletService.On("Deposit", mock.Anything, uint(10), mock.Anything, mock.Anything).Return(nil, tc.err)
+
+		ctx, response := adminDepositNewEchoContext("10", `{"amount": 100}`)
+		err := AdminDepositHandler(suite.walletService)(ctx)
+		call.Unset()
+
+		require.NoError(err)
+		require.Equal(tc.expectedCode, response.Code)
+		require.JSONEq(tc.expectedResponse, response.Body.String())
+	}
+}
+
+func TestAdminDepositHandler(t *testing.T) {
+	suite.Run(t, new(AdminDepositHandlerTestSuite))
+}
+
+func TestAdminFindUserHandler(t *testing.T) {
+	suite.Run(t, new(AdminFindUserHandlerTestSuite))
+}
+
+func TestAdminUnlockUserHandler(t *testing.T) {
+	suite.Run(t, new(AdminUnlockUserHandlerTestSuite))
+}
+
+func TestAdminGetGiftCardsHandler(t *testing.T) {
+	suite.Run(t, new(AdminGetGiftCardsHandlerTestSuite))
+}
+
+func TestAdminUpdateGiftCardStatusHandler(t *testing.T) {
+	suite.Run(t, new(AdminUpdateGiftCardStatusHandlerTestSuite))
+}
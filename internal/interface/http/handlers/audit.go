@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+type AuditEventResponse struct {
+	ID         uint               `json:"id"`
+	ActorID    *uint              `json:"actor_id"`
+	Action     domain.AuditAction `json:"action"`
+	TargetType string             `json:"target_type"`
+	TargetID   *uint              `json:"target_id"`
+	Before     json.RawMessage    `json:"before"`
+	After      json.RawMessage    `json:"after"`
+	RequestID  string             `json:"request_id"`
+	IP         string             `json:"ip"`
+	UserAgent  string             `json:"user_agent"`
+	PrevHash   string             `json:"prev_hash"`
+	Hash       string             `json:"hash"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+func newAuditEventResponse(e domain.AuditEvent) AuditEventResponse {
+	return AuditEventResponse{
+		ID:         e.ID,
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Before:     e.Before,
+		After:      e.After,
+		RequestID:  e.RequestID,
+		IP:         e.IP,
+		UserAgent:  e.UserAgent,
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+// GetAuditEvents is a page of the audit log. NextBeforeID is the before_id of
+// the next page, there is none once a page comes back empty.
+type GetAuditEvents struct {
+	Events       []AuditEventResponse `json:"events"`
+	NextBeforeID uint                 `json:"next_before_id,omitempty"`
+}
+
+type AuditChainReportResponse struct {
+	OK      bool   `json:"ok"`
+	Events  int    `json:"events"`
+	EventID uint   `json:"event_id,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// AdminGetAuditEventsHandler lists the audit log newest first. The query
+// string filters it:
+//
+//   - actor_id, action, target_type and target_id match the events
+//   - from and to, RFC 3339 times, bound the time of the events
+//   - before_id is the next_before_id of the previous page
+//   - limit is up to repository.MaxAuditPageSize
+func AdminGetAuditEventsHandler(auditService service.AuditService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		query, err := newAuditQuery(ctx)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
+		}
+
+		events, err := auditService.FindEvents(ctx.Request().Context(), query)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get audit events"})
+		}
+
+		response := GetAuditEvents{Events: make([]AuditEventResponse, 0, len(events))}
+		for _, event := range events {
+			response.Events = append(response.Events, newAuditEventResponse(event))
+		}
+
+		if len(events) > 0 {
+			response.NextBeforeID = events[len(events)-1].ID
+		}
+
+		return ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// AdminVerifyAuditLogHandler checks that the audit log was not tampered with.
+func AdminVerifyAuditLogHandler(auditService service.AuditService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		report, err := auditService.Verify(ctx.Request().Context())
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to verify audit log"})
+		}
+
+		response := AuditChainReportResponse{OK: report.OK(), Events: report.Events}
+		if report.Break != nil {
+			response.EventID = report.Break.EventID
+			response.Reason = report.Break.Reason
+		}
+
+		return ctx.JSON(http.StatusOK, response)
+	}
+}
+
+func newAuditQuery(ctx echo.Context) (repository.AuditQuery, error) {
+	query := repository.AuditQuery{
+		Action:     domain.AuditAction(ctx.QueryParam("action")),
+		TargetType: ctx.QueryParam("target_type"),
+	}
+
+	var err error
+	query.ActorID, err = idQueryParam(ctx, "actor_id")
+	if err != nil {
+		return query, err
+	}
+
+	query.TargetID, err = idQueryParam(ctx, "target_id")
+	if err != nil {
+		return query, err
+	}
+
+	query.From, err = timeQueryParam(ctx, "from")
+	if err != nil {
+		return query, err
+	}
+
+	query.To, err = timeQueryParam(ctx, "to")
+	if err != nil {
+		return query, err
+	}
+
+	beforeID, err := idQueryParam(ctx, "before_id")
+	if err != nil {
+		return query, err
+	}
+
+	if beforeID != nil {
+		query.BeforeID = *beforeID
+	}
+
+	if value := ctx.QueryParam("limit"); value != "" {
+		query.Limit, err = strconv.Atoi(value)
+		if err != nil || query.Limit < 1 {
+			return query, errors.New("invalid limit")
+		}
+	}
+
+	return query, nil
+}
+
+// idQueryParam returns the id of the query parameter, nil if it is not set.
+func idQueryParam(ctx echo.Context, name string) (*uint, error) {
+	value := ctx.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return nil, errors.New("invalid " + name)
+	}
+
+	u := uint(id)
+
+	return &u, nil
+}
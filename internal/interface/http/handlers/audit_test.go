@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+func adminAuditNewEchoContext(path, query string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodGet, path+"?"+query, nil)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", uint(1))
+
+	return ctx, response
+}
+
+type AdminAuditHandlerTestSuite struct {
+	suite.Suite
+	auditService *service.AuditServiceMock
+}
+
+func (suite *AdminAuditHandlerTestSuite) SetupSuite() {
+	suite.auditService = new(service.AuditServiceMock)
+}
+
+func (suite *AdminAuditHandlerTestSuite) TestAdminGetAuditEventsHandler_Success() {
+	require := suite.Require()
+	actorID := uint(10)
+	targetID := uint(101)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := repository.AuditQuery{ActorID: &actorID, Action: domain.AuditGiftCardCreated, TargetType: domain.AuditTargetGiftCard, TargetID: &targetID, From: &from, BeforeID: 9, Limit: 20}
+	events := []domain.AuditEvent{{
+		ID:         5,
+		ActorID:    &actorID,
+		Action:     domain.AuditGiftCardCreated,
+		TargetType: domain.AuditTargetGiftCard,
+		TargetID:   &targetID,
+		After:      []byte(`{"id":101}`),
+		RequestID:  "req",
+		IP:         "192.0.2.1",
+		UserAgent:  "curl",
+		PrevHash:   "prev",
+		Hash:       "hash",
+		CreatedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}}
+	expectedResponse := `{"events":[{"id":5,"actor_id":10,"action":"gift_card.created","target_type":"gift_card","target_id":101,"before":null,"after":{"id":101},"request_id":"req","ip":"192.0.2.1","user_agent":"curl","prev_hash":"prev","hash":"hash","created_at":"2024-01-02T03:04:05Z"}],"next_before_id":5}`
+
+	defer suite.auditService.On("FindEvents", mock.Anything, query).Return(events, nil).Unset()
+
+	ctx, response := adminAuditNewEchoContext("/admin/audit", "actor_id=10&action=gift_card.created&target_type=gift_card&target_id=101&from=2024-01-01T00:00:00Z&before_id=9&limit=20")
+	err := AdminGetAuditEventsHandler(suite.auditService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *AdminAuditHandlerTestSuite) TestAdminGetAuditEventsHandler_Empty_Success() {
+	require := suite.Require()
+
+	defer suite.auditService.On("FindEvents", mock.Anything, repository.AuditQuery{}).Return(nil, nil).Unset()
+
+	ctx, response := adminAuditNewEchoContext("/admin/audit", "")
+	err := AdminGetAuditEventsHandler(suite.auditService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(`{"events":[]}`, response.Body.String())
+}
+
+func (suite *AdminAuditHandlerTestSuite) TestAdminGetAuditEventsHandler_InvalidQuery_Failure() {
+	require := suite.Require()
+
+	for query, message := range map[string]string{
+		"actor_id=foo":  "invalid actor_id",
+		"target_id=-1":  "invalid target_id",
+		"before_id=bar": "invalid before_id",
+		"from=today":    "from must be an RFC 3339 time",
+		"limit=0":       "invalid limit",
+	} {
+		ctx, response := adminAuditNewEchoContext("/admin/audit", query)
+		err := AdminGetAuditEventsHandler(suite.auditService)(ctx)
+
+		require.NoError(err)
+		require.Equal(http.StatusBadRequest, response.Code, query)
+		require.JSONEq(`{"message":"`+message+`"}`, response.Body.String(), query)
+	}
+}
+
+func (suite *AdminAuditHandlerTestSuite) TestAdminGetAuditEventsHandler_ServiceError_Failure() {
+	require := suite.Require()
+
+	defer suite.auditService.On("FindEvents", mock.Anything, mock.Anything).Return(nil, errors.New("database error")).Unset()
+
+	ctx, response := adminAuditNewEchoContext("/admin/audit", "")
+	err := AdminGetAuditEventsHandler(suite.auditService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(`{"message":"Failed to get audit events"}`, response.Body.String())
+}
+
+func (suite *AdminAuditHandlerTestSuite) TestAdminVerifyAuditLogHandler_Success() {
+	require := suite.Require()
+
+	defer suite.auditService.On("Verify", mock.Anything).Return(&domain.AuditChainReport{Events: 3}, nil).Unset()
+
+	ctx, response := adminAuditNewEchoContext("/admin/audit/verify", "")
+	err := AdminVerifyAuditLogHandler(suite.auditService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(`{"ok":true,"events":3}`, response.Body.String())
+}
+
+func (suite *AdminAuditHandlerTestSuite) TestAdminVerifyAuditLogHandler_Broken_Success() {
+	require := suite.Require()
+	report := &domain.AuditChainReport{Events: 3, Break: &domain.AuditChainBreak{EventID: 2, Reason: "does not match its hash"}}
+
+	defer suite.auditService.On("Verify", mock.Anything).Return(report, nil).Unset()
+
+	ctx, response := adminAuditNewEchoContext("/admin/audit/verify", "")
+	err := AdminVerifyAuditLogHandler(suite.auditService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(`{"ok":false,"events":3,"event_id":2,"reason":"does not match its hash"}`, response.Body.String())
+}
+
+func (suite *AdminAuditHandlerTestSuite) TestAdminVerifyAuditLogHandler_ServiceError_Failure() {
+	require := suite.Require()
+
+	defer suite.auditService.On("Verify", mock.Anything).Return(nil, errors.New("database error")).Unset()
+
+	ctx, response := adminAuditNewEchoContext("/admin/audit/verify", "")
+	err := AdminVerifyAuditLogHandler(suite.auditService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(`{"message":"Failed to verify audit log"}`, response.Body.String())
+}
+
+func TestAdminAuditHandler(t *testing.T) {
+	suite.Run(t, new(AdminAuditHandlerTestSuite))
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+// errIfMatchRequired is returned for a request that changes a gift card
+// without saying which version of the card the change is based on.
+var errIfMatchRequired = errors.New("the If-Match header is required")
+
+// giftCardETag returns the entity tag of the given version of a gift card.
+func giftCardETag(version uint) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatchVersion returns the version of the gift card the request is
+// conditioned on by its If-Match header, nil for *. Only * and a single strong
+// entity tag of a gift card are understood, anything else never matches.
+func ifMatchVersion(ctx echo.Context) (*uint, error) {
+	header := strings.TrimSpace(ctx.Request().Header.Get(HeaderIfMatch))
+	if header == "" {
+		return nil, errIfMatchRequired
+	}
+
+	if header == "*" {
+		return nil, nil
+	}
+
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return nil, domain.ErrGiftCardVersionMismatch
+	}
+
+	version, err := strconv.ParseUint(header[1:len(header)-1], 10, 0)
+	if err != nil {
+		return nil, domain.ErrGiftCardVersionMismatch
+	}
+
+	v := uint(version)
+
+	return &v, nil
+}
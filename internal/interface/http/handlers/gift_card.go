@@ -1,28 +1,84 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
 	"github.com/jmehdipour/gift-card/internal/service"
 )
 
 type CreateGiftCardRequest struct {
-	Amount   float64 `json:"amount"`
-	GifteeID uint    `json:"giftee_id"`
+	Amount    json.Number `json:"amount"`
+	Currency  string      `json:"currency"`
+	GifteeID  uint        `json:"giftee_id"`
+	ExpiresAt *time.Time  `json:"expires_at"`
+}
+
+// Money parses the requested amount, the currency defaults to
+// domain.DefaultCurrency when it is not given.
+func (r CreateGiftCardRequest) Money() (domain.Money, error) {
+	currency := strings.ToUpper(r.Currency)
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
+	return domain.ParseMoney(r.Amount.String(), currency)
+}
+
+func (r CreateGiftCardRequest) Validate() error {
+	amount, err := r.Money()
+	if err != nil {
+		return err
+	}
+
+	if !amount.IsPositive() {
+		return domain.ErrInvalidAmount
+	}
+
+	if r.GifteeID == 0 {
+		return errors.New("invalid giftee")
+	}
+
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		return errors.New("expiry date must be in the future")
+	}
+
+	return nil
 }
 
 type GiftCardResponse struct {
-	ID       uint    `json:"id"`
-	Amount   float64 `json:"amount"`
-	Status   int     `json:"status"`
-	GifterID uint    `json:"gifter_id"`
-	GifteeID uint    `json:"giftee_id"`
+	ID              uint       `json:"id"`
+	Amount          string     `json:"amount"`
+	RemainingAmount string     `json:"remaining_amount"`
+	Currency        string     `json:"currency"`
+	Status          int        `json:"status"`
+	GifterID        uint       `json:"gifter_id"`
+	GifteeID        uint       `json:"giftee_id"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	Version         uint       `json:"version"`
+}
+
+func newGiftCardResponse(g domain.GiftCard) GiftCardResponse {
+	return GiftCardResponse{
+		ID:              g.ID,
+		Amount:          g.Amount.Decimal(),
+		RemainingAmount: g.RemainingAmount.Decimal(),
+		Currency:        g.Amount.Currency,
+		Status:          int(g.Status),
+		GifterID:        g.GifterID,
+		GifteeID:        g.GifteeID,
+		ExpiresAt:       g.ExpiresAt,
+		Version:         g.Version,
+	}
 }
 
 func CreateGiftCardHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
@@ -33,18 +89,71 @@ func CreateGiftCardHandler(giftCardService service.GiftCardService) echo.Handler
 			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
 		}
 
+		err = request.Validate()
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
+		}
+
+		amount, _ := request.Money()
 		userID := ctx.Get("user_id").(uint)
-		giftCard, err := giftCardService.CreateGiftCard(request.Amount, userID, request.GifteeID)
+		giftCard, err := giftCardService.CreateGiftCard(ctx.Request().Context(), amount, userID, request.GifteeID, request.ExpiresAt)
+		if errors.Is(err, domain.ErrInsufficientFunds) || errors.Is(err, domain.ErrGiftCardToSelf) || errors.Is(err, domain.ErrGifteeNotFound) {
+			return ctx.JSON(http.StatusUnprocessableEntity, MessageResponse{Message: err.Error()})
+		}
+
 		if err != nil {
 			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: err.Error()})
 		}
 
-		return ctx.JSON(http.StatusCreated, GiftCardResponse{
-			ID:       giftCard.ID,
-			Amount:   giftCard.Amount,
-			GifterID: giftCard.GifterID,
-			GifteeID: giftCard.GifteeID,
-			Status:   int(domain.GCSPending),
+		ctx.Response().Header().Set(HeaderETag, giftCardETag(giftCard.Version))
+
+		return ctx.JSON(http.StatusCreated, newGiftCardResponse(*giftCard))
+	}
+}
+
+type GetGiftCardResponse struct {
+	GiftCardResponse
+	StatusName string                         `json:"status_name"`
+	CreatedAt  time.Time                      `json:"created_at"`
+	UpdatedAt  time.Time                      `json:"updated_at"`
+	History    []GiftCardStatusChangeResponse `json:"history"`
+}
+
+// GetGiftCardHandler shows a gift card and its status history to its gifter
+// and its giftee. Anyone else gets a 404 like for a card that does not exist,
+// so the ids of other users' cards cannot be probed. The ETag of the response
+// is the version of the card, requests that change the card send it back in
+// If-Match.
+func GetGiftCardHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		giftCardID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid gift card ID"})
+		}
+
+		giftCard, err := giftCardService.FindGiftCard(ctx.Request().Context(), uint(giftCardID))
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift card"})
+		}
+
+		userID := ctx.Get("user_id").(uint)
+		if giftCard == nil || !giftCard.IsParticipant(userID) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Gift card not found"})
+		}
+
+		history, err := giftCardService.GetStatusHistory(ctx.Request().Context(), giftCard.ID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift card history"})
+		}
+
+		ctx.Response().Header().Set(HeaderETag, giftCardETag(giftCard.Version))
+
+		return ctx.JSON(http.StatusOK, GetGiftCardResponse{
+			GiftCardResponse: newGiftCardResponse(*giftCard),
+			StatusName:       giftCard.Status.String(),
+			CreatedAt:        giftCard.CreationDate,
+			UpdatedAt:        giftCard.UpdatedAt,
+			History:          newGiftCardStatusChangeResponses(history),
 		})
 	}
 }
@@ -66,38 +175,305 @@ func UpdateGiftCardStatusHandler(giftCardService service.GiftCardService) echo.H
 			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid gift card ID"})
 		}
 
-		giftCard, err := giftCardService.FindGiftCard(uint(giftCardID))
+		// The receiver can only decide on the card, other statuses are reached
+		// by the gifter or by the system.
+		status := domain.GiftCardStatus(request.Status)
+		if status != domain.GCSAccepted && status != domain.GCSRejected {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid gift card status for update"})
+		}
+
+		version, err := ifMatchVersion(ctx)
+		if errors.Is(err, errIfMatchRequired) {
+			return ctx.JSON(http.StatusPreconditionRequired, MessageResponse{Message: err.Error()})
+		}
+
 		if err != nil {
-			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift card"})
+			return ctx.JSON(http.StatusPreconditionFailed, MessageResponse{Message: err.Error()})
 		}
 
-		if giftCard == nil {
+		userID := ctx.Get("user_id").(uint)
+		giftCard, err := giftCardService.UpdateStatus(ctx.Request().Context(), uint(giftCardID), version, status, userID)
+		if errors.Is(err, domain.ErrGiftCardNotFound) {
 			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Gift card not found"})
 		}
 
+		if errors.Is(err, domain.ErrNotGiftCardGiftee) {
+			return ctx.JSON(http.StatusForbidden, MessageResponse{Message: fmt.Sprintf("forbidden: user %d is not the receiver of gift card %d", userID, giftCardID)})
+		}
+
+		if errors.Is(err, domain.ErrGiftCardVersionMismatch) {
+			return ctx.JSON(http.StatusPreconditionFailed, MessageResponse{Message: err.Error()})
+		}
+
+		var transitionErr *domain.InvalidTransitionError
+		if errors.As(err, &transitionErr) {
+			return ctx.JSON(http.StatusConflict, MessageResponse{Message: transitionErr.Error()})
+		}
+
+		if errors.Is(err, domain.ErrGiftCardExpired) {
+			return ctx.JSON(http.StatusConflict, MessageResponse{Message: err.Error()})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to update gift card status"})
+		}
+
+		ctx.Response().Header().Set(HeaderETag, giftCardETag(giftCard.Version))
+
+		return ctx.JSON(http.StatusOK, newGiftCardResponse(*giftCard))
+	}
+}
+
+func CancelGiftCardHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		giftCardID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid gift card ID"})
+		}
+
+		version, err := ifMatchVersion(ctx)
+		if errors.Is(err, errIfMatchRequired) {
+			return ctx.JSON(http.StatusPreconditionRequired, MessageResponse{Message: err.Error()})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusPreconditionFailed, MessageResponse{Message: err.Error()})
+		}
+
 		userID := ctx.Get("user_id").(uint)
+		giftCard, err := giftCardService.UpdateStatus(ctx.Request().Context(), uint(giftCardID), version, domain.GCSCancelled, userID)
+		if errors.Is(err, domain.ErrGiftCardNotFound) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Gift card not found"})
+		}
+
+		if errors.Is(err, domain.ErrNotGiftCardGifter) {
+			return ctx.JSON(http.StatusForbidden, MessageResponse{Message: fmt.Sprintf("forbidden: user %d is not the sender of gift card %d", userID, giftCardID)})
+		}
+
+		if errors.Is(err, domain.ErrGiftCardVersionMismatch) {
+			return ctx.JSON(http.StatusPreconditionFailed, MessageResponse{Message: err.Error()})
+		}
+
+		var transitionErr *domain.InvalidTransitionError
+		if errors.As(err, &transitionErr) {
+			return ctx.JSON(http.StatusConflict, MessageResponse{Message: transitionErr.Error()})
+		}
+
+		if errors.Is(err, domain.ErrGiftCardExpired) {
+			return ctx.JSON(http.StatusConflict, MessageResponse{Message: err.Error()})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to cancel gift card"})
+		}
+
+		ctx.Response().Header().Set(HeaderETag, giftCardETag(giftCard.Version))
+
+		return ctx.JSON(http.StatusOK, newGiftCardResponse(*giftCard))
+	}
+}
+
+type RedeemGiftCardRequest struct {
+	Code     string      `json:"code"`
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// Money parses the amount to redeem, the currency defaults to
+// domain.DefaultCurrency when it is not given.
+func (r RedeemGiftCardRequest) Money() (domain.Money, error) {
+	currency := strings.ToUpper(r.Currency)
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
+	return domain.ParseMoney(r.Amount.String(), currency)
+}
+
+func (r RedeemGiftCardRequest) Validate() error {
+	_, err := domain.ParseGiftCardCode(r.Code)
+	if err != nil {
+		return err
+	}
+
+	amount, err := r.Money()
+	if err != nil {
+		return err
+	}
+
+	if !amount.IsPositive() {
+		return domain.ErrInvalidAmount
+	}
+
+	return nil
+}
+
+type RedeemGiftCardResponse struct {
+	ID              uint   `json:"id"`
+	GiftCardID      uint   `json:"gift_card_id"`
+	Amount          string `json:"amount"`
+	RemainingAmount string `json:"remaining_amount"`
+	Currency        string `json:"currency"`
+}
+
+// RedeemGiftCardHandler lets the authenticated user, e.g. a merchant the
+// holder gave the code to, spend part or all of the remaining amount of a
+// gift card.
+func RedeemGiftCardHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		request := new(RedeemGiftCardRequest)
+		err := ctx.Bind(request)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid request body"})
+		}
+
+		err = request.Validate()
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
+		}
+
+		amount, _ := request.Money()
+		userID := ctx.Get("user_id").(uint)
+		redemption, err := giftCardService.RedeemGiftCard(ctx.Request().Context(), request.Code, userID, amount)
+		switch {
+		case errors.Is(err, domain.ErrGiftCardNotFound):
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Gift card not found"})
+		case errors.Is(err, domain.ErrCurrencyMismatch):
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
+		case errors.Is(err, domain.ErrGiftCardNotRedeemable):
+			return ctx.JSON(http.StatusConflict, MessageResponse{Message: err.Error()})
+		case errors.Is(err, domain.ErrRedemptionExceedsBalance), errors.Is(err, domain.ErrInsufficientFunds):
+			return ctx.JSON(http.StatusUnprocessableEntity, MessageResponse{Message: err.Error()})
+		case err != nil:
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to redeem gift card"})
+		}
+
+		return ctx.JSON(http.StatusOK, RedeemGiftCardResponse{
+			ID:              redemption.ID,
+			GiftCardID:      redemption.GiftCardID,
+			Amount:          redemption.Amount.Decimal(),
+			RemainingAmount: redemption.RemainingAmount.Decimal(),
+			Currency:        redemption.Amount.Currency,
+		})
+	}
+}
+
+type GetGiftCardCodeResponse struct {
+	Code string `json:"code"`
+}
+
+// GetGiftCardCodeHandler shows the redeemable code of a gift card to its
+// giftee. Anyone who knows the code can redeem the card, so it is not part of
+// any other response. The gifter is forbidden from seeing it and anyone else
+// gets a 404.
+func GetGiftCardCodeHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		giftCardID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid gift card ID"})
+		}
+
+		giftCard, err := giftCardService.FindGiftCard(ctx.Request().Context(), uint(giftCardID))
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift card"})
+		}
+
+		userID := ctx.Get("user_id").(uint)
+		if giftCard == nil || !giftCard.IsParticipant(userID) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Gift card not found"})
+		}
+
 		if giftCard.GifteeID != userID {
 			return ctx.JSON(http.StatusForbidden, MessageResponse{Message: fmt.Sprintf("forbidden: user %d is not the receiver of gift card %d", userID, giftCardID)})
 		}
 
-		status := domain.GiftCardStatus(request.Status)
-		if !status.IsValid() {
-			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid gift card status for update"})
+		return ctx.JSON(http.StatusOK, GetGiftCardCodeResponse{Code: domain.FormatGiftCardCode(giftCard.Code)})
+	}
+}
+
+type GiftCardStatusChangeResponse struct {
+	From      *int      `json:"from"`
+	To        int       `json:"to"`
+	ActorID   *uint     `json:"actor_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GetGiftCardHistoryResponse struct {
+	History []GiftCardStatusChangeResponse `json:"history"`
+}
+
+// GetGiftCardHistoryHandler shows the status history of a gift card to its
+// gifter and its giftee, anyone else gets a 404.
+func GetGiftCardHistoryHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		giftCardID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid gift card ID"})
 		}
 
-		err = giftCardService.UpdateStatus(uint(giftCardID), status)
+		giftCard, err := giftCardService.FindGiftCard(ctx.Request().Context(), uint(giftCardID))
 		if err != nil {
-			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to update gift card status"})
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift card"})
+		}
+
+		userID := ctx.Get("user_id").(uint)
+		if giftCard == nil || !giftCard.IsParticipant(userID) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Gift card not found"})
 		}
 
-		return ctx.NoContent(http.StatusOK)
+		history, err := giftCardService.GetStatusHistory(ctx.Request().Context(), uint(giftCardID))
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift card history"})
+		}
+
+		return ctx.JSON(http.StatusOK, GetGiftCardHistoryResponse{History: newGiftCardStatusChangeResponses(history)})
+	}
+}
+
+func newGiftCardStatusChangeResponses(history []domain.GiftCardStatusChange) []GiftCardStatusChangeResponse {
+	historyResponse := make([]GiftCardStatusChangeResponse, 0, len(history))
+	for _, h := range history {
+		change := GiftCardStatusChangeResponse{To: int(h.To), ActorID: h.ActorID, CreatedAt: h.CreatedAt}
+		if h.From != nil {
+			from := int(*h.From)
+			change.From = &from
+		}
+
+		historyResponse = append(historyResponse, change)
 	}
+
+	return historyResponse
 }
 
+// GetGiftCards is a page of a gift card listing. Page is only set for a page
+// asked for by its number and Total only when include_total is.
 type GetGiftCards struct {
-	GiftCards []GiftCardResponse `json:"gift_cards"`
-	Total     int                `json:"total"`
-	Page      int                `json:"page"`
+	GiftCards  []GiftCardResponse `json:"gift_cards"`
+	Total      *int               `json:"total,omitempty"`
+	Page       int                `json:"page,omitempty"`
+	PageSize   int                `json:"page_size"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+	PrevCursor string             `json:"prev_cursor,omitempty"`
+}
+
+func newGetGiftCards(query repository.GiftCardQuery, page repository.GiftCardPage) GetGiftCards {
+	giftCardsResponse := make([]GiftCardResponse, 0, len(page.GiftCards))
+	for _, g := range page.GiftCards {
+		giftCardsResponse = append(giftCardsResponse, newGiftCardResponse(g))
+	}
+
+	response := GetGiftCards{
+		GiftCards:  giftCardsResponse,
+		Total:      page.Total,
+		PageSize:   query.PageSize,
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+	if query.Cursor == "" {
+		response.Page = query.PageNumber
+	}
+
+	return response
 }
 
 func normalizeStatus(statusStr string) (*domain.GiftCardStatus, error) {
@@ -114,72 +490,168 @@ func normalizeStatus(statusStr string) (*domain.GiftCardStatus, error) {
 	return &status, nil
 }
 
-func GetReceivedGiftCardsHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
-	return func(ctx echo.Context) error {
-		userID := ctx.Get("user_id").(uint)
-		pageSize := 10
-		status, err := normalizeStatus(ctx.QueryParam("status"))
-		if err != nil {
-			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "invalid gift-card status"})
+// newGiftCardQuery reads the query of a gift card listing from the query
+// string:
+//
+//   - status, repeated or comma separated, matches any of the statuses
+//   - created_from and created_to, RFC 3339 times, bound the creation date
+//   - min_amount and max_amount bound the amount in currency, in any case and
+//     USD by default
+//   - counterparty_id matches the other user of the cards
+//   - sort is created_at or amount, order is asc or desc
+//   - page_size is up to repository.MaxGiftCardPageSize, page starts at 1 and
+//     is up to repository.MaxGiftCardPageNumber
+//   - cursor is the next_cursor or prev_cursor of a page, in place of page
+//   - include_total asks for the number of all the matching cards
+func newGiftCardQuery(ctx echo.Context) (repository.GiftCardQuery, error) {
+	query := repository.GiftCardQuery{
+		SortBy:   repository.GiftCardSort(ctx.QueryParam("sort")),
+		PageSize: repository.DefaultGiftCardPageSize,
+	}
+
+	for _, param := range ctx.QueryParams()["status"] {
+		for _, statusStr := range strings.Split(param, ",") {
+			status, err := normalizeStatus(strings.TrimSpace(statusStr))
+			if err != nil {
+				return query, errors.New("invalid gift-card status")
+			}
+
+			query.Statuses = append(query.Statuses, *status)
 		}
+	}
+
+	var err error
+	query.CreatedFrom, err = timeQueryParam(ctx, "created_from")
+	if err != nil {
+		return query, err
+	}
+
+	query.CreatedTo, err = timeQueryParam(ctx, "created_to")
+	if err != nil {
+		return query, err
+	}
+
+	currency := strings.ToUpper(ctx.QueryParam("currency"))
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
 
-		pageNumberStr := ctx.QueryParam("page")
-		pageNumberInt, _ := strconv.Atoi(pageNumberStr)
-		if pageNumberInt < 1 {
-			pageNumberInt = 1
+	query.MinAmount, err = moneyQueryParam(ctx, "min_amount", currency)
+	if err != nil {
+		return query, err
+	}
+
+	query.MaxAmount, err = moneyQueryParam(ctx, "max_amount", currency)
+	if err != nil {
+		return query, err
+	}
+
+	if value := ctx.QueryParam("counterparty_id"); value != "" {
+		counterpartyID, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return query, errors.New("invalid counterparty_id")
 		}
 
-		giftCards, totalCount, err := giftCardService.GetReceivedGiftCardsByUserID(userID, status, pageSize, pageNumberInt)
+		id := uint(counterpartyID)
+		query.CounterpartyID = &id
+	}
+
+	switch ctx.QueryParam("order") {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, errors.New("order must be asc or desc")
+	}
+
+	if value := ctx.QueryParam("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
 		if err != nil {
-			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift cards"})
+			return query, errors.New("invalid page_size")
 		}
 
-		giftCardsResponse := make([]GiftCardResponse, 0, len(giftCards))
-		for _, g := range giftCards {
-			giftCardsResponse = append(giftCardsResponse, GiftCardResponse{
-				ID:       g.ID,
-				GifteeID: g.GifteeID,
-				GifterID: g.GifterID,
-				Amount:   g.Amount,
-				Status:   int(g.Status),
-			})
+		query.PageSize = pageSize
+	}
+
+	query.PageNumber, _ = strconv.Atoi(ctx.QueryParam("page"))
+	if query.PageNumber < 1 {
+		query.PageNumber = 1
+	}
+
+	query.Cursor = ctx.QueryParam("cursor")
+
+	if value := ctx.QueryParam("include_total"); value != "" {
+		query.WithTotal, err = strconv.ParseBool(value)
+		if err != nil {
+			return query, errors.New("invalid include_total")
 		}
+	}
+
+	return query, query.Validate()
+}
+
+// timeQueryParam returns the RFC 3339 time of the query parameter, nil if it
+// is not set.
+func timeQueryParam(ctx echo.Context, name string) (*time.Time, error) {
+	value := ctx.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
 
-		return ctx.JSON(http.StatusOK, GetGiftCards{GiftCards: giftCardsResponse, Total: totalCount, Page: pageNumberInt})
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 time", name)
 	}
+
+	return &t, nil
 }
 
-func GetSentGiftCardsHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+// moneyQueryParam returns the decimal amount of the query parameter in
+// currency, nil if it is not set.
+func moneyQueryParam(ctx echo.Context, name, currency string) (*domain.Money, error) {
+	value := ctx.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	amount, err := domain.ParseMoney(value, currency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	return &amount, nil
+}
+
+func GetReceivedGiftCardsHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
 	return func(ctx echo.Context) error {
 		userID := ctx.Get("user_id").(uint)
-		pageSize := 10
-		status, err := normalizeStatus(ctx.QueryParam("status"))
+		query, err := newGiftCardQuery(ctx)
 		if err != nil {
-			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "invalid gift-card status"})
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
 		}
 
-		pageNumberStr := ctx.QueryParam("page")
-		pageNumberInt, _ := strconv.Atoi(pageNumberStr)
-		if pageNumberInt < 1 {
-			pageNumberInt = 1
+		page, err := giftCardService.GetReceivedGiftCardsByUserID(ctx.Request().Context(), userID, query)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift cards"})
 		}
 
-		giftCards, totalCount, err := giftCardService.GetSentGiftCardsByUserID(userID, status, pageSize, pageNumberInt)
+		return ctx.JSON(http.StatusOK, newGetGiftCards(query, page))
+	}
+}
+
+func GetSentGiftCardsHandler(giftCardService service.GiftCardService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		userID := ctx.Get("user_id").(uint)
+		query, err := newGiftCardQuery(ctx)
 		if err != nil {
-			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift cards"})
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
 		}
 
-		giftCardsResponse := make([]GiftCardResponse, 0, len(giftCards))
-		for _, g := range giftCards {
-			giftCardsResponse = append(giftCardsResponse, GiftCardResponse{
-				ID:       g.ID,
-				GifteeID: g.GifteeID,
-				GifterID: g.GifterID,
-				Amount:   g.Amount,
-				Status:   int(g.Status),
-			})
+		page, err := giftCardService.GetSentGiftCardsByUserID(ctx.Request().Context(), userID, query)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get gift cards"})
 		}
 
-		return ctx.JSON(http.StatusOK, GetGiftCards{GiftCards: giftCardsResponse, Total: totalCount, Page: pageNumberInt})
+		return ctx.JSON(http.StatusOK, newGetGiftCards(query, page))
 	}
 }
@@ -7,11 +7,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
 	"github.com/jmehdipour/gift-card/internal/service"
 )
 
@@ -26,12 +29,29 @@ func createGiftCardNewEchoContext(body string, userID uint) (echo.Context, *http
 	return ctx, response
 }
 
+func getGiftCardNewEchoContext(userID uint, giftCardID uint) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("/gift-cards/%d", giftCardID),
+		nil)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", userID)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(fmt.Sprintf("%d", giftCardID))
+
+	return ctx, response
+}
+
 func updateGiftCardNewEchoContext(body string, userID uint, giftCardID uint) (echo.Context, *httptest.ResponseRecorder) {
 	request := httptest.NewRequest(
 		http.MethodPut,
 		fmt.Sprintf("/gift-cards/%d/status", giftCardID),
 		bytes.NewReader([]byte(body)))
 	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(HeaderIfMatch, `"1"`)
 	response := httptest.NewRecorder()
 	e := echo.New()
 	ctx := e.NewContext(request, response)
@@ -42,6 +62,77 @@ func updateGiftCardNewEchoContext(body string, userID uint, giftCardID uint) (ec
 	return ctx, response
 }
 
+func cancelGiftCardNewEchoContext(userID uint, giftCardID uint) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf("/gift-cards/%d/cancel", giftCardID),
+		nil)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(HeaderIfMatch, `"1"`)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", userID)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(fmt.Sprintf("%d", giftCardID))
+
+	return ctx, response
+}
+
+func redeemGiftCardNewEchoContext(body string, userID uint) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodPost, "/gift-cards/redeem", bytes.NewReader([]byte(body)))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", userID)
+
+	return ctx, response
+}
+
+func getGiftCardCodeNewEchoContext(userID uint, giftCardID uint) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("/gift-cards/%d/code", giftCardID),
+		nil)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", userID)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(fmt.Sprintf("%d", giftCardID))
+
+	return ctx, response
+}
+
+func getGiftCardHistoryNewEchoContext(userID uint, giftCardID uint) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("/gift-cards/%d/history", giftCardID),
+		nil)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", userID)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(fmt.Sprintf("%d", giftCardID))
+
+	return ctx, response
+}
+
+func listGiftCardsNewEchoContext(target string, userID uint) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", userID)
+
+	return ctx, response
+}
+
 func getReceivedGiftCardsNewEchoContext(userID uint, status int) (echo.Context, *httptest.ResponseRecorder) {
 	request := httptest.NewRequest(
 		http.MethodGet,
@@ -56,239 +147,983 @@ func getReceivedGiftCardsNewEchoContext(userID uint, status int) (echo.Context,
 	return ctx, response
 }
 
-func getSentGiftCardsNewEchoContext(userID uint, status int) (echo.Context, *httptest.ResponseRecorder) {
-	request := httptest.NewRequest(
-		http.MethodGet,
-		fmt.Sprintf("/gift-cards/sent?status=%d", status),
-		nil)
-	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-	response := httptest.NewRecorder()
-	e := echo.New()
-	ctx := e.NewContext(request, response)
-	ctx.Set("user_id", userID)
+func getSentGiftCardsNewEchoContext(userID uint, status int) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("/gift-cards/sent?status=%d", status),
+		nil)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", userID)
+
+	return ctx, response
+}
+
+type CreateGiftCardsHandlerTestSuite struct {
+	suite.Suite
+	giftCardService *service.GiftCardServiceMock
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) SetupSuite() {
+	suite.giftCardService = new(service.GiftCardServiceMock)
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCard := domain.GiftCard{
+		ID:              15,
+		Amount:          domain.NewMoney(10000, "USD"),
+		RemainingAmount: domain.NewMoney(10000, "USD"),
+		Status:          domain.GCSPending,
+		GifterID:        userID,
+		GifteeID:        20,
+		Version:         1,
+	}
+	requestBody := fmt.Sprintf(`{"amount": 100, "giftee_id": %d}`, giftCard.GifteeID)
+	expectedResponse := `{"id":15,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":2,"gifter_id":10,"giftee_id":20,"version":1}`
+
+	defer suite.giftCardService.On("CreateGiftCard", mock.Anything, giftCard.Amount, userID, giftCard.GifteeID, (*time.Time)(nil)).Return(&giftCard, nil).Unset()
+
+	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
+	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.JSONEq(expectedResponse, response.Body.String())
+	require.Equal(http.StatusCreated, response.Code)
+	require.Equal(`"1"`, response.Header().Get(HeaderETag))
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_DecimalAmountWithCurrency_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCard := domain.GiftCard{
+		ID:              15,
+		Amount:          domain.NewMoney(1999, "EUR"),
+		RemainingAmount: domain.NewMoney(1999, "EUR"),
+		Status:          domain.GCSPending,
+		GifterID:        userID,
+		GifteeID:        20,
+		Version:         1,
+	}
+	requestBody := fmt.Sprintf(`{"amount": "19.99", "currency": "eur", "giftee_id": %d}`, giftCard.GifteeID)
+	expectedResponse := `{"id":15,"amount":"19.99","remaining_amount":"19.99","currency":"EUR","status":2,"gifter_id":10,"giftee_id":20,"version":1}`
+
+	defer suite.giftCardService.On("CreateGiftCard", mock.Anything, giftCard.Amount, userID, giftCard.GifteeID, (*time.Time)(nil)).Return(&giftCard, nil).Unset()
+
+	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
+	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.JSONEq(expectedResponse, response.Body.String())
+	require.Equal(http.StatusCreated, response.Code)
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_WithExpiry_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	expiresAt := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	giftCard := domain.GiftCard{
+		ID:              15,
+		Amount:          domain.NewMoney(10000, "USD"),
+		RemainingAmount: domain.NewMoney(10000, "USD"),
+		Status:          domain.GCSPending,
+		GifterID:        userID,
+		GifteeID:        20,
+		Version:         1,
+		ExpiresAt:       &expiresAt,
+	}
+	requestBody := `{"amount": 100, "giftee_id": 20, "expires_at": "2099-01-01T00:00:00Z"}`
+	expectedResponse := `{"id":15,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":2,"gifter_id":10,"giftee_id":20,"expires_at":"2099-01-01T00:00:00Z","version":1}`
+
+	defer suite.giftCardService.On("CreateGiftCard", mock.Anything, giftCard.Amount, userID, giftCard.GifteeID, &expiresAt).Return(&giftCard, nil).Unset()
+
+	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
+	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.JSONEq(expectedResponse, response.Body.String())
+	require.Equal(http.StatusCreated, response.Code)
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_PastExpiry_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	requestBody := `{"amount": 100, "giftee_id": 20, "expires_at": "2000-01-01T00:00:00Z"}`
+	expectedResponse := `{"message":"expiry date must be in the future"}`
+
+	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
+	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.JSONEq(expectedResponse, response.Body.String())
+	require.Equal(http.StatusBadRequest, response.Code)
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_InvalidRequestBody_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	requestBody := `{"amount":, "giftee_id": 20}`
+	expectedResponse := `{"message":"code=400, message=Syntax error: offset=11, error=invalid character ',' looking for beginning of value, internal=invalid character ',' looking for beginning of value"}`
+
+	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
+	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.JSONEq(expectedResponse, response.Body.String())
+	require.Equal(http.StatusBadRequest, response.Code)
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_ServiceError_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	amount := domain.NewMoney(10000, "USD")
+	requestBody := `{"amount": 100, "giftee_id": 20}`
+	expectedResponse := `{"message":"service layer error"}`
+	expectedError := errors.New("service layer error")
+
+	defer suite.giftCardService.On("CreateGiftCard", mock.Anything, amount, userID, uint(20), (*time.Time)(nil)).Return(nil, expectedError).Unset()
+
+	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
+	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.JSONEq(expectedResponse, response.Body.String())
+	require.Equal(http.StatusInternalServerError, response.Code)
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_InvalidAmount_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	expectedResponse := `{"message":"invalid amount"}`
+
+	for _, requestBody := range []string{
+		`{"amount": -10, "giftee_id": 20}`,
+		`{"amount": 0, "giftee_id": 20}`,
+		`{"amount": "10.001", "giftee_id": 20}`,
+		`{"amount": 1e3, "giftee_id": 20}`,
+	} {
+		ctx, response := createGiftCardNewEchoContext(requestBody, userID)
+		err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+
+		require.NoError(err)
+		require.JSONEq(expectedResponse, response.Body.String())
+		require.Equal(http.StatusBadRequest, response.Code)
+	}
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_InvalidCurrency_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	requestBody := `{"amount": 10, "currency": "XYZ", "giftee_id": 20}`
+	expectedResponse := `{"message":"invalid currency"}`
+
+	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
+	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.JSONEq(expectedResponse, response.Body.String())
+	require.Equal(http.StatusBadRequest, response.Code)
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_InsufficientFunds_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	amount := domain.NewMoney(10000, "USD")
+	requestBody := `{"amount": 100, "giftee_id": 20}`
+	expectedResponse := `{"message":"insufficient funds"}`
+
+	defer suite.giftCardService.On("CreateGiftCard", mock.Anything, amount, userID, uint(20), (*time.Time)(nil)).
+		Return(nil, domain.ErrInsufficientFunds).Unset()
+
+	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
+	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.JSONEq(expectedResponse, response.Body.String())
+	require.Equal(http.StatusUnprocessableEntity, response.Code)
+}
+
+func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_InvalidGiftee_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	amount := domain.NewMoney(10000, "USD")
+
+	for _, tc := range []struct {
+		gifteeID         uint
+		err              error
+		expectedResponse string
+	}{
+		{userID, domain.ErrGiftCardToSelf, `{"message":"gift card cannot be sent to its sender"}`},
+		{20, domain.ErrGifteeNotFound, `{"message":"giftee not found"}`},
+	} {
+		call := suite.giftCardService.On("CreateGiftCard", mock.Anything, amount, userID, tc.gifteeID, (*time.Time)(nil)).Return(nil, tc.err)
+
+		ctx, response := createGiftCardNewEchoContext(fmt.Sprintf(`{"amount": 100, "giftee_id": %d}`, tc.gifteeID), userID)
+		err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+		call.Unset()
+
+		require.NoError(err)
+		require.JSONEq(tc.expectedResponse, response.Body.String())
+		require.Equal(http.StatusUnprocessableEntity, response.Code)
+	}
+}
+
+type GetGiftCardHandlerTestSuite struct {
+	suite.Suite
+	giftCardService *service.GiftCardServiceMock
+}
+
+func (suite *GetGiftCardHandlerTestSuite) SetupSuite() {
+	suite.giftCardService = new(service.GiftCardServiceMock)
+}
+
+func (suite *GetGiftCardHandlerTestSuite) TestGetGiftCardHandler_Success() {
+	require := suite.Require()
+	giftCardID := uint(101)
+	gifterID := uint(10)
+	pending := domain.GCSPending
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	giftCard := domain.GiftCard{
+		ID:              giftCardID,
+		Amount:          domain.NewMoney(10000, "USD"),
+		RemainingAmount: domain.NewMoney(10000, "USD"),
+		Status:          domain.GCSAccepted,
+		GifterID:        gifterID,
+		GifteeID:        20,
+		CreationDate:    createdAt,
+		UpdatedAt:       updatedAt,
+		Version:         2,
+	}
+	history := []domain.GiftCardStatusChange{
+		{ID: 1, GiftCardID: giftCardID, To: domain.GCSPending, ActorID: &gifterID, CreatedAt: createdAt},
+		{ID: 2, GiftCardID: giftCardID, From: &pending, To: domain.GCSAccepted, ActorID: &giftCard.GifteeID, CreatedAt: updatedAt},
+	}
+	expectedResponse := `{"id":101,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":0,"status_name":"accepted",` +
+		`"gifter_id":10,"giftee_id":20,"version":2,"created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-02T00:00:00Z",` +
+		`"history":[{"from":null,"to":2,"actor_id":10,"created_at":"2024-01-01T00:00:00Z"},{"from":2,"to":0,"actor_id":20,"created_at":"2024-01-02T00:00:00Z"}]}`
+
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(&giftCard, nil).Unset()
+	defer suite.giftCardService.On("GetStatusHistory", mock.Anything, giftCardID).Return(history, nil).Unset()
+
+	for _, userID := range []uint{10, 20} {
+		ctx, response := getGiftCardNewEchoContext(userID, giftCardID)
+		err := GetGiftCardHandler(suite.giftCardService)(ctx)
+
+		require.NoError(err)
+		require.Equal(http.StatusOK, response.Code)
+		require.JSONEq(expectedResponse, response.Body.String())
+		require.Equal(`"2"`, response.Header().Get(HeaderETag))
+	}
+}
+
+func (suite *GetGiftCardHandlerTestSuite) TestGetGiftCardHandler_InvalidGiftCard_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "Invalid gift card ID"}`
+
+	ctx, response := getGiftCardNewEchoContext(10, 101)
+	ctx.SetParamValues("foo")
+	err := GetGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *GetGiftCardHandlerTestSuite) TestGetGiftCardHandler_GiftCardNotFound_Failure() {
+	require := suite.Require()
+	giftCardID := uint(101)
+	expectedResponse := `{"message": "Gift card not found"}`
+
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(nil, nil).Unset()
+
+	ctx, response := getGiftCardNewEchoContext(10, giftCardID)
+	err := GetGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *GetGiftCardHandlerTestSuite) TestGetGiftCardHandler_NotParticipant_Failure() {
+	require := suite.Require()
+	giftCardID := uint(101)
+	giftCard := domain.GiftCard{ID: giftCardID, Amount: domain.NewMoney(10000, "USD"), GifterID: 10, GifteeID: 20}
+	expectedResponse := `{"message": "Gift card not found"}`
+
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(&giftCard, nil).Unset()
+
+	ctx, response := getGiftCardNewEchoContext(30, giftCardID)
+	err := GetGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *GetGiftCardHandlerTestSuite) TestGetGiftCardHandler_FindError_Failure() {
+	require := suite.Require()
+	giftCardID := uint(101)
+	expectedResponse := `{"message": "Failed to get gift card"}`
+
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(nil, errors.New("find error")).Unset()
+
+	ctx, response := getGiftCardNewEchoContext(10, giftCardID)
+	err := GetGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *GetGiftCardHandlerTestSuite) TestGetGiftCardHandler_HistoryError_Failure() {
+	require := suite.Require()
+	giftCardID := uint(101)
+	giftCard := domain.GiftCard{ID: giftCardID, Amount: domain.NewMoney(10000, "USD"), GifterID: 10, GifteeID: 20}
+	expectedResponse := `{"message": "Failed to get gift card history"}`
+
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(&giftCard, nil).Unset()
+	defer suite.giftCardService.On("GetStatusHistory", mock.Anything, giftCardID).Return(nil, errors.New("history error")).Unset()
+
+	ctx, response := getGiftCardNewEchoContext(10, giftCardID)
+	err := GetGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+type UpdateGiftCardStatusHandlerTestSuite struct {
+	suite.Suite
+	giftCardService *service.GiftCardServiceMock
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) SetupSuite() {
+	suite.giftCardService = new(service.GiftCardServiceMock)
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	version := uint(1)
+	status := domain.GCSRejected
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
+	giftCard := domain.GiftCard{ID: giftCardID, Amount: domain.NewMoney(10000, "USD"), RemainingAmount: domain.NewMoney(10000, "USD"), Status: status, GifterID: 20, GifteeID: userID, Version: 2}
+	expectedResponse := `{"id":101,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":1,"gifter_id":20,"giftee_id":10,"version":2}`
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, status, userID).Return(&giftCard, nil).Unset()
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+	require.Equal(`"2"`, response.Header().Get(HeaderETag))
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_AnyVersion_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	status := domain.GCSAccepted
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
+	giftCard := domain.GiftCard{ID: giftCardID, Amount: domain.NewMoney(10000, "USD"), Status: status, GifterID: 20, GifteeID: userID, Version: 4}
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, (*uint)(nil), status, userID).Return(&giftCard, nil).Unset()
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	ctx.Request().Header.Set(HeaderIfMatch, "*")
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.Equal(`"4"`, response.Header().Get(HeaderETag))
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_IfMatchMissing_Failure() {
+	require := suite.Require()
+	requestBody := fmt.Sprintf(`{"status": %d}`, domain.GCSAccepted)
+	expectedResponse := `{"message": "the If-Match header is required"}`
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, 10, 101)
+	ctx.Request().Header.Del(HeaderIfMatch)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusPreconditionRequired, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_IfMatchMalformed_Failure() {
+	require := suite.Require()
+	requestBody := fmt.Sprintf(`{"status": %d}`, domain.GCSAccepted)
+	expectedResponse := `{"message": "gift card has been changed since it was read"}`
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, 10, 101)
+	ctx.Request().Header.Set(HeaderIfMatch, `W/"1"`)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusPreconditionFailed, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_VersionMismatch_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	version := uint(1)
+	status := domain.GCSAccepted
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
+	expectedResponse := `{"message": "gift card has been changed since it was read"}`
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, status, userID).Return(nil, domain.ErrGiftCardVersionMismatch).Unset()
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusPreconditionFailed, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_InvalidRequestBody_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	requestBody := `{"status": "foo"}`
+	expectedResponse := `{"message":"Invalid request body"}`
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_GiftCardNotFound_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	version := uint(1)
+	status := domain.GCSRejected
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
+	expectedResponse := `{"message": "Gift card not found"}`
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, status, userID).Return(nil, domain.ErrGiftCardNotFound).Unset()
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_InvalidGiftCard_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	status := domain.GCSRejected
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
+	expectedResponse := `{"message": "Invalid gift card ID"}`
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	ctx.SetParamValues("foo")
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_UnauthorizedUser_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	version := uint(1)
+	status := domain.GCSRejected
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
+	expectedResponse := fmt.Sprintf(`{"message": "forbidden: user %d is not the receiver of gift card %d"}`, userID, giftCardID)
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, status, userID).Return(nil, domain.ErrNotGiftCardGiftee).Unset()
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusForbidden, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_UpdateStatusError_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	version := uint(1)
+	status := domain.GCSRejected
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, status, userID).Return(nil, errors.New("update error")).Unset()
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_InvalidTransition_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	version := uint(1)
+	status := domain.GCSRejected
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
+	expectedResponse := `{"message": "gift card cannot move from accepted to rejected"}`
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, status, userID).Return(nil, &domain.InvalidTransitionError{From: domain.GCSAccepted, To: domain.GCSRejected}).Unset()
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusConflict, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_Expired_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	version := uint(1)
+	status := domain.GCSAccepted
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
+	expectedResponse := `{"message": "gift card has expired"}`
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, status, userID).Return(nil, domain.ErrGiftCardExpired).Unset()
+
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusConflict, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_StatusNotAllowed_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	requestBody := fmt.Sprintf(`{"status": %d}`, domain.GCSCancelled)
+	expectedResponse := `{"message": "Invalid gift card status for update"}`
 
-	return ctx, response
+	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
+	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
 }
 
-type CreateGiftCardsHandlerTestSuite struct {
+type CancelGiftCardHandlerTestSuite struct {
 	suite.Suite
 	giftCardService *service.GiftCardServiceMock
 }
 
-func (suite *CreateGiftCardsHandlerTestSuite) SetupSuite() {
+func (suite *CancelGiftCardHandlerTestSuite) SetupSuite() {
 	suite.giftCardService = new(service.GiftCardServiceMock)
 }
 
-func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_Success() {
+func (suite *CancelGiftCardHandlerTestSuite) TestCancelGiftCardHandler_Success() {
 	require := suite.Require()
 	userID := uint(10)
-	giftCard := domain.GiftCard{
-		ID:       15,
-		Amount:   100,
-		GifterID: userID,
-		GifteeID: 20,
-	}
-	requestBody := fmt.Sprintf(`{"amount": %v, "giftee_id": %d}`, giftCard.Amount, giftCard.GifteeID)
-	expectedResponse := `{"id":15,"amount":100,"status":2,"gifter_id":10,"giftee_id":20}`
+	giftCardID := uint(101)
+	version := uint(1)
+	giftCard := domain.GiftCard{ID: giftCardID, Amount: domain.NewMoney(10000, "USD"), RemainingAmount: domain.NewMoney(10000, "USD"), Status: domain.GCSCancelled, GifterID: userID, GifteeID: 20, Version: 2}
+	expectedResponse := `{"id":101,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":3,"gifter_id":10,"giftee_id":20,"version":2}`
 
-	defer suite.giftCardService.On("CreateGiftCard", giftCard.Amount, userID, giftCard.GifteeID).Return(&giftCard, nil).Unset()
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, domain.GCSCancelled, userID).Return(&giftCard, nil).Unset()
 
-	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
-	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+	ctx, response := cancelGiftCardNewEchoContext(userID, giftCardID)
+	err := CancelGiftCardHandler(suite.giftCardService)(ctx)
 
 	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
 	require.JSONEq(expectedResponse, response.Body.String())
-	require.Equal(http.StatusCreated, response.Code)
+	require.Equal(`"2"`, response.Header().Get(HeaderETag))
 }
 
-func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_InvalidRequestBody_Failure() {
+func (suite *CancelGiftCardHandlerTestSuite) TestCancelGiftCardHandler_IfMatchMissing_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "the If-Match header is required"}`
+
+	ctx, response := cancelGiftCardNewEchoContext(10, 101)
+	ctx.Request().Header.Del(HeaderIfMatch)
+	err := CancelGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusPreconditionRequired, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *CancelGiftCardHandlerTestSuite) TestCancelGiftCardHandler_VersionMismatch_Failure() {
 	require := suite.Require()
 	userID := uint(10)
-	giftCard := domain.GiftCard{
-		ID:       15,
-		Amount:   100,
-		GifterID: userID,
-		GifteeID: 20,
-	}
-	requestBody := fmt.Sprintf(`{"amount":, "giftee_id": %d}`, giftCard.GifteeID)
-	expectedResponse := `{"message":"code=400, message=Syntax error: offset=11, error=invalid character ',' looking for beginning of value, internal=invalid character ',' looking for beginning of value"}`
+	giftCardID := uint(101)
+	version := uint(1)
+	expectedResponse := `{"message": "gift card has been changed since it was read"}`
 
-	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
-	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, domain.GCSCancelled, userID).Return(nil, domain.ErrGiftCardVersionMismatch).Unset()
+
+	ctx, response := cancelGiftCardNewEchoContext(userID, giftCardID)
+	err := CancelGiftCardHandler(suite.giftCardService)(ctx)
 
 	require.NoError(err)
+	require.Equal(http.StatusPreconditionFailed, response.Code)
 	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *CancelGiftCardHandlerTestSuite) TestCancelGiftCardHandler_InvalidGiftCard_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "Invalid gift card ID"}`
+
+	ctx, response := cancelGiftCardNewEchoContext(10, 101)
+	ctx.SetParamValues("foo")
+	err := CancelGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
 	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
 }
 
-func (suite *CreateGiftCardsHandlerTestSuite) TestCreateGiftCardHandler_ServiceError_Failure() {
+func (suite *CancelGiftCardHandlerTestSuite) TestCancelGiftCardHandler_GiftCardNotFound_Failure() {
 	require := suite.Require()
 	userID := uint(10)
-	giftCard := domain.GiftCard{
-		Amount:   100,
-		GifterID: userID,
-		GifteeID: 20,
-	}
-	requestBody := fmt.Sprintf(`{"amount": %v, "giftee_id": %d}`, giftCard.Amount, giftCard.GifteeID)
-	expectedResponse := `{"message":"service layer error"}`
-	expectedError := errors.New("service layer error")
+	giftCardID := uint(101)
+	version := uint(1)
+	expectedResponse := `{"message": "Gift card not found"}`
 
-	defer suite.giftCardService.On("CreateGiftCard", giftCard.Amount, userID, giftCard.GifteeID).Return(nil, expectedError).Unset()
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, domain.GCSCancelled, userID).Return(nil, domain.ErrGiftCardNotFound).Unset()
 
-	ctx, response := createGiftCardNewEchoContext(requestBody, userID)
-	err := CreateGiftCardHandler(suite.giftCardService)(ctx)
+	ctx, response := cancelGiftCardNewEchoContext(userID, giftCardID)
+	err := CancelGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *CancelGiftCardHandlerTestSuite) TestCancelGiftCardHandler_UnauthorizedUser_Failure() {
+	require := suite.Require()
+	userID := uint(20)
+	giftCardID := uint(101)
+	version := uint(1)
+	expectedResponse := fmt.Sprintf(`{"message": "forbidden: user %d is not the sender of gift card %d"}`, userID, giftCardID)
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, domain.GCSCancelled, userID).Return(nil, domain.ErrNotGiftCardGifter).Unset()
+
+	ctx, response := cancelGiftCardNewEchoContext(userID, giftCardID)
+	err := CancelGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusForbidden, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *CancelGiftCardHandlerTestSuite) TestCancelGiftCardHandler_NotPending_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	version := uint(1)
+	expectedResponse := `{"message": "gift card cannot move from accepted to cancelled"}`
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, domain.GCSCancelled, userID).
+		Return(nil, &domain.InvalidTransitionError{From: domain.GCSAccepted, To: domain.GCSCancelled}).Unset()
+
+	ctx, response := cancelGiftCardNewEchoContext(userID, giftCardID)
+	err := CancelGiftCardHandler(suite.giftCardService)(ctx)
 
 	require.NoError(err)
+	require.Equal(http.StatusConflict, response.Code)
 	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *CancelGiftCardHandlerTestSuite) TestCancelGiftCardHandler_UpdateStatusError_Failure() {
+	require := suite.Require()
+	userID := uint(10)
+	giftCardID := uint(101)
+	version := uint(1)
+	expectedResponse := `{"message": "Failed to cancel gift card"}`
+
+	defer suite.giftCardService.On("UpdateStatus", mock.Anything, giftCardID, &version, domain.GCSCancelled, userID).Return(nil, errors.New("update error")).Unset()
+
+	ctx, response := cancelGiftCardNewEchoContext(userID, giftCardID)
+	err := CancelGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
 	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
 }
 
-type UpdateGiftCardStatusHandlerTestSuite struct {
+type RedeemGiftCardHandlerTestSuite struct {
 	suite.Suite
 	giftCardService *service.GiftCardServiceMock
 }
 
-func (suite *UpdateGiftCardStatusHandlerTestSuite) SetupSuite() {
+func (suite *RedeemGiftCardHandlerTestSuite) SetupSuite() {
 	suite.giftCardService = new(service.GiftCardServiceMock)
 }
 
-func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_Success() {
+func (suite *RedeemGiftCardHandlerTestSuite) TestRedeemGiftCardHandler_Success() {
 	require := suite.Require()
-	userID := uint(10)
-	giftCardID := uint(101)
-	status := domain.GCSRejected
-	requestBody := fmt.Sprintf(`{"status": %v}`, status)
-	giftCard := domain.GiftCard{
-		Amount:   100,
-		GifterID: 20,
-		GifteeID: userID,
+	userID := uint(30)
+	code := "0123-4567-89AB-CDEZ"
+	amount := domain.NewMoney(2550, "USD")
+	requestBody := fmt.Sprintf(`{"code": %q, "amount": "25.50"}`, code)
+	expectedResponse := `{"id":5,"gift_card_id":101,"amount":"25.50","remaining_amount":"74.50","currency":"USD"}`
+	redemption := &domain.GiftCardRedemption{
+		ID:              5,
+		GiftCardID:      101,
+		RedeemerID:      userID,
+		Amount:          amount,
+		RemainingAmount: domain.NewMoney(7450, "USD"),
 	}
 
-	defer suite.giftCardService.On("FindGiftCard", giftCardID).Return(&giftCard, nil).Unset()
-	defer suite.giftCardService.On("UpdateStatus", giftCardID, status).Return(nil).Unset()
+	defer suite.giftCardService.On("RedeemGiftCard", mock.Anything, code, userID, amount).Return(redemption, nil).Unset()
 
-	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
-	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+	ctx, response := redeemGiftCardNewEchoContext(requestBody, userID)
+	err := RedeemGiftCardHandler(suite.giftCardService)(ctx)
 
 	require.NoError(err)
 	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
 }
 
-func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_InvalidRequestBody_Failure() {
+func (suite *RedeemGiftCardHandlerTestSuite) TestRedeemGiftCardHandler_InvalidCode_Failure() {
 	require := suite.Require()
-	userID := uint(10)
-	giftCardID := uint(101)
-	requestBody := `{"status": "foo"}`
-	expectedResponse := `{"message":"Invalid request body"}`
+	requestBody := `{"code": "0123-4567-89AB-CDE0", "amount": "25.50"}`
+	expectedResponse := `{"message": "invalid gift card code"}`
 
-	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
-	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+	ctx, response := redeemGiftCardNewEchoContext(requestBody, 30)
+	err := RedeemGiftCardHandler(suite.giftCardService)(ctx)
 
 	require.NoError(err)
 	require.Equal(http.StatusBadRequest, response.Code)
 	require.JSONEq(expectedResponse, response.Body.String())
 }
 
-func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_FindGiftCardError_Failure() {
+func (suite *RedeemGiftCardHandlerTestSuite) TestRedeemGiftCardHandler_InvalidAmount_Failure() {
 	require := suite.Require()
-	userID := uint(10)
+	requestBody := `{"code": "0123-4567-89AB-CDEZ", "amount": 0}`
+	expectedResponse := `{"message": "invalid amount"}`
+
+	ctx, response := redeemGiftCardNewEchoContext(requestBody, 30)
+	err := RedeemGiftCardHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *RedeemGiftCardHandlerTestSuite) TestRedeemGiftCardHandler_ServiceErrors_Failure() {
+	require := suite.Require()
+	userID := uint(30)
+	code := "0123-4567-89AB-CDEZ"
+	amount := domain.NewMoney(2550, "USD")
+	requestBody := fmt.Sprintf(`{"code": %q, "amount": "25.50"}`, code)
+
+	for _, tc := range []struct {
+		err              error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{domain.ErrGiftCardNotFound, http.StatusNotFound, `{"message": "Gift card not found"}`},
+		{domain.ErrCurrencyMismatch, http.StatusBadRequest, `{"message": "currency mismatch"}`},
+		{domain.ErrGiftCardNotRedeemable, http.StatusConflict, `{"message": "gift card is not accepted"}`},
+		{domain.ErrRedemptionExceedsBalance, http.StatusUnprocessableEntity, `{"message": "redemption exceeds the remaining amount of the gift card"}`},
+		{domain.ErrInsufficientFunds, http.StatusUnprocessableEntity, `{"message": "insufficient funds"}`},
+		{errors.New("service error"), http.StatusInternalServerError, `{"message": "Failed to redeem gift card"}`},
+	} {
+		call := suite.giftCardService.On("RedeemGiftCard", mock.Anything, code, userID, amount).Return(nil, tc.err)
+
+		ctx, response := redeemGiftCardNewEchoContext(requestBody, userID)
+		err := RedeemGiftCardHandler(suite.giftCardService)(ctx)
+		call.Unset()
+
+		require.NoError(err)
+		require.Equal(tc.expectedCode, response.Code)
+		require.JSONEq(tc.expectedResponse, response.Body.String())
+	}
+}
+
+type GetGiftCardCodeHandlerTestSuite struct {
+	suite.Suite
+	giftCardService *service.GiftCardServiceMock
+}
+
+func (suite *GetGiftCardCodeHandlerTestSuite) SetupSuite() {
+	suite.giftCardService = new(service.GiftCardServiceMock)
+}
+
+func (suite *GetGiftCardCodeHandlerTestSuite) TestGetGiftCardCodeHandler_Success() {
+	require := suite.Require()
+	userID := uint(20)
 	giftCardID := uint(101)
-	status := domain.GCSRejected
-	requestBody := fmt.Sprintf(`{"status": %v}`, status)
-	expectedResponse := `{"message": "Failed to get gift card"}`
+	giftCard := domain.GiftCard{ID: giftCardID, Code: "0123456789ABCDEZ", Amount: domain.NewMoney(10000, "USD"), GifterID: 10, GifteeID: userID}
+	expectedResponse := `{"code": "0123-4567-89AB-CDEZ"}`
 
-	defer suite.giftCardService.On("FindGiftCard", giftCardID).Return(nil, errors.New("service error")).Unset()
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(&giftCard, nil).Unset()
 
-	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
-	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+	ctx, response := getGiftCardCodeNewEchoContext(userID, giftCardID)
+	err := GetGiftCardCodeHandler(suite.giftCardService)(ctx)
 
 	require.NoError(err)
-	require.Equal(http.StatusInternalServerError, response.Code)
+	require.Equal(http.StatusOK, response.Code)
 	require.JSONEq(expectedResponse, response.Body.String())
 }
 
-func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_GiftCardNotFound_Failure() {
+func (suite *GetGiftCardCodeHandlerTestSuite) TestGetGiftCardCodeHandler_Gifter_Failure() {
 	require := suite.Require()
 	userID := uint(10)
 	giftCardID := uint(101)
-	status := domain.GCSRejected
-	requestBody := fmt.Sprintf(`{"status": %v}`, status)
+	giftCard := domain.GiftCard{ID: giftCardID, Code: "0123456789ABCDEZ", Amount: domain.NewMoney(10000, "USD"), GifterID: userID, GifteeID: 20}
+	expectedResponse := fmt.Sprintf(`{"message": "forbidden: user %d is not the receiver of gift card %d"}`, userID, giftCardID)
+
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(&giftCard, nil).Unset()
+
+	ctx, response := getGiftCardCodeNewEchoContext(userID, giftCardID)
+	err := GetGiftCardCodeHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusForbidden, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *GetGiftCardCodeHandlerTestSuite) TestGetGiftCardCodeHandler_NotParticipant_Failure() {
+	require := suite.Require()
+	userID := uint(30)
+	giftCardID := uint(101)
+	giftCard := domain.GiftCard{ID: giftCardID, Code: "0123456789ABCDEZ", Amount: domain.NewMoney(10000, "USD"), GifterID: 10, GifteeID: 20}
 	expectedResponse := `{"message": "Gift card not found"}`
 
-	defer suite.giftCardService.On("FindGiftCard", giftCardID).Return(nil, nil).Unset()
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(&giftCard, nil).Unset()
 
-	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
-	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+	ctx, response := getGiftCardCodeNewEchoContext(userID, giftCardID)
+	err := GetGiftCardCodeHandler(suite.giftCardService)(ctx)
 
 	require.NoError(err)
 	require.Equal(http.StatusNotFound, response.Code)
 	require.JSONEq(expectedResponse, response.Body.String())
 }
 
-func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_InvalidGiftCard_Failure() {
+func (suite *GetGiftCardCodeHandlerTestSuite) TestGetGiftCardCodeHandler_GiftCardNotFound_Failure() {
 	require := suite.Require()
-	userID := uint(10)
 	giftCardID := uint(101)
-	status := domain.GCSRejected
-	requestBody := fmt.Sprintf(`{"status": %v}`, status)
-	expectedResponse := `{"message": "Invalid gift card ID"}`
+	expectedResponse := `{"message": "Gift card not found"}`
 
-	defer suite.giftCardService.On("FindGiftCard", giftCardID).Return(nil, nil).Unset()
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(nil, nil).Unset()
 
-	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
-	ctx.SetParamValues("foo")
-	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+	ctx, response := getGiftCardCodeNewEchoContext(20, giftCardID)
+	err := GetGiftCardCodeHandler(suite.giftCardService)(ctx)
 
 	require.NoError(err)
-	require.Equal(http.StatusBadRequest, response.Code)
+	require.Equal(http.StatusNotFound, response.Code)
 	require.JSONEq(expectedResponse, response.Body.String())
 }
 
-func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_UnauthorizedUser_Failure() {
+type GetGiftCardHistoryHandlerTestSuite struct {
+	suite.Suite
+	giftCardService *service.GiftCardServiceMock
+}
+
+func (suite *GetGiftCardHistoryHandlerTestSuite) SetupSuite() {
+	suite.giftCardService = new(service.GiftCardServiceMock)
+}
+
+func (suite *GetGiftCardHistoryHandlerTestSuite) TestGetGiftCardHistoryHandler_Success() {
 	require := suite.Require()
-	userID := uint(10)
+	userID := uint(20)
 	giftCardID := uint(101)
-	status := domain.GCSRejected
-	requestBody := fmt.Sprintf(`{"status": %v}`, status)
-	expectedResponse := fmt.Sprintf(`{"message": "forbidden: user %d is not the receiver of gift card %d"}`, userID, giftCardID)
-	giftCard := domain.GiftCard{
-		ID:       giftCardID,
-		Amount:   100,
-		GifterID: 20,
-		GifteeID: 30,
+	pending := domain.GCSPending
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	giftCard := domain.GiftCard{ID: giftCardID, Amount: domain.NewMoney(10000, "USD"), Status: domain.GCSAccepted, GifterID: 10, GifteeID: userID}
+	history := []domain.GiftCardStatusChange{
+		{ID: 1, GiftCardID: giftCardID, To: domain.GCSPending, ActorID: &giftCard.GifterID, CreatedAt: createdAt},
+		{ID: 2, GiftCardID: giftCardID, From: &pending, To: domain.GCSAccepted, ActorID: &userID, CreatedAt: createdAt},
 	}
+	expectedResponse := `{"history":[
+		{"from":null,"to":2,"actor_id":10,"created_at":"2024-01-01T00:00:00Z"},
+		{"from":2,"to":0,"actor_id":20,"created_at":"2024-01-01T00:00:00Z"}
+	]}`
 
-	defer suite.giftCardService.On("FindGiftCard", giftCardID).Return(&giftCard, nil).Unset()
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(&giftCard, nil).Unset()
+	defer suite.giftCardService.On("GetStatusHistory", mock.Anything, giftCardID).Return(history, nil).Unset()
 
-	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
-	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+	ctx, response := getGiftCardHistoryNewEchoContext(userID, giftCardID)
+	err := GetGiftCardHistoryHandler(suite.giftCardService)(ctx)
 
 	require.NoError(err)
-	require.Equal(http.StatusForbidden, response.Code)
+	require.Equal(http.StatusOK, response.Code)
 	require.JSONEq(expectedResponse, response.Body.String())
 }
 
-func (suite *UpdateGiftCardStatusHandlerTestSuite) TestUpdateGiftCardHandler_UpdateStatusError_Failure() {
+func (suite *GetGiftCardHistoryHandlerTestSuite) TestGetGiftCardHistoryHandler_GiftCardNotFound_Failure() {
+	require := suite.Require()
+	userID := uint(20)
+	giftCardID := uint(101)
+	expectedResponse := `{"message": "Gift card not found"}`
+
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(nil, nil).Unset()
+
+	ctx, response := getGiftCardHistoryNewEchoContext(userID, giftCardID)
+	err := GetGiftCardHistoryHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *GetGiftCardHistoryHandlerTestSuite) TestGetGiftCardHistoryHandler_NotParticipant_Failure() {
+	require := suite.Require()
+	userID := uint(30)
+	giftCardID := uint(101)
+	expectedResponse := `{"message": "Gift card not found"}`
+	giftCard := domain.GiftCard{ID: giftCardID, Amount: domain.NewMoney(10000, "USD"), GifterID: 10, GifteeID: 20}
+
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(&giftCard, nil).Unset()
+
+	ctx, response := getGiftCardHistoryNewEchoContext(userID, giftCardID)
+	err := GetGiftCardHistoryHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+	suite.giftCardService.AssertNotCalled(suite.T(), "GetStatusHistory", mock.Anything, mock.Anything)
+}
+
+func (suite *GetGiftCardHistoryHandlerTestSuite) TestGetGiftCardHistoryHandler_ServiceError_Failure() {
 	require := suite.Require()
 	userID := uint(10)
 	giftCardID := uint(101)
-	status := domain.GCSRejected
-	requestBody := fmt.Sprintf(`{"status": %v}`, status)
-	giftCard := domain.GiftCard{
-		ID:       giftCardID,
-		Amount:   100,
-		GifterID: 20,
-		GifteeID: userID,
-	}
+	expectedResponse := `{"message": "Failed to get gift card history"}`
+	giftCard := domain.GiftCard{ID: giftCardID, Amount: domain.NewMoney(10000, "USD"), GifterID: userID, GifteeID: 20}
 
-	defer suite.giftCardService.On("FindGiftCard", giftCardID).Return(&giftCard, nil).Unset()
-	defer suite.giftCardService.On("UpdateStatus", giftCardID, status).Return(errors.New("update error")).Unset()
+	defer suite.giftCardService.On("FindGiftCard", mock.Anything, giftCardID).Return(&giftCard, nil).Unset()
+	defer suite.giftCardService.On("GetStatusHistory", mock.Anything, giftCardID).Return(nil, errors.New("service error")).Unset()
 
-	ctx, response := updateGiftCardNewEchoContext(requestBody, userID, giftCardID)
-	err := UpdateGiftCardStatusHandler(suite.giftCardService)(ctx)
+	ctx, response := getGiftCardHistoryNewEchoContext(userID, giftCardID)
+	err := GetGiftCardHistoryHandler(suite.giftCardService)(ctx)
 
 	require.NoError(err)
 	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
 }
 
 type GetReceivedGiftCardsHandlerTestSuite struct {
@@ -304,10 +1139,11 @@ func (suite *GetReceivedGiftCardsHandlerTestSuite) TestGetReceivedGiftCardsHandl
 	require := suite.Require()
 	userID := uint(10)
 	status := domain.GCSAccepted
-	giftCards := []domain.GiftCard{{ID: 10, Amount: 100, Status: status, GifterID: 10, GifteeID: userID}}
-	expectedResponse := `{"gift_cards":[{"id":10,"amount":100,"status":0,"gifter_id":10,"giftee_id":10}],"total":1,"page":1}`
+	giftCards := []domain.GiftCard{{ID: 10, Amount: domain.NewMoney(10000, "USD"), RemainingAmount: domain.NewMoney(10000, "USD"), Status: status, GifterID: 10, GifteeID: userID, Version: 1}}
+	expectedResponse := `{"gift_cards":[{"id":10,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":0,"gifter_id":10,"giftee_id":10,"version":1}],"page":1,"page_size":10,"next_cursor":"next"}`
 
-	defer suite.giftCardService.On("GetReceivedGiftCardsByUserID", userID, &status, 10, 1).Return(giftCards, len(giftCards), nil).Unset()
+	defer suite.giftCardService.On("GetReceivedGiftCardsByUserID", mock.Anything, userID, repository.GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1}).
+		Return(repository.GiftCardPage{GiftCards: giftCards, NextCursor: "next"}, nil).Unset()
 
 	ctx, response := getReceivedGiftCardsNewEchoContext(userID, int(status))
 	err := GetReceivedGiftCardsHandler(suite.giftCardService)(ctx)
@@ -320,7 +1156,7 @@ func (suite *GetReceivedGiftCardsHandlerTestSuite) TestGetReceivedGiftCardsHandl
 func (suite *GetReceivedGiftCardsHandlerTestSuite) TestGetReceivedGiftCardsHandler_InvalidGiftCardStatus_Failure() {
 	require := suite.Require()
 	userID := uint(10)
-	status := 5
+	status := 6
 	expectedResponse := `{"message": "invalid gift-card status"}`
 
 	ctx, response := getReceivedGiftCardsNewEchoContext(userID, status)
@@ -337,8 +1173,8 @@ func (suite *GetReceivedGiftCardsHandlerTestSuite) TestGetReceivedGiftCardsHandl
 	status := domain.GCSRejected
 	expectedResponse := `{"message": "Failed to get gift cards"}`
 
-	defer suite.giftCardService.On("GetReceivedGiftCardsByUserID", userID, &status, 10, 1).
-		Return(nil, 0, errors.New("service layer error")).Unset()
+	defer suite.giftCardService.On("GetReceivedGiftCardsByUserID", mock.Anything, userID, repository.GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1}).
+		Return(repository.GiftCardPage{}, errors.New("service layer error")).Unset()
 
 	ctx, response := getReceivedGiftCardsNewEchoContext(userID, int(status))
 	err := GetReceivedGiftCardsHandler(suite.giftCardService)(ctx)
@@ -348,6 +1184,92 @@ func (suite *GetReceivedGiftCardsHandlerTestSuite) TestGetReceivedGiftCardsHandl
 	require.JSONEq(expectedResponse, response.Body.String())
 }
 
+func (suite *GetReceivedGiftCardsHandlerTestSuite) TestGetReceivedGiftCardsHandler_Query_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	counterpartyID := uint(20)
+	createdFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdTo := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	minAmount := domain.NewMoney(1000, "EUR")
+	maxAmount := domain.NewMoney(5050, "EUR")
+	query := repository.GiftCardQuery{
+		Statuses:       []domain.GiftCardStatus{domain.GCSAccepted, domain.GCSPending, domain.GCSRejected},
+		CreatedFrom:    &createdFrom,
+		CreatedTo:      &createdTo,
+		MinAmount:      &minAmount,
+		MaxAmount:      &maxAmount,
+		CounterpartyID: &counterpartyID,
+		SortBy:         repository.GiftCardSortAmount,
+		Descending:     true,
+		PageSize:       25,
+		PageNumber:     2,
+		WithTotal:      true,
+	}
+	total := 0
+	expectedResponse := `{"gift_cards":[],"total":0,"page":2,"page_size":25}`
+
+	defer suite.giftCardService.On("GetReceivedGiftCardsByUserID", mock.Anything, userID, query).Return(repository.GiftCardPage{Total: &total}, nil).Unset()
+
+	ctx, response := listGiftCardsNewEchoContext("/gift-cards/received?status=0,2&status=1&created_from=2024-01-01T00:00:00Z&created_to=2024-02-01T00:00:00Z"+
+		"&currency=eur&min_amount=10&max_amount=50.50&counterparty_id=20&sort=amount&order=desc&page_size=25&page=2&include_total=true", userID)
+	err := GetReceivedGiftCardsHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *GetReceivedGiftCardsHandlerTestSuite) TestGetReceivedGiftCardsHandler_Cursor_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	cursor := "eyJzIjoiY3JlYXRlZF9hdCIsImMiOiIyMDI0LTAxLTAxVDAwOjAwOjAwWiIsImkiOjEwfQ"
+	query := repository.GiftCardQuery{PageSize: repository.DefaultGiftCardPageSize, PageNumber: 1, Cursor: cursor}
+	expectedResponse := `{"gift_cards":[],"page_size":10,"next_cursor":"next","prev_cursor":"prev"}`
+
+	defer suite.giftCardService.On("GetReceivedGiftCardsByUserID", mock.Anything, userID, query).
+		Return(repository.GiftCardPage{NextCursor: "next", PrevCursor: "prev"}, nil).Unset()
+
+	ctx, response := listGiftCardsNewEchoContext("/gift-cards/received?cursor="+cursor, userID)
+	err := GetReceivedGiftCardsHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *GetReceivedGiftCardsHandlerTestSuite) TestGetReceivedGiftCardsHandler_InvalidQuery_Failure() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		query            string
+		expectedResponse string
+	}{
+		{"status=0,foo", `{"message": "invalid gift-card status"}`},
+		{"created_from=2024-01-01", `{"message": "created_from must be an RFC 3339 time"}`},
+		{"created_from=2024-02-01T00:00:00Z&created_to=2024-01-01T00:00:00Z", `{"message": "created_from must not be after created_to"}`},
+		{"min_amount=1.001", `{"message": "invalid min_amount: invalid amount"}`},
+		{"max_amount=1&currency=XXX", `{"message": "invalid max_amount: invalid currency"}`},
+		{"min_amount=20&max_amount=10", `{"message": "min_amount must not be more than max_amount"}`},
+		{"counterparty_id=-1", `{"message": "invalid counterparty_id"}`},
+		{"sort=code", `{"message": "cannot sort gift cards by \"code\""}`},
+		{"order=up", `{"message": "order must be asc or desc"}`},
+		{"page_size=101", `{"message": "page size must be between 1 and 100"}`},
+		{"page_size=0", `{"message": "page size must be between 1 and 100"}`},
+		{"page=10001", `{"message": "page must be between 1 and 10000"}`},
+		{"page=99999999999999999999", `{"message": "page must be between 1 and 10000"}`},
+		{"cursor=foo", `{"message": "invalid cursor"}`},
+		{"cursor=eyJzIjoiY3JlYXRlZF9hdCIsImMiOiIyMDI0LTAxLTAxVDAwOjAwOjAwWiIsImkiOjEwfQ&order=desc", `{"message": "the cursor is of a listing in another order"}`},
+		{"include_total=maybe", `{"message": "invalid include_total"}`},
+	} {
+		ctx, response := listGiftCardsNewEchoContext("/gift-cards/received?"+tc.query, 10)
+		err := GetReceivedGiftCardsHandler(suite.giftCardService)(ctx)
+
+		require.NoError(err)
+		require.Equal(http.StatusBadRequest, response.Code, tc.query)
+		require.JSONEq(tc.expectedResponse, response.Body.String(), tc.query)
+	}
+}
+
 type GetGetGiftCardsHandlerTestSuite struct {
 	suite.Suite
 	giftCardService *service.GiftCardServiceMock
@@ -361,10 +1283,11 @@ func (suite *GetGetGiftCardsHandlerTestSuite) TestGetSentGiftCardsHandler_Succes
 	require := suite.Require()
 	userID := uint(10)
 	status := domain.GCSAccepted
-	giftCards := []domain.GiftCard{{ID: 10, Amount: 100, Status: status, GifterID: 10, GifteeID: userID}}
-	expectedResponse := `{"gift_cards":[{"id":10,"amount":100,"status":0,"gifter_id":10,"giftee_id":10}],"total":1,"page":1}`
+	giftCards := []domain.GiftCard{{ID: 10, Amount: domain.NewMoney(10000, "USD"), RemainingAmount: domain.NewMoney(10000, "USD"), Status: status, GifterID: 10, GifteeID: userID, Version: 1}}
+	expectedResponse := `{"gift_cards":[{"id":10,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":0,"gifter_id":10,"giftee_id":10,"version":1}],"page":1,"page_size":10,"next_cursor":"next"}`
 
-	defer suite.giftCardService.On("GetSentGiftCardsByUserID", userID, &status, 10, 1).Return(giftCards, len(giftCards), nil).Unset()
+	defer suite.giftCardService.On("GetSentGiftCardsByUserID", mock.Anything, userID, repository.GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1}).
+		Return(repository.GiftCardPage{GiftCards: giftCards, NextCursor: "next"}, nil).Unset()
 
 	ctx, response := getSentGiftCardsNewEchoContext(userID, int(status))
 	err := GetSentGiftCardsHandler(suite.giftCardService)(ctx)
@@ -374,10 +1297,26 @@ func (suite *GetGetGiftCardsHandlerTestSuite) TestGetSentGiftCardsHandler_Succes
 	require.JSONEq(expectedResponse, response.Body.String())
 }
 
+func (suite *GetGetGiftCardsHandlerTestSuite) TestGetSentGiftCardsHandler_DefaultQuery_Success() {
+	require := suite.Require()
+	userID := uint(10)
+	query := repository.GiftCardQuery{PageSize: repository.DefaultGiftCardPageSize, PageNumber: 1}
+	expectedResponse := `{"gift_cards":[],"page":1,"page_size":10}`
+
+	defer suite.giftCardService.On("GetSentGiftCardsByUserID", mock.Anything, userID, query).Return(repository.GiftCardPage{}, nil).Unset()
+
+	ctx, response := listGiftCardsNewEchoContext("/gift-cards/sent", userID)
+	err := GetSentGiftCardsHandler(suite.giftCardService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
 func (suite *GetGetGiftCardsHandlerTestSuite) TestGetSentGiftCardsHandler_InvalidGiftCardStatus_Failure() {
 	require := suite.Require()
 	userID := uint(10)
-	status := 5
+	status := 6
 	expectedResponse := `{"message": "invalid gift-card status"}`
 
 	ctx, response := getSentGiftCardsNewEchoContext(userID, status)
@@ -394,8 +1333,8 @@ func (suite *GetGetGiftCardsHandlerTestSuite) TestGetSentGiftCardsHandler_Servic
 	status := domain.GCSRejected
 	expectedResponse := `{"message": "Failed to get gift cards"}`
 
-	defer suite.giftCardService.On("GetSentGiftCardsByUserID", userID, &status, 10, 1).
-		Return(nil, 0, errors.New("service layer error")).Unset()
+	defer suite.giftCardService.On("GetSentGiftCardsByUserID", mock.Anything, userID, repository.GiftCardQuery{Statuses: []domain.GiftCardStatus{status}, PageSize: 10, PageNumber: 1}).
+		Return(repository.GiftCardPage{}, errors.New("service layer error")).Unset()
 
 	ctx, response := getSentGiftCardsNewEchoContext(userID, int(status))
 	err := GetSentGiftCardsHandler(suite.giftCardService)(ctx)
@@ -409,10 +1348,30 @@ func TestCreateGiftCardHandler(t *testing.T) {
 	suite.Run(t, new(CreateGiftCardsHandlerTestSuite))
 }
 
+func TestGetGiftCardHandler(t *testing.T) {
+	suite.Run(t, new(GetGiftCardHandlerTestSuite))
+}
+
 func TestUpdateGiftCardStatusHandler(t *testing.T) {
 	suite.Run(t, new(UpdateGiftCardStatusHandlerTestSuite))
 }
 
+func TestCancelGiftCardHandler(t *testing.T) {
+	suite.Run(t, new(CancelGiftCardHandlerTestSuite))
+}
+
+func TestRedeemGiftCardHandler(t *testing.T) {
+	suite.Run(t, new(RedeemGiftCardHandlerTestSuite))
+}
+
+func TestGetGiftCardCodeHandler(t *testing.T) {
+	suite.Run(t, new(GetGiftCardCodeHandlerTestSuite))
+}
+
+func TestGetGiftCardHistoryHandler(t *testing.T) {
+	suite.Run(t, new(GetGiftCardHistoryHandlerTestSuite))
+}
+
 func TestGetReceivedGiftCardsHandler(t *testing.T) {
 	suite.Run(t, new(GetReceivedGiftCardsHandlerTestSuite))
 }
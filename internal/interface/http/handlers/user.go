@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/jmehdipour/gift-card/internal/domain"
 	"github.com/jmehdipour/gift-card/internal/service"
 	"github.com/jmehdipour/gift-card/utils"
 )
@@ -50,7 +54,11 @@ func CreateUserHandler(userService service.UserService) echo.HandlerFunc {
 			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
 		}
 
-		user, err := userService.CreateUser(request.Email, request.Password)
+		user, err := userService.CreateUser(ctx.Request().Context(), request.Email, request.Password)
+		if errors.Is(err, domain.ErrEmailTaken) {
+			return ctx.JSON(http.StatusConflict, MessageResponse{Message: err.Error()})
+		}
+
 		if err != nil {
 			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: err.Error()})
 		}
@@ -64,8 +72,16 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// LoginHandlerResponse holds the tokens of a session, Token is the access
+// token and ExpiresAt is when it expires.
 type LoginHandlerResponse struct {
-	Token string `json:"token"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func newLoginHandlerResponse(tokens *service.Tokens) LoginHandlerResponse {
+	return LoginHandlerResponse{Token: tokens.AccessToken, RefreshToken: tokens.RefreshToken, ExpiresAt: tokens.ExpiresAt}
 }
 
 func LoginHandler(authService service.AuthService) echo.HandlerFunc {
@@ -76,11 +92,82 @@ func LoginHandler(authService service.AuthService) echo.HandlerFunc {
 			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "invalid request body"})
 		}
 
-		token, err := authService.Login(request.Email, request.Password)
-		if err != nil || token == "" {
+		tokens, err := authService.Login(ctx.Request().Context(), request.Email, request.Password)
+		var blockedErr *domain.LoginBlockedError
+		if errors.As(err, &blockedErr) {
+			retryAfter := int(math.Ceil(time.Until(blockedErr.RetryAt).Seconds()))
+			ctx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+			message := "Too many failed logins, try again later"
+			if blockedErr.Locked {
+				message = "Too many failed logins, the account is locked, try again later"
+			}
+
+			return ctx.JSON(http.StatusTooManyRequests, MessageResponse{Message: message})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to log in"})
+		}
+
+		if tokens == nil {
 			return ctx.NoContent(http.StatusUnauthorized)
 		}
 
-		return ctx.JSON(http.StatusOK, LoginHandlerResponse{Token: token})
+		return ctx.JSON(http.StatusOK, newLoginHandlerResponse(tokens))
+	}
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func RefreshTokenHandler(authService service.AuthService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		request := new(RefreshTokenRequest)
+		err := ctx.Bind(request)
+		if err != nil || request.RefreshToken == "" {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "invalid request body"})
+		}
+
+		tokens, err := authService.Refresh(ctx.Request().Context(), request.RefreshToken)
+		if errors.Is(err, domain.ErrInvalidRefreshToken) || errors.Is(err, domain.ErrRefreshTokenReused) {
+			return ctx.JSON(http.StatusUnauthorized, MessageResponse{Message: err.Error()})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to refresh the token"})
+		}
+
+		return ctx.JSON(http.StatusOK, newLoginHandlerResponse(tokens))
+	}
+}
+
+// LogoutHandler revokes the access token of the request and, if the body
+// has one, the session of the refresh token.
+func LogoutHandler(authService service.AuthService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		accessToken := ctx.Get("access_token").(service.AccessToken)
+		request := new(RefreshTokenRequest)
+		err := ctx.Bind(request)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "invalid request body"})
+		}
+
+		err = authService.Logout(ctx.Request().Context(), accessToken, request.RefreshToken)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to log out"})
+		}
+
+		return ctx.NoContent(http.StatusNoContent)
+	}
+}
+
+// JWKSHandler serves the public keys access tokens are signed with, so other
+// services can verify the tokens on their own.
+func JWKSHandler(keys *service.KeySet) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		ctx.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+
+		return ctx.JSON(http.StatusOK, keys.JWKS())
 	}
 }
@@ -2,13 +2,16 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/jmehdipour/gift-card/internal/domain"
@@ -35,6 +38,28 @@ func loginUserNewEchoContext(body string) (echo.Context, *httptest.ResponseRecor
 	return ctx, response
 }
 
+func refreshTokenNewEchoContext(body string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodPost, "/users/refresh", bytes.NewReader([]byte(body)))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+
+	return ctx, response
+}
+
+func logoutNewEchoContext(body string, accessToken service.AccessToken) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodPost, "/users/logout", bytes.NewReader([]byte(body)))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", accessToken.UserID)
+	ctx.Set("access_token", accessToken)
+
+	return ctx, response
+}
+
 type CreateUserHandlerTestSuite struct {
 	suite.Suite
 	userService *service.UserServiceMock
@@ -53,7 +78,7 @@ func (suite *CreateUserHandlerTestSuite) TestCreateUserHandler_Success() {
 	}
 	requestBody := fmt.Sprintf(`{"email": "%s", "password": "%s"}`, user.Email, user.Password)
 
-	defer suite.userService.On("CreateUser", user.Email, user.Password).Return(&user, nil).Unset()
+	defer suite.userService.On("CreateUser", mock.Anything, user.Email, user.Password).Return(&user, nil).Unset()
 
 	ctx, response := createUserNewEchoContext(requestBody)
 
@@ -103,7 +128,7 @@ func (suite *CreateUserHandlerTestSuite) TestCreateUserHandler_UserServiceError_
 	expectedResponse := `{"message":"service layer error"}`
 	expectedError := errors.New("service layer error")
 
-	defer suite.userService.On("CreateUser", email, password).Return(nil, expectedError).Unset()
+	defer suite.userService.On("CreateUser", mock.Anything, email, password).Return(nil, expectedError).Unset()
 
 	ctx, response := createUserNewEchoContext(requestBody)
 
@@ -114,6 +139,24 @@ func (suite *CreateUserHandlerTestSuite) TestCreateUserHandler_UserServiceError_
 	require.JSONEq(expectedResponse, response.Body.String())
 }
 
+func (suite *CreateUserHandlerTestSuite) TestCreateUserHandler_EmailTaken_Failure() {
+	require := suite.Require()
+	email := "foo@bar.com"
+	password := "examplePassword"
+	requestBody := fmt.Sprintf(`{"email": "%s", "password": "%s"}`, email, password)
+	expectedResponse := fmt.Sprintf(`{"message":"%s"}`, domain.ErrEmailTaken)
+
+	defer suite.userService.On("CreateUser", mock.Anything, email, password).Return(nil, domain.ErrEmailTaken).Unset()
+
+	ctx, response := createUserNewEchoContext(requestBody)
+
+	err := CreateUserHandler(suite.userService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusConflict, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
 type LoginHandlerTestSuite struct {
 	suite.Suite
 	authService *service.AuthServiceMock
@@ -127,10 +170,11 @@ func (suite *LoginHandlerTestSuite) TestLoginHandler_Success() {
 	require := suite.Require()
 	email := "foo@example.com"
 	password := "examplePassword"
-	token := "exampleToken"
+	tokens := &service.Tokens{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC)}
 	requestBody := fmt.Sprintf(`{"email": "%s", "password": "%s"}`, email, password)
+	expectedResponse := `{"token": "access", "refresh_token": "refresh", "expires_at": "2024-01-01T00:15:00Z"}`
 
-	defer suite.authService.On("Login", email, password).Return(token, nil).Unset()
+	defer suite.authService.On("Login", mock.Anything, email, password).Return(tokens, nil).Unset()
 
 	ctx, response := loginUserNewEchoContext(requestBody)
 
@@ -138,6 +182,7 @@ func (suite *LoginHandlerTestSuite) TestLoginHandler_Success() {
 
 	require.NoError(err)
 	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
 }
 
 func (suite *LoginHandlerTestSuite) TestLoginHandler_InvalidRequestBody_Failure() {
@@ -160,7 +205,7 @@ func (suite *LoginHandlerTestSuite) TestLoginHandler_UnauthorizedUser_Success()
 	password := "examplePassword"
 	requestBody := fmt.Sprintf(`{"email": "%s", "password": "%s"}`, email, password)
 
-	defer suite.authService.On("Login", email, password).Return("", nil).Unset()
+	defer suite.authService.On("Login", mock.Anything, email, password).Return(nil, nil).Unset()
 
 	ctx, response := loginUserNewEchoContext(requestBody)
 	err := LoginHandler(suite.authService)(ctx)
@@ -169,6 +214,168 @@ func (suite *LoginHandlerTestSuite) TestLoginHandler_UnauthorizedUser_Success()
 	require.Equal(http.StatusUnauthorized, response.Code)
 }
 
+func (suite *LoginHandlerTestSuite) TestLoginHandler_Blocked_Failure() {
+	require := suite.Require()
+	email := "foo@example.com"
+	password := "examplePassword"
+	requestBody := fmt.Sprintf(`{"email": "%s", "password": "%s"}`, email, password)
+
+	for _, tc := range []struct {
+		err                error
+		expectedRetryAfter string
+		expectedBody       string
+	}{
+		{&domain.LoginBlockedError{RetryAt: time.Now().Add(1500 * time.Millisecond)}, "2", `{"message": "Too many failed logins, try again later"}`},
+		{&domain.LoginBlockedError{RetryAt: time.Now().Add(15 * time.Minute), Locked: true}, "900", `{"message": "Too many failed logins, the account is locked, try again later"}`},
+		{&domain.LoginBlockedError{RetryAt: time.Now()}, "1", `{"message": "Too many failed logins, try again later"}`},
+	} {
+		call := suite.authService.On("Login", mock.Anything, email, password).Return(nil, tc.err)
+
+		ctx, response := loginUserNewEchoContext(requestBody)
+		err := LoginHandler(suite.authService)(ctx)
+		call.Unset()
+
+		require.NoError(err)
+		require.Equal(http.StatusTooManyRequests, response.Code)
+		require.Equal(tc.expectedRetryAfter, response.Header().Get(echo.HeaderRetryAfter))
+		require.JSONEq(tc.expectedBody, response.Body.String())
+	}
+}
+
+func (suite *LoginHandlerTestSuite) TestLoginHandler_ServiceError_Failure() {
+	require := suite.Require()
+	email := "foo@example.com"
+	password := "examplePassword"
+	requestBody := fmt.Sprintf(`{"email": "%s", "password": "%s"}`, email, password)
+	expectedResponse := `{"message": "Failed to log in"}`
+
+	defer suite.authService.On("Login", mock.Anything, email, password).Return(nil, errors.New("service layer error")).Unset()
+
+	ctx, response := loginUserNewEchoContext(requestBody)
+	err := LoginHandler(suite.authService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *LoginHandlerTestSuite) TestRefreshTokenHandler_Success() {
+	require := suite.Require()
+	tokens := &service.Tokens{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC)}
+	expectedResponse := `{"token": "access", "refresh_token": "refresh", "expires_at": "2024-01-01T00:15:00Z"}`
+
+	defer suite.authService.On("Refresh", mock.Anything, "old").Return(tokens, nil).Unset()
+
+	ctx, response := refreshTokenNewEchoContext(`{"refresh_token": "old"}`)
+	err := RefreshTokenHandler(suite.authService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *LoginHandlerTestSuite) TestRefreshTokenHandler_InvalidRequestBody_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "invalid request body"}`
+
+	for _, body := range []string{`{"refresh_token": 10}`, `{}`} {
+		ctx, response := refreshTokenNewEchoContext(body)
+		err := RefreshTokenHandler(suite.authService)(ctx)
+
+		require.NoError(err)
+		require.Equal(http.StatusBadRequest, response.Code)
+		require.JSONEq(expectedResponse, response.Body.String())
+	}
+}
+
+func (suite *LoginHandlerTestSuite) TestRefreshTokenHandler_Unauthorized_Failure() {
+	require := suite.Require()
+
+	for _, expectedError := range []error{domain.ErrInvalidRefreshToken, domain.ErrRefreshTokenReused} {
+		suite.authService.On("Refresh", mock.Anything, "old").Return(nil, expectedError).Once()
+
+		ctx, response := refreshTokenNewEchoContext(`{"refresh_token": "old"}`)
+		err := RefreshTokenHandler(suite.authService)(ctx)
+
+		require.NoError(err)
+		require.Equal(http.StatusUnauthorized, response.Code)
+		require.JSONEq(fmt.Sprintf(`{"message": %q}`, expectedError), response.Body.String())
+	}
+}
+
+func (suite *LoginHandlerTestSuite) TestRefreshTokenHandler_ServiceError_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "Failed to refresh the token"}`
+
+	defer suite.authService.On("Refresh", mock.Anything, "old").Return(nil, errors.New("service layer error")).Unset()
+
+	ctx, response := refreshTokenNewEchoContext(`{"refresh_token": "old"}`)
+	err := RefreshTokenHandler(suite.authService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *LoginHandlerTestSuite) TestLogoutHandler_Success() {
+	require := suite.Require()
+	accessToken := service.AccessToken{ID: "jti", UserID: 10, ExpiresAt: time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC)}
+
+	defer suite.authService.On("Logout", mock.Anything, accessToken, "refresh").Return(nil).Unset()
+
+	ctx, response := logoutNewEchoContext(`{"refresh_token": "refresh"}`, accessToken)
+	err := LogoutHandler(suite.authService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNoContent, response.Code)
+}
+
+func (suite *LoginHandlerTestSuite) TestLogoutHandler_WithoutRefreshToken_Success() {
+	require := suite.Require()
+	accessToken := service.AccessToken{ID: "jti", UserID: 10, ExpiresAt: time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC)}
+
+	defer suite.authService.On("Logout", mock.Anything, accessToken, "").Return(nil).Unset()
+
+	ctx, response := logoutNewEchoContext("", accessToken)
+	err := LogoutHandler(suite.authService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNoContent, response.Code)
+}
+
+func (suite *LoginHandlerTestSuite) TestLogoutHandler_ServiceError_Failure() {
+	require := suite.Require()
+	accessToken := service.AccessToken{ID: "jti", UserID: 10, ExpiresAt: time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC)}
+	expectedResponse := `{"message": "Failed to log out"}`
+
+	defer suite.authService.On("Logout", mock.Anything, accessToken, "").Return(errors.New("service layer error")).Unset()
+
+	ctx, response := logoutNewEchoContext("", accessToken)
+	err := LogoutHandler(suite.authService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *LoginHandlerTestSuite) TestJWKSHandler_Success() {
+	require := suite.Require()
+	keys, err := service.NewEphemeralKeySet()
+	require.NoError(err)
+	request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	response := httptest.NewRecorder()
+	ctx := echo.New().NewContext(request, response)
+
+	err = JWKSHandler(keys)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.Equal("public, max-age=300", response.Header().Get(echo.HeaderCacheControl))
+	var jwks service.JWKS
+	require.NoError(json.Unmarshal(response.Body.Bytes(), &jwks))
+	require.Equal(keys.JWKS(), jwks)
+}
+
 func TestCreateUserHandler(t *testing.T) {
 	suite.Run(t, new(CreateUserHandlerTestSuite))
 }
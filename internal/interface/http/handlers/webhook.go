@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+type CreateWebhookRequest struct {
+	URL string `json:"url"`
+}
+
+// UpdateWebhookRequest replaces the webhook, Active defaults to true when it
+// is not given.
+type UpdateWebhookRequest struct {
+	URL    string `json:"url"`
+	Active *bool  `json:"active"`
+}
+
+// WebhookResponse is a webhook as it is returned by the API. The secret is
+// only returned once, when the webhook is created.
+type WebhookResponse struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newWebhookResponse(w domain.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:        w.ID,
+		URL:       w.URL,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+type GetWebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uint            `json:"id"`
+	EventID        uint            `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+func newWebhookDeliveryResponse(d domain.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Payload:        d.Payload,
+		Status:         d.Status.String(),
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+
+	if d.Status == domain.WDSPending {
+		nextAttemptAt := d.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+
+	return response
+}
+
+type GetWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Total      int                       `json:"total"`
+	Page       int                       `json:"page"`
+}
+
+func CreateWebhookHandler(webhookService service.WebhookService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		request := new(CreateWebhookRequest)
+		err := ctx.Bind(request)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid request body"})
+		}
+
+		userID := ctx.Get("user_id").(uint)
+		webhook, err := webhookService.CreateWebhook(ctx.Request().Context(), userID, request.URL)
+		if errors.Is(err, domain.ErrInvalidWebhookURL) || errors.Is(err, domain.ErrWebhookURLNotPublic) {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to create webhook"})
+		}
+
+		response := newWebhookResponse(*webhook)
+		response.Secret = webhook.Secret
+
+		return ctx.JSON(http.StatusCreated, response)
+	}
+}
+
+func GetWebhooksHandler(webhookService service.WebhookService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		userID := ctx.Get("user_id").(uint)
+		webhooks, err := webhookService.GetWebhooks(ctx.Request().Context(), userID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get webhooks"})
+		}
+
+		webhooksResponse := make([]WebhookResponse, 0, len(webhooks))
+		for _, w := range webhooks {
+			webhooksResponse = append(webhooksResponse, newWebhookResponse(w))
+		}
+
+		return ctx.JSON(http.StatusOK, GetWebhooksResponse{Webhooks: webhooksResponse})
+	}
+}
+
+func GetWebhookHandler(webhookService service.WebhookService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		webhookID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid webhook ID"})
+		}
+
+		userID := ctx.Get("user_id").(uint)
+		webhook, err := webhookService.GetWebhook(ctx.Request().Context(), userID, uint(webhookID))
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Webhook not found"})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get webhook"})
+		}
+
+		return ctx.JSON(http.StatusOK, newWebhookResponse(*webhook))
+	}
+}
+
+func UpdateWebhookHandler(webhookService service.WebhookService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		webhookID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid webhook ID"})
+		}
+
+		request := new(UpdateWebhookRequest)
+		err = ctx.Bind(request)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid request body"})
+		}
+
+		active := true
+		if request.Active != nil {
+			active = *request.Active
+		}
+
+		userID := ctx.Get("user_id").(uint)
+		webhook, err := webhookService.UpdateWebhook(ctx.Request().Context(), userID, uint(webhookID), request.URL, active)
+		if errors.Is(err, domain.ErrInvalidWebhookURL) || errors.Is(err, domain.ErrWebhookURLNotPublic) {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: err.Error()})
+		}
+
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Webhook not found"})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to update webhook"})
+		}
+
+		return ctx.JSON(http.StatusOK, newWebhookResponse(*webhook))
+	}
+}
+
+func DeleteWebhookHandler(webhookService service.WebhookService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		webhookID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid webhook ID"})
+		}
+
+		userID := ctx.Get("user_id").(uint)
+		err = webhookService.DeleteWebhook(ctx.Request().Context(), userID, uint(webhookID))
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Webhook not found"})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to delete webhook"})
+		}
+
+		return ctx.NoContent(http.StatusNoContent)
+	}
+}
+
+func GetWebhookDeliveriesHandler(webhookService service.WebhookService) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		webhookID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, MessageResponse{Message: "Invalid webhook ID"})
+		}
+
+		pageSize := 10
+		pageNumberStr := ctx.QueryParam("page")
+		pageNumberInt, _ := strconv.Atoi(pageNumberStr)
+		if pageNumberInt < 1 {
+			pageNumberInt = 1
+		}
+
+		userID := ctx.Get("user_id").(uint)
+		deliveries, totalCount, err := webhookService.GetDeliveries(ctx.Request().Context(), userID, uint(webhookID), pageSize, pageNumberInt)
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			return ctx.JSON(http.StatusNotFound, MessageResponse{Message: "Webhook not found"})
+		}
+
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, MessageResponse{Message: "Failed to get webhook deliveries"})
+		}
+
+		deliveriesResponse := make([]WebhookDeliveryResponse, 0, len(deliveries))
+		for _, d := range deliveries {
+			deliveriesResponse = append(deliveriesResponse, newWebhookDeliveryResponse(d))
+		}
+
+		return ctx.JSON(http.StatusOK, GetWebhookDeliveriesResponse{Deliveries: deliveriesResponse, Total: totalCount, Page: pageNumberInt})
+	}
+}
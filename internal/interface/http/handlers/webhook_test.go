@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+func webhookNewEchoContext(method, path, body string, userID uint, webhookID string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, response)
+	ctx.Set("user_id", userID)
+	if webhookID != "" {
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(webhookID)
+	}
+
+	return ctx, response
+}
+
+type WebhookHandlersTestSuite struct {
+	suite.Suite
+	webhookService *service.WebhookServiceMock
+}
+
+func (suite *WebhookHandlersTestSuite) SetupTest() {
+	suite.webhookService = new(service.WebhookServiceMock)
+}
+
+func (suite *WebhookHandlersTestSuite) TestCreateWebhookHandler_Success() {
+	require := suite.Require()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	webhook := &domain.Webhook{ID: 3, UserID: 10, URL: "https://example.com/hook", Secret: "secret", Active: true, CreatedAt: createdAt}
+	expectedResponse := `{"id":3,"url":"https://example.com/hook","active":true,"secret":"secret","created_at":"2024-01-01T00:00:00Z"}`
+
+	suite.webhookService.On("CreateWebhook", mock.Anything, uint(10), "https://example.com/hook").Return(webhook, nil).Once()
+	ctx, response := webhookNewEchoContext(http.MethodPost, "/webhooks", `{"url": "https://example.com/hook"}`, 10, "")
+
+	err := CreateWebhookHandler(suite.webhookService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusCreated, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *WebhookHandlersTestSuite) TestCreateWebhookHandler_InvalidURL_Failure() {
+	require := suite.Require()
+	expectedResponse := fmt.Sprintf(`{"message": "%s"}`, domain.ErrInvalidWebhookURL)
+
+	suite.webhookService.On("CreateWebhook", mock.Anything, uint(10), "example.com").Return(nil, domain.ErrInvalidWebhookURL).Once()
+	ctx, response := webhookNewEchoContext(http.MethodPost, "/webhooks", `{"url": "example.com"}`, 10, "")
+
+	err := CreateWebhookHandler(suite.webhookService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *WebhookHandlersTestSuite) TestCreateWebhookHandler_InternalURL_Failure() {
+	require := suite.Require()
+	expectedResponse := fmt.Sprintf(`{"message": "%s"}`, domain.ErrWebhookURLNotPublic)
+
+	suite.webhookService.On("CreateWebhook", mock.Anything, uint(10), "http://127.0.0.1/hook").Return(nil, domain.ErrWebhookURLNotPublic).Once()
+	ctx, response := webhookNewEchoContext(http.MethodPost, "/webhooks", `{"url": "http://127.0.0.1/hook"}`, 10, "")
+
+	err := CreateWebhookHandler(suite.webhookService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *WebhookHandlersTestSuite) TestGetWebhooksHandler_Success() {
+	require := suite.Require()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	webhooks := []domain.Webhook{{ID: 3, UserID: 10, URL: "https://example.com/hook", Secret: "secret", Active: true, CreatedAt: createdAt}}
+	expectedResponse := `{"webhooks":[{"id":3,"url":"https://example.com/hook","active":true,"created_at":"2024-01-01T00:00:00Z"}]}`
+
+	suite.webhookService.On("GetWebhooks", mock.Anything, uint(10)).Return(webhooks, nil).Once()
+	ctx, response := webhookNewEchoContext(http.MethodGet, "/webhooks", "", 10, "")
+
+	err := GetWebhooksHandler(suite.webhookService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *WebhookHandlersTestSuite) TestGetWebhookHandler_NotFound_Failure() {
+	require := suite.Require()
+
+	suite.webhookService.On("GetWebhook", mock.Anything, uint(10), uint(3)).Return(nil, domain.ErrWebhookNotFound).Once()
+	ctx, response := webhookNewEchoContext(http.MethodGet, "/webhooks/3", "", 10, "3")
+
+	err := GetWebhookHandler(suite.webhookService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, response.Code)
+	require.JSONEq(`{"message": "Webhook not found"}`, response.Body.String())
+}
+
+func (suite *WebhookHandlersTestSuite) TestUpdateWebhookHandler_Success() {
+	require := suite.Require()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	webhook := &domain.Webhook{ID: 3, UserID: 10, URL: "https://example.com/new", Active: false, CreatedAt: createdAt}
+	expectedResponse := `{"id":3,"url":"https://example.com/new","active":false,"created_at":"2024-01-01T00:00:00Z"}`
+
+	suite.webhookService.On("UpdateWebhook", mock.Anything, uint(10), uint(3), "https://example.com/new", false).Return(webhook, nil).Once()
+	ctx, response := webhookNewEchoContext(http.MethodPut, "/webhooks/3", `{"url": "https://example.com/new", "active": false}`, 10, "3")
+
+	err := UpdateWebhookHandler(suite.webhookService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *WebhookHandlersTestSuite) TestDeleteWebhookHandler_Success() {
+	require := suite.Require()
+
+	suite.webhookService.On("DeleteWebhook", mock.Anything, uint(10), uint(3)).Return(nil).Once()
+	ctx, response := webhookNewEchoContext(http.MethodDelete, "/webhooks/3", "", 10, "3")
+
+	err := DeleteWebhookHandler(suite.webhookService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusNoContent, response.Code)
+}
+
+func (suite *WebhookHandlersTestSuite) TestDeleteWebhookHandler_InvalidID_Failure() {
+	require := suite.Require()
+
+	ctx, response := webhookNewEchoContext(http.MethodDelete, "/webhooks/abc", "", 10, "abc")
+
+	err := DeleteWebhookHandler(suite.webhookService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, response.Code)
+	require.JSONEq(`{"message": "Invalid webhook ID"}`, response.Body.String())
+}
+
+func (suite *WebhookHandlersTestSuite) TestGetWebhookDeliveriesHandler_Success() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deliveries := []domain.WebhookDelivery{
+		{ID: 8, WebhookID: 3, EventID: 6, EventType: domain.EventGiftCardStatusChanged, Payload: []byte(`{"gift_card_id":101}`), Status: domain.WDSPending, Attempts: 1, NextAttemptAt: now.Add(30 * time.Second), LastStatusCode: 500, CreatedAt: now, UpdatedAt: now},
+		{ID: 7, WebhookID: 3, EventID: 5, EventType: domain.EventGiftCardStatusChanged, Payload: []byte(`{"gift_card_id":100}`), Status: domain.WDSSucceeded, Attempts: 1, LastStatusCode: 200, CreatedAt: now, UpdatedAt: now},
+	}
+	expectedResponse := `{"deliveries":[` +
+		`{"id":8,"event_id":6,"event_type":"gift_card.status_changed","payload":{"gift_card_id":101},"status":"pending","attempts":1,"next_attempt_at":"2024-01-01T00:00:30Z","last_status_code":500,"created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"},` +
+		`{"id":7,"event_id":5,"event_type":"gift_card.status_changed","payload":{"gift_card_id":100},"status":"succeeded","attempts":1,"last_status_code":200,"created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}` +
+		`],"total":2,"page":1}`
+
+	suite.webhookService.On("GetDeliveries", mock.Anything, uint(10), uint(3), 10, 1).Return(deliveries, 2, nil).Once()
+	ctx, response := webhookNewEchoContext(http.MethodGet, "/webhooks/3/deliveries", "", 10, "3")
+
+	err := GetWebhookDeliveriesHandler(suite.webhookService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, response.Code)
+	require.JSONEq(expectedResponse, response.Body.String())
+}
+
+func (suite *WebhookHandlersTestSuite) TestGetWebhookDeliveriesHandler_ServiceError_Failure() {
+	require := suite.Require()
+
+	suite.webhookService.On("GetDeliveries", mock.Anything, uint(10), uint(3), 10, 1).Return(nil, 0, errors.New("service error")).Once()
+	ctx, response := webhookNewEchoContext(http.MethodGet, "/webhooks/3/deliveries", "", 10, "3")
+
+	err := GetWebhookDeliveriesHandler(suite.webhookService)(ctx)
+
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, response.Code)
+	require.JSONEq(`{"message": "Failed to get webhook deliveries"}`, response.Body.String())
+}
+
+func TestWebhookHandlers(t *testing.T) {
+	suite.Run(t, new(WebhookHandlersTestSuite))
+}
@@ -1,46 +1,35 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 
-	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/service"
 )
 
-func ValidateUser() echo.MiddlewareFunc {
+// ValidateUser rejects requests without a valid access token, one signed by a
+// known key with the algorithm of that key. It sets the user_id of the token
+// on the context and the token itself as access_token.
+func ValidateUser(authService service.AuthService) echo.MiddlewareFunc {
 	return func(handler echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
 			token := ctx.Request().Header.Get("Authorization")
-			claims, err := parseAndValidateToken(token, config.C.User.Secret)
-			if err != nil {
+			accessToken, err := authService.Authenticate(ctx.Request().Context(), token)
+			if errors.Is(err, domain.ErrInvalidAccessToken) {
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
 			}
 
-			userID := claims["user_id"].(float64)
 			if err != nil {
-				return echo.NewHTTPError(http.StatusUnauthorized, "user_id not found in token claims")
+				return err
 			}
 
-			ctx.Set("user_id", uint(userID))
+			ctx.Set("user_id", accessToken.UserID)
+			ctx.Set("access_token", *accessToken)
 
 			return handler(ctx)
 		}
 	}
 }
-
-func parseAndValidateToken(tokenString string, signingKey string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(signingKey), nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if token.Valid {
-		return token.Claims.(jwt.MapClaims), nil
-	}
-
-	return nil, echo.NewHTTPError(http.StatusUnauthorized, "invalid token claims type")
-}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/interface/http/handlers"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotency-Replayed"
+)
+
+// unreplayedHeaders are the response headers that belong to a single response
+// and are not stored for replays.
+var unreplayedHeaders = []string{echo.HeaderContentLength, echo.HeaderXRequestID, "Date"}
+
+// Idempotency makes a mutating endpoint safe to retry. A request with an
+// Idempotency-Key header is handled once per user and key, retries get the
+// stored response of the first request, headers included, and a key reused
+// for a different request is rejected. It must run after ValidateUser.
+func Idempotency(idempotencyService service.IdempotencyService) echo.MiddlewareFunc {
+	return func(handler echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			key := ctx.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return handler(ctx)
+			}
+
+			if len(key) > 255 {
+				return ctx.JSON(http.StatusBadRequest, handlers.MessageResponse{Message: "Idempotency-Key is too long"})
+			}
+
+			body, err := io.ReadAll(ctx.Request().Body)
+			if err != nil {
+				return ctx.JSON(http.StatusBadRequest, handlers.MessageResponse{Message: "Invalid request body"})
+			}
+
+			ctx.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			userID := ctx.Get("user_id").(uint)
+			stored, err := idempotencyService.Begin(ctx.Request().Context(), userID, key, requestHash(ctx.Request(), body))
+			switch {
+			case errors.Is(err, domain.ErrIdempotencyKeyReused):
+				return ctx.JSON(http.StatusUnprocessableEntity, handlers.MessageResponse{Message: err.Error()})
+			case errors.Is(err, domain.ErrIdempotencyKeyInProgress):
+				return ctx.JSON(http.StatusConflict, handlers.MessageResponse{Message: err.Error()})
+			case err != nil:
+				return ctx.JSON(http.StatusInternalServerError, handlers.MessageResponse{Message: "Failed to check idempotency key"})
+			}
+
+			if stored.IsCompleted() {
+				header := ctx.Response().Header()
+				for name, values := range stored.ResponseHeaders {
+					header[name] = values
+				}
+
+				header.Set(HeaderIdempotencyReplayed, "true")
+				ctx.Response().WriteHeader(stored.StatusCode)
+				_, err = ctx.Response().Write(stored.ResponseBody)
+
+				return err
+			}
+
+			recorder := &responseRecorder{ResponseWriter: ctx.Response().Writer}
+			ctx.Response().Writer = recorder
+			err = handler(ctx)
+
+			// The outcome is stored even if the client went away or the
+			// request timed out meanwhile, a retry would wait for the key
+			// otherwise.
+			storeCtx := context.WithoutCancel(ctx.Request().Context())
+
+			// Server errors and errors the handler did not write a response
+			// for are not stored, so that the request can be retried.
+			status := ctx.Response().Status
+			if err != nil || !ctx.Response().Committed || status >= http.StatusInternalServerError {
+				if releaseErr := idempotencyService.Release(storeCtx, *stored); releaseErr != nil {
+					log.Errorf("releasing idempotency key failed: %v", releaseErr)
+				}
+
+				return err
+			}
+
+			header := ctx.Response().Header().Clone()
+			for _, name := range unreplayedHeaders {
+				header.Del(name)
+			}
+
+			if completeErr := idempotencyService.Complete(storeCtx, *stored, status, header, recorder.body.Bytes()); completeErr != nil {
+				log.Errorf("storing idempotent response failed: %v", completeErr)
+			}
+
+			return nil
+		}
+	}
+}
+
+// requestHash identifies a request by its method, path and body.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body written by a handler.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
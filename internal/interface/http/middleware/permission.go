@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+// RequirePermission rejects requests whose access token does not grant the
+// permission. It must run after ValidateUser.
+func RequirePermission(permission domain.Permission) echo.MiddlewareFunc {
+	return func(handler echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			accessToken := ctx.Get("access_token").(service.AccessToken)
+			if !accessToken.Can(permission) {
+				return echo.NewHTTPError(http.StatusForbidden, "forbidden: missing permission "+string(permission))
+			}
+
+			return handler(ctx)
+		}
+	}
+}
+
+// AuditLog records who did what through the endpoints it wraps and how it
+// went in the audit log, denied attempts included. It must run after
+// ValidateUser.
+func AuditLog(auditService service.AuditService) echo.MiddlewareFunc {
+	return func(handler echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			err := handler(ctx)
+
+			status := ctx.Response().Status
+			if he, ok := err.(*echo.HTTPError); ok {
+				status = he.Code
+			} else if err != nil {
+				status = http.StatusInternalServerError
+			}
+
+			var params map[string]string
+			if len(ctx.ParamNames()) > 0 {
+				params = make(map[string]string, len(ctx.ParamNames()))
+				for i, name := range ctx.ParamNames() {
+					params[name] = ctx.ParamValues()[i]
+				}
+			}
+
+			// The request is recorded even if the client went away or it
+			// timed out meanwhile.
+			recordCtx := context.WithoutCancel(ctx.Request().Context())
+			accessToken := ctx.Get("access_token").(service.AccessToken)
+			recordErr := auditService.RecordAdminRequest(recordCtx, accessToken.UserID, service.AdminRequest{
+				Method: ctx.Request().Method,
+				Path:   ctx.Path(),
+				Params: params,
+				Query:  ctx.QueryString(),
+				Status: status,
+			})
+			if recordErr != nil {
+				log.Errorf("recording admin request failed: %v", recordErr)
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+// RequestInfo puts the id, the client IP and the user agent of the request on
+// its context, for the audit log and the login throttling. The client IP is
+// the one the IPExtractor of the server finds, so it is only taken from
+// headers set by trusted proxies. It must run after the RequestID middleware
+// of echo.
+func RequestInfo() echo.MiddlewareFunc {
+	return func(handler echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			requestCtx := domain.WithRequestInfo(ctx.Request().Context(), domain.RequestInfo{
+				ID:        ctx.Response().Header().Get(echo.HeaderXRequestID),
+				IP:        ctx.RealIP(),
+				UserAgent: ctx.Request().UserAgent(),
+			})
+			ctx.SetRequest(ctx.Request().WithContext(requestCtx))
+
+			return handler(ctx)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestTimeout bounds the context of every request by timeout. The queries
+// of a request run on its context, so they are cancelled once it times out,
+// just like they are when the client goes away. Zero disables the timeout.
+func RequestTimeout(timeout time.Duration) echo.MiddlewareFunc {
+	return func(handler echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if timeout <= 0 {
+				return handler(ctx)
+			}
+
+			requestCtx, cancel := context.WithTimeout(ctx.Request().Context(), timeout)
+			defer cancel()
+			ctx.SetRequest(ctx.Request().WithContext(requestCtx))
+
+			return handler(ctx)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,11 +14,15 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/messaging"
 	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
 	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/seed"
 	"github.com/jmehdipour/gift-card/internal/interface/http/handlers"
 	"github.com/jmehdipour/gift-card/internal/interface/http/middleware"
 	"github.com/jmehdipour/gift-card/internal/service"
+	"github.com/jmehdipour/gift-card/internal/worker"
 )
 
 // Package http is a package for defining and creating a http server
@@ -52,7 +57,16 @@ type echoServer struct {
 func NewServer() Server {
 	e := echo.New()
 	e.HideBanner = true
+	ipExtractor, err := newIPExtractor(config.C.HTTPServer.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Cannot parse the trusted proxies: %v", err)
+	}
+
+	e.IPExtractor = ipExtractor
 	e.Use(echomw.Logger())
+	e.Use(echomw.RequestID())
+	e.Use(middleware.RequestInfo())
+	e.Use(middleware.RequestTimeout(config.C.HTTPServer.RequestTimeout))
 
 	return &echoServer{
 		e: e,
@@ -62,29 +76,73 @@ func NewServer() Server {
 // Serve starts the echo server and listens on the configured port
 func (s *echoServer) Serve() {
 	ctx := context.Background()
-	db, err := database.CreateDatabase(config.C.Database.String())
-	if err != nil {
-		log.Fatalf("Cannot connect to database: %v", err)
-	}
-
-	userRepo := repository.NewUserRepository(db)
-	giftCardRepo := repository.NewGiftCardRepository(db)
+	repos := newRepositories()
 
-	userService := service.NewUserService(userRepo)
-	authService := service.NewAuthService(userRepo)
-	giftCardService := service.NewGiftCardService(giftCardRepo)
+	userService := service.NewUserService(repos.users, repos.loginFailures)
+	keys := newKeySet()
+	authService := service.NewAuthService(repos.users, repos.refreshTokens, repos.revokedTokens, repos.loginFailures, repos.audit, keys, config.C.User)
+	giftCardService := service.NewGiftCardService(repos.giftCards, repos.unitOfWork, config.C.GiftCard.DefaultTTL)
+	walletService := service.NewWalletService(repos.unitOfWork)
+	idempotencyService := service.NewIdempotencyService(repos.idempotency, config.C.HTTPServer.Idempotency.Lease, config.C.HTTPServer.Idempotency.TTL)
+	webhookSender := messaging.NewHTTPWebhookSender(messaging.NewWebhookHTTPClient(config.C.Webhook.Delivery.Timeout))
+	webhookService := service.NewWebhookService(repos.webhooks, webhookSender, config.C.Webhook.Delivery.Lease)
+	auditService := service.NewAuditService(repos.audit)
 
 	s.e.GET("/", func(ctx echo.Context) error {
 		return ctx.String(http.StatusOK, asciiArt)
 	})
 
+	s.e.GET("/.well-known/jwks.json", handlers.JWKSHandler(keys))
+
 	s.e.POST("/users/register", handlers.CreateUserHandler(userService))
 	s.e.POST("/users/login", handlers.LoginHandler(authService))
+	s.e.POST("/users/refresh", handlers.RefreshTokenHandler(authService))
+	s.e.POST("/users/logout", handlers.LogoutHandler(authService), middleware.ValidateUser(authService))
+
+	s.e.POST("/gift-cards", handlers.CreateGiftCardHandler(giftCardService), middleware.ValidateUser(authService), middleware.Idempotency(idempotencyService))
+	s.e.GET("/gift-cards/:id", handlers.GetGiftCardHandler(giftCardService), middleware.ValidateUser(authService))
+	s.e.PUT("/gift-cards/:id/status", handlers.UpdateGiftCardStatusHandler(giftCardService), middleware.ValidateUser(authService), middleware.Idempotency(idempotencyService))
+	s.e.POST("/gift-cards/redeem", handlers.RedeemGiftCardHandler(giftCardService), middleware.ValidateUser(authService), middleware.Idempotency(idempotencyService))
+	s.e.GET("/gift-cards/:id/code", handlers.GetGiftCardCodeHandler(giftCardService), middleware.ValidateUser(authService))
+	s.e.POST("/gift-cards/:id/cancel", handlers.CancelGiftCardHandler(giftCardService), middleware.ValidateUser(authService), middleware.Idempotency(idempotencyService))
+	s.e.GET("/gift-cards/:id/history", handlers.GetGiftCardHistoryHandler(giftCardService), middleware.ValidateUser(authService))
+	s.e.GET("/gift-cards/received", handlers.GetReceivedGiftCardsHandler(giftCardService), middleware.ValidateUser(authService))
+	s.e.GET("/gift-cards/sent", handlers.GetSentGiftCardsHandler(giftCardService), middleware.ValidateUser(authService))
+
+	s.e.POST("/webhooks", handlers.CreateWebhookHandler(webhookService), middleware.ValidateUser(authService))
+	s.e.GET("/webhooks", handlers.GetWebhooksHandler(webhookService), middleware.ValidateUser(authService))
+	s.e.GET("/webhooks/:id", handlers.GetWebhookHandler(webhookService), middleware.ValidateUser(authService))
+	s.e.PUT("/webhooks/:id", handlers.UpdateWebhookHandler(webhookService), middleware.ValidateUser(authService))
+	s.e.DELETE("/webhooks/:id", handlers.DeleteWebhookHandler(webhookService), middleware.ValidateUser(authService))
+	s.e.GET("/webhooks/:id/deliveries", handlers.GetWebhookDeliveriesHandler(webhookService), middleware.ValidateUser(authService))
+
+	admin := s.e.Group("/admin", middleware.ValidateUser(authService), middleware.AuditLog(auditService))
+	admin.GET("/users", handlers.AdminFindUserHandler(userService), middleware.RequirePermission(domain.PermissionReadUsers))
+	admin.POST("/users/:id/unlock", handlers.AdminUnlockUserHandler(userService), middleware.RequirePermission(domain.PermissionUnlockUsers))
+	admin.POST("/users/:id/deposits", handlers.AdminDepositHandler(walletService), middleware.RequirePermission(domain.PermissionDepositFunds))
+	admin.GET("/users/:id/gift-cards/received", handlers.AdminGetReceivedGiftCardsHandler(giftCardService), middleware.RequirePermission(domain.PermissionReadGiftCards))
+	admin.GET("/users/:id/gift-cards/sent", handlers.AdminGetSentGiftCardsHandler(giftCardService), middleware.RequirePermission(domain.PermissionReadGiftCards))
+	admin.POST("/gift-cards/:id/expire", handlers.AdminExpireGiftCardHandler(giftCardService), middleware.RequirePermission(domain.PermissionExpireGiftCard))
+	admin.POST("/gift-cards/:id/void", handlers.AdminVoidGiftCardHandler(giftCardService), middleware.RequirePermission(domain.PermissionVoidGiftCard))
+	admin.GET("/audit", handlers.AdminGetAuditEventsHandler(auditService), middleware.RequirePermission(domain.PermissionReadAuditLog))
+	admin.GET("/audit/verify", handlers.AdminVerifyAuditLogHandler(auditService), middleware.RequirePermission(domain.PermissionReadAuditLog))
+
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+	if config.C.GiftCard.Expiry.Enabled {
+		expiryWorker := worker.NewExpiryWorker(giftCardService, config.C.GiftCard.Expiry.Interval, config.C.GiftCard.Expiry.BatchSize)
+		go expiryWorker.Run(workerCtx)
+	}
 
-	s.e.POST("/gift-cards", handlers.CreateGiftCardHandler(giftCardService), middleware.ValidateUser())
-	s.e.PUT("/gift-cards/:id/status", handlers.UpdateGiftCardStatusHandler(giftCardService), middleware.ValidateUser())
-	s.e.GET("/gift-cards/received", handlers.GetReceivedGiftCardsHandler(giftCardService), middleware.ValidateUser())
-	s.e.GET("/gift-cards/sent", handlers.GetSentGiftCardsHandler(giftCardService), middleware.ValidateUser())
+	if config.C.Webhook.Delivery.Enabled {
+		webhookWorker := worker.NewWebhookWorker(webhookService, config.C.Webhook.Delivery.Interval, config.C.Webhook.Delivery.BatchSize)
+		go webhookWorker.Run(workerCtx)
+	}
+
+	if config.C.Audit.Chain.Enabled {
+		auditChainWorker := worker.NewAuditChainWorker(auditService, config.C.Audit.Chain.Interval, config.C.Audit.Chain.BatchSize)
+		go auditChainWorker.Run(workerCtx)
+	}
 
 	go func() {
 		if err := s.e.Start(config.C.HTTPServer.Address); err != nil && err != http.ErrServerClosed {
@@ -97,6 +155,7 @@ func (s *echoServer) Serve() {
 		syscall.SIGTERM,
 		syscall.SIGINT)
 	<-quit
+	stopWorkers()
 
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
@@ -104,3 +163,111 @@ func (s *echoServer) Serve() {
 		log.Fatalf("error in shutdown: %v", err)
 	}
 }
+
+// newIPExtractor finds the client IP of a request. Without trusted proxies it
+// is the IP of the connection, headers a client can set are not trusted.
+// Behind the proxies it is the last IP of X-Forwarded-For that is not one of
+// them.
+func newIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// newKeySet loads the configured signing keys. Without any it only starts
+// if the ephemeral signing key is enabled, it generates a key then, the
+// tokens it signs are lost on restart and other instances of the server
+// cannot verify them.
+func newKeySet() *service.KeySet {
+	if len(config.C.User.SigningKeys) == 0 {
+		if !config.C.User.EphemeralSigningKey {
+			log.Fatal("No signing keys are configured, configure user.signing_keys or enable user.ephemeral_signing_key for development")
+		}
+
+		keys, err := service.NewEphemeralKeySet()
+		if err != nil {
+			log.Fatalf("Cannot generate a signing key: %v", err)
+		}
+
+		log.Warn("No signing keys are configured, using a generated one, tokens are lost on restart")
+
+		return keys
+	}
+
+	keys, err := service.LoadKeySet(config.C.User)
+	if err != nil {
+		log.Fatalf("Cannot load the signing keys: %v", err)
+	}
+
+	return keys
+}
+
+type repositories struct {
+	users         repository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
+	revokedTokens repository.RevokedTokenRepository
+	giftCards     repository.GiftCardRepository
+	idempotency   repository.IdempotencyRepository
+	webhooks      repository.WebhookRepository
+	audit         repository.AuditRepository
+	loginFailures repository.LoginFailureRepository
+	unitOfWork    repository.UnitOfWork
+}
+
+// newRepositories builds the repositories of the configured database driver.
+// The memory driver needs no database, its repositories share a store that
+// is seeded with the demo data and lost on shutdown.
+func newRepositories() repositories {
+	if config.C.Database.Driver == "memory" {
+		store := repository.NewMemoryStore()
+		repos := repositories{
+			users:         repository.NewMemoryUserRepository(store),
+			refreshTokens: repository.NewMemoryRefreshTokenRepository(store),
+			revokedTokens: repository.NewMemoryRevokedTokenRepository(store),
+			giftCards:     repository.NewMemoryGiftCardRepository(store),
+			idempotency:   repository.NewMemoryIdempotencyRepository(store),
+			webhooks:      repository.NewMemoryWebhookRepository(store),
+			audit:         repository.NewMemoryAuditRepository(store),
+			loginFailures: repository.NewMemoryLoginFailureRepository(store),
+			unitOfWork:    repository.NewMemoryUnitOfWork(store),
+		}
+
+		_, err := seed.SeedRepositories(context.Background(), repos.users, repository.NewMemoryWalletRepository(store), repos.giftCards, config.C.Seed.AdminPassword)
+		if err != nil {
+			log.Fatalf("Cannot seed the memory store: %v", err)
+		}
+
+		log.Warn("Using the memory driver, data is lost on shutdown")
+
+		return repos
+	}
+
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
+	if err != nil {
+		log.Fatalf("Cannot connect to database: %v", err)
+	}
+
+	return repositories{
+		users:         repository.NewUserRepository(db),
+		refreshTokens: repository.NewRefreshTokenRepository(db),
+		revokedTokens: repository.NewRevokedTokenRepository(db),
+		giftCards:     repository.NewGiftCardRepository(db),
+		idempotency:   repository.NewIdempotencyRepository(db),
+		webhooks:      repository.NewWebhookRepository(db),
+		audit:         repository.NewAuditRepository(db),
+		loginFailures: repository.NewLoginFailureRepository(db),
+		unitOfWork:    repository.NewUnitOfWork(db),
+	}
+}
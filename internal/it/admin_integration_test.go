@@ -0,0 +1,415 @@
+//go:build integration
+// +build integration
+
+package it
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/interface/http/handlers"
+)
+
+func makeAdminRequest(method, path, token string) (string, int, error) {
+	request, err := http.NewRequest(method, baseURL+path, nil)
+	if err != nil {
+		return "", 0, err
+	}
+
+	request.Header.Set(echo.HeaderAuthorization, token)
+
+	client := http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer response.Body.Close()
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, response.Body); err != nil {
+		return "", 0, err
+	}
+
+	return responseBody.String(), response.StatusCode, nil
+}
+
+func makeAdminDepositRequest(userID uint, token, requestBody string) (string, int, error) {
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/admin/users/%d/deposits", baseURL, userID), bytes.NewReader([]byte(requestBody)))
+	if err != nil {
+		return "", 0, err
+	}
+
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(echo.HeaderAuthorization, token)
+
+	client := http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer response.Body.Close()
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, response.Body); err != nil {
+		return "", 0, err
+	}
+
+	return responseBody.String(), response.StatusCode, nil
+}
+
+type AdminIntegrationTestSuite struct {
+	suite.Suite
+	AdminToken string
+	UserToken  string
+}
+
+func (suite *AdminIntegrationTestSuite) SetupSuite() {
+	require := suite.Require()
+
+	adminToken, err := loginUser("admin@example.com", "password")
+	require.NoError(err)
+	userToken, err := loginUser("test0@example.com", "password")
+	require.NoError(err)
+
+	suite.AdminToken = adminToken
+	suite.UserToken = userToken
+}
+
+// createGiftCard creates a pending gift card from test0 to test1.
+func (suite *AdminIntegrationTestSuite) createGiftCard() handlers.GiftCardResponse {
+	require := suite.Require()
+
+	response, statusCode, err := makeCreateGiftCardRequest(suite.UserToken, `{"amount": 10, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	var giftCard handlers.GiftCardResponse
+	require.NoError(json.Unmarshal([]byte(response), &giftCard))
+
+	return giftCard
+}
+
+func (suite *AdminIntegrationTestSuite) TestFindUser_Success() {
+	require := suite.Require()
+
+	response, statusCode, err := makeAdminRequest(http.MethodGet, "/admin/users?email=test1@example.com", suite.AdminToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	var user handlers.AdminUserResponse
+	require.NoError(json.Unmarshal([]byte(response), &user))
+	require.Equal(uint(2), user.ID)
+	require.Equal("test1@example.com", user.Email)
+	require.Empty(user.Roles)
+}
+
+func (suite *AdminIntegrationTestSuite) TestFindUser_NotFound_Failure() {
+	require := suite.Require()
+
+	response, statusCode, err := makeAdminRequest(http.MethodGet, "/admin/users?email=missing@example.com", suite.AdminToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, statusCode)
+	require.JSONEq(`{"message": "User not found"}`, response)
+}
+
+func (suite *AdminIntegrationTestSuite) TestGetSentGiftCards_Success() {
+	require := suite.Require()
+	giftCard := suite.createGiftCard()
+
+	response, statusCode, err := makeAdminRequest(http.MethodGet, "/admin/users/1/gift-cards/sent?status=2&page_size=100", suite.AdminToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	var giftCards handlers.GetGiftCards
+	require.NoError(json.Unmarshal([]byte(response), &giftCards))
+	require.Contains(giftCardResponseIDs(giftCards.GiftCards), giftCard.ID)
+}
+
+func (suite *AdminIntegrationTestSuite) TestVoidGiftCard_Success() {
+	require := suite.Require()
+	giftCard := suite.createGiftCard()
+
+	response, statusCode, err := makeAdminRequest(http.MethodPost, fmt.Sprintf("/admin/gift-cards/%d/void", giftCard.ID), suite.AdminToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	require.NoError(json.Unmarshal([]byte(response), &giftCard))
+	require.Equal(int(domain.GCSVoided), giftCard.Status)
+	require.Equal(uint(2), giftCard.Version)
+
+	response, statusCode, err = makeAdminRequest(http.MethodPost, fmt.Sprintf("/admin/gift-cards/%d/expire", giftCard.ID), suite.AdminToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusConflict, statusCode)
+	require.JSONEq(`{"message": "gift card cannot move from voided to expired"}`, response)
+}
+
+func (suite *AdminIntegrationTestSuite) TestVoidGiftCard_Accepted_Success() {
+	require := suite.Require()
+	giftCard := suite.createGiftCard()
+	giftee, err := loginUser("test1@example.com", "password")
+	require.NoError(err)
+	_, statusCode, err := makeUpdateGiftCardRequest(int(giftCard.ID), giftee, `"1"`, `{"status": 0}`)
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+
+	response, statusCode, err := makeAdminRequest(http.MethodPost, fmt.Sprintf("/admin/gift-cards/%d/void", giftCard.ID), suite.AdminToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	require.NoError(json.Unmarshal([]byte(response), &giftCard))
+	require.Equal(int(domain.GCSVoided), giftCard.Status)
+	require.Equal("0.00", giftCard.RemainingAmount)
+	require.Equal(uint(3), giftCard.Version)
+}
+
+func (suite *AdminIntegrationTestSuite) TestExpireGiftCard_Success() {
+	require := suite.Require()
+	giftCard := suite.createGiftCard()
+
+	response, statusCode, err := makeAdminRequest(http.MethodPost, fmt.Sprintf("/admin/gift-cards/%d/expire", giftCard.ID), suite.AdminToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	require.NoError(json.Unmarshal([]byte(response), &giftCard))
+	require.Equal(int(domain.GCSExpired), giftCard.Status)
+}
+
+// getAuditEvents lists the audit events of the query string.
+func (suite *AdminIntegrationTestSuite) getAuditEvents(query string) []handlers.AuditEventResponse {
+	require := suite.Require()
+
+	response, statusCode, err := makeAdminRequest(http.MethodGet, "/admin/audit?"+query, suite.AdminToken)
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode, response)
+
+	var events handlers.GetAuditEvents
+	require.NoError(json.Unmarshal([]byte(response), &events))
+
+	return events.Events
+}
+
+// waitForAuditEvents lists the events of the query until done accepts them.
+// The failed logins are chained into the log by a worker, so they show up a
+// little after the response.
+func (suite *AdminIntegrationTestSuite) waitForAuditEvents(query string, done func([]handlers.AuditEventResponse) bool) []handlers.AuditEventResponse {
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(50 * time.Millisecond) {
+		events := suite.getAuditEvents(query)
+		if done(events) {
+			return events
+		}
+
+		suite.Require().True(time.Now().Before(deadline), "audit events of %s: %v", query, events)
+	}
+}
+
+func (suite *AdminIntegrationTestSuite) TestAuditLog_Success() {
+	require := suite.Require()
+	email := fmt.Sprintf("audit%d@example.com", time.Now().UnixNano())
+	response, statusCode, err := makeCreateUserRequest(fmt.Sprintf(`{"email": "%s", "password": "password"}`, email))
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+	var user handlers.CreateUserResponse
+	require.NoError(json.Unmarshal([]byte(response), &user))
+	_, err = loginUser(email, "wrong")
+	require.Error(err)
+	_, err = loginUser(email, "password")
+	require.NoError(err)
+	giftCard := suite.createGiftCard()
+
+	events := suite.getAuditEvents(fmt.Sprintf("target_type=gift_card&target_id=%d", giftCard.ID))
+	require.Len(events, 1)
+	require.Equal(domain.AuditGiftCardCreated, events[0].Action)
+	require.Equal(uint(1), *events[0].ActorID)
+	require.Equal("null", string(events[0].Before))
+	var snapshot domain.AuditGiftCard
+	require.NoError(json.Unmarshal(events[0].After, &snapshot))
+	require.True(snapshot.ExpiresAt.Equal(*giftCard.ExpiresAt))
+	snapshot.ExpiresAt = nil
+	require.Equal(domain.AuditGiftCard{ID: giftCard.ID, Amount: "10.00", RemainingAmount: "10.00", Currency: "USD", Status: "pending", GifterID: 1, GifteeID: 2, Version: 1}, snapshot)
+	require.NotEmpty(events[0].RequestID)
+	require.NotEmpty(events[0].IP)
+	require.Equal("Go-http-client/1.1", events[0].UserAgent)
+
+	// The failed login is chained by the worker, before or after the login
+	// that followed it.
+	query := fmt.Sprintf("target_type=user&target_id=%d", user.ID)
+	events = suite.waitForAuditEvents(query, func(events []handlers.AuditEventResponse) bool { return len(events) == 3 })
+	byAction := map[domain.AuditAction]handlers.AuditEventResponse{}
+	for _, event := range events {
+		byAction[event.Action] = event
+	}
+
+	require.Len(byAction, 3)
+	require.Equal(user.ID, *byAction[domain.AuditUserLoggedIn].ActorID)
+	require.Nil(byAction[domain.AuditUserLoginFailed].ActorID)
+	require.JSONEq(fmt.Sprintf(`{"email":"%s"}`, email), string(byAction[domain.AuditUserLoginFailed].After))
+	require.Equal(domain.AuditUserRegistered, events[2].Action)
+	require.JSONEq(fmt.Sprintf(`{"id":%d,"email":"%s","roles":[]}`, user.ID, email), string(events[2].After))
+
+	// The events of the admin requests are recorded after the response, the
+	// one of the previous listing is the newest by now.
+	events = suite.getAuditEvents("action=admin.request&limit=1")
+	require.Len(events, 1)
+	require.Equal(uint(3), *events[0].ActorID)
+	require.JSONEq(fmt.Sprintf(`{"method":"GET","path":"/admin/audit","query":"target_type=user&target_id=%d","status":200}`, user.ID), string(events[0].After))
+
+	response, statusCode, err = makeAdminRequest(http.MethodGet, "/admin/audit/verify", suite.AdminToken)
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	var report handlers.AuditChainReportResponse
+	require.NoError(json.Unmarshal([]byte(response), &report))
+	require.True(report.OK, response)
+	require.NotZero(report.Events)
+}
+
+func (suite *AdminIntegrationTestSuite) TestAuditLog_SpoofedIP_Success() {
+	require := suite.Require()
+	email := fmt.Sprintf("spoofed%d@example.com", time.Now().UnixNano())
+	request, err := http.NewRequest(http.MethodPost, baseURL+"/users/login", bytes.NewReader([]byte(fmt.Sprintf(`{"email": "%s", "password": "wrong"}`, email))))
+	require.NoError(err)
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+	request.Header.Set(echo.HeaderXRealIP, "203.0.113.8")
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(err)
+	_ = response.Body.Close()
+	require.Equal(http.StatusUnauthorized, response.StatusCode)
+
+	events := suite.waitForAuditEvents("action=user.login_failed&limit=1", func(events []handlers.AuditEventResponse) bool {
+		return len(events) == 1 && string(events[0].After) == fmt.Sprintf(`{"email":"%s"}`, email)
+	})
+	require.JSONEq(fmt.Sprintf(`{"email":"%s"}`, email), string(events[0].After))
+	require.Equal("127.0.0.1", events[0].IP)
+}
+
+func (suite *AdminIntegrationTestSuite) TestAuditLog_Pages_Success() {
+	require := suite.Require()
+
+	first := suite.getAuditEvents("limit=2")
+	require.Len(first, 2)
+	require.Greater(first[0].ID, first[1].ID)
+
+	second := suite.getAuditEvents(fmt.Sprintf("before_id=%d&limit=2", first[1].ID))
+	require.NotEmpty(second)
+	require.Less(second[0].ID, first[1].ID)
+}
+
+func (suite *AdminIntegrationTestSuite) TestUnlockUser_Success() {
+	require := suite.Require()
+
+	response, statusCode, err := makeCreateUserRequest(`{"email": "throttled@example.com", "password": "password"}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	var user handlers.CreateUserResponse
+	require.NoError(json.Unmarshal([]byte(response), &user))
+
+	for i := 0; statusCode != http.StatusTooManyRequests; i++ {
+		require.Less(i, 10)
+		_, statusCode, err = makeLoginRequest(`{"email": "throttled@example.com", "password": "wrong"}`)
+		require.NoError(err)
+	}
+
+	response, statusCode, err = makeLoginRequest(`{"email": "throttled@example.com", "password": "password"}`)
+	require.NoError(err)
+	require.Equal(http.StatusTooManyRequests, statusCode)
+	require.JSONEq(`{"message":"Too many failed logins, try again later"}`, response)
+
+	response, statusCode, err = makeAdminRequest(http.MethodPost, fmt.Sprintf("/admin/users/%d/unlock", user.ID), suite.AdminToken)
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	require.Contains(response, `"email":"throttled@example.com"`)
+
+	_, err = loginUser("throttled@example.com", "password")
+	require.NoError(err)
+
+	events := suite.getAuditEvents(fmt.Sprintf("action=login.unlocked&target_id=%d", user.ID))
+	require.Len(events, 1)
+}
+
+func (suite *AdminIntegrationTestSuite) TestDeposit_Success() {
+	require := suite.Require()
+
+	response, statusCode, err := makeCreateUserRequest(`{"email": "funded@example.com", "password": "examplePassword"}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+	var user handlers.CreateUserResponse
+	require.NoError(json.Unmarshal([]byte(response), &user))
+	userToken, err := loginUser("funded@example.com", "examplePassword")
+	require.NoError(err)
+
+	response, statusCode, err = makeCreateGiftCardRequest(userToken, `{"amount": 10, "giftee_id": 2}`)
+
+	require.NoError(err)
+	require.Equal(http.StatusUnprocessableEntity, statusCode)
+	require.JSONEq(`{"message": "insufficient funds"}`, response)
+
+	response, statusCode, err = makeAdminDepositRequest(user.ID, suite.AdminToken, `{"amount": 50}`)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	require.JSONEq(fmt.Sprintf(`{"user_id": %d, "balance": "50.00", "held": "0.00", "currency": "USD"}`, user.ID), response)
+
+	_, statusCode, err = makeCreateGiftCardRequest(userToken, `{"amount": 10, "giftee_id": 2}`)
+
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	events := suite.getAuditEvents(fmt.Sprintf("action=wallet.deposited&target_id=%d", user.ID))
+	require.Len(events, 1)
+}
+
+func (suite *AdminIntegrationTestSuite) TestDeposit_UserNotFound_Failure() {
+	require := suite.Require()
+
+	response, statusCode, err := makeAdminDepositRequest(1000, suite.AdminToken, `{"amount": 50}`)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, statusCode)
+	require.JSONEq(`{"message": "User not found"}`, response)
+}
+
+func (suite *AdminIntegrationTestSuite) TestAdmin_MissingPermission_Failure() {
+	require := suite.Require()
+
+	for _, request := range []struct{ method, path string }{
+		{http.MethodGet, "/admin/users?email=test1@example.com"},
+		{http.MethodGet, "/admin/users/2/gift-cards/received"},
+		{http.MethodPost, "/admin/users/2/unlock"},
+		{http.MethodPost, "/admin/gift-cards/1/void"},
+		{http.MethodPost, "/admin/users/2/deposits"},
+		{http.MethodGet, "/admin/audit"},
+		{http.MethodGet, "/admin/audit/verify"},
+	} {
+		response, statusCode, err := makeAdminRequest(request.method, request.path, suite.UserToken)
+
+		require.NoError(err)
+		require.Equal(http.StatusForbidden, statusCode, request.path)
+		require.Contains(response, "forbidden: missing permission")
+	}
+}
+
+func (suite *AdminIntegrationTestSuite) TestAdmin_Unauthorized_Failure() {
+	require := suite.Require()
+
+	_, statusCode, err := makeAdminRequest(http.MethodGet, "/admin/users?email=test1@example.com", "")
+
+	require.NoError(err)
+	require.Equal(http.StatusUnauthorized, statusCode)
+}
+
+func TestAdminIntegration(t *testing.T) {
+	suite.Run(t, new(AdminIntegrationTestSuite))
+}
@@ -11,6 +11,7 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/suite"
@@ -20,13 +21,76 @@ import (
 )
 
 func makeCreateGiftCardRequest(token, requestBody string) (string, int, error) {
-	request, err := http.NewRequest(http.MethodPost, "http://localhost:8080/gift-cards", bytes.NewReader([]byte(requestBody)))
+	responseBody, _, statusCode, err := makeIdempotentCreateGiftCardRequest(token, "", requestBody)
+
+	return responseBody, statusCode, err
+}
+
+// makeIdempotentCreateGiftCardRequest returns the headers of the response
+// next to its body.
+func makeIdempotentCreateGiftCardRequest(token, idempotencyKey, requestBody string) (string, http.Header, int, error) {
+	request, err := http.NewRequest(http.MethodPost, baseURL+"/gift-cards", bytes.NewReader([]byte(requestBody)))
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(echo.HeaderAuthorization, token)
+	if idempotencyKey != "" {
+		request.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	client := http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	defer response.Body.Close()
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, response.Body); err != nil {
+		return "", nil, 0, err
+	}
+
+	return responseBody.String(), response.Header, response.StatusCode, nil
+}
+
+// makeGetGiftCardRequest returns the ETag of the response next to its body.
+func makeGetGiftCardRequest(giftCardID int, token string) (string, string, int, error) {
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/gift-cards/%d", baseURL, giftCardID), nil)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(echo.HeaderAuthorization, token)
+
+	client := http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	defer response.Body.Close()
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, response.Body); err != nil {
+		return "", "", 0, err
+	}
+
+	return responseBody.String(), response.Header.Get(handlers.HeaderETag), response.StatusCode, nil
+}
+
+func makeUpdateGiftCardRequest(giftCardID int, token, ifMatch, requestBody string) (string, int, error) {
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/gift-cards/%d/status", baseURL, giftCardID), bytes.NewReader([]byte(requestBody)))
 	if err != nil {
 		return "", 0, err
 	}
 
 	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	request.Header.Set(echo.HeaderAuthorization, token)
+	if ifMatch != "" {
+		request.Header.Set(handlers.HeaderIfMatch, ifMatch)
+	}
 
 	client := http.Client{}
 	response, err := client.Do(request)
@@ -43,14 +107,17 @@ func makeCreateGiftCardRequest(token, requestBody string) (string, int, error) {
 	return responseBody.String(), response.StatusCode, nil
 }
 
-func makeUpdateGiftCardRequest(giftCardID int, token, requestBody string) (string, int, error) {
-	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://localhost:8080/gift-cards/%d/status", giftCardID), bytes.NewReader([]byte(requestBody)))
+func makeCancelGiftCardRequest(giftCardID int, token, ifMatch string) (string, int, error) {
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/gift-cards/%d/cancel", baseURL, giftCardID), nil)
 	if err != nil {
 		return "", 0, err
 	}
 
 	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	request.Header.Set(echo.HeaderAuthorization, token)
+	if ifMatch != "" {
+		request.Header.Set(handlers.HeaderIfMatch, ifMatch)
+	}
 
 	client := http.Client{}
 	response, err := client.Do(request)
@@ -67,8 +134,8 @@ func makeUpdateGiftCardRequest(giftCardID int, token, requestBody string) (strin
 	return responseBody.String(), response.StatusCode, nil
 }
 
-func makeGetReceivedGiftCardsRequest(token string, status int) (string, int, error) {
-	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:8080/gift-cards/received?status=%d", status), nil)
+func makeRedeemGiftCardRequest(token, requestBody string) (string, int, error) {
+	request, err := http.NewRequest(http.MethodPost, baseURL+"/gift-cards/redeem", bytes.NewReader([]byte(requestBody)))
 	if err != nil {
 		return "", 0, err
 	}
@@ -91,8 +158,55 @@ func makeGetReceivedGiftCardsRequest(token string, status int) (string, int, err
 	return responseBody.String(), response.StatusCode, nil
 }
 
-func makeGetSentGiftCardsRequest(token string, status int) (string, int, error) {
-	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:8080/gift-cards/sent?status=%d", status), nil)
+func makeGetGiftCardCodeRequest(giftCardID int, token string) (string, int, error) {
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/gift-cards/%d/code", baseURL, giftCardID), nil)
+	if err != nil {
+		return "", 0, err
+	}
+
+	request.Header.Set(echo.HeaderAuthorization, token)
+
+	client := http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer response.Body.Close()
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, response.Body); err != nil {
+		return "", 0, err
+	}
+
+	return responseBody.String(), response.StatusCode, nil
+}
+
+func makeGetReceivedGiftCardsRequest(token, query string) (string, int, error) {
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/gift-cards/received?%s", baseURL, query), nil)
+	if err != nil {
+		return "", 0, err
+	}
+
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(echo.HeaderAuthorization, token)
+
+	client := http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer response.Body.Close()
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, response.Body); err != nil {
+		return "", 0, err
+	}
+
+	return responseBody.String(), response.StatusCode, nil
+}
+
+func makeGetSentGiftCardsRequest(token, query string) (string, int, error) {
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/gift-cards/sent?%s", baseURL, query), nil)
 	if err != nil {
 		return "", 0, err
 	}
@@ -135,9 +249,18 @@ func loginUser(email, password string) (string, error) {
 	return loginResponse.Token, nil
 }
 
+// seededGiftCardVersion is the version of the decided gift cards of the seed,
+// which are created pending and then decided on.
+const seededGiftCardVersion uint = 2
+
+// GiftCardsIntegrationTestSuite sends the gift cards it creates from test0,
+// whose token is Token, to test1, whose token is GifteeToken, like the seed
+// does. OutsiderToken belongs to a user who takes part in none of them.
 type GiftCardsIntegrationTestSuite struct {
 	suite.Suite
-	Token string
+	Token         string
+	GifteeToken   string
+	OutsiderToken string
 }
 
 func (suite *GiftCardsIntegrationTestSuite) SetupSuite() {
@@ -146,19 +269,54 @@ func (suite *GiftCardsIntegrationTestSuite) SetupSuite() {
 	token, err := loginUser("test0@example.com", "password")
 	require.NoError(err)
 
+	gifteeToken, err := loginUser("test1@example.com", "password")
+	require.NoError(err)
+
+	_, statusCode, err := makeCreateUserRequest(`{"email": "outsider@example.com", "password": "password"}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	outsiderToken, err := loginUser("outsider@example.com", "password")
+	require.NoError(err)
+
 	suite.Token = token
+	suite.GifteeToken = gifteeToken
+	suite.OutsiderToken = outsiderToken
 }
 
 func (suite *GiftCardsIntegrationTestSuite) TestCreateGiftCard_Success() {
 	require := suite.Require()
-	requestBody := `{"amount": 100, "giftee_id": 20}`
-	expectedResponse := `{"id":3,"amount":100,"status":2,"gifter_id":1,"giftee_id":20}`
+	requestBody := `{"amount": 100, "giftee_id": 2, "expires_at": "2099-01-01T00:00:00Z"}`
+	expectedResponse := `{"id":%d,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":2,"gifter_id":1,"giftee_id":2,"expires_at":"2099-01-01T00:00:00Z","version":1}`
 
 	response, statusCode, err := makeCreateGiftCardRequest(suite.Token, requestBody)
 
 	require.NoError(err)
-	require.JSONEq(expectedResponse, response)
 	require.Equal(http.StatusCreated, statusCode)
+
+	// The id depends on the gift cards the other tests created before.
+	var giftCard handlers.GiftCardResponse
+	require.NoError(json.Unmarshal([]byte(response), &giftCard))
+	require.NotZero(giftCard.ID)
+	require.JSONEq(fmt.Sprintf(expectedResponse, giftCard.ID), response)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestCreateGiftCard_InvalidGiftee_Failure() {
+	require := suite.Require()
+
+	for _, tc := range []struct {
+		requestBody      string
+		expectedResponse string
+	}{
+		{`{"amount": 10, "giftee_id": 1}`, `{"message": "gift card cannot be sent to its sender"}`},
+		{`{"amount": 10, "giftee_id": 1000}`, `{"message": "giftee not found"}`},
+	} {
+		response, statusCode, err := makeCreateGiftCardRequest(suite.Token, tc.requestBody)
+
+		require.NoError(err)
+		require.Equal(http.StatusUnprocessableEntity, statusCode, tc.requestBody)
+		require.JSONEq(tc.expectedResponse, response, tc.requestBody)
+	}
 }
 
 func (suite *GiftCardsIntegrationTestSuite) TestCreateGiftCard_InvalidRequestBody_Failure() {
@@ -173,15 +331,136 @@ func (suite *GiftCardsIntegrationTestSuite) TestCreateGiftCard_InvalidRequestBod
 	require.Equal(http.StatusBadRequest, statusCode)
 }
 
+func (suite *GiftCardsIntegrationTestSuite) TestCreateGiftCard_IdempotentReplay_Success() {
+	require := suite.Require()
+	idempotencyKey := fmt.Sprintf("create-%d", time.Now().UnixNano())
+
+	firstResponse, firstHeader, statusCode, err := makeIdempotentCreateGiftCardRequest(suite.Token, idempotencyKey, `{"amount": 5, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+	require.NotEmpty(firstHeader.Get(handlers.HeaderETag))
+
+	replayResponse, replayHeader, statusCode, err := makeIdempotentCreateGiftCardRequest(suite.Token, idempotencyKey, `{"amount": 5, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+	require.JSONEq(firstResponse, replayResponse)
+	require.Equal(firstHeader.Get(handlers.HeaderETag), replayHeader.Get(handlers.HeaderETag))
+	require.Equal("true", replayHeader.Get("Idempotency-Replayed"))
+
+	response, _, statusCode, err := makeIdempotentCreateGiftCardRequest(suite.Token, idempotencyKey, `{"amount": 6, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusUnprocessableEntity, statusCode)
+	require.JSONEq(`{"message": "idempotency key was already used with a different request"}`, response)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestGetGiftCard_Success() {
+	require := suite.Require()
+
+	createResponse, statusCode, err := makeCreateGiftCardRequest(suite.Token, `{"amount": 10, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	var giftCard handlers.GiftCardResponse
+	require.NoError(json.Unmarshal([]byte(createResponse), &giftCard))
+
+	giftee, err := loginUser("test1@example.com", "password")
+	require.NoError(err)
+
+	response, etag, statusCode, err := makeGetGiftCardRequest(int(giftCard.ID), giftee)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	require.Equal(`"1"`, etag)
+
+	var found handlers.GetGiftCardResponse
+	require.NoError(json.Unmarshal([]byte(response), &found))
+	require.Equal(giftCard, found.GiftCardResponse)
+	require.Equal("pending", found.StatusName)
+	require.False(found.CreatedAt.IsZero())
+	require.False(found.UpdatedAt.IsZero())
+	require.Len(found.History, 1)
+	require.Equal(int(domain.GCSPending), found.History[0].To)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestGetGiftCard_NotParticipant_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "Gift card not found"}`
+
+	response, _, statusCode, err := makeGetGiftCardRequest(1, suite.OutsiderToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, statusCode)
+	require.JSONEq(expectedResponse, response)
+}
+
 func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_Success() {
 	require := suite.Require()
 	requestBody := `{"status": 1}`
 
-	response, statusCode, err := makeUpdateGiftCardRequest(1, suite.Token, requestBody)
+	createResponse, statusCode, err := makeCreateGiftCardRequest(suite.Token, `{"amount": 10, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	var giftCard handlers.GiftCardResponse
+	require.NoError(json.Unmarshal([]byte(createResponse), &giftCard))
+
+	_, etag, statusCode, err := makeGetGiftCardRequest(int(giftCard.ID), suite.GifteeToken)
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	require.Equal(`"1"`, etag)
+
+	response, statusCode, err := makeUpdateGiftCardRequest(int(giftCard.ID), suite.GifteeToken, etag, requestBody)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	require.NoError(json.Unmarshal([]byte(response), &giftCard))
+	require.Equal(int(domain.GCSRejected), giftCard.Status)
+	require.Equal(uint(2), giftCard.Version)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_StaleVersion_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "gift card has been changed since it was read"}`
+
+	createResponse, statusCode, err := makeCreateGiftCardRequest(suite.Token, `{"amount": 10, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	var giftCard handlers.GiftCardResponse
+	require.NoError(json.Unmarshal([]byte(createResponse), &giftCard))
 
+	_, statusCode, err = makeUpdateGiftCardRequest(int(giftCard.ID), suite.GifteeToken, `"1"`, `{"status": 0}`)
 	require.NoError(err)
 	require.Equal(http.StatusOK, statusCode)
-	require.Empty(response)
+
+	response, statusCode, err := makeUpdateGiftCardRequest(int(giftCard.ID), suite.GifteeToken, `"1"`, `{"status": 1}`)
+
+	require.NoError(err)
+	require.Equal(http.StatusPreconditionFailed, statusCode)
+	require.JSONEq(expectedResponse, response)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_IfMatchMissing_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "the If-Match header is required"}`
+
+	response, statusCode, err := makeUpdateGiftCardRequest(1, suite.Token, "", `{"status": 1}`)
+
+	require.NoError(err)
+	require.Equal(http.StatusPreconditionRequired, statusCode)
+	require.JSONEq(expectedResponse, response)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_NotPending_Failure() {
+	require := suite.Require()
+	requestBody := `{"status": 1}`
+	expectedResponse := `{"message": "gift card cannot move from accepted to rejected"}`
+
+	response, statusCode, err := makeUpdateGiftCardRequest(1, suite.GifteeToken, "*", requestBody)
+
+	require.NoError(err)
+	require.Equal(http.StatusConflict, statusCode)
+	require.JSONEq(expectedResponse, response)
 }
 
 func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_InvalidRequestBody_Failure() {
@@ -189,7 +468,7 @@ func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_InvalidRequestBod
 	requestBody := `{"status": "foo"}`
 	expectedResponse := `{"message":"Invalid request body"}`
 
-	response, statusCode, err := makeUpdateGiftCardRequest(1, suite.Token, requestBody)
+	response, statusCode, err := makeUpdateGiftCardRequest(1, suite.Token, "*", requestBody)
 
 	require.NoError(err)
 	require.Equal(http.StatusBadRequest, statusCode)
@@ -199,37 +478,143 @@ func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_InvalidRequestBod
 func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_GiftCardNotFound_Failure() {
 	require := suite.Require()
 	status := domain.GCSRejected
-	requestBody := fmt.Sprintf(`{"status": %v}`, status)
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
 	expectedResponse := `{"message": "Gift card not found"}`
 
-	response, statusCode, err := makeUpdateGiftCardRequest(101, suite.Token, requestBody)
+	response, statusCode, err := makeUpdateGiftCardRequest(101, suite.Token, "*", requestBody)
 
 	require.NoError(err)
 	require.Equal(http.StatusNotFound, statusCode)
 	require.JSONEq(expectedResponse, response)
 }
 
-func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_UnauthorizedUser_Failure() {
+func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_NotParticipant_Failure() {
 	require := suite.Require()
 	status := domain.GCSRejected
-	requestBody := fmt.Sprintf(`{"status": %v}`, status)
-	expectedResponse := `{"message": "forbidden: user 2 is not the receiver of gift card 1"}`
+	requestBody := fmt.Sprintf(`{"status": %d}`, status)
+	expectedResponse := `{"message": "Gift card not found"}`
+
+	response, statusCode, err := makeUpdateGiftCardRequest(1, suite.OutsiderToken, "*", requestBody)
 
-	token, err := loginUser("test1@example.com", "password")
 	require.NoError(err)
+	require.Equal(http.StatusNotFound, statusCode)
+	require.JSONEq(expectedResponse, response)
+}
 
-	response, statusCode, err := makeUpdateGiftCardRequest(1, token, requestBody)
+func (suite *GiftCardsIntegrationTestSuite) TestUpdateGiftCard_Gifter_Failure() {
+	require := suite.Require()
+
+	createResponse, statusCode, err := makeCreateGiftCardRequest(suite.Token, `{"amount": 10, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	var giftCard handlers.GiftCardResponse
+	require.NoError(json.Unmarshal([]byte(createResponse), &giftCard))
+	expectedResponse := fmt.Sprintf(`{"message": "forbidden: user 1 is not the receiver of gift card %d"}`, giftCard.ID)
+
+	response, statusCode, err := makeUpdateGiftCardRequest(int(giftCard.ID), suite.Token, "*", `{"status": 0}`)
 
 	require.NoError(err)
 	require.Equal(http.StatusForbidden, statusCode)
 	require.JSONEq(expectedResponse, response)
 }
 
+func (suite *GiftCardsIntegrationTestSuite) TestCancelGiftCard_Success() {
+	require := suite.Require()
+
+	createResponse, statusCode, err := makeCreateGiftCardRequest(suite.Token, `{"amount": 10, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	var giftCard handlers.GiftCardResponse
+	require.NoError(json.Unmarshal([]byte(createResponse), &giftCard))
+
+	response, statusCode, err := makeCancelGiftCardRequest(int(giftCard.ID), suite.Token, `"1"`)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	require.NoError(json.Unmarshal([]byte(response), &giftCard))
+	require.Equal(int(domain.GCSCancelled), giftCard.Status)
+	require.Equal(uint(2), giftCard.Version)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestCancelGiftCard_NotPending_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "gift card cannot move from accepted to cancelled"}`
+
+	response, statusCode, err := makeCancelGiftCardRequest(1, suite.Token, "*")
+
+	require.NoError(err)
+	require.Equal(http.StatusConflict, statusCode)
+	require.JSONEq(expectedResponse, response)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestCancelGiftCard_NotParticipant_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "Gift card not found"}`
+
+	response, statusCode, err := makeCancelGiftCardRequest(1, suite.OutsiderToken, "*")
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, statusCode)
+	require.JSONEq(expectedResponse, response)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestGetGiftCardCode_NotParticipant_Failure() {
+	require := suite.Require()
+	expectedResponse := `{"message": "Gift card not found"}`
+
+	response, statusCode, err := makeGetGiftCardCodeRequest(1, suite.OutsiderToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, statusCode)
+	require.JSONEq(expectedResponse, response)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestRedeemGiftCard_Success() {
+	require := suite.Require()
+
+	createResponse, statusCode, err := makeCreateGiftCardRequest(suite.Token, `{"amount": 10, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	var giftCard handlers.GiftCardResponse
+	require.NoError(json.Unmarshal([]byte(createResponse), &giftCard))
+
+	_, statusCode, err = makeUpdateGiftCardRequest(int(giftCard.ID), suite.GifteeToken, "*", fmt.Sprintf(`{"status": %d}`, domain.GCSAccepted))
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+
+	codeResponse, statusCode, err := makeGetGiftCardCodeRequest(int(giftCard.ID), suite.GifteeToken)
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+
+	var code handlers.GetGiftCardCodeResponse
+	require.NoError(json.Unmarshal([]byte(codeResponse), &code))
+
+	// The gifter redeems the card as a merchant the giftee pays with it.
+	merchantToken := suite.Token
+
+	response, statusCode, err := makeRedeemGiftCardRequest(merchantToken, fmt.Sprintf(`{"code": %q, "amount": "4.25"}`, code.Code))
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+
+	var redemption handlers.RedeemGiftCardResponse
+	require.NoError(json.Unmarshal([]byte(response), &redemption))
+	require.Equal(giftCard.ID, redemption.GiftCardID)
+	require.Equal("5.75", redemption.RemainingAmount)
+
+	response, statusCode, err = makeRedeemGiftCardRequest(merchantToken, fmt.Sprintf(`{"code": %q, "amount": "6"}`, code.Code))
+	require.NoError(err)
+	require.Equal(http.StatusUnprocessableEntity, statusCode)
+	require.JSONEq(`{"message": "redemption exceeds the remaining amount of the gift card"}`, response)
+}
+
 func (suite *GiftCardsIntegrationTestSuite) TestGetReceivedGiftCards_AcceptedStatus_Success() {
 	require := suite.Require()
-	expectedResponse := `{"gift_cards":[{"id":1,"amount":100,"status":0,"gifter_id":1,"giftee_id":1}, {"id":2,"amount":100,"status":0,"gifter_id":1,"giftee_id":1}],"total":2,"page":1}`
+	expectedResponse := fmt.Sprintf(`{"gift_cards":[{"id":1,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":0,"gifter_id":1,"giftee_id":2,"version":%[1]d}, {"id":2,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":0,"gifter_id":1,"giftee_id":2,"version":%[1]d}],"total":2,"page":1,"page_size":10}`, seededGiftCardVersion)
 
-	response, statusCode, err := makeGetReceivedGiftCardsRequest(suite.Token, int(domain.GCSAccepted))
+	response, statusCode, err := makeGetReceivedGiftCardsRequest(suite.GifteeToken, fmt.Sprintf("status=%d&include_total=true", int(domain.GCSAccepted)))
 
 	require.NoError(err)
 	require.Equal(http.StatusOK, statusCode)
@@ -238,20 +623,73 @@ func (suite *GiftCardsIntegrationTestSuite) TestGetReceivedGiftCards_AcceptedSta
 
 func (suite *GiftCardsIntegrationTestSuite) TestGetReceivedGiftCards_RejectedStatus_Success() {
 	require := suite.Require()
-	expectedResponse := `{"gift_cards":[{"id":3,"amount":100,"status":1,"gifter_id":1,"giftee_id":1}, {"id":4,"amount":100,"status":1,"gifter_id":1,"giftee_id":1}],"total":2,"page":1}`
+	expectedResponse := fmt.Sprintf(`{"gift_cards":[{"id":3,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":1,"gifter_id":1,"giftee_id":2,"version":%[1]d}, {"id":4,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":1,"gifter_id":1,"giftee_id":2,"version":%[1]d}],"total":2,"page":1,"page_size":10}`, seededGiftCardVersion)
 
-	response, statusCode, err := makeGetReceivedGiftCardsRequest(suite.Token, int(domain.GCSRejected))
+	response, statusCode, err := makeGetReceivedGiftCardsRequest(suite.GifteeToken, fmt.Sprintf("status=%d&include_total=true", int(domain.GCSRejected)))
 
 	require.NoError(err)
 	require.Equal(http.StatusOK, statusCode)
 	require.JSONEq(expectedResponse, response)
 }
 
+func (suite *GiftCardsIntegrationTestSuite) TestGetReceivedGiftCards_Query_Success() {
+	require := suite.Require()
+
+	response, statusCode, err := makeGetReceivedGiftCardsRequest(suite.GifteeToken, "status=0,1&sort=created_at&order=desc&page_size=3&include_total=true")
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+
+	var giftCards handlers.GetGiftCards
+	require.NoError(json.Unmarshal([]byte(response), &giftCards))
+	require.Equal(4, *giftCards.Total)
+	require.Equal(3, giftCards.PageSize)
+	require.Equal([]uint{4, 3, 2}, giftCardResponseIDs(giftCards.GiftCards))
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestGetReceivedGiftCards_Cursor_Success() {
+	require := suite.Require()
+	list := func(query string) handlers.GetGiftCards {
+		response, statusCode, err := makeGetReceivedGiftCardsRequest(suite.GifteeToken, "status=0,1&order=desc&page_size=3"+query)
+		require.NoError(err)
+		require.Equal(http.StatusOK, statusCode, response)
+
+		var giftCards handlers.GetGiftCards
+		require.NoError(json.Unmarshal([]byte(response), &giftCards))
+
+		return giftCards
+	}
+
+	first := list("")
+	require.Nil(first.Total)
+	require.Equal([]uint{4, 3, 2}, giftCardResponseIDs(first.GiftCards))
+	require.Empty(first.PrevCursor)
+
+	next := list("&cursor=" + first.NextCursor)
+	require.Zero(next.Page)
+	require.Equal([]uint{1}, giftCardResponseIDs(next.GiftCards))
+	require.Empty(next.NextCursor)
+
+	prev := list("&cursor=" + next.PrevCursor)
+	require.Equal([]uint{4, 3, 2}, giftCardResponseIDs(prev.GiftCards))
+	require.Empty(prev.PrevCursor)
+}
+
+func (suite *GiftCardsIntegrationTestSuite) TestGetReceivedGiftCards_InvalidCursor_Failure() {
+	require := suite.Require()
+
+	response, statusCode, err := makeGetReceivedGiftCardsRequest(suite.GifteeToken, "cursor=foo")
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, statusCode)
+	require.JSONEq(`{"message": "invalid cursor"}`, response)
+}
+
 func (suite *GiftCardsIntegrationTestSuite) TestGetReceivedGiftCards_InvalidGiftCardStatus_Failure() {
 	require := suite.Require()
 	expectedResponse := `{"message": "invalid gift-card status"}`
 
-	response, statusCode, err := makeGetReceivedGiftCardsRequest(suite.Token, 5)
+	response, statusCode, err := makeGetReceivedGiftCardsRequest(suite.GifteeToken, "status=6")
 
 	require.NoError(err)
 	require.Equal(http.StatusBadRequest, statusCode)
@@ -260,9 +698,9 @@ func (suite *GiftCardsIntegrationTestSuite) TestGetReceivedGiftCards_InvalidGift
 
 func (suite *GiftCardsIntegrationTestSuite) TestGetSentGiftCards_AcceptedStatus_Success() {
 	require := suite.Require()
-	expectedResponse := `{"gift_cards":[{"id":1,"amount":100,"status":0,"gifter_id":1,"giftee_id":1}, {"id":2,"amount":100,"status":0,"gifter_id":1,"giftee_id":1}],"total":2,"page":1}`
+	expectedResponse := fmt.Sprintf(`{"gift_cards":[{"id":1,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":0,"gifter_id":1,"giftee_id":2,"version":%[1]d}, {"id":2,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":0,"gifter_id":1,"giftee_id":2,"version":%[1]d}],"total":2,"page":1,"page_size":10}`, seededGiftCardVersion)
 
-	response, statusCode, err := makeGetSentGiftCardsRequest(suite.Token, int(domain.GCSAccepted))
+	response, statusCode, err := makeGetSentGiftCardsRequest(suite.Token, fmt.Sprintf("status=%d&include_total=true", int(domain.GCSAccepted)))
 
 	require.NoError(err)
 	require.Equal(http.StatusOK, statusCode)
@@ -271,9 +709,9 @@ func (suite *GiftCardsIntegrationTestSuite) TestGetSentGiftCards_AcceptedStatus_
 
 func (suite *GiftCardsIntegrationTestSuite) TestGetSentGiftCards_RejectedStatus_Success() {
 	require := suite.Require()
-	expectedResponse := `{"gift_cards":[{"id":3,"amount":100,"status":1,"gifter_id":1,"giftee_id":1}, {"id":4,"amount":100,"status":1,"gifter_id":1,"giftee_id":1}],"total":2,"page":1}`
+	expectedResponse := fmt.Sprintf(`{"gift_cards":[{"id":3,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":1,"gifter_id":1,"giftee_id":2,"version":%[1]d}, {"id":4,"amount":"100.00","remaining_amount":"100.00","currency":"USD","status":1,"gifter_id":1,"giftee_id":2,"version":%[1]d}],"total":2,"page":1,"page_size":10}`, seededGiftCardVersion)
 
-	response, statusCode, err := makeGetSentGiftCardsRequest(suite.Token, int(domain.GCSRejected))
+	response, statusCode, err := makeGetSentGiftCardsRequest(suite.Token, fmt.Sprintf("status=%d&include_total=true", int(domain.GCSRejected)))
 
 	require.NoError(err)
 	require.Equal(http.StatusOK, statusCode)
@@ -284,7 +722,7 @@ func (suite *GiftCardsIntegrationTestSuite) TestGetSentGiftCards_InvalidGiftCard
 	require := suite.Require()
 	expectedResponse := `{"message": "invalid gift-card status"}`
 
-	response, statusCode, err := makeGetSentGiftCardsRequest(suite.Token, 5)
+	response, statusCode, err := makeGetSentGiftCardsRequest(suite.Token, "status=6")
 
 	require.NoError(err)
 	require.Equal(http.StatusBadRequest, statusCode)
@@ -294,3 +732,12 @@ func (suite *GiftCardsIntegrationTestSuite) TestGetSentGiftCards_InvalidGiftCard
 func TestCreateGiftCard(t *testing.T) {
 	suite.Run(t, new(GiftCardsIntegrationTestSuite))
 }
+
+func giftCardResponseIDs(giftCards []handlers.GiftCardResponse) []uint {
+	var ids []uint
+	for _, giftCard := range giftCards {
+		ids = append(ids, giftCard.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,109 @@
+//go:build integration
+// +build integration
+
+package it
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/database"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/migration"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/seed"
+	server "github.com/jmehdipour/gift-card/internal/interface/http"
+)
+
+// baseURL is the address of the server under test. Unless
+// GIFT_CARD_IT_BASE_URL points the suite at a running server, such as the
+// docker-compose one, the server is started in-process on a temporary SQLite
+// database, or on the memory driver if GIFT_CARD_IT_DRIVER is memory.
+var baseURL = os.Getenv("GIFT_CARD_IT_BASE_URL")
+
+func TestMain(m *testing.M) {
+	if baseURL != "" {
+		os.Exit(m.Run())
+	}
+
+	dir, err := os.MkdirTemp("", "gift-card-it")
+	if err != nil {
+		fmt.Println("creating temp dir failed: ", err)
+		os.Exit(1)
+	}
+
+	err = startServer(filepath.Join(dir, "gift-card.db"))
+	if err != nil {
+		fmt.Println("starting server failed: ", err)
+		_ = os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// startServer serves the API on a free local port and waits until it
+// answers. The SQLite database at path is migrated and seeded first, the
+// memory driver seeds itself when the server starts.
+func startServer(path string) error {
+	config.Init("")
+	config.C.Database = config.SQLDatabase{Driver: "sqlite", DB: path}
+	config.C.Seed.AdminPassword = "password"
+	config.C.User.EphemeralSigningKey = true
+	config.C.Audit.Chain.Interval = 50 * time.Millisecond
+	if os.Getenv("GIFT_CARD_IT_DRIVER") == "memory" {
+		config.C.Database = config.SQLDatabase{Driver: "memory"}
+	} else if err := prepareDatabase(); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+
+	config.C.HTTPServer.Address = listener.Addr().String()
+	_ = listener.Close()
+	baseURL = "http://" + config.C.HTTPServer.Address
+
+	go server.NewServer().Serve()
+
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		response, err := http.Get(baseURL + "/")
+		if err == nil {
+			_ = response.Body.Close()
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("server did not start on %s", baseURL)
+}
+
+func prepareDatabase() error {
+	db, err := database.CreateDatabase(config.C.Database.Driver, config.C.Database.String())
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+	migrations, err := migration.Load(database.SQLite)
+	if err != nil {
+		return err
+	}
+
+	if _, err := migration.NewMigrator(db, migrations).Up(); err != nil {
+		return err
+	}
+
+	_, err = seed.Seed(context.Background(), db, config.C.Seed.AdminPassword)
+
+	return err
+}
@@ -5,19 +5,27 @@ package it
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"testing"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/interface/http/handlers"
+	"github.com/jmehdipour/gift-card/internal/service"
 )
 
 func makeCreateUserRequest(requestBody string) (string, int, error) {
-	request, err := http.NewRequest(http.MethodPost, "http://localhost:8080/users/register", bytes.NewReader([]byte(requestBody)))
+	request, err := http.NewRequest(http.MethodPost, baseURL+"/users/register", bytes.NewReader([]byte(requestBody)))
 	if err != nil {
 		return "", 0, err
 	}
@@ -40,7 +48,7 @@ func makeCreateUserRequest(requestBody string) (string, int, error) {
 }
 
 func makeLoginRequest(requestBody string) (string, int, error) {
-	request, err := http.NewRequest(http.MethodPost, "http://localhost:8080/users/login", bytes.NewReader([]byte(requestBody)))
+	request, err := http.NewRequest(http.MethodPost, baseURL+"/users/login", bytes.NewReader([]byte(requestBody)))
 	if err != nil {
 		return "", 0, err
 	}
@@ -62,6 +70,94 @@ func makeLoginRequest(requestBody string) (string, int, error) {
 	return responseBody.String(), response.StatusCode, nil
 }
 
+func makeRefreshRequest(refreshToken string) (string, int, error) {
+	requestBody := fmt.Sprintf(`{"refresh_token": "%s"}`, refreshToken)
+	request, err := http.NewRequest(http.MethodPost, baseURL+"/users/refresh", bytes.NewReader([]byte(requestBody)))
+	if err != nil {
+		return "", 0, err
+	}
+
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	client := http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer response.Body.Close()
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, response.Body); err != nil {
+		return "", 0, err
+	}
+
+	return responseBody.String(), response.StatusCode, nil
+}
+
+func makeLogoutRequest(token, refreshToken string) (string, int, error) {
+	requestBody := fmt.Sprintf(`{"refresh_token": "%s"}`, refreshToken)
+	request, err := http.NewRequest(http.MethodPost, baseURL+"/users/logout", bytes.NewReader([]byte(requestBody)))
+	if err != nil {
+		return "", 0, err
+	}
+
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(echo.HeaderAuthorization, token)
+
+	client := http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer response.Body.Close()
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, response.Body); err != nil {
+		return "", 0, err
+	}
+
+	return responseBody.String(), response.StatusCode, nil
+}
+
+func makeJWKSRequest() (string, int, error) {
+	response, err := http.Get(baseURL + "/.well-known/jwks.json")
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer response.Body.Close()
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, response.Body); err != nil {
+		return "", 0, err
+	}
+
+	return responseBody.String(), response.StatusCode, nil
+}
+
+// publicKey returns the public key of the JSON Web Key.
+func publicKey(jwk service.JWK) (interface{}, error) {
+	switch jwk.KeyType {
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+
+		return ed25519.PublicKey(x), err
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}
+
+	return nil, fmt.Errorf("unknown key type %s", jwk.KeyType)
+}
+
 type CreateUserIntegrationTestSuite struct {
 	suite.Suite
 }
@@ -151,6 +247,132 @@ func (suite *LoginIntegrationTestSuite) TestLogin_UnauthorizedUser_Success() {
 	require.Empty(response)
 }
 
+type SessionIntegrationTestSuite struct {
+	suite.Suite
+}
+
+func (suite *SessionIntegrationTestSuite) login() handlers.LoginHandlerResponse {
+	require := suite.Require()
+
+	response, statusCode, err := makeLoginRequest(`{"email": "test1@example.com", "password": "password"}`)
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+
+	return suite.tokens(response)
+}
+
+func (suite *SessionIntegrationTestSuite) tokens(response string) handlers.LoginHandlerResponse {
+	var tokens handlers.LoginHandlerResponse
+	suite.Require().NoError(json.Unmarshal([]byte(response), &tokens))
+	suite.Require().NotEmpty(tokens.Token)
+	suite.Require().NotEmpty(tokens.RefreshToken)
+
+	return tokens
+}
+
+func (suite *SessionIntegrationTestSuite) TestRefresh_Success() {
+	require := suite.Require()
+	tokens := suite.login()
+
+	response, statusCode, err := makeRefreshRequest(tokens.RefreshToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	refreshed := suite.tokens(response)
+	require.NotEqual(tokens.Token, refreshed.Token)
+	require.NotEqual(tokens.RefreshToken, refreshed.RefreshToken)
+
+	_, statusCode, err = makeGetReceivedGiftCardsRequest(refreshed.Token, "")
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+}
+
+func (suite *SessionIntegrationTestSuite) TestRefresh_InvalidToken_Failure() {
+	require := suite.Require()
+
+	response, statusCode, err := makeRefreshRequest("unknown")
+
+	require.NoError(err)
+	require.Equal(http.StatusUnauthorized, statusCode)
+	require.JSONEq(`{"message":"invalid refresh token"}`, response)
+}
+
+// TestRefresh_Reused_Failure replays a used refresh token, which revokes the
+// session so that the token it was rotated into stops working too.
+func (suite *SessionIntegrationTestSuite) TestRefresh_Reused_Failure() {
+	require := suite.Require()
+	tokens := suite.login()
+	response, statusCode, err := makeRefreshRequest(tokens.RefreshToken)
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	refreshed := suite.tokens(response)
+
+	response, statusCode, err = makeRefreshRequest(tokens.RefreshToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusUnauthorized, statusCode)
+	require.JSONEq(`{"message":"refresh token was already used, the session is revoked"}`, response)
+
+	_, statusCode, err = makeRefreshRequest(refreshed.RefreshToken)
+	require.NoError(err)
+	require.Equal(http.StatusUnauthorized, statusCode)
+}
+
+func (suite *SessionIntegrationTestSuite) TestLogout_Success() {
+	require := suite.Require()
+	tokens := suite.login()
+
+	_, statusCode, err := makeLogoutRequest(tokens.Token, tokens.RefreshToken)
+
+	require.NoError(err)
+	require.Equal(http.StatusNoContent, statusCode)
+
+	response, statusCode, err := makeGetReceivedGiftCardsRequest(tokens.Token, "")
+	require.NoError(err)
+	require.Equal(http.StatusUnauthorized, statusCode)
+	require.JSONEq(`{"message":"invalid token"}`, response)
+
+	_, statusCode, err = makeRefreshRequest(tokens.RefreshToken)
+	require.NoError(err)
+	require.Equal(http.StatusUnauthorized, statusCode)
+}
+
+// TestJWKS_Success verifies an access token the way another service would,
+// with nothing but the published keys.
+func (suite *SessionIntegrationTestSuite) TestJWKS_Success() {
+	require := suite.Require()
+	tokens := suite.login()
+
+	response, statusCode, err := makeJWKSRequest()
+
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+	var jwks service.JWKS
+	require.NoError(json.Unmarshal([]byte(response), &jwks))
+	require.NotEmpty(jwks.Keys)
+
+	token, err := jwt.Parse(tokens.Token, func(token *jwt.Token) (interface{}, error) {
+		for _, jwk := range jwks.Keys {
+			if jwk.KeyID == token.Header["kid"] && jwk.Algorithm == token.Method.Alg() {
+				return publicKey(jwk)
+			}
+		}
+
+		return nil, fmt.Errorf("no key %v", token.Header["kid"])
+	}, jwt.WithValidMethods([]string{"RS256", "EdDSA"}), jwt.WithIssuer("gift-card"), jwt.WithAudience("gift-card"))
+	require.NoError(err)
+	require.True(token.Valid)
+}
+
+func (suite *SessionIntegrationTestSuite) TestLogout_Unauthorized_Failure() {
+	require := suite.Require()
+
+	_, statusCode, err := makeLogoutRequest("invalid", "")
+
+	require.NoError(err)
+	require.Equal(http.StatusUnauthorized, statusCode)
+}
+
 func TestCreateUserIntegration(t *testing.T) {
 	suite.Run(t, new(CreateUserIntegrationTestSuite))
 }
@@ -158,3 +380,7 @@ func TestCreateUserIntegration(t *testing.T) {
 func TestLoginIntegration(t *testing.T) {
 	suite.Run(t, new(LoginIntegrationTestSuite))
 }
+
+func TestSessionIntegration(t *testing.T) {
+	suite.Run(t, new(SessionIntegrationTestSuite))
+}
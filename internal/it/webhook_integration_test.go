@@ -0,0 +1,122 @@
+//go:build integration
+// +build integration
+
+package it
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/interface/http/handlers"
+)
+
+func makeWebhookRequest(method, path, token, requestBody string) (string, int, error) {
+	request, err := http.NewRequest(method, baseURL+path, bytes.NewReader([]byte(requestBody)))
+	if err != nil {
+		return "", 0, err
+	}
+
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.Header.Set(echo.HeaderAuthorization, token)
+
+	client := http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer response.Body.Close()
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, response.Body); err != nil {
+		return "", 0, err
+	}
+
+	return responseBody.String(), response.StatusCode, nil
+}
+
+type WebhooksIntegrationTestSuite struct {
+	suite.Suite
+	Token string
+}
+
+func (suite *WebhooksIntegrationTestSuite) SetupSuite() {
+	require := suite.Require()
+
+	token, err := loginUser("test0@example.com", "password")
+	require.NoError(err)
+
+	suite.Token = token
+}
+
+func (suite *WebhooksIntegrationTestSuite) TestCreateWebhook_InvalidURL_Failure() {
+	require := suite.Require()
+	expectedResponse := fmt.Sprintf(`{"message": "%s"}`, domain.ErrInvalidWebhookURL)
+
+	response, statusCode, err := makeWebhookRequest(http.MethodPost, "/webhooks", suite.Token, `{"url": "example.com"}`)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, statusCode)
+	require.JSONEq(expectedResponse, response)
+}
+
+func (suite *WebhooksIntegrationTestSuite) TestCreateWebhook_InternalURL_Failure() {
+	require := suite.Require()
+	expectedResponse := fmt.Sprintf(`{"message": "%s"}`, domain.ErrWebhookURLNotPublic)
+
+	response, statusCode, err := makeWebhookRequest(http.MethodPost, "/webhooks", suite.Token, `{"url": "http://127.0.0.1:1/hook"}`)
+
+	require.NoError(err)
+	require.Equal(http.StatusBadRequest, statusCode)
+	require.JSONEq(expectedResponse, response)
+}
+
+func (suite *WebhooksIntegrationTestSuite) TestWebhookDeliveries_StatusChange_Success() {
+	require := suite.Require()
+
+	response, statusCode, err := makeWebhookRequest(http.MethodPost, "/webhooks", suite.Token, `{"url": "https://example.com/hook"}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	var webhook handlers.WebhookResponse
+	require.NoError(json.Unmarshal([]byte(response), &webhook))
+	require.Len(webhook.Secret, 64)
+
+	response, statusCode, err = makeCreateGiftCardRequest(suite.Token, `{"amount": 10, "giftee_id": 2}`)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, statusCode)
+
+	var giftCard handlers.GiftCardResponse
+	require.NoError(json.Unmarshal([]byte(response), &giftCard))
+
+	gifteeToken, err := loginUser("test1@example.com", "password")
+	require.NoError(err)
+
+	_, statusCode, err = makeUpdateGiftCardRequest(int(giftCard.ID), gifteeToken, `"1"`, `{"status": 1}`)
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+
+	response, statusCode, err = makeWebhookRequest(http.MethodGet, fmt.Sprintf("/webhooks/%d/deliveries", webhook.ID), suite.Token, "")
+	require.NoError(err)
+	require.Equal(http.StatusOK, statusCode)
+
+	var deliveries handlers.GetWebhookDeliveriesResponse
+	require.NoError(json.Unmarshal([]byte(response), &deliveries))
+	require.Equal(1, deliveries.Total)
+	require.Equal(string(domain.EventGiftCardStatusChanged), deliveries.Deliveries[0].EventType)
+
+	_, statusCode, err = makeWebhookRequest(http.MethodDelete, fmt.Sprintf("/webhooks/%d", webhook.ID), suite.Token, "")
+	require.NoError(err)
+	require.Equal(http.StatusNoContent, statusCode)
+}
+
+func TestWebhooksIntegration(t *testing.T) {
+	suite.Run(t, new(WebhooksIntegrationTestSuite))
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+// auditVerifyBatchSize is the number of events Verify reads at a time.
+const auditVerifyBatchSize = 500
+
+// AdminRequest is a request to the admin API, for the audit log.
+type AdminRequest struct {
+	Method string            `json:"method"`
+	Path   string            `json:"path"`
+	Params map[string]string `json:"params,omitempty"`
+	Query  string            `json:"query,omitempty"`
+	Status int               `json:"status"`
+}
+
+type AuditService interface {
+	RecordAdminRequest(ctx context.Context, actorID uint, request AdminRequest) error
+	FindEvents(ctx context.Context, query repository.AuditQuery) ([]domain.AuditEvent, error)
+	Verify(ctx context.Context) (*domain.AuditChainReport, error)
+	ChainPendingEvents(ctx context.Context, batchSize int) (int, error)
+}
+
+type auditService struct {
+	auditRepository repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditService{auditRepository: auditRepo}
+}
+
+// RecordAdminRequest records the request of the staff member, denied ones
+// included.
+func (s *auditService) RecordAdminRequest(ctx context.Context, actorID uint, request AdminRequest) error {
+	event, err := domain.NewAuditEvent(ctx, domain.AuditAdminRequest, &actorID, domain.AuditTargetRequest, nil, nil, request)
+	if err != nil {
+		return err
+	}
+
+	return s.auditRepository.Record(ctx, &event)
+}
+
+// FindEvents lists the events of the query newest first, DefaultAuditPageSize
+// of them unless the query limits them to at most MaxAuditPageSize.
+func (s *auditService) FindEvents(ctx context.Context, query repository.AuditQuery) ([]domain.AuditEvent, error) {
+	if query.Limit <= 0 {
+		query.Limit = repository.DefaultAuditPageSize
+	}
+
+	if query.Limit > repository.MaxAuditPageSize {
+		query.Limit = repository.MaxAuditPageSize
+	}
+
+	return s.auditRepository.Find(ctx, query)
+}
+
+// ChainPendingEvents chains up to batchSize of the queued events into the log,
+// oldest first, and returns how many it chained.
+func (s *auditService) ChainPendingEvents(ctx context.Context, batchSize int) (int, error) {
+	return s.auditRepository.ChainPending(ctx, batchSize)
+}
+
+// Verify walks the whole log and checks that its events add up to a chain
+// that ends at the last event recorded. The last hash is read first, events
+// recorded meanwhile extend the chain past it.
+func (s *auditService) Verify(ctx context.Context) (*domain.AuditChainReport, error) {
+	lastHash, err := s.auditRepository.LastHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.AuditChainReport{}
+	var prevHash string
+	var lastID uint
+	seen := lastHash == ""
+	for {
+		events, err := s.auditRepository.FindAfter(ctx, lastID, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Events += len(events)
+		for _, event := range events {
+			if event.Hash == lastHash {
+				seen = true
+			}
+		}
+
+		prevHash, report.Break = domain.VerifyAuditChain(prevHash, events)
+		if report.Break != nil {
+			return report, nil
+		}
+
+		if len(events) > 0 {
+			lastID = events[len(events)-1].ID
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			break
+		}
+	}
+
+	if !seen {
+		report.Break = &domain.AuditChainBreak{EventID: lastID, Reason: "is not the last event recorded"}
+	}
+
+	return report, nil
+}
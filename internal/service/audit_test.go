@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+type AuditServiceTestSuite struct {
+	suite.Suite
+	auditRepo    *repository.AuditRepositoryMock
+	auditService *auditService
+}
+
+func (suite *AuditServiceTestSuite) SetupTest() {
+	suite.auditRepo = new(repository.AuditRepositoryMock)
+	suite.auditService = &auditService{
+		auditRepository: suite.auditRepo,
+	}
+}
+
+func (suite *AuditServiceTestSuite) TestNewAuditService() {
+	require := suite.Require()
+
+	service := NewAuditService(suite.auditRepo)
+
+	require.NotNil(service)
+}
+
+func (suite *AuditServiceTestSuite) TestRecordAdminRequest_Success() {
+	require := suite.Require()
+	ctx := domain.WithRequestInfo(context.Background(), domain.RequestInfo{ID: "req", IP: "192.0.2.1", UserAgent: "curl"})
+
+	suite.auditRepo.On("Record", mock.Anything, mock.MatchedBy(func(e *domain.AuditEvent) bool {
+		return e.Action == domain.AuditAdminRequest && *e.ActorID == 3 && e.TargetType == domain.AuditTargetRequest &&
+			e.TargetID == nil && e.RequestID == "req" && e.IP == "192.0.2.1" && e.UserAgent == "curl" &&
+			string(e.After) == `{"method":"GET","path":"/admin/users","query":"email=foo%40example.com","status":200}`
+	})).Return(nil)
+	err := suite.auditService.RecordAdminRequest(ctx, 3, AdminRequest{Method: "GET", Path: "/admin/users", Query: "email=foo%40example.com", Status: 200})
+
+	require.NoError(err)
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuditServiceTestSuite) TestFindEvents_Limit_Success() {
+	require := suite.Require()
+	events := []domain.AuditEvent{{ID: 1}}
+
+	for limit, expected := range map[int]int{0: repository.DefaultAuditPageSize, 10: 10, 1000: repository.MaxAuditPageSize} {
+		call := suite.auditRepo.On("Find", mock.Anything, repository.AuditQuery{Action: domain.AuditUserLoggedIn, Limit: expected}).Return(events, nil)
+		found, err := suite.auditService.FindEvents(context.Background(), repository.AuditQuery{Action: domain.AuditUserLoggedIn, Limit: limit})
+		call.Unset()
+
+		require.NoError(err)
+		require.Equal(events, found)
+	}
+}
+
+func (suite *AuditServiceTestSuite) TestVerify_Success() {
+	require := suite.Require()
+	events := chainAuditEvents(3)
+
+	defer suite.auditRepo.On("LastHash", mock.Anything).Return(events[2].Hash, nil).Unset()
+	defer suite.auditRepo.On("FindAfter", mock.Anything, uint(0), auditVerifyBatchSize).Return(events, nil).Unset()
+	report, err := suite.auditService.Verify(context.Background())
+
+	require.NoError(err)
+	require.True(report.OK())
+	require.Equal(3, report.Events)
+}
+
+func (suite *AuditServiceTestSuite) TestVerify_Empty_Success() {
+	require := suite.Require()
+
+	defer suite.auditRepo.On("LastHash", mock.Anything).Return("", nil).Unset()
+	defer suite.auditRepo.On("FindAfter", mock.Anything, uint(0), auditVerifyBatchSize).Return(nil, nil).Unset()
+	report, err := suite.auditService.Verify(context.Background())
+
+	require.NoError(err)
+	require.True(report.OK())
+	require.Zero(report.Events)
+}
+
+func (suite *AuditServiceTestSuite) TestVerify_Tampered_Failure() {
+	require := suite.Require()
+	events := chainAuditEvents(3)
+	events[1].After = []byte(`{"email":"bar@example.com"}`)
+
+	defer suite.auditRepo.On("LastHash", mock.Anything).Return(events[2].Hash, nil).Unset()
+	defer suite.auditRepo.On("FindAfter", mock.Anything, uint(0), auditVerifyBatchSize).Return(events, nil).Unset()
+	report, err := suite.auditService.Verify(context.Background())
+
+	require.NoError(err)
+	require.False(report.OK())
+	require.Equal(uint(2), report.Break.EventID)
+}
+
+func (suite *AuditServiceTestSuite) TestVerify_Removed_Failure() {
+	require := suite.Require()
+	events := chainAuditEvents(3)
+
+	defer suite.auditRepo.On("LastHash", mock.Anything).Return(events[2].Hash, nil).Unset()
+	defer suite.auditRepo.On("FindAfter", mock.Anything, uint(0), auditVerifyBatchSize).Return(events[:2], nil).Unset()
+	report, err := suite.auditService.Verify(context.Background())
+
+	require.NoError(err)
+	require.False(report.OK())
+	require.Equal(uint(2), report.Break.EventID)
+}
+
+func (suite *AuditServiceTestSuite) TestVerify_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("database error")
+
+	defer suite.auditRepo.On("LastHash", mock.Anything).Return("", expectedError).Unset()
+	report, err := suite.auditService.Verify(context.Background())
+
+	require.ErrorIs(err, expectedError)
+	require.Nil(report)
+}
+
+func (suite *AuditServiceTestSuite) TestChainPendingEvents_Success() {
+	require := suite.Require()
+
+	defer suite.auditRepo.On("ChainPending", mock.Anything, 100).Return(3, nil).Unset()
+	chained, err := suite.auditService.ChainPendingEvents(context.Background(), 100)
+
+	require.NoError(err)
+	require.Equal(3, chained)
+}
+
+func (suite *AuditServiceTestSuite) TestChainPendingEvents_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("database error")
+
+	defer suite.auditRepo.On("ChainPending", mock.Anything, 100).Return(0, expectedError).Unset()
+	_, err := suite.auditService.ChainPendingEvents(context.Background(), 100)
+
+	require.ErrorIs(err, expectedError)
+}
+
+// chainAuditEvents returns a chain of n login events.
+func chainAuditEvents(n int) []domain.AuditEvent {
+	events := make([]domain.AuditEvent, n)
+	var prevHash string
+	for i := range events {
+		events[i] = domain.AuditEvent{ID: uint(i + 1), Action: domain.AuditUserLoginFailed, TargetType: domain.AuditTargetUser, After: []byte(`{"email":"foo@example.com"}`)}
+		events[i].Chain(prevHash, time.Now())
+		prevHash = events[i].Hash
+	}
+
+	return events
+}
+
+func TestAuditService(t *testing.T) {
+	suite.Run(t, new(AuditServiceTestSuite))
+}
@@ -1,45 +1,341 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/domain"
 	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
 )
 
+// Tokens are the tokens of a session. The access token authenticates requests
+// until ExpiresAt, the refresh token is exchanged for new tokens once.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// AccessToken is what a valid access token says. Permissions are the ones
+// the roles of the user granted when the token was issued.
+type AccessToken struct {
+	ID          string
+	UserID      uint
+	Roles       []domain.Role
+	Permissions []domain.Permission
+	ExpiresAt   time.Time
+}
+
+// Can reports whether the token grants the permission.
+func (t AccessToken) Can(permission domain.Permission) bool {
+	return domain.HasPermission(t.Permissions, permission)
+}
+
 type AuthService interface {
-	Login(email, password string) (string, error)
+	Login(ctx context.Context, email, password string) (*Tokens, error)
+	Refresh(ctx context.Context, refreshToken string) (*Tokens, error)
+	Logout(ctx context.Context, accessToken AccessToken, refreshToken string) error
+	Authenticate(ctx context.Context, token string) (*AccessToken, error)
 }
 
 type authService struct {
-	userRepository repository.UserRepository
+	userRepository         repository.UserRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	revokedTokenRepository repository.RevokedTokenRepository
+	loginFailureRepository repository.LoginFailureRepository
+	auditRepository        repository.AuditRepository
+	keys                   *KeySet
+	config                 config.User
+}
+
+func NewAuthService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, revokedTokenRepo repository.RevokedTokenRepository, loginFailureRepo repository.LoginFailureRepository, auditRepo repository.AuditRepository, keys *KeySet, cfg config.User) AuthService {
+	return &authService{
+		userRepository:         userRepo,
+		refreshTokenRepository: refreshTokenRepo,
+		revokedTokenRepository: revokedTokenRepo,
+		loginFailureRepository: loginFailureRepo,
+		auditRepository:        auditRepo,
+		keys:                   keys,
+		config:                 cfg,
+	}
+}
+
+// Login starts a session of the user, it returns nil if the credentials are
+// wrong, whether the email is registered or not. Both outcomes are recorded
+// in the audit log.
+//
+// Failed logins are counted per email and per client IP. Every login is
+// counted as failed before its password is checked, so concurrent guesses are
+// throttled by each other, and a login of either key that has to wait fails
+// with a *domain.LoginBlockedError. A successful login forgets the failures
+// of its email and takes back its attempt of the IP.
+func (s *authService) Login(ctx context.Context, email, password string) (*Tokens, error) {
+	now := time.Now()
+	throttles := s.loginThrottles(ctx, email)
+	for i, throttle := range throttles {
+		if _, err := s.loginFailureRepository.Attempt(ctx, throttle.key, now, throttle.policy); err != nil {
+			if releaseErr := s.releaseLogin(ctx, throttles[:i]); releaseErr != nil {
+				return nil, releaseErr
+			}
+
+			return nil, err
+		}
+	}
+
+	user, err := s.userRepository.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a user the password is checked all the same, so that the time
+	// a login takes does not tell whether the email is registered.
+	if user == nil {
+		domain.CheckDummyPassword(password)
+
+		return nil, s.recordLoginFailed(ctx, email, nil)
+	}
+
+	if !user.CheckPassword(password) {
+		return nil, s.recordLoginFailed(ctx, email, user)
+	}
+
+	if err := s.loginFailureRepository.Reset(ctx, throttles[0].key); err != nil {
+		return nil, err
+	}
+
+	if err := s.releaseLogin(ctx, throttles[1:]); err != nil {
+		return nil, err
+	}
+
+	familyID, err := domain.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issue(ctx, user, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := domain.NewAuditEvent(ctx, domain.AuditUserLoggedIn, &user.ID, domain.AuditTargetUser, &user.ID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditRepository.Record(ctx, &event); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// loginThrottle is a key failed logins are counted by and its policy.
+type loginThrottle struct {
+	key    string
+	policy domain.LoginPolicy
 }
 
-func NewAuthService(userRepo repository.UserRepository) AuthService {
-	return &authService{userRepository: userRepo}
+// loginThrottles returns the throttles of a login, the one of the email
+// first. The IP of the request is not known outside of a request.
+func (s *authService) loginThrottles(ctx context.Context, email string) []loginThrottle {
+	throttles := []loginThrottle{{key: domain.AccountLoginKey(email), policy: domain.LoginPolicy(s.config.Login.Account)}}
+	if ip := domain.RequestInfoFrom(ctx).IP; ip != "" {
+		throttles = append(throttles, loginThrottle{key: domain.IPLoginKey(ip), policy: domain.LoginPolicy(s.config.Login.IP)})
+	}
+
+	return throttles
+}
+
+// releaseLogin takes back the attempts of a login that were counted against
+// the throttles.
+func (s *authService) releaseLogin(ctx context.Context, throttles []loginThrottle) error {
+	for _, throttle := range throttles {
+		if err := s.loginFailureRepository.Release(ctx, throttle.key, throttle.policy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailed records a failed login with the email that was tried.
+// The target is the user of the email, nil if there is none. Anyone can fail
+// to log in, so the event is only queued and a worker chains it into the
+// audit log later instead of making every attempt wait for the chain.
+func (s *authService) recordLoginFailed(ctx context.Context, email string, user *domain.User) error {
+	var targetID *uint
+	if user != nil {
+		targetID = &user.ID
+	}
+
+	event, err := domain.NewAuditEvent(ctx, domain.AuditUserLoginFailed, nil, domain.AuditTargetUser, targetID, nil, map[string]string{"email": email})
+	if err != nil {
+		return err
+	}
+
+	return s.auditRepository.Enqueue(ctx, &event)
 }
 
-func (s *authService) Login(email, password string) (string, error) {
-	user, err := s.userRepository.FindByEmail(email)
+// Refresh exchanges the refresh token for new tokens of its session. A token
+// that was used already revokes the session, whoever has the newer tokens
+// has to log in again.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	token, err := s.refreshTokenRepository.FindByHash(ctx, domain.HashRefreshToken(refreshToken))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if user == nil || !user.CheckPassword(password) {
-		return "", nil
+	if token == nil {
+		return nil, domain.ErrInvalidRefreshToken
 	}
 
-	token := jwt.New(jwt.SigningMethodHS256)
-	token.Claims = jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+	if token.IsUsed() {
+		return nil, s.revokeReused(ctx, token.FamilyID)
 	}
-	signedToken, err := token.SignedString([]byte(config.C.User.Secret))
+
+	if !token.IsActive(time.Now()) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	err = s.refreshTokenRepository.Use(ctx, token.ID)
+	if errors.Is(err, domain.ErrRefreshTokenReused) {
+		return nil, s.revokeReused(ctx, token.FamilyID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The roles of the user are read again, so a refresh picks up the roles
+	// that were granted or taken away since the login.
+	user, err := s.userRepository.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	return s.issue(ctx, user, token.FamilyID)
+}
+
+func (s *authService) revokeReused(ctx context.Context, familyID string) error {
+	if err := s.refreshTokenRepository.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+
+	return domain.ErrRefreshTokenReused
+}
+
+// Logout revokes the access token and the session of the refresh token, if
+// it is one of the same user.
+func (s *authService) Logout(ctx context.Context, accessToken AccessToken, refreshToken string) error {
+	err := s.revokedTokenRepository.Revoke(ctx, accessToken.ID, accessToken.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	token, err := s.refreshTokenRepository.FindByHash(ctx, domain.HashRefreshToken(refreshToken))
+	if err != nil {
+		return err
+	}
+
+	if token == nil || token.UserID != accessToken.UserID {
+		return nil
+	}
+
+	return s.refreshTokenRepository.RevokeFamily(ctx, token.FamilyID)
+}
+
+// Authenticate returns what the access token says if it is valid and was not
+// revoked, domain.ErrInvalidAccessToken otherwise. The token must be signed
+// by one of the keys, with the algorithm of that key, and name the issuer
+// and audience of the config.
+func (s *authService) Authenticate(ctx context.Context, token string) (*AccessToken, error) {
+	var claims accessTokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, s.keys.Keyfunc,
+		jwt.WithValidMethods(s.keys.Algorithms()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(s.config.Issuer),
+		jwt.WithAudience(s.config.Audience),
+	)
+	if err != nil || claims.ID == "" || claims.UserID == 0 {
+		return nil, domain.ErrInvalidAccessToken
+	}
+
+	revoked, err := s.revokedTokenRepository.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		return nil, domain.ErrInvalidAccessToken
+	}
+
+	return &AccessToken{
+		ID:          claims.ID,
+		UserID:      claims.UserID,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		ExpiresAt:   claims.ExpiresAt.Time,
+	}, nil
+}
+
+type accessTokenClaims struct {
+	UserID      uint                `json:"user_id"`
+	Roles       []domain.Role       `json:"roles,omitempty"`
+	Permissions []domain.Permission `json:"permissions,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// issue returns new tokens of the session of the family.
+func (s *authService) issue(ctx context.Context, user *domain.User, familyID string) (*Tokens, error) {
+	jti, err := domain.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.AccessTokenTTL).Truncate(time.Second)
+	accessToken, err := s.keys.Sign(accessTokenClaims{
+		UserID:      user.ID,
+		Roles:       user.Roles,
+		Permissions: user.Permissions(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    s.config.Issuer,
+			Audience:  jwt.ClaimStrings{s.config.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, tokenHash, err := domain.NewRefreshTokenSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.refreshTokenRepository.Create(ctx, &domain.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(s.config.RefreshTokenTTL),
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return signedToken, nil
+	return &Tokens{AccessToken: accessToken, RefreshToken: refreshToken, ExpiresAt: expiresAt}, nil
 }
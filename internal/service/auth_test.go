@@ -0,0 +1,467 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+var (
+	accountLoginPolicy = config.LoginPolicy{Window: 15 * time.Minute, FreeFailures: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second, LockoutAfter: 10, LockoutDuration: 15 * time.Minute}
+	ipLoginPolicy      = config.LoginPolicy{Window: 15 * time.Minute, FreeFailures: 20, BaseDelay: time.Second, MaxDelay: 30 * time.Second, LockoutAfter: 100, LockoutDuration: 15 * time.Minute}
+)
+
+type AuthServiceTestSuite struct {
+	suite.Suite
+	userRepo         *repository.UserRepositoryMock
+	refreshTokenRepo *repository.RefreshTokenRepositoryMock
+	revokedTokenRepo *repository.RevokedTokenRepositoryMock
+	loginFailureRepo *repository.LoginFailureRepositoryMock
+	auditRepo        *repository.AuditRepositoryMock
+	authService      AuthService
+	currentKey       ed25519.PrivateKey
+	previousKey      *rsa.PrivateKey
+	keys             *KeySet
+}
+
+// SetupSuite creates the keys once, generating RSA keys is slow. The EdDSA
+// key is the active one, the RS256 key the one it was rotated from.
+func (suite *AuthServiceTestSuite) SetupSuite() {
+	require := suite.Require()
+
+	var err error
+	_, suite.currentKey, err = ed25519.GenerateKey(rand.Reader)
+	require.NoError(err)
+	suite.previousKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+
+	suite.keys, err = NewKeySet("current",
+		SigningKey{ID: "current", PrivateKey: suite.currentKey},
+		SigningKey{ID: "previous", PrivateKey: suite.previousKey},
+	)
+	require.NoError(err)
+}
+
+func (suite *AuthServiceTestSuite) SetupTest() {
+	suite.userRepo = new(repository.UserRepositoryMock)
+	suite.refreshTokenRepo = new(repository.RefreshTokenRepositoryMock)
+	suite.revokedTokenRepo = new(repository.RevokedTokenRepositoryMock)
+	suite.loginFailureRepo = new(repository.LoginFailureRepositoryMock)
+	suite.auditRepo = new(repository.AuditRepositoryMock)
+	suite.authService = NewAuthService(suite.userRepo, suite.refreshTokenRepo, suite.revokedTokenRepo, suite.loginFailureRepo, suite.auditRepo, suite.keys, config.User{
+		Issuer:          "gift-card",
+		Audience:        "gift-card-api",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+		Login: config.Login{
+			Account: accountLoginPolicy,
+			IP:      ipLoginPolicy,
+		},
+	})
+}
+
+func (suite *AuthServiceTestSuite) TestLogin_Success() {
+	require := suite.Require()
+	user := &domain.User{ID: 10, Email: "foo@example.com"}
+	require.NoError(user.SetPassword("password"))
+
+	var created *domain.RefreshToken
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "account:foo@example.com", mock.AnythingOfType("time.Time"), domain.LoginPolicy(accountLoginPolicy)).
+		Return(&domain.LoginFailures{Key: "account:foo@example.com", Count: 1}, nil)
+	suite.loginFailureRepo.On("Reset", mock.Anything, "account:foo@example.com").Return(nil)
+	suite.userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+	suite.refreshTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.RefreshToken")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*domain.RefreshToken) }).
+		Return(nil)
+	suite.revokedTokenRepo.On("IsRevoked", mock.Anything, mock.Anything).Return(false, nil)
+	suite.auditRepo.On("Record", mock.Anything, mock.MatchedBy(func(e *domain.AuditEvent) bool {
+		return e.Action == domain.AuditUserLoggedIn && *e.ActorID == 10 && *e.TargetID == 10
+	})).Return(nil)
+
+	tokens, err := suite.authService.Login(context.Background(), user.Email, "password")
+
+	require.NoError(err)
+	require.NotNil(tokens)
+	require.Equal(uint(10), created.UserID)
+	require.Len(created.FamilyID, 32)
+	require.Equal(domain.HashRefreshToken(tokens.RefreshToken), created.TokenHash)
+	require.WithinDuration(time.Now().Add(time.Hour), created.ExpiresAt, time.Minute)
+	require.WithinDuration(time.Now().Add(15*time.Minute), tokens.ExpiresAt, time.Minute)
+
+	accessToken, err := suite.authService.Authenticate(context.Background(), tokens.AccessToken)
+	require.NoError(err)
+	require.Equal(uint(10), accessToken.UserID)
+	require.Len(accessToken.ID, 32)
+	require.Equal(tokens.ExpiresAt, accessToken.ExpiresAt)
+	require.Empty(accessToken.Roles)
+	require.False(accessToken.Can(domain.PermissionReadUsers))
+}
+
+func (suite *AuthServiceTestSuite) TestLogin_WrongPassword_Failure() {
+	require := suite.Require()
+	user := &domain.User{ID: 10, Email: "foo@example.com"}
+	require.NoError(user.SetPassword("password"))
+
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "account:foo@example.com", mock.AnythingOfType("time.Time"), domain.LoginPolicy(accountLoginPolicy)).
+		Return(&domain.LoginFailures{Key: "account:foo@example.com", Count: 1}, nil)
+	suite.userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+	suite.auditRepo.On("Enqueue", mock.Anything, mock.MatchedBy(func(e *domain.AuditEvent) bool {
+		return e.Action == domain.AuditUserLoginFailed && e.ActorID == nil && *e.TargetID == 10 &&
+			string(e.After) == `{"email":"foo@example.com"}`
+	})).Return(nil)
+
+	tokens, err := suite.authService.Login(context.Background(), user.Email, "wrong")
+
+	require.NoError(err)
+	require.Nil(tokens)
+	suite.refreshTokenRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+	suite.loginFailureRepo.AssertNotCalled(suite.T(), "Reset", mock.Anything, mock.Anything)
+	suite.loginFailureRepo.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything)
+	suite.auditRepo.AssertExpectations(suite.T())
+	suite.loginFailureRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestLogin_UnknownEmail_Failure() {
+	require := suite.Require()
+
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "account:nobody@example.com", mock.Anything, mock.Anything).Return(&domain.LoginFailures{Count: 1}, nil)
+	suite.userRepo.On("FindByEmail", mock.Anything, "nobody@example.com").Return(nil, nil)
+	suite.auditRepo.On("Enqueue", mock.Anything, mock.MatchedBy(func(e *domain.AuditEvent) bool {
+		return e.Action == domain.AuditUserLoginFailed && e.ActorID == nil && e.TargetID == nil
+	})).Return(nil)
+
+	tokens, err := suite.authService.Login(context.Background(), "nobody@example.com", "password")
+
+	require.NoError(err)
+	require.Nil(tokens)
+	suite.auditRepo.AssertExpectations(suite.T())
+	suite.loginFailureRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestLogin_CountsIP_Success() {
+	require := suite.Require()
+	ctx := domain.WithRequestInfo(context.Background(), domain.RequestInfo{IP: "192.0.2.1"})
+
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "account:nobody@example.com", mock.Anything, domain.LoginPolicy(accountLoginPolicy)).Return(&domain.LoginFailures{Count: 1}, nil)
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "ip:192.0.2.1", mock.Anything, domain.LoginPolicy(ipLoginPolicy)).Return(&domain.LoginFailures{Count: 6}, nil)
+	suite.userRepo.On("FindByEmail", mock.Anything, "nobody@example.com").Return(nil, nil)
+	suite.auditRepo.On("Enqueue", mock.Anything, mock.Anything).Return(nil)
+
+	tokens, err := suite.authService.Login(ctx, "nobody@example.com", "password")
+
+	require.NoError(err)
+	require.Nil(tokens)
+	suite.loginFailureRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestLogin_ReleasesIP_Success() {
+	require := suite.Require()
+	ctx := domain.WithRequestInfo(context.Background(), domain.RequestInfo{IP: "192.0.2.1"})
+	user := &domain.User{ID: 10, Email: "foo@example.com"}
+	require.NoError(user.SetPassword("password"))
+
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "account:foo@example.com", mock.Anything, domain.LoginPolicy(accountLoginPolicy)).Return(&domain.LoginFailures{Count: 1}, nil)
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "ip:192.0.2.1", mock.Anything, domain.LoginPolicy(ipLoginPolicy)).Return(&domain.LoginFailures{Count: 6}, nil)
+	suite.loginFailureRepo.On("Reset", mock.Anything, "account:foo@example.com").Return(nil)
+	suite.loginFailureRepo.On("Release", mock.Anything, "ip:192.0.2.1", domain.LoginPolicy(ipLoginPolicy)).Return(nil)
+	suite.userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+	suite.refreshTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+	suite.auditRepo.On("Record", mock.Anything, mock.Anything).Return(nil)
+
+	tokens, err := suite.authService.Login(ctx, user.Email, "password")
+
+	require.NoError(err)
+	require.NotNil(tokens)
+	suite.loginFailureRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestLogin_Blocked_Failure() {
+	require := suite.Require()
+	blocked := &domain.LoginBlockedError{RetryAt: time.Now().Add(2 * time.Second)}
+
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "account:foo@example.com", mock.Anything, domain.LoginPolicy(accountLoginPolicy)).Return(nil, blocked)
+
+	tokens, err := suite.authService.Login(context.Background(), "Foo@Example.com", "password")
+
+	require.ErrorIs(err, blocked)
+	require.Nil(tokens)
+	suite.userRepo.AssertNotCalled(suite.T(), "FindByEmail", mock.Anything, mock.Anything)
+	suite.loginFailureRepo.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestLogin_IPBlocked_Failure checks that a login the IP blocks takes back
+// its attempt of the account, its password was never checked.
+func (suite *AuthServiceTestSuite) TestLogin_IPBlocked_Failure() {
+	require := suite.Require()
+	ctx := domain.WithRequestInfo(context.Background(), domain.RequestInfo{IP: "192.0.2.1"})
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	blocked := &domain.LoginBlockedError{RetryAt: lockedUntil, Locked: true}
+
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "account:foo@example.com", mock.Anything, domain.LoginPolicy(accountLoginPolicy)).Return(&domain.LoginFailures{Count: 1}, nil)
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "ip:192.0.2.1", mock.Anything, domain.LoginPolicy(ipLoginPolicy)).Return(nil, blocked)
+	suite.loginFailureRepo.On("Release", mock.Anything, "account:foo@example.com", domain.LoginPolicy(accountLoginPolicy)).Return(nil)
+
+	tokens, err := suite.authService.Login(ctx, "foo@example.com", "password")
+
+	require.ErrorIs(err, blocked)
+	require.Nil(tokens)
+	suite.userRepo.AssertNotCalled(suite.T(), "FindByEmail", mock.Anything, mock.Anything)
+	suite.loginFailureRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestLogin_AttemptError_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("database error")
+
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "account:foo@example.com", mock.Anything, mock.Anything).Return(nil, expectedError)
+
+	tokens, err := suite.authService.Login(context.Background(), "foo@example.com", "password")
+
+	require.ErrorIs(err, expectedError)
+	require.Nil(tokens)
+}
+
+func (suite *AuthServiceTestSuite) TestLogin_RecordFailure() {
+	require := suite.Require()
+	user := &domain.User{ID: 10, Email: "foo@example.com"}
+	require.NoError(user.SetPassword("password"))
+	expectedError := errors.New("database error")
+
+	suite.loginFailureRepo.On("Attempt", mock.Anything, "account:foo@example.com", mock.Anything, mock.Anything).Return(&domain.LoginFailures{Count: 1}, nil)
+	suite.loginFailureRepo.On("Reset", mock.Anything, "account:foo@example.com").Return(nil)
+	suite.userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+	suite.refreshTokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+	suite.auditRepo.On("Record", mock.Anything, mock.Anything).Return(expectedError)
+
+	tokens, err := suite.authService.Login(context.Background(), user.Email, "password")
+
+	require.ErrorIs(err, expectedError)
+	require.Nil(tokens)
+}
+
+func (suite *AuthServiceTestSuite) TestRefresh_Success() {
+	require := suite.Require()
+	stored := &domain.RefreshToken{ID: 5, UserID: 10, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	user := &domain.User{ID: 10, Email: "foo@example.com", Roles: []domain.Role{domain.RoleAdmin}}
+
+	suite.refreshTokenRepo.On("FindByHash", mock.Anything, domain.HashRefreshToken("old")).Return(stored, nil)
+	suite.refreshTokenRepo.On("Use", mock.Anything, uint(5)).Return(nil)
+	suite.userRepo.On("FindByID", mock.Anything, uint(10)).Return(user, nil)
+	suite.refreshTokenRepo.On("Create", mock.Anything, mock.MatchedBy(func(t *domain.RefreshToken) bool {
+		return t.UserID == 10 && t.FamilyID == "family"
+	})).Return(nil)
+	suite.revokedTokenRepo.On("IsRevoked", mock.Anything, mock.Anything).Return(false, nil)
+
+	tokens, err := suite.authService.Refresh(context.Background(), "old")
+
+	require.NoError(err)
+	require.NotEqual("old", tokens.RefreshToken)
+	suite.refreshTokenRepo.AssertExpectations(suite.T())
+
+	accessToken, err := suite.authService.Authenticate(context.Background(), tokens.AccessToken)
+	require.NoError(err)
+	require.Equal([]domain.Role{domain.RoleAdmin}, accessToken.Roles)
+	require.Equal(user.Permissions(), accessToken.Permissions)
+	require.True(accessToken.Can(domain.PermissionReadAuditLog))
+}
+
+func (suite *AuthServiceTestSuite) TestRefresh_UserDeleted_Failure() {
+	require := suite.Require()
+	stored := &domain.RefreshToken{ID: 5, UserID: 10, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+
+	suite.refreshTokenRepo.On("FindByHash", mock.Anything, domain.HashRefreshToken("old")).Return(stored, nil)
+	suite.refreshTokenRepo.On("Use", mock.Anything, uint(5)).Return(nil)
+	suite.userRepo.On("FindByID", mock.Anything, uint(10)).Return(nil, nil)
+
+	tokens, err := suite.authService.Refresh(context.Background(), "old")
+
+	require.ErrorIs(err, domain.ErrInvalidRefreshToken)
+	require.Nil(tokens)
+}
+
+func (suite *AuthServiceTestSuite) TestRefresh_Unknown_Failure() {
+	require := suite.Require()
+
+	suite.refreshTokenRepo.On("FindByHash", mock.Anything, domain.HashRefreshToken("old")).Return(nil, nil)
+
+	tokens, err := suite.authService.Refresh(context.Background(), "old")
+
+	require.ErrorIs(err, domain.ErrInvalidRefreshToken)
+	require.Nil(tokens)
+}
+
+func (suite *AuthServiceTestSuite) TestRefresh_Expired_Failure() {
+	require := suite.Require()
+	stored := &domain.RefreshToken{ID: 5, UserID: 10, FamilyID: "family", ExpiresAt: time.Now().Add(-time.Second)}
+
+	suite.refreshTokenRepo.On("FindByHash", mock.Anything, domain.HashRefreshToken("old")).Return(stored, nil)
+
+	tokens, err := suite.authService.Refresh(context.Background(), "old")
+
+	require.ErrorIs(err, domain.ErrInvalidRefreshToken)
+	require.Nil(tokens)
+	suite.refreshTokenRepo.AssertNotCalled(suite.T(), "Use", mock.Anything, mock.Anything)
+}
+
+func (suite *AuthServiceTestSuite) TestRefresh_Reused_Failure() {
+	require := suite.Require()
+	usedAt := time.Now().Add(-time.Minute)
+	stored := &domain.RefreshToken{ID: 5, UserID: 10, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}
+
+	suite.refreshTokenRepo.On("FindByHash", mock.Anything, domain.HashRefreshToken("old")).Return(stored, nil)
+	suite.refreshTokenRepo.On("RevokeFamily", mock.Anything, "family").Return(nil)
+
+	tokens, err := suite.authService.Refresh(context.Background(), "old")
+
+	require.ErrorIs(err, domain.ErrRefreshTokenReused)
+	require.Nil(tokens)
+	suite.refreshTokenRepo.AssertExpectations(suite.T())
+}
+
+// TestRefresh_ConcurrentlyReused_Failure loses the race to use the token to
+// another request.
+func (suite *AuthServiceTestSuite) TestRefresh_ConcurrentlyReused_Failure() {
+	require := suite.Require()
+	stored := &domain.RefreshToken{ID: 5, UserID: 10, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+
+	suite.refreshTokenRepo.On("FindByHash", mock.Anything, domain.HashRefreshToken("old")).Return(stored, nil)
+	suite.refreshTokenRepo.On("Use", mock.Anything, uint(5)).Return(domain.ErrRefreshTokenReused)
+	suite.refreshTokenRepo.On("RevokeFamily", mock.Anything, "family").Return(nil)
+
+	tokens, err := suite.authService.Refresh(context.Background(), "old")
+
+	require.ErrorIs(err, domain.ErrRefreshTokenReused)
+	require.Nil(tokens)
+	suite.refreshTokenRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestLogout_Success() {
+	require := suite.Require()
+	accessToken := AccessToken{ID: "jti", UserID: 10, ExpiresAt: time.Now().Add(time.Minute)}
+	stored := &domain.RefreshToken{ID: 5, UserID: 10, FamilyID: "family"}
+
+	suite.revokedTokenRepo.On("Revoke", mock.Anything, "jti", accessToken.ExpiresAt).Return(nil)
+	suite.refreshTokenRepo.On("FindByHash", mock.Anything, domain.HashRefreshToken("refresh")).Return(stored, nil)
+	suite.refreshTokenRepo.On("RevokeFamily", mock.Anything, "family").Return(nil)
+
+	err := suite.authService.Logout(context.Background(), accessToken, "refresh")
+
+	require.NoError(err)
+	suite.revokedTokenRepo.AssertExpectations(suite.T())
+	suite.refreshTokenRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuthServiceTestSuite) TestLogout_RefreshTokenOfAnotherUser_Success() {
+	require := suite.Require()
+	accessToken := AccessToken{ID: "jti", UserID: 10, ExpiresAt: time.Now().Add(time.Minute)}
+	stored := &domain.RefreshToken{ID: 5, UserID: 20, FamilyID: "family"}
+
+	suite.revokedTokenRepo.On("Revoke", mock.Anything, "jti", accessToken.ExpiresAt).Return(nil)
+	suite.refreshTokenRepo.On("FindByHash", mock.Anything, domain.HashRefreshToken("refresh")).Return(stored, nil)
+
+	err := suite.authService.Logout(context.Background(), accessToken, "refresh")
+
+	require.NoError(err)
+	suite.refreshTokenRepo.AssertNotCalled(suite.T(), "RevokeFamily", mock.Anything, mock.Anything)
+}
+
+func (suite *AuthServiceTestSuite) TestLogout_RevokeError_Failure() {
+	require := suite.Require()
+	accessToken := AccessToken{ID: "jti", UserID: 10, ExpiresAt: time.Now().Add(time.Minute)}
+	expectedError := errors.New("repo error")
+
+	suite.revokedTokenRepo.On("Revoke", mock.Anything, "jti", accessToken.ExpiresAt).Return(expectedError)
+
+	err := suite.authService.Logout(context.Background(), accessToken, "refresh")
+
+	require.ErrorIs(err, expectedError)
+	suite.refreshTokenRepo.AssertNotCalled(suite.T(), "FindByHash", mock.Anything, mock.Anything)
+}
+
+func (suite *AuthServiceTestSuite) TestAuthenticate_Revoked_Failure() {
+	require := suite.Require()
+	token := suite.signToken(jwt.SigningMethodEdDSA, suite.currentKey, "current", jwt.MapClaims{"user_id": 10, "jti": "jti", "iss": "gift-card", "aud": "gift-card-api", "exp": time.Now().Add(time.Minute).Unix()})
+
+	suite.revokedTokenRepo.On("IsRevoked", mock.Anything, "jti").Return(true, nil)
+
+	accessToken, err := suite.authService.Authenticate(context.Background(), token)
+
+	require.ErrorIs(err, domain.ErrInvalidAccessToken)
+	require.Nil(accessToken)
+}
+
+// TestAuthenticate_RotatedKey_Success checks that the tokens of a key that is
+// no longer the active one are still accepted.
+func (suite *AuthServiceTestSuite) TestAuthenticate_RotatedKey_Success() {
+	require := suite.Require()
+	exp := time.Now().Add(time.Minute).Truncate(time.Second)
+	token := suite.signToken(jwt.SigningMethodRS256, suite.previousKey, "previous", jwt.MapClaims{"user_id": 10, "jti": "jti", "iss": "gift-card", "aud": "gift-card-api", "exp": exp.Unix()})
+
+	suite.revokedTokenRepo.On("IsRevoked", mock.Anything, "jti").Return(false, nil)
+
+	accessToken, err := suite.authService.Authenticate(context.Background(), token)
+
+	require.NoError(err)
+	require.Equal(&AccessToken{ID: "jti", UserID: 10, ExpiresAt: exp}, accessToken)
+}
+
+func (suite *AuthServiceTestSuite) TestAuthenticate_InvalidToken_Failure() {
+	require := suite.Require()
+	exp := time.Now().Add(time.Minute).Unix()
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(err)
+	claims := jwt.MapClaims{"user_id": 10, "jti": "jti", "iss": "gift-card", "aud": "gift-card-api", "exp": exp}
+
+	for name, token := range map[string]string{
+		"empty":                      "",
+		"malformed":                  "not-a-token",
+		"HMAC":                       suite.signToken(jwt.SigningMethodHS256, []byte("secret"), "current", claims),
+		"HMAC with the public key":   suite.signToken(jwt.SigningMethodHS256, []byte(suite.currentKey.Public().(ed25519.PublicKey)), "current", claims),
+		"other algorithm of the kid": suite.signToken(jwt.SigningMethodRS256, suite.previousKey, "current", claims),
+		"other key":                  suite.signToken(jwt.SigningMethodEdDSA, otherKey, "current", claims),
+		"unknown kid":                suite.signToken(jwt.SigningMethodEdDSA, suite.currentKey, "unknown", claims),
+		"no kid":                     suite.signToken(jwt.SigningMethodEdDSA, suite.currentKey, "", claims),
+		"expired":                    suite.signToken(jwt.SigningMethodEdDSA, suite.currentKey, "current", jwt.MapClaims{"user_id": 10, "jti": "jti", "iss": "gift-card", "aud": "gift-card-api", "exp": time.Now().Add(-time.Minute).Unix()}),
+		"no expiry":                  suite.signToken(jwt.SigningMethodEdDSA, suite.currentKey, "current", jwt.MapClaims{"user_id": 10, "jti": "jti", "iss": "gift-card", "aud": "gift-card-api"}),
+		"no jti":                     suite.signToken(jwt.SigningMethodEdDSA, suite.currentKey, "current", jwt.MapClaims{"user_id": 10, "iss": "gift-card", "aud": "gift-card-api", "exp": exp}),
+		"no issuer":                  suite.signToken(jwt.SigningMethodEdDSA, suite.currentKey, "current", jwt.MapClaims{"user_id": 10, "jti": "jti", "aud": "gift-card-api", "exp": exp}),
+		"other issuer":               suite.signToken(jwt.SigningMethodEdDSA, suite.currentKey, "current", jwt.MapClaims{"user_id": 10, "jti": "jti", "iss": "other", "aud": "gift-card-api", "exp": exp}),
+		"no audience":                suite.signToken(jwt.SigningMethodEdDSA, suite.currentKey, "current", jwt.MapClaims{"user_id": 10, "jti": "jti", "iss": "gift-card", "exp": exp}),
+		"other audience":             suite.signToken(jwt.SigningMethodEdDSA, suite.currentKey, "current", jwt.MapClaims{"user_id": 10, "jti": "jti", "iss": "gift-card", "aud": []string{"other"}, "exp": exp}),
+	} {
+		accessToken, err := suite.authService.Authenticate(context.Background(), token)
+
+		require.ErrorIs(err, domain.ErrInvalidAccessToken, name)
+		require.Nil(accessToken, name)
+	}
+
+	suite.revokedTokenRepo.AssertNotCalled(suite.T(), "IsRevoked", mock.Anything, mock.Anything)
+}
+
+func (suite *AuthServiceTestSuite) signToken(method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	suite.Require().NoError(err)
+
+	return signed
+}
+
+func TestAuthService(t *testing.T) {
+	suite.Run(t, new(AuthServiceTestSuite))
+}
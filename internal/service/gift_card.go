@@ -1,33 +1,78 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/jmehdipour/gift-card/internal/domain"
 	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
 )
 
 type GiftCardService interface {
-	CreateGiftCard(amount float64, gifterID, gifteeID uint) (*domain.GiftCard, error)
-	FindGiftCard(id uint) (*domain.GiftCard, error)
-	UpdateStatus(giftCardID uint, status domain.GiftCardStatus) error
-	GetReceivedGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error)
-	GetSentGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error)
+	CreateGiftCard(ctx context.Context, amount domain.Money, gifterID, gifteeID uint, expiresAt *time.Time) (*domain.GiftCard, error)
+	FindGiftCard(ctx context.Context, id uint) (*domain.GiftCard, error)
+	UpdateStatus(ctx context.Context, giftCardID uint, version *uint, status domain.GiftCardStatus, actorID uint) (*domain.GiftCard, error)
+	ForceUpdateStatus(ctx context.Context, giftCardID uint, version *uint, status domain.GiftCardStatus, actorID uint) (*domain.GiftCard, error)
+	GetStatusHistory(ctx context.Context, giftCardID uint) ([]domain.GiftCardStatusChange, error)
+	ExpireOverdueGiftCards(ctx context.Context, now time.Time, batchSize int) (int, error)
+	RedeemGiftCard(ctx context.Context, code string, redeemerID uint, amount domain.Money) (*domain.GiftCardRedemption, error)
+	GetReceivedGiftCardsByUserID(ctx context.Context, userID uint, query repository.GiftCardQuery) (repository.GiftCardPage, error)
+	GetSentGiftCardsByUserID(ctx context.Context, userID uint, query repository.GiftCardQuery) (repository.GiftCardPage, error)
 }
 
 type giftCardService struct {
 	giftCardRepository repository.GiftCardRepository
+	unitOfWork         repository.UnitOfWork
+	defaultTTL         time.Duration
 }
 
-func NewGiftCardService(giftCardRepo repository.GiftCardRepository) GiftCardService {
-	return &giftCardService{giftCardRepository: giftCardRepo}
+// NewGiftCardService creates the gift card service, gift cards created without
+// an expiry date expire after defaultTTL or never if it is zero.
+func NewGiftCardService(giftCardRepo repository.GiftCardRepository, unitOfWork repository.UnitOfWork, defaultTTL time.Duration) GiftCardService {
+	return &giftCardService{giftCardRepository: giftCardRepo, unitOfWork: unitOfWork, defaultTTL: defaultTTL}
 }
 
-func (s *giftCardService) CreateGiftCard(amount float64, gifterID, gifteeID uint) (*domain.GiftCard, error) {
+// CreateGiftCard sends a pending gift card of amount from the gifter to the
+// giftee, who must be another registered user. The giftee is looked up in the
+// transaction the card is created in.
+func (s *giftCardService) CreateGiftCard(ctx context.Context, amount domain.Money, gifterID, gifteeID uint, expiresAt *time.Time) (*domain.GiftCard, error) {
+	if gifteeID == gifterID {
+		return nil, domain.ErrGiftCardToSelf
+	}
+
+	if expiresAt == nil && s.defaultTTL > 0 {
+		defaultExpiresAt := time.Now().Add(s.defaultTTL)
+		expiresAt = &defaultExpiresAt
+	}
+
+	code, err := domain.NewGiftCardCode()
+	if err != nil {
+		return nil, err
+	}
+
 	giftCard := domain.GiftCard{
-		Amount:   amount,
-		GifterID: gifterID,
-		GifteeID: gifteeID,
+		Code:            code,
+		Amount:          amount,
+		RemainingAmount: amount,
+		Status:          domain.GCSPending,
+		GifterID:        gifterID,
+		GifteeID:        gifteeID,
+		ExpiresAt:       expiresAt,
 	}
-	err := s.giftCardRepository.Create(&giftCard)
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		giftee, err := repos.Users.FindByID(ctx, gifteeID)
+		if err != nil {
+			return err
+		}
+
+		if giftee == nil {
+			return domain.ErrGifteeNotFound
+		}
+
+		return repos.GiftCards.Create(ctx, &giftCard)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -35,18 +80,118 @@ func (s *giftCardService) CreateGiftCard(amount float64, gifterID, gifteeID uint
 	return &giftCard, nil
 }
 
-func (s *giftCardService) FindGiftCard(id uint) (*domain.GiftCard, error) {
-	return s.giftCardRepository.FindByID(id)
+func (s *giftCardService) FindGiftCard(ctx context.Context, id uint) (*domain.GiftCard, error) {
+	return s.giftCardRepository.FindByID(ctx, id)
+}
+
+// UpdateStatus moves the gift card to status on behalf of the actor if the
+// actor may do so, see domain.GiftCard.CheckActor, and returns the updated
+// card. A card the actor does not take part in is reported as not found. If
+// version is given the card must still be at it. The card stays locked from
+// the check until it is updated, so a concurrent request cannot change it in
+// between.
+func (s *giftCardService) UpdateStatus(ctx context.Context, giftCardID uint, version *uint, status domain.GiftCardStatus, actorID uint) (*domain.GiftCard, error) {
+	var updated *domain.GiftCard
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		giftCard, err := repos.GiftCards.FindByIDForUpdate(ctx, giftCardID)
+		if err != nil {
+			return err
+		}
+
+		if giftCard == nil || !giftCard.IsParticipant(actorID) {
+			return domain.ErrGiftCardNotFound
+		}
+
+		err = giftCard.CheckActor(status, actorID)
+		if err != nil {
+			return err
+		}
+
+		err = repos.GiftCards.UpdateStatus(ctx, giftCardID, version, status, &actorID)
+		if err != nil {
+			return err
+		}
+
+		updated, err = repos.GiftCards.FindByID(ctx, giftCardID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// ForceUpdateStatus moves the gift card to status on behalf of a member of
+// the staff, who is neither its gifter nor its giftee, and returns the updated
+// card. It is only meant for expiring or voiding a card, which the staff may
+// do before or after its expiry date.
+func (s *giftCardService) ForceUpdateStatus(ctx context.Context, giftCardID uint, version *uint, status domain.GiftCardStatus, actorID uint) (*domain.GiftCard, error) {
+	err := s.giftCardRepository.ForceUpdateStatus(ctx, giftCardID, version, status, &actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.giftCardRepository.FindByID(ctx, giftCardID)
+}
+
+func (s *giftCardService) GetStatusHistory(ctx context.Context, giftCardID uint) ([]domain.GiftCardStatusChange, error) {
+	return s.giftCardRepository.FindStatusHistory(ctx, giftCardID)
+}
+
+// ExpireOverdueGiftCards expires up to batchSize gift cards that are overdue
+// at now and returns how many of them it expired. Each card is expired in its
+// own transaction, so cards that another worker expired, or that were decided
+// on, in the meantime are skipped. A card that fails to expire does not stop
+// the batch, it stays overdue for a later run and its error is returned with
+// the others after the batch.
+func (s *giftCardService) ExpireOverdueGiftCards(ctx context.Context, now time.Time, batchSize int) (int, error) {
+	ids, err := s.giftCardRepository.FindOverdueGiftCardIDs(ctx, now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	var errs []error
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		err = s.giftCardRepository.UpdateStatus(ctx, id, nil, domain.GCSExpired, nil)
+		var transitionErr *domain.InvalidTransitionError
+		if errors.As(err, &transitionErr) {
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("expire gift card %d: %w", id, err))
+			continue
+		}
+
+		expired++
+	}
+
+	return expired, errors.Join(errs...)
 }
 
-func (s *giftCardService) UpdateStatus(giftCardID uint, status domain.GiftCardStatus) error {
-	return s.giftCardRepository.UpdateStatus(giftCardID, status)
+// RedeemGiftCard spends amount of the gift card with the given code, the code
+// may be formatted the way it is shown to the holder.
+func (s *giftCardService) RedeemGiftCard(ctx context.Context, code string, redeemerID uint, amount domain.Money) (*domain.GiftCardRedemption, error) {
+	code, err := domain.ParseGiftCardCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.giftCardRepository.Redeem(ctx, code, redeemerID, amount)
 }
 
-func (s *giftCardService) GetReceivedGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error) {
-	return s.giftCardRepository.FindReceivedGiftCardsByUserID(userID, status, pageSize, pageNumber)
+func (s *giftCardService) GetReceivedGiftCardsByUserID(ctx context.Context, userID uint, query repository.GiftCardQuery) (repository.GiftCardPage, error) {
+	return s.giftCardRepository.FindReceivedGiftCardsByUserID(ctx, userID, query)
 }
 
-func (s *giftCardService) GetSentGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error) {
-	return s.giftCardRepository.FindSentGiftCardsByUserID(userID, status, pageSize, pageNumber)
+func (s *giftCardService) GetSentGiftCardsByUserID(ctx context.Context, userID uint, query repository.GiftCardQuery) (repository.GiftCardPage, error) {
+	return s.giftCardRepository.FindSentGiftCardsByUserID(ctx, userID, query)
 }
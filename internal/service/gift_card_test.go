@@ -1,8 +1,10 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -13,21 +15,27 @@ import (
 
 type GiftCardServiceTestSuite struct {
 	suite.Suite
+	userRepo        *repository.UserRepositoryMock
 	giftCardRepo    *repository.GiftCardRepositoryMock
+	unitOfWork      *repository.UnitOfWorkMock
 	giftCardService *giftCardService
 }
 
 func (suite *GiftCardServiceTestSuite) SetupTest() {
+	suite.userRepo = new(repository.UserRepositoryMock)
 	suite.giftCardRepo = new(repository.GiftCardRepositoryMock)
+	suite.unitOfWork = &repository.UnitOfWorkMock{Repositories: repository.Repositories{Users: suite.userRepo, GiftCards: suite.giftCardRepo}}
+	suite.unitOfWork.On("Do", mock.Anything).Return(nil)
 	suite.giftCardService = &giftCardService{
 		giftCardRepository: suite.giftCardRepo,
+		unitOfWork:         suite.unitOfWork,
 	}
 }
 
 func (suite *GiftCardServiceTestSuite) TestNewGiftCardRepository() {
 	require := suite.Require()
 
-	service := NewGiftCardService(suite.giftCardRepo)
+	service := NewGiftCardService(suite.giftCardRepo, suite.unitOfWork, 0)
 
 	require.NotNil(service)
 }
@@ -38,14 +46,47 @@ func (suite *GiftCardServiceTestSuite) TestCreateGiftCard_Success() {
 		ID:       15,
 		GifterID: 10,
 		GifteeID: 20,
-		Amount:   100,
+		Amount:   domain.NewMoney(10000, "USD"),
 	}
 
-	defer suite.giftCardRepo.On("Create", mock.Anything).Return(nil).Unset()
-	giftCardResult, err := suite.giftCardService.CreateGiftCard(giftCard.Amount, giftCard.GifterID, giftCard.GifteeID)
+	defer suite.userRepo.On("FindByID", mock.Anything, giftCard.GifteeID).Return(&domain.User{ID: giftCard.GifteeID}, nil).Unset()
+	defer suite.giftCardRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Unset()
+	giftCardResult, err := suite.giftCardService.CreateGiftCard(context.Background(), giftCard.Amount, giftCard.GifterID, giftCard.GifteeID, nil)
 
 	require.NoError(err)
 	require.Equal(giftCard.ID, giftCardResult.ID)
+	require.Equal(giftCard.Amount, giftCardResult.RemainingAmount)
+
+	code, err := domain.ParseGiftCardCode(giftCardResult.Code)
+	require.NoError(err)
+	require.Equal(giftCardResult.Code, code)
+}
+
+func (suite *GiftCardServiceTestSuite) TestCreateGiftCard_DefaultExpiry_Success() {
+	require := suite.Require()
+	suite.giftCardService.defaultTTL = time.Hour
+
+	defer suite.userRepo.On("FindByID", mock.Anything, uint(20)).Return(&domain.User{ID: 20}, nil).Unset()
+	defer suite.giftCardRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Unset()
+	before := time.Now()
+	giftCardResult, err := suite.giftCardService.CreateGiftCard(context.Background(), domain.NewMoney(10000, "USD"), 10, 20, nil)
+
+	require.NoError(err)
+	require.NotNil(giftCardResult.ExpiresAt)
+	require.WithinRange(*giftCardResult.ExpiresAt, before.Add(time.Hour), time.Now().Add(time.Hour))
+}
+
+func (suite *GiftCardServiceTestSuite) TestCreateGiftCard_GivenExpiry_Success() {
+	require := suite.Require()
+	suite.giftCardService.defaultTTL = time.Hour
+	expiresAt := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	defer suite.userRepo.On("FindByID", mock.Anything, uint(20)).Return(&domain.User{ID: 20}, nil).Unset()
+	defer suite.giftCardRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Unset()
+	giftCardResult, err := suite.giftCardService.CreateGiftCard(context.Background(), domain.NewMoney(10000, "USD"), 10, 20, &expiresAt)
+
+	require.NoError(err)
+	require.Equal(&expiresAt, giftCardResult.ExpiresAt)
 }
 
 func (suite *GiftCardServiceTestSuite) TestCreateGiftCard_Failure() {
@@ -54,24 +95,59 @@ func (suite *GiftCardServiceTestSuite) TestCreateGiftCard_Failure() {
 	giftCard := domain.GiftCard{
 		GifterID: 10,
 		GifteeID: 20,
-		Amount:   100,
+		Amount:   domain.NewMoney(10000, "USD"),
 	}
 
-	defer suite.giftCardRepo.On("Create", mock.Anything).Return(expectedError).Unset()
-	giftCardResult, err := suite.giftCardService.CreateGiftCard(giftCard.Amount, giftCard.GifterID, giftCard.GifteeID)
+	defer suite.userRepo.On("FindByID", mock.Anything, giftCard.GifteeID).Return(&domain.User{ID: giftCard.GifteeID}, nil).Unset()
+	defer suite.giftCardRepo.On("Create", mock.Anything, mock.Anything).Return(expectedError).Unset()
+	giftCardResult, err := suite.giftCardService.CreateGiftCard(context.Background(), giftCard.Amount, giftCard.GifterID, giftCard.GifteeID, nil)
 
 	require.Error(err)
 	require.EqualError(expectedError, err.Error())
 	require.Empty(giftCardResult)
 }
 
+func (suite *GiftCardServiceTestSuite) TestCreateGiftCard_ToSelf_Failure() {
+	require := suite.Require()
+
+	giftCardResult, err := suite.giftCardService.CreateGiftCard(context.Background(), domain.NewMoney(10000, "USD"), 10, 10, nil)
+
+	require.ErrorIs(err, domain.ErrGiftCardToSelf)
+	require.Nil(giftCardResult)
+	suite.userRepo.AssertNotCalled(suite.T(), "FindByID", mock.Anything, mock.Anything)
+	suite.giftCardRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *GiftCardServiceTestSuite) TestCreateGiftCard_GifteeNotFound_Failure() {
+	require := suite.Require()
+
+	defer suite.userRepo.On("FindByID", mock.Anything, uint(20)).Return(nil, nil).Unset()
+	giftCardResult, err := suite.giftCardService.CreateGiftCard(context.Background(), domain.NewMoney(10000, "USD"), 10, 20, nil)
+
+	require.ErrorIs(err, domain.ErrGifteeNotFound)
+	require.Nil(giftCardResult)
+	suite.giftCardRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *GiftCardServiceTestSuite) TestCreateGiftCard_FindGifteeError_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("repo error")
+
+	defer suite.userRepo.On("FindByID", mock.Anything, uint(20)).Return(nil, expectedError).Unset()
+	giftCardResult, err := suite.giftCardService.CreateGiftCard(context.Background(), domain.NewMoney(10000, "USD"), 10, 20, nil)
+
+	require.ErrorIs(err, expectedError)
+	require.Nil(giftCardResult)
+	suite.giftCardRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
 func (suite *GiftCardServiceTestSuite) TestFindGiftCard_Failure() {
 	require := suite.Require()
 	expectedError := errors.New("repo error")
 	id := uint(10)
 
-	defer suite.giftCardRepo.On("FindByID", mock.Anything).Return(nil, expectedError).Unset()
-	giftCardResult, err := suite.giftCardService.FindGiftCard(id)
+	defer suite.giftCardRepo.On("FindByID", mock.Anything, mock.Anything).Return(nil, expectedError).Unset()
+	giftCardResult, err := suite.giftCardService.FindGiftCard(context.Background(), id)
 
 	require.Error(err)
 	require.EqualError(expectedError, err.Error())
@@ -84,11 +160,11 @@ func (suite *GiftCardServiceTestSuite) TestFindGiftCard_Success() {
 		ID:       10,
 		GifterID: 10,
 		GifteeID: 20,
-		Amount:   100,
+		Amount:   domain.NewMoney(10000, "USD"),
 	}
 
-	defer suite.giftCardRepo.On("FindByID", mock.Anything).Return(&giftCard, nil).Unset()
-	giftCardResult, err := suite.giftCardService.FindGiftCard(giftCard.ID)
+	defer suite.giftCardRepo.On("FindByID", mock.Anything, mock.Anything).Return(&giftCard, nil).Unset()
+	giftCardResult, err := suite.giftCardService.FindGiftCard(context.Background(), giftCard.ID)
 
 	require.NoError(err)
 	require.Equal(&giftCard, giftCardResult)
@@ -97,9 +173,33 @@ func (suite *GiftCardServiceTestSuite) TestFindGiftCard_Success() {
 func (suite *GiftCardServiceTestSuite) TestUpdateStatus_Success() {
 	require := suite.Require()
 	id := uint(10)
+	actorID := uint(20)
+	giftCard := domain.GiftCard{ID: id, Status: domain.GCSPending, GifterID: 30, GifteeID: actorID, Version: 1}
+
+	updated := giftCard
+	updated.Status = domain.GCSAccepted
+	updated.Version = 2
 
-	defer suite.giftCardRepo.On("UpdateStatus", id, domain.GCSAccepted).Return(nil).Unset()
-	err := suite.giftCardService.UpdateStatus(id, domain.GCSAccepted)
+	defer suite.giftCardRepo.On("FindByIDForUpdate", mock.Anything, id).Return(&giftCard, nil).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, id, &giftCard.Version, domain.GCSAccepted, &actorID).Return(nil).Unset()
+	defer suite.giftCardRepo.On("FindByID", mock.Anything, id).Return(&updated, nil).Unset()
+	result, err := suite.giftCardService.UpdateStatus(context.Background(), id, &giftCard.Version, domain.GCSAccepted, actorID)
+
+	require.NoError(err)
+	require.Equal(&updated, result)
+	suite.unitOfWork.AssertCalled(suite.T(), "Do", mock.Anything)
+}
+
+func (suite *GiftCardServiceTestSuite) TestUpdateStatus_Cancel_Success() {
+	require := suite.Require()
+	id := uint(10)
+	actorID := uint(30)
+	giftCard := domain.GiftCard{ID: id, Status: domain.GCSPending, GifterID: actorID, GifteeID: 20}
+
+	defer suite.giftCardRepo.On("FindByIDForUpdate", mock.Anything, id).Return(&giftCard, nil).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, id, (*uint)(nil), domain.GCSCancelled, &actorID).Return(nil).Unset()
+	defer suite.giftCardRepo.On("FindByID", mock.Anything, id).Return(&giftCard, nil).Unset()
+	_, err := suite.giftCardService.UpdateStatus(context.Background(), id, nil, domain.GCSCancelled, actorID)
 
 	require.NoError(err)
 }
@@ -108,67 +208,283 @@ func (suite *GiftCardServiceTestSuite) TestUpdateStatus_Failure() {
 	require := suite.Require()
 	expectedError := errors.New("repo error")
 	id := uint(10)
+	giftCard := domain.GiftCard{ID: id, Status: domain.GCSPending, GifterID: 30, GifteeID: 20}
+
+	defer suite.giftCardRepo.On("FindByIDForUpdate", mock.Anything, id).Return(&giftCard, nil).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, id, (*uint)(nil), domain.GCSAccepted, mock.Anything).Return(expectedError).Unset()
+	_, err := suite.giftCardService.UpdateStatus(context.Background(), id, nil, domain.GCSAccepted, 20)
+
+	require.ErrorIs(err, expectedError)
+}
+
+func (suite *GiftCardServiceTestSuite) TestUpdateStatus_VersionMismatch_Failure() {
+	require := suite.Require()
+	id := uint(10)
+	actorID := uint(20)
+	version := uint(1)
+	giftCard := domain.GiftCard{ID: id, Status: domain.GCSPending, GifterID: 30, GifteeID: actorID, Version: 2}
+
+	defer suite.giftCardRepo.On("FindByIDForUpdate", mock.Anything, id).Return(&giftCard, nil).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, id, &version, domain.GCSAccepted, &actorID).Return(domain.ErrGiftCardVersionMismatch).Unset()
+	result, err := suite.giftCardService.UpdateStatus(context.Background(), id, &version, domain.GCSAccepted, actorID)
+
+	require.ErrorIs(err, domain.ErrGiftCardVersionMismatch)
+	require.Nil(result)
+	suite.giftCardRepo.AssertNotCalled(suite.T(), "FindByID", mock.Anything, mock.Anything)
+}
+
+func (suite *GiftCardServiceTestSuite) TestUpdateStatus_FindByIDForUpdate_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("repo error")
+	id := uint(10)
+
+	defer suite.giftCardRepo.On("FindByIDForUpdate", mock.Anything, id).Return(nil, expectedError).Unset()
+	_, err := suite.giftCardService.UpdateStatus(context.Background(), id, nil, domain.GCSAccepted, 20)
+
+	require.ErrorIs(err, expectedError)
+	suite.giftCardRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *GiftCardServiceTestSuite) TestUpdateStatus_NotFound_Failure() {
+	require := suite.Require()
+	id := uint(10)
 
-	defer suite.giftCardRepo.On("UpdateStatus", id, domain.GCSAccepted).Return(expectedError).Unset()
-	err := suite.giftCardService.UpdateStatus(id, domain.GCSAccepted)
+	defer suite.giftCardRepo.On("FindByIDForUpdate", mock.Anything, id).Return(nil, nil).Unset()
+	_, err := suite.giftCardService.UpdateStatus(context.Background(), id, nil, domain.GCSAccepted, 20)
+
+	require.ErrorIs(err, domain.ErrGiftCardNotFound)
+	suite.giftCardRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *GiftCardServiceTestSuite) TestUpdateStatus_NotParticipant_Failure() {
+	require := suite.Require()
+	id := uint(10)
+	giftCard := domain.GiftCard{ID: id, Status: domain.GCSPending, GifterID: 30, GifteeID: 20}
+
+	defer suite.giftCardRepo.On("FindByIDForUpdate", mock.Anything, id).Return(&giftCard, nil).Unset()
+	for _, status := range []domain.GiftCardStatus{domain.GCSAccepted, domain.GCSRejected, domain.GCSCancelled} {
+		_, err := suite.giftCardService.UpdateStatus(context.Background(), id, nil, status, 40)
+
+		require.ErrorIs(err, domain.ErrGiftCardNotFound)
+	}
+
+	suite.giftCardRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *GiftCardServiceTestSuite) TestUpdateStatus_NotGiftee_Failure() {
+	require := suite.Require()
+	id := uint(10)
+	giftCard := domain.GiftCard{ID: id, Status: domain.GCSPending, GifterID: 30, GifteeID: 20}
+
+	defer suite.giftCardRepo.On("FindByIDForUpdate", mock.Anything, id).Return(&giftCard, nil).Unset()
+	_, err := suite.giftCardService.UpdateStatus(context.Background(), id, nil, domain.GCSRejected, 30)
+
+	require.ErrorIs(err, domain.ErrNotGiftCardGiftee)
+	suite.giftCardRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *GiftCardServiceTestSuite) TestUpdateStatus_NotGifter_Failure() {
+	require := suite.Require()
+	id := uint(10)
+	giftCard := domain.GiftCard{ID: id, Status: domain.GCSPending, GifterID: 30, GifteeID: 20}
+
+	defer suite.giftCardRepo.On("FindByIDForUpdate", mock.Anything, id).Return(&giftCard, nil).Unset()
+	_, err := suite.giftCardService.UpdateStatus(context.Background(), id, nil, domain.GCSCancelled, 20)
+
+	require.ErrorIs(err, domain.ErrNotGiftCardGifter)
+	suite.giftCardRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *GiftCardServiceTestSuite) TestForceUpdateStatus_Success() {
+	require := suite.Require()
+	id := uint(10)
+	actorID := uint(99)
+	version := uint(1)
+	voided := domain.GiftCard{ID: id, Status: domain.GCSVoided, GifterID: 30, GifteeID: 20, Version: 2}
+
+	defer suite.giftCardRepo.On("ForceUpdateStatus", mock.Anything, id, &version, domain.GCSVoided, &actorID).Return(nil).Unset()
+	defer suite.giftCardRepo.On("FindByID", mock.Anything, id).Return(&voided, nil).Unset()
+	result, err := suite.giftCardService.ForceUpdateStatus(context.Background(), id, &version, domain.GCSVoided, actorID)
+
+	require.NoError(err)
+	require.Equal(&voided, result)
+	suite.unitOfWork.AssertNotCalled(suite.T(), "Do", mock.Anything)
+}
+
+func (suite *GiftCardServiceTestSuite) TestForceUpdateStatus_Failure() {
+	require := suite.Require()
+	id := uint(10)
+	actorID := uint(99)
+
+	defer suite.giftCardRepo.On("ForceUpdateStatus", mock.Anything, id, (*uint)(nil), domain.GCSExpired, &actorID).Return(domain.ErrGiftCardNotFound).Unset()
+	result, err := suite.giftCardService.ForceUpdateStatus(context.Background(), id, nil, domain.GCSExpired, actorID)
+
+	require.ErrorIs(err, domain.ErrGiftCardNotFound)
+	require.Nil(result)
+}
+
+func (suite *GiftCardServiceTestSuite) TestGetStatusHistory_Success() {
+	require := suite.Require()
+	id := uint(10)
+	actorID := uint(20)
+
+	history := []domain.GiftCardStatusChange{{ID: 1, GiftCardID: id, To: domain.GCSPending, ActorID: &actorID}}
+	defer suite.giftCardRepo.On("FindStatusHistory", mock.Anything, id).Return(history, nil).Unset()
+	result, err := suite.giftCardService.GetStatusHistory(context.Background(), id)
+
+	require.NoError(err)
+	require.Equal(history, result)
+}
+
+func (suite *GiftCardServiceTestSuite) TestGetStatusHistory_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("repo error")
+	id := uint(10)
+
+	defer suite.giftCardRepo.On("FindStatusHistory", mock.Anything, id).Return([]domain.GiftCardStatusChange(nil), expectedError).Unset()
+	result, err := suite.giftCardService.GetStatusHistory(context.Background(), id)
 
 	require.Error(err)
+	require.Empty(result)
+}
+
+func (suite *GiftCardServiceTestSuite) TestExpireOverdueGiftCards_Success() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	defer suite.giftCardRepo.On("FindOverdueGiftCardIDs", mock.Anything, now, 10).Return([]uint{1, 2, 3}, nil).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, uint(1), (*uint)(nil), domain.GCSExpired, (*uint)(nil)).Return(nil).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, uint(2), (*uint)(nil), domain.GCSExpired, (*uint)(nil)).
+		Return(&domain.InvalidTransitionError{From: domain.GCSExpired, To: domain.GCSExpired}).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, uint(3), (*uint)(nil), domain.GCSExpired, (*uint)(nil)).Return(nil).Unset()
+	expired, err := suite.giftCardService.ExpireOverdueGiftCards(context.Background(), now, 10)
+
+	require.NoError(err)
+	require.Equal(2, expired)
+}
+
+func (suite *GiftCardServiceTestSuite) TestExpireOverdueGiftCards_UpdateStatus_Failure() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedError := errors.New("repo error")
+
+	defer suite.giftCardRepo.On("FindOverdueGiftCardIDs", mock.Anything, now, 10).Return([]uint{1, 2, 3, 4}, nil).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, uint(1), (*uint)(nil), domain.GCSExpired, (*uint)(nil)).Return(nil).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, uint(2), (*uint)(nil), domain.GCSExpired, (*uint)(nil)).Return(domain.ErrGiftCardVersionMismatch).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, uint(3), (*uint)(nil), domain.GCSExpired, (*uint)(nil)).Return(expectedError).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, uint(4), (*uint)(nil), domain.GCSExpired, (*uint)(nil)).Return(nil).Unset()
+	expired, err := suite.giftCardService.ExpireOverdueGiftCards(context.Background(), now, 10)
+
+	require.ErrorIs(err, domain.ErrGiftCardVersionMismatch)
+	require.ErrorIs(err, expectedError)
+	require.ErrorContains(err, "expire gift card 2: ")
+	require.Equal(2, expired)
+}
+
+func (suite *GiftCardServiceTestSuite) TestExpireOverdueGiftCards_Canceled_Failure() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	defer suite.giftCardRepo.On("FindOverdueGiftCardIDs", mock.Anything, now, 10).Return([]uint{1, 2}, nil).Unset()
+	defer suite.giftCardRepo.On("UpdateStatus", mock.Anything, uint(1), (*uint)(nil), domain.GCSExpired, (*uint)(nil)).
+		Return(nil).Run(func(mock.Arguments) { cancel() }).Unset()
+	expired, err := suite.giftCardService.ExpireOverdueGiftCards(ctx, now, 10)
+
+	require.ErrorIs(err, context.Canceled)
+	require.Equal(1, expired)
+	suite.giftCardRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, uint(2), (*uint)(nil), domain.GCSExpired, (*uint)(nil))
+}
+
+func (suite *GiftCardServiceTestSuite) TestExpireOverdueGiftCards_Find_Failure() {
+	require := suite.Require()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedError := errors.New("repo error")
+
+	defer suite.giftCardRepo.On("FindOverdueGiftCardIDs", mock.Anything, now, 10).Return(nil, expectedError).Unset()
+	expired, err := suite.giftCardService.ExpireOverdueGiftCards(context.Background(), now, 10)
+
+	require.ErrorIs(err, expectedError)
+	require.Zero(expired)
+}
+
+func (suite *GiftCardServiceTestSuite) TestRedeemGiftCard_Success() {
+	require := suite.Require()
+	amount := domain.NewMoney(2500, "USD")
+	redemption := &domain.GiftCardRedemption{ID: 5, GiftCardID: 10, RedeemerID: 30, Amount: amount, RemainingAmount: domain.NewMoney(7500, "USD")}
+
+	defer suite.giftCardRepo.On("Redeem", mock.Anything, "0123456789ABCDEZ", uint(30), amount).Return(redemption, nil).Unset()
+	result, err := suite.giftCardService.RedeemGiftCard(context.Background(), "0123-4567-89ab-cdez", 30, amount)
+
+	require.NoError(err)
+	require.Equal(redemption, result)
+}
+
+func (suite *GiftCardServiceTestSuite) TestRedeemGiftCard_InvalidCode_Failure() {
+	require := suite.Require()
+
+	result, err := suite.giftCardService.RedeemGiftCard(context.Background(), "0123-4567-89AB-CDE0", 30, domain.NewMoney(2500, "USD"))
+
+	require.ErrorIs(err, domain.ErrInvalidGiftCardCode)
+	require.Nil(result)
 }
 
 func (suite *GiftCardServiceTestSuite) TestFindReceivedGiftCardsByUserID_Failure() {
 	require := suite.Require()
 	expectedError := errors.New("repo error")
 	userID := uint(10)
+	query := repository.GiftCardQuery{PageSize: 10, PageNumber: 1}
 
-	defer suite.giftCardRepo.On("FindReceivedGiftCardsByUserID", userID, (*domain.GiftCardStatus)(nil), 10, 1).
-		Return([]domain.GiftCard{}, 0, expectedError).Unset()
-	giftCards, total, err := suite.giftCardService.GetReceivedGiftCardsByUserID(userID, nil, 10, 1)
+	defer suite.giftCardRepo.On("FindReceivedGiftCardsByUserID", mock.Anything, userID, query).
+		Return(repository.GiftCardPage{}, expectedError).Unset()
+	page, err := suite.giftCardService.GetReceivedGiftCardsByUserID(context.Background(), userID, query)
 
 	require.Error(err)
-	require.Empty(giftCards)
-	require.Zero(total)
+	require.Empty(page.GiftCards)
 }
 
 func (suite *GiftCardServiceTestSuite) TestFindReceivedGiftCardsByUserID_Success() {
 	require := suite.Require()
 	userID := uint(20)
+	query := repository.GiftCardQuery{PageSize: 10, PageNumber: 1}
 
-	giftCards := []domain.GiftCard{{ID: 10, Amount: 100, Status: domain.GCSAccepted, GifterID: 10, GifteeID: 20}}
-	defer suite.giftCardRepo.On("FindReceivedGiftCardsByUserID", userID, (*domain.GiftCardStatus)(nil), 10, 1).
-		Return(giftCards, 1, nil).Unset()
-	giftCardsResult, total, err := suite.giftCardService.GetReceivedGiftCardsByUserID(userID, nil, 10, 1)
+	giftCards := []domain.GiftCard{{ID: 10, Amount: domain.NewMoney(10000, "USD"), Status: domain.GCSAccepted, GifterID: 10, GifteeID: 20}}
+	expected := repository.GiftCardPage{GiftCards: giftCards, NextCursor: "next"}
+	defer suite.giftCardRepo.On("FindReceivedGiftCardsByUserID", mock.Anything, userID, query).
+		Return(expected, nil).Unset()
+	page, err := suite.giftCardService.GetReceivedGiftCardsByUserID(context.Background(), userID, query)
 
 	require.NoError(err)
-	require.Equal(giftCards, giftCardsResult)
-	require.Equal(len(giftCards), total)
+	require.Equal(expected, page)
 }
 
 func (suite *GiftCardServiceTestSuite) TestFindSentGiftCardsByUserID_Failure() {
 	require := suite.Require()
 	expectedError := errors.New("repo error")
 	userID := uint(10)
+	query := repository.GiftCardQuery{PageSize: 10, PageNumber: 1}
 
-	defer suite.giftCardRepo.On("FindSentGiftCardsByUserID", userID, (*domain.GiftCardStatus)(nil), 10, 1).
-		Return([]domain.GiftCard{}, 0, expectedError).Unset()
-	giftCards, total, err := suite.giftCardService.GetSentGiftCardsByUserID(userID, nil, 10, 1)
+	defer suite.giftCardRepo.On("FindSentGiftCardsByUserID", mock.Anything, userID, query).
+		Return(repository.GiftCardPage{}, expectedError).Unset()
+	page, err := suite.giftCardService.GetSentGiftCardsByUserID(context.Background(), userID, query)
 
 	require.Error(err)
-	require.Empty(giftCards)
-	require.Zero(total)
+	require.Empty(page.GiftCards)
 }
 
 func (suite *GiftCardServiceTestSuite) TestFindSendGiftCardsByUserID_Success() {
 	require := suite.Require()
 	userID := uint(10)
+	query := repository.GiftCardQuery{PageSize: 10, PageNumber: 1}
 
-	giftCards := []domain.GiftCard{{ID: 10, Amount: 100, Status: domain.GCSAccepted, GifterID: 10, GifteeID: 20}}
-	defer suite.giftCardRepo.On("FindSentGiftCardsByUserID", userID, (*domain.GiftCardStatus)(nil), 10, 1).
-		Return(giftCards, 1, nil).Unset()
-	giftCardsResult, total, err := suite.giftCardService.GetSentGiftCardsByUserID(userID, nil, 10, 1)
+	giftCards := []domain.GiftCard{{ID: 10, Amount: domain.NewMoney(10000, "USD"), Status: domain.GCSAccepted, GifterID: 10, GifteeID: 20}}
+	expected := repository.GiftCardPage{GiftCards: giftCards, NextCursor: "next"}
+	defer suite.giftCardRepo.On("FindSentGiftCardsByUserID", mock.Anything, userID, query).
+		Return(expected, nil).Unset()
+	page, err := suite.giftCardService.GetSentGiftCardsByUserID(context.Background(), userID, query)
 
 	require.NoError(err)
-	require.Equal(giftCards, giftCardsResult)
-	require.Equal(len(giftCards), total)
+	require.Equal(expected, page)
 }
 
 func TestGiftCardService(t *testing.T) {
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, userID uint, key, requestHash string) (*domain.IdempotencyKey, error)
+	Complete(ctx context.Context, reservation domain.IdempotencyKey, statusCode int, headers map[string][]string, body []byte) error
+	Release(ctx context.Context, reservation domain.IdempotencyKey) error
+}
+
+type idempotencyService struct {
+	idempotencyRepository repository.IdempotencyRepository
+	lease                 time.Duration
+	ttl                   time.Duration
+}
+
+// NewIdempotencyService returns an IdempotencyService whose requests hold
+// their key for lease and whose keys are kept for ttl, see
+// domain.IdempotencyKey.
+func NewIdempotencyService(idempotencyRepo repository.IdempotencyRepository, lease, ttl time.Duration) IdempotencyService {
+	return &idempotencyService{
+		idempotencyRepository: idempotencyRepo,
+		lease:                 lease,
+		ttl:                   ttl,
+	}
+}
+
+// Begin reserves the key for a request. It returns the reservation when the
+// request is new and should be handled, and the completed key when the
+// request is a replay whose stored response should be returned instead.
+func (s *idempotencyService) Begin(ctx context.Context, userID uint, key, requestHash string) (*domain.IdempotencyKey, error) {
+	reservation, err := domain.NewIdempotencyKey(userID, key, requestHash, time.Now(), s.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.idempotencyRepository.Reserve(ctx, reservation, s.lease)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.Token == reservation.Token {
+		return stored, nil
+	}
+
+	if stored.RequestHash != requestHash {
+		return nil, domain.ErrIdempotencyKeyReused
+	}
+
+	if !stored.IsCompleted() {
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+
+	return stored, nil
+}
+
+// Complete stores the response of the request that holds the reservation.
+func (s *idempotencyService) Complete(ctx context.Context, reservation domain.IdempotencyKey, statusCode int, headers map[string][]string, body []byte) error {
+	reservation.StatusCode = statusCode
+	reservation.ResponseHeaders = headers
+	reservation.ResponseBody = body
+
+	return s.idempotencyRepository.Complete(ctx, reservation)
+}
+
+func (s *idempotencyService) Release(ctx context.Context, reservation domain.IdempotencyKey) error {
+	return s.idempotencyRepository.Release(ctx, reservation)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+type IdempotencyServiceTestSuite struct {
+	suite.Suite
+	idempotencyRepo    *repository.IdempotencyRepositoryMock
+	idempotencyService *idempotencyService
+}
+
+func (suite *IdempotencyServiceTestSuite) SetupTest() {
+	suite.idempotencyRepo = new(repository.IdempotencyRepositoryMock)
+	suite.idempotencyService = &idempotencyService{
+		idempotencyRepository: suite.idempotencyRepo,
+		lease:                 time.Minute,
+		ttl:                   time.Hour,
+	}
+}
+
+func (suite *IdempotencyServiceTestSuite) TestNewIdempotencyService() {
+	require := suite.Require()
+
+	service := NewIdempotencyService(suite.idempotencyRepo, time.Minute, time.Hour)
+
+	require.NotNil(service)
+}
+
+// reservationOf matches a reservation of the key for the request, made for
+// the lease and ttl of the suite.
+func reservationOf(userID uint, key, requestHash string) interface{} {
+	return mock.MatchedBy(func(k domain.IdempotencyKey) bool {
+		return k.UserID == userID && k.Key == key && k.RequestHash == requestHash && k.Token != "" &&
+			k.ExpiresAt.Equal(k.LockedAt.Add(time.Hour))
+	})
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_NewKey_Success() {
+	require := suite.Require()
+	var reserved domain.IdempotencyKey
+
+	suite.idempotencyRepo.On("Reserve", mock.Anything, reservationOf(10, "key", "hash"), time.Minute).
+		Run(func(args mock.Arguments) { reserved = args.Get(1).(domain.IdempotencyKey) }).
+		Return(&reserved, nil)
+	stored, err := suite.idempotencyService.Begin(context.Background(), 10, "key", "hash")
+
+	require.NoError(err)
+	require.Equal(&reserved, stored)
+	require.False(stored.IsCompleted())
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_Replay_Success() {
+	require := suite.Require()
+	existing := &domain.IdempotencyKey{Key: "key", UserID: 10, RequestHash: "hash", Token: "other", StatusCode: 201, ResponseBody: []byte(`{"id":1}`)}
+
+	suite.idempotencyRepo.On("Reserve", mock.Anything, reservationOf(10, "key", "hash"), time.Minute).Return(existing, nil)
+	stored, err := suite.idempotencyService.Begin(context.Background(), 10, "key", "hash")
+
+	require.NoError(err)
+	require.Equal(existing, stored)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_DifferentRequest_Failure() {
+	require := suite.Require()
+	existing := &domain.IdempotencyKey{Key: "key", UserID: 10, RequestHash: "other", Token: "other", StatusCode: 201}
+
+	suite.idempotencyRepo.On("Reserve", mock.Anything, reservationOf(10, "key", "hash"), time.Minute).Return(existing, nil)
+	stored, err := suite.idempotencyService.Begin(context.Background(), 10, "key", "hash")
+
+	require.ErrorIs(err, domain.ErrIdempotencyKeyReused)
+	require.Nil(stored)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_InProgress_Failure() {
+	require := suite.Require()
+	existing := &domain.IdempotencyKey{Key: "key", UserID: 10, RequestHash: "hash", Token: "other"}
+
+	suite.idempotencyRepo.On("Reserve", mock.Anything, reservationOf(10, "key", "hash"), time.Minute).Return(existing, nil)
+	stored, err := suite.idempotencyService.Begin(context.Background(), 10, "key", "hash")
+
+	require.ErrorIs(err, domain.ErrIdempotencyKeyInProgress)
+	require.Nil(stored)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestBegin_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("repo error")
+
+	suite.idempotencyRepo.On("Reserve", mock.Anything, reservationOf(10, "key", "hash"), time.Minute).Return(nil, expectedError)
+	stored, err := suite.idempotencyService.Begin(context.Background(), 10, "key", "hash")
+
+	require.ErrorIs(err, expectedError)
+	require.Nil(stored)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestComplete_Success() {
+	require := suite.Require()
+	reservation := domain.IdempotencyKey{Key: "key", UserID: 10, RequestHash: "hash", Token: "token"}
+	headers := map[string][]string{"Etag": {`"1"`}}
+	completed := reservation
+	completed.StatusCode = 201
+	completed.ResponseHeaders = headers
+	completed.ResponseBody = []byte(`{"id":1}`)
+
+	defer suite.idempotencyRepo.On("Complete", mock.Anything, completed).Return(nil).Unset()
+	err := suite.idempotencyService.Complete(context.Background(), reservation, 201, headers, []byte(`{"id":1}`))
+
+	require.NoError(err)
+}
+
+func (suite *IdempotencyServiceTestSuite) TestRelease_Success() {
+	require := suite.Require()
+	reservation := domain.IdempotencyKey{Key: "key", UserID: 10, RequestHash: "hash", Token: "token"}
+
+	defer suite.idempotencyRepo.On("Release", mock.Anything, reservation).Return(nil).Unset()
+	err := suite.idempotencyService.Release(context.Background(), reservation)
+
+	require.NoError(err)
+}
+
+func TestIdempotencyService(t *testing.T) {
+	suite.Run(t, new(IdempotencyServiceTestSuite))
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+type LedgerService interface {
+	Reconcile(ctx context.Context) (*domain.ReconciliationReport, error)
+}
+
+type ledgerService struct {
+	ledgerRepository repository.LedgerRepository
+}
+
+func NewLedgerService(ledgerRepo repository.LedgerRepository) LedgerService {
+	return &ledgerService{ledgerRepository: ledgerRepo}
+}
+
+// Reconcile recomputes the balance of every wallet and of every accepted gift
+// card from the ledger and reports the accounts whose cached balances, or
+// remaining amounts, drifted away from it.
+func (s *ledgerService) Reconcile(ctx context.Context) (*domain.ReconciliationReport, error) {
+	snapshot, err := s.ledgerRepository.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ReconciliationReport{
+		Accounts:                 len(snapshot.AccountBalances),
+		UnbalancedTransactionIDs: snapshot.UnbalancedTransactionIDs,
+	}
+
+	seen := make(map[string]struct{}, len(snapshot.Wallets)*2+len(snapshot.GiftCards))
+	check := func(account string, cached domain.Money) {
+		seen[account] = struct{}{}
+		ledger, ok := snapshot.AccountBalances[account]
+		if !ok {
+			ledger = domain.NewMoney(0, cached.Currency)
+		}
+
+		if ledger != cached {
+			report.Drifts = append(report.Drifts, domain.LedgerDrift{Account: account, Ledger: ledger, Cached: cached})
+		}
+	}
+
+	for _, w := range snapshot.Wallets {
+		check(domain.WalletAvailableAccount(w.UserID, w.Balance.Currency), w.Balance)
+		check(domain.WalletHeldAccount(w.UserID, w.Held.Currency), w.Held)
+	}
+
+	for _, c := range snapshot.GiftCards {
+		check(domain.GiftCardAccount(c.ID, c.RemainingAmount.Currency), c.RemainingAmount)
+	}
+
+	// Wallet and gift card accounts that only exist in the ledger have lost
+	// their cached balance altogether.
+	for account, balance := range snapshot.AccountBalances {
+		if _, ok := seen[account]; ok || !(strings.HasPrefix(account, "wallet:") || strings.HasPrefix(account, "gift_card:")) {
+			continue
+		}
+
+		if balance.Amount != 0 {
+			report.Drifts = append(report.Drifts, domain.LedgerDrift{
+				Account: account,
+				Ledger:  balance,
+				Cached:  domain.NewMoney(0, balance.Currency),
+			})
+		}
+	}
+
+	sort.Slice(report.Drifts, func(i, j int) bool {
+		return report.Drifts[i].Account < report.Drifts[j].Account
+	})
+
+	return report, nil
+}
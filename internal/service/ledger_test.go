@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+type LedgerServiceTestSuite struct {
+	suite.Suite
+	ledgerRepo    *repository.LedgerRepositoryMock
+	ledgerService *ledgerService
+}
+
+func (suite *LedgerServiceTestSuite) SetupTest() {
+	suite.ledgerRepo = new(repository.LedgerRepositoryMock)
+	suite.ledgerService = &ledgerService{
+		ledgerRepository: suite.ledgerRepo,
+	}
+}
+
+func (suite *LedgerServiceTestSuite) TestNewLedgerService() {
+	require := suite.Require()
+
+	service := NewLedgerService(suite.ledgerRepo)
+
+	require.NotNil(service)
+}
+
+func (suite *LedgerServiceTestSuite) TestReconcile_Balanced_Success() {
+	require := suite.Require()
+	snapshot := &domain.LedgerSnapshot{
+		AccountBalances: map[string]domain.Money{
+			domain.ExternalDepositsAccount("USD"):   domain.NewMoney(-100000, "USD"),
+			domain.WalletAvailableAccount(1, "USD"): domain.NewMoney(90000, "USD"),
+			domain.WalletHeldAccount(1, "USD"):      domain.NewMoney(5000, "USD"),
+			domain.GiftCardAccount(101, "USD"):      domain.NewMoney(5000, "USD"),
+		},
+		Wallets:   []domain.Wallet{{ID: 1, UserID: 1, Balance: domain.NewMoney(90000, "USD"), Held: domain.NewMoney(5000, "USD")}},
+		GiftCards: []domain.GiftCard{{ID: 101, RemainingAmount: domain.NewMoney(5000, "USD")}},
+	}
+
+	defer suite.ledgerRepo.On("Snapshot", mock.Anything, mock.Anything).Return(snapshot, nil).Unset()
+	report, err := suite.ledgerService.Reconcile(context.Background())
+
+	require.NoError(err)
+	require.True(report.OK())
+	require.Equal(4, report.Accounts)
+}
+
+func (suite *LedgerServiceTestSuite) TestReconcile_Drift_Success() {
+	require := suite.Require()
+	snapshot := &domain.LedgerSnapshot{
+		AccountBalances: map[string]domain.Money{
+			domain.ExternalDepositsAccount("USD"):   domain.NewMoney(-105000, "USD"),
+			domain.WalletAvailableAccount(1, "USD"): domain.NewMoney(90000, "USD"),
+			domain.WalletHeldAccount(1, "USD"):      domain.NewMoney(10000, "USD"),
+			domain.WalletAvailableAccount(2, "USD"): domain.NewMoney(5000, "USD"),
+			domain.GiftCardAccount(101, "USD"):      domain.NewMoney(2500, "USD"),
+			domain.GiftCardAccount(102, "USD"):      domain.NewMoney(1000, "USD"),
+		},
+		Wallets:                  []domain.Wallet{{ID: 1, UserID: 1, Balance: domain.NewMoney(85000, "USD"), Held: domain.NewMoney(10000, "USD")}},
+		GiftCards:                []domain.GiftCard{{ID: 101, RemainingAmount: domain.NewMoney(5000, "USD")}},
+		UnbalancedTransactionIDs: []uint{4},
+	}
+	expectedDrifts := []domain.LedgerDrift{
+		{Account: domain.GiftCardAccount(101, "USD"), Ledger: domain.NewMoney(2500, "USD"), Cached: domain.NewMoney(5000, "USD")},
+		{Account: domain.GiftCardAccount(102, "USD"), Ledger: domain.NewMoney(1000, "USD"), Cached: domain.NewMoney(0, "USD")},
+		{Account: domain.WalletAvailableAccount(1, "USD"), Ledger: domain.NewMoney(90000, "USD"), Cached: domain.NewMoney(85000, "USD")},
+		{Account: domain.WalletAvailableAccount(2, "USD"), Ledger: domain.NewMoney(5000, "USD"), Cached: domain.NewMoney(0, "USD")},
+	}
+
+	defer suite.ledgerRepo.On("Snapshot", mock.Anything, mock.Anything).Return(snapshot, nil).Unset()
+	report, err := suite.ledgerService.Reconcile(context.Background())
+
+	require.NoError(err)
+	require.False(report.OK())
+	require.Equal(expectedDrifts, report.Drifts)
+	require.Equal([]uint{4}, report.UnbalancedTransactionIDs)
+}
+
+func (suite *LedgerServiceTestSuite) TestReconcile_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("repo error")
+
+	defer suite.ledgerRepo.On("Snapshot", mock.Anything, mock.Anything).Return(nil, expectedError).Unset()
+	report, err := suite.ledgerService.Reconcile(context.Background())
+
+	require.EqualError(err, expectedError.Error())
+	require.Nil(report)
+}
+
+func TestLedgerService(t *testing.T) {
+	suite.Run(t, new(LedgerServiceTestSuite))
+}
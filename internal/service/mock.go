@@ -1,17 +1,54 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/stretchr/testify/mock"
 
 	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
 )
 
 type UserServiceMock struct {
 	mock.Mock
 }
 
-func (s *UserServiceMock) CreateUser(email, password string) (*domain.User, error) {
-	args := s.Called(email, password)
+func (s *UserServiceMock) CreateUser(ctx context.Context, email, password string) (*domain.User, error) {
+	args := s.Called(ctx, email, password)
+
+	var r0 *domain.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.User)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *UserServiceMock) FindUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	args := s.Called(ctx, email)
+
+	var r0 *domain.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.User)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *UserServiceMock) SetRoles(ctx context.Context, email string, roles []domain.Role) (*domain.User, error) {
+	args := s.Called(ctx, email, roles)
+
+	var r0 *domain.User
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.User)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *UserServiceMock) UnlockUser(ctx context.Context, id uint, actorID *uint) (*domain.User, error) {
+	args := s.Called(ctx, id, actorID)
 
 	var r0 *domain.User
 	if args.Get(0) != nil {
@@ -25,8 +62,8 @@ type GiftCardServiceMock struct {
 	mock.Mock
 }
 
-func (s *GiftCardServiceMock) CreateGiftCard(amount float64, gifterID, gifteeID uint) (*domain.GiftCard, error) {
-	args := s.Called(amount, gifterID, gifteeID)
+func (s *GiftCardServiceMock) CreateGiftCard(ctx context.Context, amount domain.Money, gifterID, gifteeID uint, expiresAt *time.Time) (*domain.GiftCard, error) {
+	args := s.Called(ctx, amount, gifterID, gifteeID, expiresAt)
 
 	var r0 *domain.GiftCard
 	if args.Get(0) != nil {
@@ -36,8 +73,25 @@ func (s *GiftCardServiceMock) CreateGiftCard(amount float64, gifterID, gifteeID
 	return r0, args.Error(1)
 }
 
-func (s *GiftCardServiceMock) FindGiftCard(id uint) (*domain.GiftCard, error) {
-	args := s.Called(id)
+func (s *GiftCardServiceMock) ExpireOverdueGiftCards(ctx context.Context, now time.Time, batchSize int) (int, error) {
+	args := s.Called(ctx, now, batchSize)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (s *GiftCardServiceMock) RedeemGiftCard(ctx context.Context, code string, redeemerID uint, amount domain.Money) (*domain.GiftCardRedemption, error) {
+	args := s.Called(ctx, code, redeemerID, amount)
+
+	var r0 *domain.GiftCardRedemption
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.GiftCardRedemption)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *GiftCardServiceMock) FindGiftCard(ctx context.Context, id uint) (*domain.GiftCard, error) {
+	args := s.Called(ctx, id)
 
 	var r0 *domain.GiftCard
 	if args.Get(0) != nil {
@@ -47,40 +101,224 @@ func (s *GiftCardServiceMock) FindGiftCard(id uint) (*domain.GiftCard, error) {
 	return r0, args.Error(1)
 }
 
-func (s *GiftCardServiceMock) UpdateStatus(giftCardID uint, status domain.GiftCardStatus) error {
-	args := s.Called(giftCardID, status)
+func (s *GiftCardServiceMock) UpdateStatus(ctx context.Context, giftCardID uint, version *uint, status domain.GiftCardStatus, actorID uint) (*domain.GiftCard, error) {
+	args := s.Called(ctx, giftCardID, version, status, actorID)
+
+	var r0 *domain.GiftCard
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.GiftCard)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *GiftCardServiceMock) ForceUpdateStatus(ctx context.Context, giftCardID uint, version *uint, status domain.GiftCardStatus, actorID uint) (*domain.GiftCard, error) {
+	args := s.Called(ctx, giftCardID, version, status, actorID)
+
+	var r0 *domain.GiftCard
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.GiftCard)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *GiftCardServiceMock) GetStatusHistory(ctx context.Context, giftCardID uint) ([]domain.GiftCardStatusChange, error) {
+	args := s.Called(ctx, giftCardID)
+
+	var r0 []domain.GiftCardStatusChange
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]domain.GiftCardStatusChange)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *GiftCardServiceMock) GetReceivedGiftCardsByUserID(ctx context.Context, userID uint, query repository.GiftCardQuery) (repository.GiftCardPage, error) {
+	args := s.Called(ctx, userID, query)
+
+	return args.Get(0).(repository.GiftCardPage), args.Error(1)
+}
+
+func (s *GiftCardServiceMock) GetSentGiftCardsByUserID(ctx context.Context, userID uint, query repository.GiftCardQuery) (repository.GiftCardPage, error) {
+	args := s.Called(ctx, userID, query)
+
+	return args.Get(0).(repository.GiftCardPage), args.Error(1)
+}
+
+type AuthServiceMock struct {
+	mock.Mock
+}
+
+func (s *AuthServiceMock) Login(ctx context.Context, email, password string) (*Tokens, error) {
+	args := s.Called(ctx, email, password)
+
+	var r0 *Tokens
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*Tokens)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *AuthServiceMock) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	args := s.Called(ctx, refreshToken)
+
+	var r0 *Tokens
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*Tokens)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *AuthServiceMock) Logout(ctx context.Context, accessToken AccessToken, refreshToken string) error {
+	args := s.Called(ctx, accessToken, refreshToken)
 
 	return args.Error(0)
 }
 
-func (s *GiftCardServiceMock) GetReceivedGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error) {
-	args := s.Called(userID, status, pageSize, pageNumber)
+func (s *AuthServiceMock) Authenticate(ctx context.Context, token string) (*AccessToken, error) {
+	args := s.Called(ctx, token)
 
-	var r0 []domain.GiftCard
+	var r0 *AccessToken
 	if args.Get(0) != nil {
-		r0 = args.Get(0).([]domain.GiftCard)
+		r0 = args.Get(0).(*AccessToken)
 	}
 
-	return r0, args.Int(1), args.Error(2)
+	return r0, args.Error(1)
+}
+
+type OutboxServiceMock struct {
+	mock.Mock
 }
 
-func (s *GiftCardServiceMock) GetSentGiftCardsByUserID(userID uint, status *domain.GiftCardStatus, pageSize int, pageNumber int) ([]domain.GiftCard, int, error) {
-	args := s.Called(userID, status, pageSize, pageNumber)
+func (s *OutboxServiceMock) RelayEvents(ctx context.Context, batchSize int) (int, error) {
+	args := s.Called(ctx, batchSize)
 
-	var r0 []domain.GiftCard
+	return args.Int(0), args.Error(1)
+}
+
+type WebhookServiceMock struct {
+	mock.Mock
+}
+
+func (s *WebhookServiceMock) CreateWebhook(ctx context.Context, userID uint, url string) (*domain.Webhook, error) {
+	args := s.Called(ctx, userID, url)
+
+	var r0 *domain.Webhook
 	if args.Get(0) != nil {
-		r0 = args.Get(0).([]domain.GiftCard)
+		r0 = args.Get(0).(*domain.Webhook)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *WebhookServiceMock) GetWebhooks(ctx context.Context, userID uint) ([]domain.Webhook, error) {
+	args := s.Called(ctx, userID)
+
+	var r0 []domain.Webhook
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]domain.Webhook)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *WebhookServiceMock) GetWebhook(ctx context.Context, userID, id uint) (*domain.Webhook, error) {
+	args := s.Called(ctx, userID, id)
+
+	var r0 *domain.Webhook
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.Webhook)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *WebhookServiceMock) UpdateWebhook(ctx context.Context, userID, id uint, url string, active bool) (*domain.Webhook, error) {
+	args := s.Called(ctx, userID, id, url, active)
+
+	var r0 *domain.Webhook
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.Webhook)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *WebhookServiceMock) DeleteWebhook(ctx context.Context, userID, id uint) error {
+	args := s.Called(ctx, userID, id)
+
+	return args.Error(0)
+}
+
+func (s *WebhookServiceMock) GetDeliveries(ctx context.Context, userID, webhookID uint, pageSize int, pageNumber int) ([]domain.WebhookDelivery, int, error) {
+	args := s.Called(ctx, userID, webhookID, pageSize, pageNumber)
+
+	var r0 []domain.WebhookDelivery
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]domain.WebhookDelivery)
 	}
 
 	return r0, args.Int(1), args.Error(2)
 }
 
-type AuthServiceMock struct {
+func (s *WebhookServiceMock) DeliverDueWebhooks(ctx context.Context, now time.Time, batchSize int) (int, error) {
+	args := s.Called(ctx, now, batchSize)
+
+	return args.Int(0), args.Error(1)
+}
+
+type AuditServiceMock struct {
+	mock.Mock
+}
+
+func (s *AuditServiceMock) RecordAdminRequest(ctx context.Context, actorID uint, request AdminRequest) error {
+	args := s.Called(ctx, actorID, request)
+
+	return args.Error(0)
+}
+
+func (s *AuditServiceMock) FindEvents(ctx context.Context, query repository.AuditQuery) ([]domain.AuditEvent, error) {
+	args := s.Called(ctx, query)
+
+	var r0 []domain.AuditEvent
+	if args.Get(0) != nil {
+		r0 = args.Get(0).([]domain.AuditEvent)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *AuditServiceMock) Verify(ctx context.Context) (*domain.AuditChainReport, error) {
+	args := s.Called(ctx)
+
+	var r0 *domain.AuditChainReport
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.AuditChainReport)
+	}
+
+	return r0, args.Error(1)
+}
+
+func (s *AuditServiceMock) ChainPendingEvents(ctx context.Context, batchSize int) (int, error) {
+	args := s.Called(ctx, batchSize)
+
+	return args.Int(0), args.Error(1)
+}
+
+type WalletServiceMock struct {
 	mock.Mock
 }
 
-func (s *AuthServiceMock) Login(email, password string) (string, error) {
-	args := s.Called(email, password)
+func (s *WalletServiceMock) Deposit(ctx context.Context, userID uint, amount domain.Money, actorID *uint) (*domain.Wallet, error) {
+	args := s.Called(ctx, userID, amount, actorID)
 
-	return args.String(0), args.Error(1)
+	var r0 *domain.Wallet
+	if args.Get(0) != nil {
+		r0 = args.Get(0).(*domain.Wallet)
+	}
+
+	return r0, args.Error(1)
 }
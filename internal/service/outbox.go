@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmehdipour/gift-card/internal/infrastructure/messaging"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+type OutboxService interface {
+	RelayEvents(ctx context.Context, batchSize int) (int, error)
+}
+
+type outboxService struct {
+	outboxRepository repository.OutboxRepository
+	publisher        messaging.EventPublisher
+}
+
+func NewOutboxService(outboxRepo repository.OutboxRepository, publisher messaging.EventPublisher) OutboxService {
+	return &outboxService{
+		outboxRepository: outboxRepo,
+		publisher:        publisher,
+	}
+}
+
+// RelayEvents publishes up to batchSize events from the outbox and returns
+// how many of them were published.
+func (s *outboxService) RelayEvents(ctx context.Context, batchSize int) (int, error) {
+	return s.outboxRepository.Relay(ctx, batchSize, s.publisher.Publish)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/messaging"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+type OutboxServiceTestSuite struct {
+	suite.Suite
+	outboxRepo    *repository.OutboxRepositoryMock
+	publisher     *messaging.EventPublisherMock
+	outboxService *outboxService
+}
+
+func (suite *OutboxServiceTestSuite) SetupTest() {
+	suite.outboxRepo = new(repository.OutboxRepositoryMock)
+	suite.publisher = new(messaging.EventPublisherMock)
+	suite.outboxService = &outboxService{
+		outboxRepository: suite.outboxRepo,
+		publisher:        suite.publisher,
+	}
+}
+
+func (suite *OutboxServiceTestSuite) TestNewOutboxService() {
+	require := suite.Require()
+
+	service := NewOutboxService(suite.outboxRepo, suite.publisher)
+
+	require.NotNil(service)
+}
+
+func (suite *OutboxServiceTestSuite) TestRelayEvents_Success() {
+	require := suite.Require()
+	event := domain.Event{ID: 1, Type: domain.EventGiftCardCreated, AggregateID: 101, Payload: []byte(`{}`)}
+
+	suite.publisher.On("Publish", event).Return(nil).Once()
+	suite.outboxRepo.On("Relay", mock.Anything, 100, mock.Anything).
+		Run(func(args mock.Arguments) {
+			publish := args.Get(2).(func(domain.Event) error)
+			require.NoError(publish(event))
+		}).
+		Return(1, nil).Once()
+	published, err := suite.outboxService.RelayEvents(context.Background(), 100)
+
+	require.NoError(err)
+	require.Equal(1, published)
+	suite.publisher.AssertExpectations(suite.T())
+}
+
+func (suite *OutboxServiceTestSuite) TestRelayEvents_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("repository error")
+
+	suite.outboxRepo.On("Relay", mock.Anything, 100, mock.Anything).Return(0, expectedError).Once()
+	published, err := suite.outboxService.RelayEvents(context.Background(), 100)
+
+	require.ErrorIs(err, expectedError)
+	require.Zero(published)
+}
+
+func TestOutboxService(t *testing.T) {
+	suite.Run(t, new(OutboxServiceTestSuite))
+}
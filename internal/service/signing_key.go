@@ -0,0 +1,234 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+	"github.com/jmehdipour/gift-card/internal/domain"
+)
+
+// minRSAKeyBits is the smallest RSA key access tokens are signed with.
+const minRSAKeyBits = 2048
+
+// SigningKey is a private key access tokens are signed with, ID is the kid of
+// the tokens it signs.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+}
+
+// KeySet holds the keys of the access tokens. The active key signs new
+// tokens, every key verifies the tokens it signed.
+type KeySet struct {
+	active string
+	keys   map[string]SigningKey
+	ids    []string
+}
+
+// JWK is the public part of a signing key as a JSON Web Key.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKeySet returns the set of the keys, active is the id of the one that
+// signs new tokens. A key without an algorithm gets the one of its type.
+func NewKeySet(active string, keys ...SigningKey) (*KeySet, error) {
+	set := &KeySet{active: active, keys: make(map[string]SigningKey, len(keys))}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("signing key has no id")
+		}
+
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("signing key %s is duplicated", key.ID)
+		}
+
+		algorithm, err := signingAlgorithm(key)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", key.ID, err)
+		}
+
+		key.Algorithm = algorithm
+		set.keys[key.ID] = key
+		set.ids = append(set.ids, key.ID)
+	}
+
+	if _, ok := set.keys[active]; !ok {
+		return nil, fmt.Errorf("active signing key %q is not one of the signing keys", active)
+	}
+
+	return set, nil
+}
+
+// LoadKeySet reads the signing keys of the config from their PEM files.
+func LoadKeySet(cfg config.User) (*KeySet, error) {
+	keys := make([]SigningKey, 0, len(cfg.SigningKeys))
+	for _, k := range cfg.SigningKeys {
+		privateKey, err := readPrivateKey(k.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", k.ID, err)
+		}
+
+		keys = append(keys, SigningKey{ID: k.ID, Algorithm: k.Algorithm, PrivateKey: privateKey})
+	}
+
+	return NewKeySet(cfg.ActiveKeyID, keys...)
+}
+
+// NewEphemeralKeySet returns a set of a new EdDSA key, the tokens it signs
+// are only good for the process.
+func NewEphemeralKeySet() (*KeySet, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := domain.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeySet(id, SigningKey{ID: id, PrivateKey: privateKey})
+}
+
+// Sign signs the claims with the active key.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key := s.keys[s.active]
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.PrivateKey)
+}
+
+// Keyfunc returns the public key of the kid of the token. The token must be
+// signed with the algorithm of that key, so a public key can never be used
+// as the secret of an HMAC or with another algorithm.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("signing key %s does not sign %s", kid, token.Method.Alg())
+	}
+
+	return key.PrivateKey.Public(), nil
+}
+
+// Algorithms returns the algorithms of the keys.
+func (s *KeySet) Algorithms() []string {
+	var algorithms []string
+	seen := make(map[string]bool)
+	for _, id := range s.ids {
+		algorithm := s.keys[id].Algorithm
+		if !seen[algorithm] {
+			seen[algorithm] = true
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+
+	return algorithms
+}
+
+// JWKS returns the public keys of the set.
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(s.ids))}
+	for _, id := range s.ids {
+		key := s.keys[id]
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch publicKey := key.PrivateKey.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// signingAlgorithm returns the algorithm of the key, which must fit its type.
+func signingAlgorithm(key SigningKey) (string, error) {
+	switch privateKey := key.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		if privateKey.N.BitLen() < minRSAKeyBits {
+			return "", fmt.Errorf("RSA key has %d bits, at least %d are required", privateKey.N.BitLen(), minRSAKeyBits)
+		}
+
+		if key.Algorithm != "" && key.Algorithm != jwt.SigningMethodRS256.Alg() {
+			return "", fmt.Errorf("RSA key cannot sign %s", key.Algorithm)
+		}
+
+		return jwt.SigningMethodRS256.Alg(), nil
+	case ed25519.PrivateKey:
+		if key.Algorithm != "" && key.Algorithm != jwt.SigningMethodEdDSA.Alg() {
+			return "", fmt.Errorf("Ed25519 key cannot sign %s", key.Algorithm)
+		}
+
+		return jwt.SigningMethodEdDSA.Alg(), nil
+	}
+
+	return "", fmt.Errorf("%T is not an RSA or Ed25519 key", key.PrivateKey)
+}
+
+// readPrivateKey reads a PKCS #8 or PKCS #1 private key from a PEM file.
+func readPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%T is not an RSA or Ed25519 key", privateKey)
+	}
+
+	return signer, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/config"
+)
+
+type KeySetTestSuite struct {
+	suite.Suite
+	edKey  ed25519.PrivateKey
+	rsaKey *rsa.PrivateKey
+}
+
+func (suite *KeySetTestSuite) SetupSuite() {
+	require := suite.Require()
+
+	var err error
+	_, suite.edKey, err = ed25519.GenerateKey(rand.Reader)
+	require.NoError(err)
+	suite.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+}
+
+// writeKey writes the key to a PEM file in a temporary directory.
+func (suite *KeySetTestSuite) writeKey(name, blockType string, der []byte) string {
+	path := filepath.Join(suite.T().TempDir(), name)
+	suite.Require().NoError(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+
+	return path
+}
+
+func (suite *KeySetTestSuite) TestLoadKeySet_Success() {
+	require := suite.Require()
+	edDER, err := x509.MarshalPKCS8PrivateKey(suite.edKey)
+	require.NoError(err)
+
+	keys, err := LoadKeySet(config.User{
+		SigningKeys: []config.SigningKey{
+			{ID: "2024-02", PrivateKeyFile: suite.writeKey("2024-02.pem", "PRIVATE KEY", edDER)},
+			{ID: "2024-01", Algorithm: "RS256", PrivateKeyFile: suite.writeKey("2024-01.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(suite.rsaKey))},
+		},
+		ActiveKeyID: "2024-02",
+	})
+
+	require.NoError(err)
+	require.Equal([]string{"EdDSA", "RS256"}, keys.Algorithms())
+
+	token, err := keys.Sign(jwt.RegisteredClaims{ID: "jti"})
+	require.NoError(err)
+	parsed, err := jwt.Parse(token, keys.Keyfunc)
+	require.NoError(err)
+	require.Equal("EdDSA", parsed.Method.Alg())
+	require.Equal("2024-02", parsed.Header["kid"])
+}
+
+func (suite *KeySetTestSuite) TestLoadKeySet_Failure() {
+	require := suite.Require()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(err)
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(suite.edKey)
+	require.NoError(err)
+	edFile := suite.writeKey("ed.pem", "PRIVATE KEY", edDER)
+
+	for name, cfg := range map[string]config.User{
+		"missing file":    {SigningKeys: []config.SigningKey{{ID: "a", PrivateKeyFile: filepath.Join(suite.T().TempDir(), "missing.pem")}}, ActiveKeyID: "a"},
+		"not PEM":         {SigningKeys: []config.SigningKey{{ID: "a", PrivateKeyFile: suite.writeKey("raw.pem", "", nil)}}, ActiveKeyID: "a"},
+		"ECDSA key":       {SigningKeys: []config.SigningKey{{ID: "a", PrivateKeyFile: suite.writeKey("ec.pem", "PRIVATE KEY", ecDER)}}, ActiveKeyID: "a"},
+		"small RSA key":   {SigningKeys: []config.SigningKey{{ID: "a", PrivateKeyFile: suite.writeKey("small.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(smallKey))}}, ActiveKeyID: "a"},
+		"wrong algorithm": {SigningKeys: []config.SigningKey{{ID: "a", Algorithm: "RS256", PrivateKeyFile: edFile}}, ActiveKeyID: "a"},
+		"no id":           {SigningKeys: []config.SigningKey{{PrivateKeyFile: edFile}}},
+		"duplicated id":   {SigningKeys: []config.SigningKey{{ID: "a", PrivateKeyFile: edFile}, {ID: "a", PrivateKeyFile: edFile}}, ActiveKeyID: "a"},
+		"unknown active":  {SigningKeys: []config.SigningKey{{ID: "a", PrivateKeyFile: edFile}}, ActiveKeyID: "b"},
+		"no keys":         {},
+	} {
+		keys, err := LoadKeySet(cfg)
+
+		require.Error(err, name)
+		require.Nil(keys, name)
+	}
+}
+
+// TestJWKS_Success checks that a token can be verified with nothing but the
+// published keys.
+func (suite *KeySetTestSuite) TestJWKS_Success() {
+	require := suite.Require()
+	keys, err := NewKeySet("rsa",
+		SigningKey{ID: "ed", PrivateKey: suite.edKey},
+		SigningKey{ID: "rsa", PrivateKey: suite.rsaKey},
+	)
+	require.NoError(err)
+
+	jwks := keys.JWKS()
+
+	require.Len(jwks.Keys, 2)
+	ed, rsaJWK := jwks.Keys[0], jwks.Keys[1]
+	require.Equal(JWK{KeyType: "OKP", KeyID: "ed", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: ed.X}, ed)
+	x, err := base64.RawURLEncoding.DecodeString(ed.X)
+	require.NoError(err)
+	require.Equal([]byte(suite.edKey.Public().(ed25519.PublicKey)), x)
+
+	require.Equal("RSA", rsaJWK.KeyType)
+	require.Equal("RS256", rsaJWK.Algorithm)
+	require.Equal("AQAB", rsaJWK.E)
+	n, err := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	require.NoError(err)
+	publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+
+	token, err := keys.Sign(jwt.RegisteredClaims{ID: "jti"})
+	require.NoError(err)
+	_, err = jwt.Parse(token, func(*jwt.Token) (interface{}, error) { return publicKey, nil }, jwt.WithValidMethods([]string{rsaJWK.Algorithm}))
+	require.NoError(err)
+}
+
+func (suite *KeySetTestSuite) TestNewEphemeralKeySet_Success() {
+	require := suite.Require()
+
+	keys, err := NewEphemeralKeySet()
+
+	require.NoError(err)
+	require.Equal([]string{"EdDSA"}, keys.Algorithms())
+	require.Len(keys.JWKS().Keys, 1)
+}
+
+func TestKeySet(t *testing.T) {
+	suite.Run(t, new(KeySetTestSuite))
+}
@@ -1,30 +1,97 @@
 package service
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/jmehdipour/gift-card/internal/domain"
 	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
 )
 
 type UserService interface {
-	CreateUser(email, password string) (*domain.User, error)
+	CreateUser(ctx context.Context, email, password string) (*domain.User, error)
+	FindUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	SetRoles(ctx context.Context, email string, roles []domain.Role) (*domain.User, error)
+	UnlockUser(ctx context.Context, id uint, actorID *uint) (*domain.User, error)
 }
 
 type userService struct {
-	userRepository repository.UserRepository
+	userRepository         repository.UserRepository
+	loginFailureRepository repository.LoginFailureRepository
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepository: userRepo}
+func NewUserService(userRepo repository.UserRepository, loginFailureRepo repository.LoginFailureRepository) UserService {
+	return &userService{userRepository: userRepo, loginFailureRepository: loginFailureRepo}
 }
 
-func (s *userService) CreateUser(email, password string) (*domain.User, error) {
+func (s *userService) CreateUser(ctx context.Context, email, password string) (*domain.User, error) {
 	user := &domain.User{Email: email}
 	err := user.SetPassword(password)
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.userRepository.Create(user)
+	err = s.userRepository.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// FindUserByEmail returns the user with the email or domain.ErrUserNotFound.
+func (s *userService) FindUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, err := s.userRepository.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// SetRoles replaces the roles of the user with the email. The new roles are
+// in the access tokens issued from the next login or refresh on.
+func (s *userService) SetRoles(ctx context.Context, email string, roles []domain.Role) (*domain.User, error) {
+	for _, role := range roles {
+		if !role.IsValid() {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidRole, role)
+		}
+	}
+
+	user, err := s.FindUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.userRepository.SetRoles(ctx, user.ID, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Roles = roles
+
+	return user, nil
+}
+
+// UnlockUser forgets the failed logins of the email of the user with the id,
+// which lifts a lockout or a delay of its logins before they expire. The
+// failures of the IPs they came from are kept. actorID is nil for the
+// commands.
+func (s *userService) UnlockUser(ctx context.Context, id uint, actorID *uint) (*domain.User, error) {
+	user, err := s.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	_, err = s.loginFailureRepository.Unlock(ctx, domain.AccountLoginKey(user.Email), actorID, &user.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -13,21 +14,24 @@ import (
 
 type UserServiceTestSuite struct {
 	suite.Suite
-	userRepo    *repository.UserRepositoryMock
-	userService *userService
+	userRepo         *repository.UserRepositoryMock
+	loginFailureRepo *repository.LoginFailureRepositoryMock
+	userService      *userService
 }
 
 func (suite *UserServiceTestSuite) SetupTest() {
 	suite.userRepo = new(repository.UserRepositoryMock)
+	suite.loginFailureRepo = new(repository.LoginFailureRepositoryMock)
 	suite.userService = &userService{
-		userRepository: suite.userRepo,
+		userRepository:         suite.userRepo,
+		loginFailureRepository: suite.loginFailureRepo,
 	}
 }
 
 func (suite *UserServiceTestSuite) TestNewGiftCardRepository() {
 	require := suite.Require()
 
-	repo := NewUserService(suite.userRepo)
+	repo := NewUserService(suite.userRepo, suite.loginFailureRepo)
 
 	require.NotNil(repo)
 }
@@ -40,8 +44,8 @@ func (suite *UserServiceTestSuite) TestCreateUser_Success() {
 		Password: "password",
 	}
 
-	defer suite.userRepo.On("Create", mock.Anything).Return(nil).Unset()
-	userResult, err := suite.userService.CreateUser(user.Email, user.Password)
+	defer suite.userRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Unset()
+	userResult, err := suite.userService.CreateUser(context.Background(), user.Email, user.Password)
 
 	require.NoError(err)
 	require.Equal(user.ID, userResult.ID)
@@ -55,15 +59,82 @@ func (suite *UserServiceTestSuite) TestCreateUser_Failure() {
 		Password: "password",
 	}
 
-	defer suite.userRepo.On("Create", mock.Anything).Return(expectedError).Unset()
+	defer suite.userRepo.On("Create", mock.Anything, mock.Anything).Return(expectedError).Unset()
 
-	userResult, err := suite.userService.CreateUser(user.Email, user.Password)
+	userResult, err := suite.userService.CreateUser(context.Background(), user.Email, user.Password)
 
 	require.Error(err)
 	require.EqualError(expectedError, err.Error())
 	require.Empty(userResult)
 }
 
+func (suite *UserServiceTestSuite) TestFindUserByEmail_NotFound_Failure() {
+	require := suite.Require()
+
+	defer suite.userRepo.On("FindByEmail", mock.Anything, "foo@example.com").Return(nil, nil).Unset()
+	user, err := suite.userService.FindUserByEmail(context.Background(), "foo@example.com")
+
+	require.ErrorIs(err, domain.ErrUserNotFound)
+	require.Nil(user)
+}
+
+func (suite *UserServiceTestSuite) TestSetRoles_Success() {
+	require := suite.Require()
+	user := &domain.User{ID: 15, Email: "foo@example.com"}
+	roles := []domain.Role{domain.RoleSupport}
+
+	defer suite.userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil).Unset()
+	defer suite.userRepo.On("SetRoles", mock.Anything, user.ID, roles).Return(nil).Unset()
+	result, err := suite.userService.SetRoles(context.Background(), user.Email, roles)
+
+	require.NoError(err)
+	require.Equal(roles, result.Roles)
+	require.Equal([]domain.Permission{
+		domain.PermissionExpireGiftCard,
+		domain.PermissionReadGiftCards,
+		domain.PermissionVoidGiftCard,
+		domain.PermissionReadUsers,
+		domain.PermissionUnlockUsers,
+	}, result.Permissions())
+}
+
+func (suite *UserServiceTestSuite) TestSetRoles_InvalidRole_Failure() {
+	require := suite.Require()
+
+	result, err := suite.userService.SetRoles(context.Background(), "foo@example.com", []domain.Role{"root"})
+
+	require.ErrorIs(err, domain.ErrInvalidRole)
+	require.Nil(result)
+	suite.userRepo.AssertNotCalled(suite.T(), "SetRoles", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestUnlockUser_Success() {
+	require := suite.Require()
+	user := &domain.User{ID: 10, Email: "Foo@example.com"}
+	actorID := uint(3)
+
+	suite.userRepo.On("FindByID", mock.Anything, uint(10)).Return(user, nil)
+	suite.loginFailureRepo.On("Unlock", mock.Anything, "account:foo@example.com", &actorID, &user.ID).Return(true, nil)
+
+	unlocked, err := suite.userService.UnlockUser(context.Background(), 10, &actorID)
+
+	require.NoError(err)
+	require.Equal(user, unlocked)
+	suite.loginFailureRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestUnlockUser_NotFound_Failure() {
+	require := suite.Require()
+
+	suite.userRepo.On("FindByID", mock.Anything, uint(10)).Return(nil, nil)
+
+	user, err := suite.userService.UnlockUser(context.Background(), 10, nil)
+
+	require.ErrorIs(err, domain.ErrUserNotFound)
+	require.Nil(user)
+	suite.loginFailureRepo.AssertNotCalled(suite.T(), "Unlock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestUserService(t *testing.T) {
 	suite.Run(t, new(UserServiceTestSuite))
 }
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+type WalletService interface {
+	Deposit(ctx context.Context, userID uint, amount domain.Money, actorID *uint) (*domain.Wallet, error)
+}
+
+type walletService struct {
+	unitOfWork repository.UnitOfWork
+}
+
+func NewWalletService(unitOfWork repository.UnitOfWork) WalletService {
+	return &walletService{unitOfWork: unitOfWork}
+}
+
+// Deposit funds the wallet of the user with amount from outside on behalf of
+// the actor and returns the wallet, domain.ErrUserNotFound if there is no
+// such user. The deposit is recorded in the ledger and the audit log.
+func (s *walletService) Deposit(ctx context.Context, userID uint, amount domain.Money, actorID *uint) (*domain.Wallet, error) {
+	if !amount.IsPositive() {
+		return nil, domain.ErrInvalidAmount
+	}
+
+	var wallet *domain.Wallet
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		user, err := repos.Users.FindByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return domain.ErrUserNotFound
+		}
+
+		err = repos.Wallets.Deposit(ctx, userID, amount, actorID)
+		if err != nil {
+			return err
+		}
+
+		wallet, err = repos.Wallets.FindByUserID(ctx, userID, amount.Currency)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
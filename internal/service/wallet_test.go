@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+type WalletServiceTestSuite struct {
+	suite.Suite
+	userRepo      *repository.UserRepositoryMock
+	walletRepo    *repository.WalletRepositoryMock
+	unitOfWork    *repository.UnitOfWorkMock
+	walletService WalletService
+}
+
+func (suite *WalletServiceTestSuite) SetupTest() {
+	suite.userRepo = new(repository.UserRepositoryMock)
+	suite.walletRepo = new(repository.WalletRepositoryMock)
+	suite.unitOfWork = &repository.UnitOfWorkMock{Repositories: repository.Repositories{Users: suite.userRepo, Wallets: suite.walletRepo}}
+	suite.unitOfWork.On("Do", mock.Anything).Return(nil)
+	suite.walletService = NewWalletService(suite.unitOfWork)
+}
+
+func (suite *WalletServiceTestSuite) TestDeposit_Success() {
+	require := suite.Require()
+	userID, actorID := uint(10), uint(1)
+	amount := domain.NewMoney(25000, "USD")
+	wallet := &domain.Wallet{ID: 3, UserID: userID, Balance: domain.NewMoney(35000, "USD"), Held: domain.NewMoney(0, "USD")}
+
+	suite.userRepo.On("FindByID", mock.Anything, userID).Return(&domain.User{ID: userID}, nil)
+	suite.walletRepo.On("Deposit", mock.Anything, userID, amount, &actorID).Return(nil)
+	suite.walletRepo.On("FindByUserID", mock.Anything, userID, "USD").Return(wallet, nil)
+
+	result, err := suite.walletService.Deposit(context.Background(), userID, amount, &actorID)
+
+	require.NoError(err)
+	require.Equal(wallet, result)
+}
+
+func (suite *WalletServiceTestSuite) TestDeposit_InvalidAmount_Failure() {
+	require := suite.Require()
+
+	for _, amount := range []domain.Money{domain.NewMoney(0, "USD"), domain.NewMoney(-100, "USD")} {
+		result, err := suite.walletService.Deposit(context.Background(), 10, amount, nil)
+
+		require.ErrorIs(err, domain.ErrInvalidAmount)
+		require.Nil(result)
+	}
+
+	suite.unitOfWork.AssertNotCalled(suite.T(), "Do", mock.Anything)
+}
+
+func (suite *WalletServiceTestSuite) TestDeposit_UserNotFound_Failure() {
+	require := suite.Require()
+
+	suite.userRepo.On("FindByID", mock.Anything, uint(10)).Return(nil, nil)
+
+	result, err := suite.walletService.Deposit(context.Background(), 10, domain.NewMoney(25000, "USD"), nil)
+
+	require.ErrorIs(err, domain.ErrUserNotFound)
+	require.Nil(result)
+	suite.walletRepo.AssertNotCalled(suite.T(), "Deposit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *WalletServiceTestSuite) TestDeposit_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("repo error")
+	amount := domain.NewMoney(25000, "USD")
+
+	suite.userRepo.On("FindByID", mock.Anything, uint(10)).Return(&domain.User{ID: 10}, nil)
+	suite.walletRepo.On("Deposit", mock.Anything, uint(10), amount, (*uint)(nil)).Return(expectedError)
+
+	result, err := suite.walletService.Deposit(context.Background(), 10, amount, nil)
+
+	require.ErrorIs(err, expectedError)
+	require.Nil(result)
+	suite.walletRepo.AssertNotCalled(suite.T(), "FindByUserID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWalletService(t *testing.T) {
+	suite.Run(t, new(WalletServiceTestSuite))
+}
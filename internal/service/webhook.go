@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/messaging"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+var errWebhookInactive = errors.New("webhook is inactive")
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, userID uint, url string) (*domain.Webhook, error)
+	GetWebhooks(ctx context.Context, userID uint) ([]domain.Webhook, error)
+	GetWebhook(ctx context.Context, userID, id uint) (*domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, userID, id uint, url string, active bool) (*domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, id uint) error
+	GetDeliveries(ctx context.Context, userID, webhookID uint, pageSize int, pageNumber int) ([]domain.WebhookDelivery, int, error)
+	DeliverDueWebhooks(ctx context.Context, now time.Time, batchSize int) (int, error)
+}
+
+type webhookService struct {
+	webhookRepository repository.WebhookRepository
+	sender            messaging.WebhookSender
+	lease             time.Duration
+}
+
+// NewWebhookService returns a WebhookService whose deliveries are claimed for
+// lease while they are being sent, it should be longer than sending a whole
+// batch can take.
+func NewWebhookService(webhookRepo repository.WebhookRepository, sender messaging.WebhookSender, lease time.Duration) WebhookService {
+	return &webhookService{
+		webhookRepository: webhookRepo,
+		sender:            sender,
+		lease:             lease,
+	}
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, userID uint, url string) (*domain.Webhook, error) {
+	if err := domain.ValidateWebhookURL(url); err != nil {
+		return nil, err
+	}
+
+	secret, err := domain.NewWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &domain.Webhook{UserID: userID, URL: url, Secret: secret, Active: true}
+	err = s.webhookRepository.Create(ctx, webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *webhookService) GetWebhooks(ctx context.Context, userID uint) ([]domain.Webhook, error) {
+	return s.webhookRepository.FindByUserID(ctx, userID)
+}
+
+// GetWebhook returns the webhook if it belongs to the user. Webhooks of other
+// users are reported as not found.
+func (s *webhookService) GetWebhook(ctx context.Context, userID, id uint) (*domain.Webhook, error) {
+	webhook, err := s.webhookRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if webhook == nil || webhook.UserID != userID {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+func (s *webhookService) UpdateWebhook(ctx context.Context, userID, id uint, url string, active bool) (*domain.Webhook, error) {
+	if err := domain.ValidateWebhookURL(url); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.GetWebhook(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.URL = url
+	webhook.Active = active
+	err = s.webhookRepository.Update(ctx, webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, userID, id uint) error {
+	if _, err := s.GetWebhook(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.webhookRepository.Delete(ctx, id)
+}
+
+func (s *webhookService) GetDeliveries(ctx context.Context, userID, webhookID uint, pageSize int, pageNumber int) ([]domain.WebhookDelivery, int, error) {
+	if _, err := s.GetWebhook(ctx, userID, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.webhookRepository.FindDeliveriesByWebhookID(ctx, webhookID, pageSize, pageNumber)
+}
+
+// DeliverDueWebhooks sends up to batchSize deliveries that are due at now and
+// records the outcome of every attempt. It returns how many deliveries it
+// attempted. Deliveries to webhooks that were deactivated in the meantime are
+// dead-lettered without being sent.
+func (s *webhookService) DeliverDueWebhooks(ctx context.Context, now time.Time, batchSize int) (int, error) {
+	deliveries, err := s.webhookRepository.ClaimDueDeliveries(ctx, now, s.lease, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	webhooks := make(map[uint]*domain.Webhook)
+	for i, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = s.webhookRepository.FindByID(ctx, delivery.WebhookID)
+			if err != nil {
+				return i, fmt.Errorf("find webhook %d: %w", delivery.WebhookID, err)
+			}
+
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		if webhook == nil || !webhook.Active {
+			delivery.DeadLetter(errWebhookInactive)
+		} else {
+			statusCode, err := s.sender.Send(ctx, *webhook, delivery)
+			delivery.RecordAttempt(time.Now(), statusCode, err)
+		}
+
+		err = s.webhookRepository.UpdateDelivery(ctx, delivery)
+		if err != nil {
+			return i, fmt.Errorf("update webhook delivery %d: %w", delivery.ID, err)
+		}
+	}
+
+	return len(deliveries), nil
+}
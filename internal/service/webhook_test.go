@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/domain"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/messaging"
+	"github.com/jmehdipour/gift-card/internal/infrastructure/persistance/repository"
+)
+
+type WebhookServiceTestSuite struct {
+	suite.Suite
+	webhookRepo    *repository.WebhookRepositoryMock
+	sender         *messaging.WebhookSenderMock
+	webhookService *webhookService
+}
+
+func (suite *WebhookServiceTestSuite) SetupTest() {
+	suite.webhookRepo = new(repository.WebhookRepositoryMock)
+	suite.sender = new(messaging.WebhookSenderMock)
+	suite.webhookService = &webhookService{
+		webhookRepository: suite.webhookRepo,
+		sender:            suite.sender,
+		lease:             time.Minute,
+	}
+}
+
+func (suite *WebhookServiceTestSuite) TestNewWebhookService() {
+	require := suite.Require()
+
+	service := NewWebhookService(suite.webhookRepo, suite.sender, time.Minute)
+
+	require.NotNil(service)
+}
+
+func (suite *WebhookServiceTestSuite) TestCreateWebhook_Success() {
+	require := suite.Require()
+
+	suite.webhookRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Webhook")).Return(nil).Once()
+	webhook, err := suite.webhookService.CreateWebhook(context.Background(), 10, "https://example.com/hook")
+
+	require.NoError(err)
+	require.Equal(uint(10), webhook.UserID)
+	require.Equal("https://example.com/hook", webhook.URL)
+	require.True(webhook.Active)
+	require.Len(webhook.Secret, 64)
+}
+
+func (suite *WebhookServiceTestSuite) TestCreateWebhook_InvalidURL_Failure() {
+	require := suite.Require()
+
+	webhook, err := suite.webhookService.CreateWebhook(context.Background(), 10, "ftp://example.com/hook")
+
+	require.ErrorIs(err, domain.ErrInvalidWebhookURL)
+	require.Nil(webhook)
+	suite.webhookRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *WebhookServiceTestSuite) TestCreateWebhook_InternalURL_Failure() {
+	require := suite.Require()
+
+	for _, url := range []string{"http://127.0.0.1/hook", "http://localhost:8080/hook", "http://10.0.0.1/hook", "http://169.254.169.254/latest", "http://[::1]/hook", "http://[::ffff:192.168.0.1]/hook"} {
+		webhook, err := suite.webhookService.CreateWebhook(context.Background(), 10, url)
+
+		require.ErrorIs(err, domain.ErrWebhookURLNotPublic, url)
+		require.Nil(webhook)
+	}
+
+	suite.webhookRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *WebhookServiceTestSuite) TestGetWebhook_OtherUser_Failure() {
+	require := suite.Require()
+
+	suite.webhookRepo.On("FindByID", mock.Anything, uint(3)).Return(&domain.Webhook{ID: 3, UserID: 20}, nil).Once()
+	webhook, err := suite.webhookService.GetWebhook(context.Background(), 10, 3)
+
+	require.ErrorIs(err, domain.ErrWebhookNotFound)
+	require.Nil(webhook)
+}
+
+func (suite *WebhookServiceTestSuite) TestUpdateWebhook_Success() {
+	require := suite.Require()
+	expectedResult := &domain.Webhook{ID: 3, UserID: 10, URL: "https://example.com/new", Active: false}
+
+	suite.webhookRepo.On("FindByID", mock.Anything, uint(3)).Return(&domain.Webhook{ID: 3, UserID: 10, URL: "https://example.com/hook", Active: true}, nil).Once()
+	suite.webhookRepo.On("Update", mock.Anything, expectedResult).Return(nil).Once()
+	webhook, err := suite.webhookService.UpdateWebhook(context.Background(), 10, 3, "https://example.com/new", false)
+
+	require.NoError(err)
+	require.Equal(expectedResult, webhook)
+}
+
+func (suite *WebhookServiceTestSuite) TestDeleteWebhook_NotFound_Failure() {
+	require := suite.Require()
+
+	suite.webhookRepo.On("FindByID", mock.Anything, uint(3)).Return(nil, nil).Once()
+	err := suite.webhookService.DeleteWebhook(context.Background(), 10, 3)
+
+	require.ErrorIs(err, domain.ErrWebhookNotFound)
+	suite.webhookRepo.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func (suite *WebhookServiceTestSuite) TestDeliverDueWebhooks_Success() {
+	require := suite.Require()
+	now := time.Now()
+	webhook := &domain.Webhook{ID: 3, UserID: 10, URL: "https://example.com/hook", Secret: "secret", Active: true}
+	succeeded := domain.WebhookDelivery{ID: 7, WebhookID: 3}
+	failed := domain.WebhookDelivery{ID: 8, WebhookID: 3}
+
+	suite.webhookRepo.On("ClaimDueDeliveries", mock.Anything, now, time.Minute, 10).Return([]domain.WebhookDelivery{succeeded, failed}, nil).Once()
+	suite.webhookRepo.On("FindByID", mock.Anything, uint(3)).Return(webhook, nil).Once()
+	suite.sender.On("Send", mock.Anything, *webhook, succeeded).Return(200, nil).Once()
+	suite.sender.On("Send", mock.Anything, *webhook, failed).Return(500, nil).Once()
+	suite.webhookRepo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		return d.ID == 7 && d.Status == domain.WDSSucceeded && d.Attempts == 1 && d.LastStatusCode == 200
+	})).Return(nil).Once()
+	suite.webhookRepo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		return d.ID == 8 && d.Status == domain.WDSPending && d.Attempts == 1 && d.LastStatusCode == 500 && d.NextAttemptAt.After(now)
+	})).Return(nil).Once()
+	delivered, err := suite.webhookService.DeliverDueWebhooks(context.Background(), now, 10)
+
+	require.NoError(err)
+	require.Equal(2, delivered)
+	suite.webhookRepo.AssertExpectations(suite.T())
+	suite.sender.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookServiceTestSuite) TestDeliverDueWebhooks_LastAttempt_DeadLetter() {
+	require := suite.Require()
+	now := time.Now()
+	webhook := &domain.Webhook{ID: 3, Active: true}
+	delivery := domain.WebhookDelivery{ID: 7, WebhookID: 3, Attempts: domain.MaxWebhookAttempts - 1}
+	sendError := errors.New("connection refused")
+
+	suite.webhookRepo.On("ClaimDueDeliveries", mock.Anything, now, time.Minute, 10).Return([]domain.WebhookDelivery{delivery}, nil).Once()
+	suite.webhookRepo.On("FindByID", mock.Anything, uint(3)).Return(webhook, nil).Once()
+	suite.sender.On("Send", mock.Anything, *webhook, delivery).Return(0, sendError).Once()
+	suite.webhookRepo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		return d.Status == domain.WDSDead && d.Attempts == domain.MaxWebhookAttempts && d.LastError == "connection refused"
+	})).Return(nil).Once()
+	delivered, err := suite.webhookService.DeliverDueWebhooks(context.Background(), now, 10)
+
+	require.NoError(err)
+	require.Equal(1, delivered)
+	suite.webhookRepo.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookServiceTestSuite) TestDeliverDueWebhooks_InactiveWebhook_DeadLetter() {
+	require := suite.Require()
+	now := time.Now()
+	delivery := domain.WebhookDelivery{ID: 7, WebhookID: 3}
+
+	suite.webhookRepo.On("ClaimDueDeliveries", mock.Anything, now, time.Minute, 10).Return([]domain.WebhookDelivery{delivery}, nil).Once()
+	suite.webhookRepo.On("FindByID", mock.Anything, uint(3)).Return(&domain.Webhook{ID: 3, Active: false}, nil).Once()
+	suite.webhookRepo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(d domain.WebhookDelivery) bool {
+		return d.Status == domain.WDSDead && d.Attempts == 0
+	})).Return(nil).Once()
+	delivered, err := suite.webhookService.DeliverDueWebhooks(context.Background(), now, 10)
+
+	require.NoError(err)
+	require.Equal(1, delivered)
+	suite.sender.AssertNotCalled(suite.T(), "Send", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *WebhookServiceTestSuite) TestDeliverDueWebhooks_ClaimError_Failure() {
+	require := suite.Require()
+	now := time.Now()
+	expectedError := errors.New("repository error")
+
+	suite.webhookRepo.On("ClaimDueDeliveries", mock.Anything, now, time.Minute, 10).Return(nil, expectedError).Once()
+	delivered, err := suite.webhookService.DeliverDueWebhooks(context.Background(), now, 10)
+
+	require.ErrorIs(err, expectedError)
+	require.Zero(delivered)
+}
+
+func TestWebhookService(t *testing.T) {
+	suite.Run(t, new(WebhookServiceTestSuite))
+}
@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+// AuditChainWorker chains the queued audit events, the failed logins, into
+// the audit log in batches. A batch locks the chain once for all of its
+// events, so a burst of failed logins does not queue up on the lock that
+// every audited change takes.
+type AuditChainWorker struct {
+	loop
+}
+
+func NewAuditChainWorker(auditService service.AuditService, interval time.Duration, batchSize int) *AuditChainWorker {
+	chain := func(ctx context.Context, limit int) (int, error) {
+		return auditService.ChainPendingEvents(ctx, limit)
+	}
+
+	return &AuditChainWorker{loop: newLoop("audit chain", "%d audit events chained", interval, batchSize, chain)}
+}
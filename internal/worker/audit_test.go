@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+type AuditChainWorkerTestSuite struct {
+	suite.Suite
+	auditService *service.AuditServiceMock
+	worker       *AuditChainWorker
+}
+
+func (suite *AuditChainWorkerTestSuite) SetupTest() {
+	suite.auditService = new(service.AuditServiceMock)
+	suite.worker = NewAuditChainWorker(suite.auditService, time.Minute, 2)
+}
+
+func (suite *AuditChainWorkerTestSuite) TestRunOnce_Success() {
+	require := suite.Require()
+
+	suite.auditService.On("ChainPendingEvents", mock.Anything, 2).Return(2, nil).Once()
+	suite.auditService.On("ChainPendingEvents", mock.Anything, 2).Return(1, nil).Once()
+	chained, err := suite.worker.RunOnce(context.Background())
+
+	require.NoError(err)
+	require.Equal(3, chained)
+	suite.auditService.AssertExpectations(suite.T())
+}
+
+func (suite *AuditChainWorkerTestSuite) TestRunOnce_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("service error")
+
+	suite.auditService.On("ChainPendingEvents", mock.Anything, 2).Return(0, expectedError).Once()
+	chained, err := suite.worker.RunOnce(context.Background())
+
+	require.ErrorIs(err, expectedError)
+	require.Zero(chained)
+}
+
+func (suite *AuditChainWorkerTestSuite) TestNew_InvalidConfig_Failure() {
+	require := suite.Require()
+
+	require.Panics(func() { NewAuditChainWorker(suite.auditService, time.Minute, 0) })
+	require.Panics(func() { NewAuditChainWorker(suite.auditService, 0, 2) })
+}
+
+func TestAuditChainWorker(t *testing.T) {
+	suite.Run(t, new(AuditChainWorkerTestSuite))
+}
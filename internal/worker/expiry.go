@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+// ExpiryWorker moves pending gift cards past their expiry date to the expired
+// status in batches. Every card is expired in its own transaction that locks
+// the card and checks its status again, so several workers can run at once
+// without expiring a card twice or racing with a giftee deciding on it.
+type ExpiryWorker struct {
+	loop
+}
+
+func NewExpiryWorker(giftCardService service.GiftCardService, interval time.Duration, batchSize int) *ExpiryWorker {
+	expire := func(ctx context.Context, limit int) (int, error) {
+		return giftCardService.ExpireOverdueGiftCards(ctx, time.Now(), limit)
+	}
+
+	return &ExpiryWorker{loop: newLoop("gift card expiry", "%d gift cards expired", interval, batchSize, expire)}
+}
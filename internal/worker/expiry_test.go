@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+type ExpiryWorkerTestSuite struct {
+	suite.Suite
+	giftCardService *service.GiftCardServiceMock
+	worker          *ExpiryWorker
+}
+
+func (suite *ExpiryWorkerTestSuite) SetupTest() {
+	suite.giftCardService = new(service.GiftCardServiceMock)
+	suite.worker = NewExpiryWorker(suite.giftCardService, time.Minute, 2)
+}
+
+func (suite *ExpiryWorkerTestSuite) TestRunOnce_Success() {
+	require := suite.Require()
+
+	suite.giftCardService.On("ExpireOverdueGiftCards", mock.Anything, mock.Anything, 2).Return(2, nil).Once()
+	suite.giftCardService.On("ExpireOverdueGiftCards", mock.Anything, mock.Anything, 2).Return(1, nil).Once()
+	expired, err := suite.worker.RunOnce(context.Background())
+
+	require.NoError(err)
+	require.Equal(3, expired)
+	suite.giftCardService.AssertExpectations(suite.T())
+}
+
+func (suite *ExpiryWorkerTestSuite) TestRunOnce_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("service error")
+
+	suite.giftCardService.On("ExpireOverdueGiftCards", mock.Anything, mock.Anything, 2).Return(2, nil).Once()
+	suite.giftCardService.On("ExpireOverdueGiftCards", mock.Anything, mock.Anything, 2).Return(1, expectedError).Once()
+	expired, err := suite.worker.RunOnce(context.Background())
+
+	require.ErrorIs(err, expectedError)
+	require.Equal(3, expired)
+}
+
+// TestRun_Success checks that a run that failed is logged and retried on
+// the next tick, and that Run returns once ctx is done.
+func (suite *ExpiryWorkerTestSuite) TestRun_Success() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	suite.worker = NewExpiryWorker(suite.giftCardService, time.Millisecond, 2)
+
+	suite.giftCardService.On("ExpireOverdueGiftCards", mock.Anything, mock.Anything, 2).Return(0, errors.New("service error")).Once()
+	suite.giftCardService.On("ExpireOverdueGiftCards", mock.Anything, mock.Anything, 2).Return(0, nil).Run(func(mock.Arguments) { cancel() })
+	suite.worker.Run(ctx)
+
+	suite.giftCardService.AssertExpectations(suite.T())
+}
+
+func (suite *ExpiryWorkerTestSuite) TestNew_InvalidConfig_Failure() {
+	require := suite.Require()
+
+	require.Panics(func() { NewExpiryWorker(suite.giftCardService, time.Minute, 0) })
+	require.Panics(func() { NewExpiryWorker(suite.giftCardService, 0, 2) })
+}
+
+func TestExpiryWorker(t *testing.T) {
+	suite.Run(t, new(ExpiryWorkerTestSuite))
+}
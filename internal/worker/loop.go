@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// loop runs batch every interval until ctx is done. A run calls batch with
+// batchSize until a batch comes back smaller, so that a backlog is worked off
+// without waiting for the next tick. name is the name of the work in the log
+// and report formats the number of items a run handled.
+type loop struct {
+	name      string
+	report    string
+	interval  time.Duration
+	batchSize int
+	batch     func(ctx context.Context, limit int) (int, error)
+}
+
+// newLoop panics on an interval or a batch size that is not positive, a
+// ticker cannot tick without an interval and a run would never end without a
+// batch size. The config is validated before the workers are created.
+func newLoop(name, report string, interval time.Duration, batchSize int, batch func(ctx context.Context, limit int) (int, error)) loop {
+	if interval <= 0 || batchSize <= 0 {
+		panic(fmt.Sprintf("%s needs a positive interval and batch size, got %s and %d", name, interval, batchSize))
+	}
+
+	return loop{name: name, report: report, interval: interval, batchSize: batchSize, batch: batch}
+}
+
+// Run runs the work every interval until ctx is done.
+func (l loop) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := l.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Errorf("%s failed: %v", l.name, err)
+		}
+
+		if n > 0 {
+			log.Infof(l.report, n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs batches until a batch comes back smaller than the batch size
+// and returns how many items they handled.
+func (l loop) RunOnce(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := l.batch(ctx, l.batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+
+		if n < l.batchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+// RelayWorker publishes the events recorded in the outbox. An event is marked
+// published only after the publisher accepted it, so events are delivered at
+// least once and a relay that fails is retried on the next tick.
+type RelayWorker struct {
+	loop
+}
+
+func NewRelayWorker(outboxService service.OutboxService, interval time.Duration, batchSize int) *RelayWorker {
+	return &RelayWorker{loop: newLoop("outbox relay", "%d events published", interval, batchSize, outboxService.RelayEvents)}
+}
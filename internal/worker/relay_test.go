@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+type RelayWorkerTestSuite struct {
+	suite.Suite
+	outboxService *service.OutboxServiceMock
+	worker        *RelayWorker
+}
+
+func (suite *RelayWorkerTestSuite) SetupTest() {
+	suite.outboxService = new(service.OutboxServiceMock)
+	suite.worker = NewRelayWorker(suite.outboxService, time.Second, 2)
+}
+
+func (suite *RelayWorkerTestSuite) TestRunOnce_Success() {
+	require := suite.Require()
+
+	suite.outboxService.On("RelayEvents", mock.Anything, 2).Return(2, nil).Once()
+	suite.outboxService.On("RelayEvents", mock.Anything, 2).Return(0, nil).Once()
+	published, err := suite.worker.RunOnce(context.Background())
+
+	require.NoError(err)
+	require.Equal(2, published)
+	suite.outboxService.AssertExpectations(suite.T())
+}
+
+func (suite *RelayWorkerTestSuite) TestRunOnce_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("service error")
+
+	suite.outboxService.On("RelayEvents", mock.Anything, 2).Return(1, expectedError).Once()
+	published, err := suite.worker.RunOnce(context.Background())
+
+	require.ErrorIs(err, expectedError)
+	require.Equal(1, published)
+}
+
+func (suite *RelayWorkerTestSuite) TestRun_Success() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	suite.worker = NewRelayWorker(suite.outboxService, time.Millisecond, 2)
+
+	suite.outboxService.On("RelayEvents", mock.Anything, 2).Return(0, errors.New("service error")).Once()
+	suite.outboxService.On("RelayEvents", mock.Anything, 2).Return(0, nil).Run(func(mock.Arguments) { cancel() })
+	suite.worker.Run(ctx)
+
+	suite.outboxService.AssertExpectations(suite.T())
+}
+
+func (suite *RelayWorkerTestSuite) TestNew_InvalidConfig_Failure() {
+	require := suite.Require()
+
+	require.Panics(func() { NewRelayWorker(suite.outboxService, time.Minute, 0) })
+	require.Panics(func() { NewRelayWorker(suite.outboxService, 0, 2) })
+}
+
+func TestRelayWorker(t *testing.T) {
+	suite.Run(t, new(RelayWorkerTestSuite))
+}
@@ -0,0 +1,23 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+// WebhookWorker sends the due webhook deliveries. Deliveries are claimed
+// before they are sent, so several workers can run at once without sending a
+// delivery twice.
+type WebhookWorker struct {
+	loop
+}
+
+func NewWebhookWorker(webhookService service.WebhookService, interval time.Duration, batchSize int) *WebhookWorker {
+	deliver := func(ctx context.Context, limit int) (int, error) {
+		return webhookService.DeliverDueWebhooks(ctx, time.Now(), limit)
+	}
+
+	return &WebhookWorker{loop: newLoop("webhook delivery", "%d webhook deliveries attempted", interval, batchSize, deliver)}
+}
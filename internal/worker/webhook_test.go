@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/jmehdipour/gift-card/internal/service"
+)
+
+type WebhookWorkerTestSuite struct {
+	suite.Suite
+	webhookService *service.WebhookServiceMock
+	worker         *WebhookWorker
+}
+
+func (suite *WebhookWorkerTestSuite) SetupTest() {
+	suite.webhookService = new(service.WebhookServiceMock)
+	suite.worker = NewWebhookWorker(suite.webhookService, time.Second, 2)
+}
+
+func (suite *WebhookWorkerTestSuite) TestRunOnce_Success() {
+	require := suite.Require()
+
+	suite.webhookService.On("DeliverDueWebhooks", mock.Anything, mock.Anything, 2).Return(2, nil).Once()
+	suite.webhookService.On("DeliverDueWebhooks", mock.Anything, mock.Anything, 2).Return(1, nil).Once()
+	delivered, err := suite.worker.RunOnce(context.Background())
+
+	require.NoError(err)
+	require.Equal(3, delivered)
+	suite.webhookService.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookWorkerTestSuite) TestRunOnce_Failure() {
+	require := suite.Require()
+	expectedError := errors.New("service error")
+
+	suite.webhookService.On("DeliverDueWebhooks", mock.Anything, mock.Anything, 2).Return(0, expectedError).Once()
+	delivered, err := suite.worker.RunOnce(context.Background())
+
+	require.ErrorIs(err, expectedError)
+	require.Zero(delivered)
+}
+
+func (suite *WebhookWorkerTestSuite) TestRun_Success() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	suite.worker = NewWebhookWorker(suite.webhookService, time.Millisecond, 2)
+
+	suite.webhookService.On("DeliverDueWebhooks", mock.Anything, mock.Anything, 2).Return(0, errors.New("service error")).Once()
+	suite.webhookService.On("DeliverDueWebhooks", mock.Anything, mock.Anything, 2).Return(0, nil).Run(func(mock.Arguments) { cancel() })
+	suite.worker.Run(ctx)
+
+	suite.webhookService.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookWorkerTestSuite) TestNew_InvalidConfig_Failure() {
+	require := suite.Require()
+
+	require.Panics(func() { NewWebhookWorker(suite.webhookService, time.Minute, 0) })
+	require.Panics(func() { NewWebhookWorker(suite.webhookService, 0, 2) })
+}
+
+func TestWebhookWorker(t *testing.T) {
+	suite.Run(t, new(WebhookWorkerTestSuite))
+}